@@ -0,0 +1,124 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	cfg "api-gateway/config"
+	"api-gateway/logger"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	ProductViewTopic    = "product_views"
+	HistoryMergeTopic   = "browsing_history_merge"
+	productViewQueueLen = 1024
+)
+
+// ProductViewEvent: một lượt xem trang chi tiết sản phẩm.
+// user_id rỗng nghĩa là khách chưa đăng nhập, khi đó chỉ định danh được qua device_id
+type ProductViewEvent struct {
+	ProductID string    `json:"product_id"`
+	UserID    string    `json:"user_id,omitempty"`
+	DeviceID  string    `json:"device_id,omitempty"`
+	Platform  string    `json:"platform,omitempty"`
+	ViewedAt  time.Time `json:"viewed_at"`
+}
+
+// HistoryMergeEvent gửi khi login để gộp lịch sử xem của device vào tài khoản
+type HistoryMergeEvent struct {
+	UserID   string    `json:"user_id"`
+	DeviceID string    `json:"device_id"`
+	MergedAt time.Time `json:"merged_at"`
+}
+
+type queuedMessage struct {
+	topic string
+	key   string
+	value interface{}
+}
+
+var (
+	writer *kafka.Writer
+	queue  chan queuedMessage
+	done   chan struct{}
+)
+
+// InitProducer bật producer nếu có KAFKA_BROKERS, không có thì mọi Publish đều bị bỏ qua
+func InitProducer() {
+	brokers := strings.Split(cfg.Get("KAFKA_BROKERS", ""), ",")
+	if len(brokers) == 0 || strings.TrimSpace(brokers[0]) == "" {
+		logger.Info("KAFKA_BROKERS not set, browsing events are disabled")
+		return
+	}
+
+	writer = &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Balancer:     &kafka.Hash{},
+		BatchTimeout: 50 * time.Millisecond,
+		RequiredAcks: kafka.RequireOne,
+	}
+	queue = make(chan queuedMessage, productViewQueueLen)
+	done = make(chan struct{})
+
+	go func() {
+		defer close(done)
+		for msg := range queue {
+			value, err := json.Marshal(msg.value)
+			if err != nil {
+				logger.Err("Failed to marshal kafka message", err, logger.Str("topic", msg.topic))
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			err = writer.WriteMessages(ctx, kafka.Message{
+				Topic: msg.topic,
+				Key:   []byte(msg.key),
+				Value: value,
+			})
+			cancel()
+			if err != nil {
+				logger.Err("Failed to publish kafka message", err, logger.Str("topic", msg.topic))
+			}
+		}
+	}()
+}
+
+// Close đợi queue gửi hết rồi đóng writer
+func Close() {
+	if writer == nil {
+		return
+	}
+	close(queue)
+	<-done
+	writer.Close()
+}
+
+// enqueue không bao giờ block request, queue đầy thì bỏ event
+func enqueue(topic, key string, value interface{}) {
+	if writer == nil {
+		return
+	}
+	select {
+	case queue <- queuedMessage{topic: topic, key: key, value: value}:
+	default:
+		logger.Debug("Kafka queue is full, dropping event", logger.Str("topic", topic))
+	}
+}
+
+// PublishProductView key theo product_id để consumer xếp hạng độ phổ biến đếm theo partition
+func PublishProductView(event ProductViewEvent) {
+	if event.ProductID == "" || (event.UserID == "" && event.DeviceID == "") {
+		return
+	}
+	enqueue(ProductViewTopic, event.ProductID, event)
+}
+
+func PublishHistoryMerge(event HistoryMergeEvent) {
+	if event.UserID == "" || event.DeviceID == "" {
+		return
+	}
+	enqueue(HistoryMergeTopic, event.DeviceID, event)
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"api-gateway/helpers"
+	"api-gateway/kafka"
 	"api-gateway/logger"
 	"api-gateway/middleware"
 	"api-gateway/redisdb"
@@ -27,6 +28,9 @@ func main() {
 	redisdb.InitRedis()
 	defer redisdb.RedisClient.Close()
 
+	kafka.InitProducer()
+	defer kafka.Close()
+
 	ginrouter := gin.Default()
 	ginrouter.Use(middleware.CORSMiddleware())
 	ginrouter.Use(middleware.RateLimitMiddleware(redisdb.RedisClient, 100, 20*time.Second, "rate_limit"))
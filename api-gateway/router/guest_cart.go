@@ -0,0 +1,83 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"api-gateway/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ForwardGuestRequest chuyển request giỏ hàng của khách chưa đăng nhập sang cart-service.
+// Giỏ định danh bằng Device-Id của DeviceInfoMiddleware, không bao giờ chuyển X-User-ID từ client.
+func ForwardGuestRequest(c *gin.Context, serviceURL string, method string) {
+	deviceID := c.GetHeader("Device-Id")
+	if deviceID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Device ID not found"})
+		return
+	}
+
+	var bodyBytes []byte
+	if c.Request.Body != nil {
+		bodyBytes, _ = io.ReadAll(c.Request.Body)
+	}
+
+	req, err := http.NewRequest(method, serviceURL, bytes.NewReader(bodyBytes))
+	if err != nil {
+		logger.Err("Error creating request", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to create request"})
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Device-Id", deviceID)
+
+	client := &http.Client{Timeout: time.Second * 30}
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Err("Error in request", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to connect to service"})
+		return
+	}
+	defer resp.Body.Close()
+
+	responseBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Err("Error reading response", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error reading response"})
+		return
+	}
+
+	c.Data(resp.StatusCode, resp.Header.Get("Content-Type"), responseBytes)
+}
+
+// mergeGuestCart gộp giỏ guest của device vào giỏ user, trả về số item đã gộp và các conflict
+func mergeGuestCart(userID, deviceID string) (json.RawMessage, error) {
+	req, err := http.NewRequest("POST", "http://cart-service:8083/cart/merge", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", userID)
+	req.Header.Set("X-Device-Id", deviceID)
+
+	client := &http.Client{Timeout: time.Second * 5}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cart-service returned %d: %s", resp.StatusCode, string(body))
+	}
+	return json.RawMessage(body), nil
+}
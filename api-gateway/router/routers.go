@@ -14,6 +14,8 @@ import (
 	"time"
 
 	// "github.com/Dattt2k2/golang-project/api-gateway/middleware"
+	helper "api-gateway/helpers"
+	"api-gateway/kafka"
 	"api-gateway/logger"
 	"api-gateway/middleware"
 
@@ -105,10 +107,16 @@ func ForwardWebSocketToService(c *gin.Context, serviceURL string) {
 
 var productIDRe = regexp.MustCompile(`/products/[^/]+$`)
 var reviewIDRe = regexp.MustCompile(`/v1/products/[^/]+$`)
+var identityHeaders = map[string]bool{"X-User-Id": true, "X-Email": true, "X-Role": true}
+
+var publicGetRe = regexp.MustCompile(`/categories(/|$)|/products/get/category/|/products/get/[^/?]+(/(price-history|components))?(\?|$)|/products/questions/|/wishlists/shared/|/products/[^/]+/recommendations`)
 
 func ForwardRequestToService(c *gin.Context, serviceURL string, method string, contentType string) {
-	// Handle public routes without auth
-	if strings.HasSuffix(serviceURL, "/products/get") || strings.HasSuffix(serviceURL, "/search") || strings.HasSuffix(serviceURL, "/advanced-search") || productIDRe.MatchString(serviceURL) || reviewIDRe.MatchString(serviceURL) {
+	// Handle public routes without auth.
+	// Request đã qua AuthMiddleware thì luôn đi nhánh dưới để X-User-ID lấy từ token, không đoán theo URL đích
+	// (vd /products/drafts của seller khớp productIDRe)
+	_, authenticated := c.Get("uid")
+	if !authenticated && (strings.HasSuffix(serviceURL, "/products/get") || strings.HasSuffix(serviceURL, "/search") || strings.HasSuffix(serviceURL, "/advanced-search") || productIDRe.MatchString(serviceURL) || reviewIDRe.MatchString(serviceURL) || (method == "GET" && publicGetRe.MatchString(serviceURL))) {
 		client := &http.Client{Timeout: time.Second * 30}
 		req, err := http.NewRequest(method, serviceURL, nil)
 		if err != nil {
@@ -118,6 +126,10 @@ func ForwardRequestToService(c *gin.Context, serviceURL string, method string, c
 		}
 
 		for k, v := range c.Request.Header {
+			// header định danh chỉ do gateway set từ token, không nhận từ client
+			if identityHeaders[http.CanonicalHeaderKey(k)] {
+				continue
+			}
 			for _, vv := range v {
 				req.Header.Add(k, vv)
 			}
@@ -270,6 +282,37 @@ func ForwardRequestToService(c *gin.Context, serviceURL string, method string, c
 	c.Data(resp.StatusCode, resp.Header.Get("Content-Type"), responseBytes)
 }
 
+// optionalUserID: user id từ token nếu có và hợp lệ, route public không bắt buộc đăng nhập
+func optionalUserID(c *gin.Context) string {
+	tokenString, err := helper.ExtractBearerToken(c)
+	if err != nil {
+		tokenString, _ = c.Cookie("auth_token")
+	}
+	if tokenString == "" {
+		return ""
+	}
+	if claims, msg := helper.ValidateToken(tokenString); msg == "" {
+		return claims.Uid
+	}
+	return ""
+}
+
+// recordProductView ghi nhận lượt xem bất đồng bộ qua kafka, không ảnh hưởng response.
+// Route public nên token là tuỳ chọn, không có thì chỉ dùng Device-Id của DeviceInfoMiddleware
+func recordProductView(c *gin.Context) {
+	if c.Writer.Status() != http.StatusOK {
+		return
+	}
+
+	kafka.PublishProductView(kafka.ProductViewEvent{
+		ProductID: c.Param("id"),
+		UserID:    optionalUserID(c),
+		DeviceID:  c.GetHeader("Device-Id"),
+		Platform:  c.GetHeader("X-Platform"),
+		ViewedAt:  time.Now(),
+	})
+}
+
 func SetupRouter(router *gin.Engine) {
 	var client = &http.Client{}
 
@@ -369,6 +412,21 @@ func SetupRouter(router *gin.Engine) {
 			c.Set("role", loginResponse.User_type)
 			c.Set("uid", loginResponse.Uid)
 
+			// Gộp lịch sử xem lúc chưa đăng nhập trên device này vào tài khoản
+			kafka.PublishHistoryMerge(kafka.HistoryMergeEvent{
+				UserID:   loginResponse.Uid,
+				DeviceID: c.GetHeader("Device-Id"),
+				MergedAt: time.Now(),
+			})
+
+			// Gộp giỏ guest ngay để trả conflict cho client, lỗi thì không chặn đăng nhập
+			var cartMerge json.RawMessage
+			if loginResponse.User_type == "USER" {
+				cartMerge, err = mergeGuestCart(loginResponse.Uid, c.GetHeader("Device-Id"))
+				if err != nil {
+					logger.Err("Failed to merge guest cart", err, logger.Str("user_id", loginResponse.Uid))
+				}
+			}
 			// c.SetCookie("auth_token", loginResponse.Token, 60*60*24*7, "/", "", c.Request.TLS != nil, true)
 			// c.SetCookie("refresh_token", loginResponse.RefreshToken, 60*60*24*30, "/", "", c.Request.TLS != nil, true)
 
@@ -380,6 +438,7 @@ func SetupRouter(router *gin.Engine) {
 				"uid":           loginResponse.Uid,
 				"access_token":  loginResponse.Token,
 				"refresh_token": loginResponse.RefreshToken,
+				"cart_merge":    cartMerge,
 			})
 		})
 
@@ -463,16 +522,78 @@ func SetupRouter(router *gin.Engine) {
 			ForwardRequestToService(c, "http://product-service:8082/products/search?name="+c.Query("name"), "GET", "application/json")
 		})
 		publicRoutes.GET("/products-info/:id", func(c *gin.Context) {
-			ForwardRequestToService(c, "http://product-service:8082/products/"+c.Param("id"), "GET", "application/json")
+			ForwardRequestToService(c, "http://product-service:8082/products/get/"+c.Param("id"), "GET", "application/json")
+			recordProductView(c)
 		})
+		// Tìm kiếm: phân trang, sort, filter, facet đều qua query string
 		publicRoutes.GET("/search", func(c *gin.Context) {
-			ForwardRequestToService(c, "http://search-service:8086/search?query="+c.Query("query"), "GET", "application/json")
+			ForwardSearchRequest(c, "http://search-service:8086/search?"+c.Request.URL.RawQuery, "GET")
 		})
 		publicRoutes.GET("/advanced-search", func(c *gin.Context) {
-			ForwardRequestToService(c, "http://search-service:8086/advanced-search?query="+c.Query("query")+"&category="+c.Query("category")+"&brand="+c.Query("brand"), "GET", "application/json")
+			ForwardSearchRequest(c, "http://search-service:8086/search/advanced?"+c.Request.URL.RawQuery, "GET")
+		})
+		publicRoutes.GET("/search/autocomplete", func(c *gin.Context) {
+			ForwardSearchRequest(c, "http://search-service:8086/search/autocomplete?"+c.Request.URL.RawQuery, "GET")
+		})
+		// người dùng bấm vào một kết quả tìm kiếm, body có query_id từ response của /search
+		publicRoutes.POST("/search/click", func(c *gin.Context) {
+			ForwardSearchRequest(c, "http://search-service:8086/search/click", "POST")
+		})
+		// Storefront của shop: hồ sơ + số liệu, và sản phẩm đang bán (cùng tham số với /search)
+		publicRoutes.GET("/vendors/:vendor_id", func(c *gin.Context) {
+			ForwardSearchRequest(c, "http://search-service:8086/search/vendors/"+c.Param("vendor_id"), "GET")
+		})
+		publicRoutes.GET("/vendors/:vendor_id/products", func(c *gin.Context) {
+			ForwardSearchRequest(c, "http://search-service:8086/search/vendors/"+c.Param("vendor_id")+"/products?"+c.Request.URL.RawQuery, "GET")
 		})
 		publicRoutes.GET("/products/category/:category", func(c *gin.Context) {
-			ForwardRequestToService(c, "http://product-service:8082/products/category/"+c.Param("category"), "GET", "application/json")
+			ForwardRequestToService(c, "http://product-service:8082/products/get/category/"+c.Param("category")+"?"+c.Request.URL.RawQuery, "GET", "application/json")
+		})
+
+		publicRoutes.GET("/products-info/:id/price-history", func(c *gin.Context) {
+			ForwardRequestToService(c, "http://product-service:8082/products/get/"+c.Param("id")+"/price-history?"+c.Request.URL.RawQuery, "GET", "application/json")
+		})
+		// Giỏ hàng guest, định danh bằng device_id
+		publicRoutes.POST("/guest/cart/add/:id", func(c *gin.Context) {
+			ForwardGuestRequest(c, "http://cart-service:8083/cart/guest/add/"+c.Param("id"), "POST")
+		})
+		publicRoutes.GET("/guest/cart/get", func(c *gin.Context) {
+			ForwardGuestRequest(c, "http://cart-service:8083/cart/guest/get", "GET")
+		})
+		publicRoutes.PUT("/guest/cart/update/:id", func(c *gin.Context) {
+			ForwardGuestRequest(c, "http://cart-service:8083/cart/guest/update/"+c.Param("id"), "PUT")
+		})
+		publicRoutes.DELETE("/guest/cart/delete/:id", func(c *gin.Context) {
+			ForwardGuestRequest(c, "http://cart-service:8083/cart/guest/delete/"+c.Param("id"), "DELETE")
+		})
+		publicRoutes.DELETE("/guest/cart/clear", func(c *gin.Context) {
+			ForwardGuestRequest(c, "http://cart-service:8083/cart/guest/clear", "DELETE")
+		})
+
+		publicRoutes.GET("/products-info/:id/components", func(c *gin.Context) {
+			ForwardRequestToService(c, "http://product-service:8082/products/get/"+c.Param("id")+"/components", "GET", "application/json")
+		})
+		publicRoutes.GET("/products-info/:id/recommendations", func(c *gin.Context) {
+			ForwardRequestToService(c, "http://product-service:8082/products/"+c.Param("id")+"/recommendations?"+c.Request.URL.RawQuery, "GET", "application/json")
+		})
+		publicRoutes.GET("/products-info/:id/questions", func(c *gin.Context) {
+			ForwardRequestToService(c, "http://review-service:8089/products/questions/"+c.Param("id")+"?"+c.Request.URL.RawQuery, "GET", "application/json")
+		})
+
+		// Category tree
+		publicRoutes.GET("/categories/tree", func(c *gin.Context) {
+			ForwardRequestToService(c, "http://product-service:8082/categories/tree", "GET", "application/json")
+		})
+		publicRoutes.GET("/categories/:slug", func(c *gin.Context) {
+			ForwardRequestToService(c, "http://product-service:8082/categories/"+c.Param("slug"), "GET", "application/json")
+		})
+		publicRoutes.GET("/categories/:slug/breadcrumbs", func(c *gin.Context) {
+			ForwardRequestToService(c, "http://product-service:8082/categories/"+c.Param("slug")+"/breadcrumbs", "GET", "application/json")
+		})
+
+		// Wishlist được chia sẻ qua link
+		publicRoutes.GET("/wishlists/shared/:token", func(c *gin.Context) {
+			ForwardRequestToService(c, "http://user-service:8095/wishlists/shared/"+c.Param("token"), "GET", "application/json")
 		})
 	}
 
@@ -520,6 +641,28 @@ func SetupRouter(router *gin.Engine) {
 			userGroup.DELETE("/cart/delete/:id", func(c *gin.Context) {
 				ForwardRequestToService(c, "http://cart-service:8083/cart/delete/"+c.Param("id"), "DELETE", "application/json")
 			})
+			userGroup.POST("/cart/merge", func(c *gin.Context) {
+				uid, _ := c.Get("uid")
+				result, err := mergeGuestCart(fmt.Sprint(uid), c.GetHeader("Device-Id"))
+				if err != nil {
+					logger.Err("Failed to merge guest cart", err)
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge guest cart"})
+					return
+				}
+				c.Data(http.StatusOK, "application/json", result)
+			})
+			userGroup.PUT("/cart/update/:id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://cart-service:8083/cart/update/"+c.Param("id"), "PUT", "application/json")
+			})
+			userGroup.POST("/cart/save-for-later/:id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://cart-service:8083/cart/save-for-later/"+c.Param("id"), "POST", "application/json")
+			})
+			userGroup.POST("/cart/move-to-cart/:id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://cart-service:8083/cart/move-to-cart/"+c.Param("id"), "POST", "application/json")
+			})
+			userGroup.DELETE("/cart/saved/:id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://cart-service:8083/cart/saved/"+c.Param("id"), "DELETE", "application/json")
+			})
 
 			// Order routes
 			userGroup.POST("/order/cart", func(c *gin.Context) {
@@ -534,6 +677,13 @@ func SetupRouter(router *gin.Engine) {
 			userGroup.POST("/order/cancel/:order_id", func(c *gin.Context) {
 				ForwardRequestToService(c, "http://order-service:8084/user/order/cancel/"+c.Param("order_id"), "POST", "application/json")
 			})
+			// Sản phẩm số: license key + link tải có giới hạn lượt
+			userGroup.GET("/order/:order_id/downloads", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://order-service:8084/order/"+c.Param("order_id")+"/downloads", "GET", "application/json")
+			})
+			userGroup.POST("/order/:order_id/downloads/:product_id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://order-service:8084/order/"+c.Param("order_id")+"/downloads/"+c.Param("product_id"), "POST", "application/json")
+			})
 
 			// Review routes
 			userGroup.POST("/product/review/:product_id", func(c *gin.Context) {
@@ -542,10 +692,83 @@ func SetupRouter(router *gin.Engine) {
 			userGroup.GET("/product/review/:product_id", func(c *gin.Context) {
 				ForwardRequestToService(c, "http://review-service:8089/v1/products/"+c.Param("product_id")+"/reviews", "GET", "application/json")
 			})
+
+			// Q&A routes
+			userGroup.POST("/product/question/:product_id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://review-service:8089/products/questions/"+c.Param("product_id"), "POST", "application/json")
+			})
+			userGroup.POST("/question/:question_id/answers", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://review-service:8089/questions/"+c.Param("question_id")+"/answers", "POST", "application/json")
+			})
+			userGroup.POST("/question/:question_id/answers/:answer_id/upvote", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://review-service:8089/questions/"+c.Param("question_id")+"/answers/"+c.Param("answer_id")+"/upvote", "POST", "application/json")
+			})
 			userGroup.POST("/upload/presigned-url", func(c *gin.Context) {
 				ForwardRequestToService(c, "http://product-service:8082/upload/presigned-url", "POST", "application/json")
 			})
 
+			// Gợi ý cá nhân hoá
+			userGroup.GET("/recommendations", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/recommendations/for-you?"+c.Request.URL.RawQuery, "GET", "application/json")
+			})
+
+			// Wishlist routes
+			// Browsing history
+			userGroup.GET("/history", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://user-service:8095/me/history?"+c.Request.URL.RawQuery, "GET", "application/json")
+			})
+			userGroup.DELETE("/history", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://user-service:8095/me/history", "DELETE", "application/json")
+			})
+			userGroup.DELETE("/history/:product_id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://user-service:8095/me/history/"+c.Param("product_id"), "DELETE", "application/json")
+			})
+
+			userGroup.POST("/wishlists", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://user-service:8095/me/wishlists", "POST", "application/json")
+			})
+			userGroup.GET("/wishlists", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://user-service:8095/me/wishlists", "GET", "application/json")
+			})
+			userGroup.GET("/wishlists/:wishlist_id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://user-service:8095/me/wishlists/"+c.Param("wishlist_id"), "GET", "application/json")
+			})
+			userGroup.PUT("/wishlists/:wishlist_id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://user-service:8095/me/wishlists/"+c.Param("wishlist_id"), "PUT", "application/json")
+			})
+			userGroup.DELETE("/wishlists/:wishlist_id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://user-service:8095/me/wishlists/"+c.Param("wishlist_id"), "DELETE", "application/json")
+			})
+			userGroup.POST("/wishlists/:wishlist_id/share", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://user-service:8095/me/wishlists/"+c.Param("wishlist_id")+"/share", "POST", "application/json")
+			})
+			userGroup.DELETE("/wishlists/:wishlist_id/share", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://user-service:8095/me/wishlists/"+c.Param("wishlist_id")+"/share", "DELETE", "application/json")
+			})
+			userGroup.POST("/wishlists/:wishlist_id/items", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://user-service:8095/me/wishlists/"+c.Param("wishlist_id")+"/items", "POST", "application/json")
+			})
+			userGroup.PUT("/wishlists/:wishlist_id/items/:item_id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://user-service:8095/me/wishlists/"+c.Param("wishlist_id")+"/items/"+c.Param("item_id"), "PUT", "application/json")
+			})
+			userGroup.DELETE("/wishlists/:wishlist_id/items/:item_id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://user-service:8095/me/wishlists/"+c.Param("wishlist_id")+"/items/"+c.Param("item_id"), "DELETE", "application/json")
+			})
+
+			// Shop đang follow và feed
+			userGroup.GET("/following", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://user-service:8095/me/following", "GET", "application/json")
+			})
+			userGroup.PUT("/following/:vendor_id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://user-service:8095/me/following/"+c.Param("vendor_id"), "PUT", "application/json")
+			})
+			userGroup.DELETE("/following/:vendor_id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://user-service:8095/me/following/"+c.Param("vendor_id"), "DELETE", "application/json")
+			})
+			userGroup.GET("/feed", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://user-service:8095/me/feed?"+c.Request.URL.RawQuery, "GET", "application/json")
+			})
+
 			// Address routes
 			userGroup.POST("/address", func(c *gin.Context) {
 				ForwardRequestToService(c, "http://user-service:8085/users/addresses", "POST", "application/json")
@@ -578,10 +801,31 @@ func SetupRouter(router *gin.Engine) {
 				ForwardRequestToService(c, "http://product-service:8082/products/add", "POST", "application/json")
 			})
 
+			sellerGroup.POST("/products/bundles", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/products/bundles/add", "POST", "application/json")
+			})
+			sellerGroup.POST("/products/digital/upload-url", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/products/digital/upload-url", "POST", "application/json")
+			})
+			sellerGroup.POST("/products/digital", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/products/digital/add", "POST", "application/json")
+			})
+			sellerGroup.POST("/products/digital/:id/license-keys", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/products/digital/"+c.Param("id")+"/license-keys", "POST", "application/json")
+			})
+
 			sellerGroup.GET("/products", func(c *gin.Context) {
 				ForwardRequestToService(c, "http://product-service:8082/products/user", "GET", "application/json")
 			})
 
+			// hồ sơ storefront của shop
+			sellerGroup.GET("/storefront", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://user-service:8095/me/storefront", "GET", "application/json")
+			})
+			sellerGroup.PUT("/storefront", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://user-service:8095/me/storefront", "PUT", "application/json")
+			})
+
 			sellerGroup.DELETE("/products/delete/:id", func(c *gin.Context) {
 				ForwardRequestToService(c, "http://product-service:8082/products/delete/"+c.Param("id"), "DELETE", "application/json")
 			})
@@ -589,6 +833,44 @@ func SetupRouter(router *gin.Engine) {
 			sellerGroup.PUT("/products/edit/:id", func(c *gin.Context) {
 				ForwardRequestToService(c, "http://product-service:8082/products/edit/"+c.Param("id"), "PUT", "application/json")
 			})
+			sellerGroup.POST("/products/:id/price-schedules", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/products/pricing/"+c.Param("id")+"/schedules", "POST", "application/json")
+			})
+			sellerGroup.GET("/products/:id/price-schedules", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/products/pricing/"+c.Param("id")+"/schedules", "GET", "application/json")
+			})
+			sellerGroup.DELETE("/price-schedules/:schedule_id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/products/pricing/schedules/"+c.Param("schedule_id"), "DELETE", "application/json")
+			})
+
+			// Draft và lịch sử chỉnh sửa sản phẩm
+			sellerGroup.POST("/products/drafts", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/products/drafts/add", "POST", "application/json")
+			})
+			sellerGroup.GET("/products/drafts", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/products/drafts", "GET", "application/json")
+			})
+			sellerGroup.GET("/products/drafts/:id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/products/drafts/"+c.Param("id"), "GET", "application/json")
+			})
+			sellerGroup.PUT("/products/drafts/:id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/products/drafts/"+c.Param("id"), "PUT", "application/json")
+			})
+			sellerGroup.DELETE("/products/drafts/:id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/products/drafts/"+c.Param("id"), "DELETE", "application/json")
+			})
+			sellerGroup.POST("/products/drafts/:id/publish", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/products/drafts/"+c.Param("id")+"/publish", "POST", "application/json")
+			})
+			sellerGroup.GET("/products/:id/revisions", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/products/revisions/"+c.Param("id")+"?"+c.Request.URL.RawQuery, "GET", "application/json")
+			})
+			sellerGroup.GET("/products/:id/revisions/diff", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/products/revisions/"+c.Param("id")+"/diff?"+c.Request.URL.RawQuery, "GET", "application/json")
+			})
+			sellerGroup.POST("/products/:id/revisions/:revision/rollback", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/products/revisions/"+c.Param("id")+"/rollback/"+c.Param("revision"), "POST", "application/json")
+			})
 			sellerGroup.GET("/products/images/:filename", func(ctx *gin.Context) {
 				ForwardRequestToService(ctx, "http://product-service:8082/images/"+ctx.Param("filename"), "GET", "image/png")
 			})
@@ -615,6 +897,55 @@ func SetupRouter(router *gin.Engine) {
 				ForwardRequestToService(c, "http://order-service:8084/admin/delete-order/"+c.Param("order_id"), "DELETE", "application/json")
 			})
 
+			// Category management
+			adminGroup.POST("/categories", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/categories", "POST", "application/json")
+			})
+			adminGroup.PUT("/categories/:id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/categories/"+c.Param("id"), "PUT", "application/json")
+			})
+			adminGroup.DELETE("/categories/:id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/categories/"+c.Param("id"), "DELETE", "application/json")
+			})
+			adminGroup.POST("/categories/migrate", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://product-service:8082/categories/migrate?"+c.Request.URL.RawQuery, "POST", "application/json")
+			})
+
+			// Synonym cho tìm kiếm sản phẩm
+			adminGroup.GET("/search/synonyms", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://search-service:8086/search/admin/synonyms", "GET", "application/json")
+			})
+			adminGroup.PUT("/search/synonyms", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://search-service:8086/search/admin/synonyms", "PUT", "application/json")
+			})
+			adminGroup.PUT("/search/synonyms/:id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://search-service:8086/search/admin/synonyms/"+c.Param("id"), "PUT", "application/json")
+			})
+			adminGroup.DELETE("/search/synonyms/:id", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://search-service:8086/search/admin/synonyms/"+c.Param("id"), "DELETE", "application/json")
+			})
+
+			// Dựng lại index tìm kiếm và chuyển alias
+			adminGroup.GET("/search/reindex", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://search-service:8086/search/admin/reindex", "GET", "application/json")
+			})
+			adminGroup.POST("/search/reindex", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://search-service:8086/search/admin/reindex", "POST", "application/json")
+			})
+			adminGroup.POST("/search/reindex/rollback", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://search-service:8086/search/admin/reindex/rollback", "POST", "application/json")
+			})
+
+			// Báo cáo tìm kiếm: top-queries, zero-results, low-ctr, trending
+			adminGroup.GET("/search/analytics/:report", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://search-service:8086/search/admin/analytics/"+c.Param("report")+"?"+c.Request.URL.RawQuery, "GET", "application/json")
+			})
+
+			// Thống kê nhắc nhở giỏ bỏ quên
+			adminGroup.GET("/carts/abandoned/stats", func(c *gin.Context) {
+				ForwardRequestToService(c, "http://cart-service:8083/cart/admin/abandoned/stats?"+c.Request.URL.RawQuery, "GET", "application/json")
+			})
+
 		}
 
 		// // Cart routes
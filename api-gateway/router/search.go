@@ -0,0 +1,44 @@
+package router
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"api-gateway/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ForwardSearchRequest chuyển request tìm kiếm public sang search-service.
+// X-User-ID chỉ lấy từ token đã xác thực; route /search đi thẳng qua Traefik thì header này bị xoá (strip-identity-headers).
+func ForwardSearchRequest(c *gin.Context, serviceURL string, method string) {
+	var bodyBytes []byte
+	if c.Request.Body != nil {
+		bodyBytes, _ = io.ReadAll(c.Request.Body)
+	}
+
+	req, err := http.NewRequest(method, serviceURL, bytes.NewReader(bodyBytes))
+	if err != nil {
+		logger.Err("Error creating request", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to create request"})
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Device-Id", c.GetHeader("Device-Id"))
+	if userID := optionalUserID(c); userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+
+	client := &http.Client{Timeout: time.Second * 30}
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Err("Error in request", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to connect to service"})
+		return
+	}
+	defer resp.Body.Close()
+
+	c.DataFromReader(resp.StatusCode, resp.ContentLength, resp.Header.Get("Content-Type"), resp.Body, nil)
+}
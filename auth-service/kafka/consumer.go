@@ -0,0 +1,53 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"auth-service/logger"
+	"auth-service/websocket"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// ConsumeInventoryAlerts nhận cảnh báo tồn kho từ product-service và đẩy realtime cho seller qua websocket
+func ConsumeInventoryAlerts() {
+	broker := os.Getenv("KAFKA_BROKER")
+	if broker == "" {
+		broker = "kafka:9092"
+	}
+
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{broker},
+		Topic:   "inventory_alerts",
+		GroupID: "auth-service-ws",
+	})
+	defer r.Close()
+
+	for {
+		m, err := r.ReadMessage(context.Background())
+		if err != nil {
+			logger.Error("Failed to read inventory alert", logger.ErrField(err))
+			continue
+		}
+
+		var payload map[string]interface{}
+		if err := json.Unmarshal(m.Value, &payload); err != nil {
+			logger.Error("Failed to unmarshal inventory alert", logger.ErrField(err))
+			continue
+		}
+
+		sellerID, _ := payload["seller_id"].(string)
+		event, _ := payload["type"].(string)
+		if sellerID == "" || event == "" {
+			continue
+		}
+		delete(payload, "type")
+		delete(payload, "timestamp")
+
+		if err := websocket.SendEvent(sellerID, event, payload); err != nil {
+			logger.Err("Failed to push inventory alert", err, logger.Str("seller_id", sellerID))
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	// "auth-service/database"
 	"auth-service/database"
 	"auth-service/helpers"
+	"auth-service/kafka"
 	"auth-service/logger"
 	"auth-service/models"
 	"auth-service/routes"
@@ -76,6 +77,8 @@ func main() {
 	routes.AuthRoutes(router)
 	routes.UserRoutes(router)
 
+	go kafka.ConsumeInventoryAlerts()
+
 	router.Run(":" + port)
 
 }
@@ -334,6 +334,16 @@ func (s *authServiceImpl) UpdateUserRole(ctx context.Context, userID, newRole st
 		return err
 	}
 
+	// user-service giữ bản sao user_type (storefront/follow chỉ cho SELLER), đồng bộ qua event
+	roleEvent := map[string]interface{}{
+		"id":         userID,
+		"user_type":  newRole,
+		"updated_at": time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := kafka.SendJSONMessage(kafka.NewKafkaWriter("kafka:9092", "user.role_updated"), roleEvent); err != nil {
+		return errors.New("failed to publish role change")
+	}
+
 	accessToken, err := helpers.GenerateToken(*user.Email, *user.FirstName, *user.LastName, newRole, userID, time.Hour*24)
 	if err != nil {
 		return err
@@ -86,3 +86,27 @@ func NotifyRoleChange(userID, newRole, newAccessTOken, newRefreshToken string) e
 
 	return nil
 }
+
+// SendEvent đẩy một event bất kỳ (vd. cảnh báo tồn kho) tới user đang kết nối
+func SendEvent(userID, event string, payload map[string]interface{}) error {
+	conn := GetConnection(userID)
+	if conn == nil {
+		return nil
+	}
+
+	message := map[string]interface{}{
+		"event":     event,
+		"timestamp": time.Now().Unix(),
+	}
+	for k, v := range payload {
+		message[k] = v
+	}
+
+	err := conn.WriteJSON(message)
+	if err != nil {
+		RemoveConnection(userID)
+		return err
+	}
+
+	return nil
+}
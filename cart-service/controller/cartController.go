@@ -447,10 +447,13 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
 	logger "cart-service/log"
+	"cart-service/models"
+	"cart-service/repository"
 	"cart-service/service"
 
 	"github.com/gin-gonic/gin"
@@ -458,11 +461,22 @@ import (
 
 type CartController struct {
 	cartService service.CartService
+	// header định danh chủ giỏ: X-User-ID cho user, X-Device-Id cho guest
+	ownerHeader string
 }
 
 func NewCartController(cartService service.CartService) *CartController {
 	return &CartController{
 		cartService: cartService,
+		ownerHeader: "X-User-ID",
+	}
+}
+
+// NewGuestCartController dùng chung handler với giỏ user, chủ giỏ là device_id gateway gửi qua X-Device-Id
+func NewGuestCartController(cartService service.CartService) *CartController {
+	return &CartController{
+		cartService: cartService,
+		ownerHeader: "X-Device-Id",
 	}
 }
 
@@ -510,7 +524,7 @@ func (ctrl *CartController) AddToCart() gin.HandlerFunc {
 			return
 		}
 
-		uid := c.GetHeader("X-User-ID")
+		uid := c.GetHeader(ctrl.ownerHeader)
 		if uid == "" {
 			logger.Err("User id not found", nil)
 			c.JSON(http.StatusBadRequest, gin.H{"error": "User id not found"})
@@ -577,16 +591,21 @@ func (ctrl *CartController) GetCart() gin.HandlerFunc {
 			return
 		}
 
-		uid := c.GetHeader("X-User-ID")
+		uid := c.GetHeader(ctrl.ownerHeader)
 		if uid == "" {
 			logger.Err("User id not found", nil)
 			c.JSON(http.StatusBadRequest, gin.H{"error": "User id not found"})
 			return
 		}
 
-		cart, err := ctrl.cartService.GetUserCart(c, uid)
+		cart, notices, err := ctrl.cartService.RevalidateCart(c, uid)
+		if err != nil && cart != nil {
+			// product-service lỗi thì vẫn trả giỏ đã lưu, chỉ thiếu notice
+			logger.Err("Failed to revalidate cart", err)
+			err = nil
+		}
 		if err != nil {
-			if err.Error() == "mmongo: no documents in result" {
+			if err.Error() == "mmongo: no documents in result" || errors.Is(err, repository.ErrCartNotFound) {
 				c.JSON(http.StatusOK, gin.H{"message": "Cart is empty"})
 				return
 			}
@@ -594,10 +613,15 @@ func (ctrl *CartController) GetCart() gin.HandlerFunc {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch cart"})
 			return
 		}
+		if notices == nil {
+			notices = []models.CartNotice{}
+		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"user_id":  uid,
-			"products": cart.Items,
+			"user_id":     uid,
+			"products":    cart.Items,
+			"saved_items": cart.SavedItems,
+			"notices":     notices,
 		})
 	}
 }
@@ -605,7 +629,7 @@ func (ctrl *CartController) GetCart() gin.HandlerFunc {
 func (ctrl *CartController) DeleteProductFromCart() gin.HandlerFunc {
 	return func(c *gin.Context) {
 
-		userID := c.GetHeader("X-User-ID")
+		userID := c.GetHeader(ctrl.ownerHeader)
 		if userID == "" {
 			logger.Err("User id not found", nil)
 			c.JSON(http.StatusBadRequest, gin.H{"error": "User id not found"})
@@ -639,9 +663,110 @@ func (ctrl *CartController) DeleteProductFromCart() gin.HandlerFunc {
 	}
 }
 
+func (ctrl *CartController) UpdateQuantity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetHeader(ctrl.ownerHeader)
+		if userID == "" {
+			logger.Err("User id not found", nil)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User id not found"})
+			return
+		}
+
+		productID := c.Param("id")
+		if productID == "" {
+			logger.Err("Product id not found", nil)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Product id not found"})
+			return
+		}
+
+		var requestBody struct {
+			Quantity int `json:"quantity" binding:"required,min=1"`
+		}
+		if err := c.ShouldBindJSON(&requestBody); err != nil {
+			logger.Err("Failed to bind JSON", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to get quantity"})
+			return
+		}
+
+		if err := ctrl.cartService.UpdateQuantity(c, userID, productID, requestBody.Quantity); err != nil {
+			writeCartItemError(c, "Failed to update cart item", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Cart item updated successfully"})
+	}
+}
+
+func (ctrl *CartController) SaveForLater() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetHeader(ctrl.ownerHeader)
+		if userID == "" {
+			logger.Err("User id not found", nil)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User id not found"})
+			return
+		}
+
+		if err := ctrl.cartService.SaveForLater(c, userID, c.Param("id")); err != nil {
+			writeCartItemError(c, "Failed to save item for later", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Item saved for later"})
+	}
+}
+
+func (ctrl *CartController) MoveToCart() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetHeader(ctrl.ownerHeader)
+		if userID == "" {
+			logger.Err("User id not found", nil)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User id not found"})
+			return
+		}
+
+		if err := ctrl.cartService.MoveToCart(c, userID, c.Param("id")); err != nil {
+			writeCartItemError(c, "Failed to move item to cart", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Item moved to cart"})
+	}
+}
+
+func (ctrl *CartController) DeleteSavedItem() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetHeader(ctrl.ownerHeader)
+		if userID == "" {
+			logger.Err("User id not found", nil)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User id not found"})
+			return
+		}
+
+		if err := ctrl.cartService.DeleteSavedItem(c, userID, c.Param("id")); err != nil {
+			writeCartItemError(c, "Failed to delete saved item", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Saved item deleted successfully"})
+	}
+}
+
+func writeCartItemError(c *gin.Context, message string, err error) {
+	switch err.Error() {
+	case "product not found in cart", "product not found in saved items":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "not enough stock available", "product is not available for sale", "quantity must be at least 1",
+		"Invalid User ID format", "Invalid Device ID format", "Invalid Product ID format":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		logger.Err(message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
 func (ctrl *CartController) ClearCart() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		uid := c.GetHeader("X-User-ID")
+		uid := c.GetHeader(ctrl.ownerHeader)
 		if uid == "" {
 			logger.Err("User id not found", nil)
 			c.JSON(http.StatusBadRequest, gin.H{"error": "User id not found"})
@@ -664,6 +789,27 @@ func (ctrl *CartController) ClearCart() gin.HandlerFunc {
 	}
 }
 
+// MergeGuestCart gộp giỏ guest (X-Device-Id) vào giỏ user (X-User-ID), gateway gọi ngay sau khi đăng nhập
+func (ctrl *CartController) MergeGuestCart() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			logger.Err("User id not found", nil)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User id not found"})
+			return
+		}
+
+		result, err := ctrl.cartService.MergeGuestCart(c, userID, c.GetHeader("X-Device-Id"))
+		if err != nil {
+			logger.Err("Failed to merge guest cart", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge guest cart"})
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
 func (ctrl *CartController) InternalClearCart(userID string) error {
 	return ctrl.cartService.ClearCart(context.Background(), userID)
 }
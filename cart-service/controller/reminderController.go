@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	logger "cart-service/log"
+	"cart-service/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AbandonedCartController struct {
+	reminderService *service.AbandonedCartService
+}
+
+func NewAbandonedCartController(reminderService *service.AbandonedCartService) *AbandonedCartController {
+	return &AbandonedCartController{reminderService: reminderService}
+}
+
+// GetStats: tỉ lệ giỏ bỏ quên được recovered sau nhắc nhở, ?days= mặc định 30
+func (ctrl *AbandonedCartController) GetStats() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+		if err != nil || days <= 0 || days > 365 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 365"})
+			return
+		}
+
+		stats, err := ctrl.reminderService.Stats(c, days)
+		if err != nil {
+			logger.Err("Failed to get abandoned cart stats", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get abandoned cart stats"})
+			return
+		}
+
+		c.JSON(http.StatusOK, stats)
+	}
+}
@@ -84,8 +84,11 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"log"
 
+	"cart-service/models"
+	"cart-service/repository"
 	"cart-service/service"
 	pb "github.com/Dattt2k2/golang-project/module/gRPC-cart/service"
 	"google.golang.org/grpc/codes"
@@ -96,11 +99,13 @@ import (
 type CartServer struct {
 	pb.UnimplementedCartServiceServer
 	cartService service.CartService
+	reminderService *service.AbandonedCartService
 }
 
-func NewCartServer(cartService service.CartService) *CartServer {
+func NewCartServer(cartService service.CartService, reminderService *service.AbandonedCartService) *CartServer {
 	return &CartServer{
 		cartService: cartService,
+		reminderService: reminderService,
 
 	}
 }
@@ -112,7 +117,20 @@ func (s *CartServer) GetCartItems (ctx context.Context, req *pb.CartRequest) (*p
 		return nil, status.Errorf(codes.InvalidArgument, "User ID is required")
 	}
 
-	cart, err := s.cartService.GetUserCart(ctx, userID)
+	// checkout (order-service bật revalidate) lấy giỏ đã đối chiếu giá, tồn kho mới nhất;
+	// các service khác chỉ đọc, không được ghi vào giỏ của user
+	var cart *models.Cart
+	var notices []models.CartNotice
+	var err error
+	if req.Revalidate {
+		cart, notices, err = s.cartService.RevalidateCart(ctx, userID)
+	} else {
+		cart, err = s.cartService.GetUserCart(ctx, userID)
+	}
+	if err != nil && cart != nil {
+		log.Printf("Error revalidating cart: %v", err)
+		return nil, status.Errorf(codes.Unavailable, "Failed to revalidate cart: %v", err)
+	}
 	if err != nil {
 		log.Printf("Error getting cart: %v", err)
 		return nil, status.Errorf(codes.Internal, "Cart not found: %v", err)
@@ -133,7 +151,52 @@ func (s *CartServer) GetCartItems (ctx context.Context, req *pb.CartRequest) (*p
 	response := &pb.CartResponse{
 		Items: items, 
 	}
+	for _, notice := range notices {
+		response.Notices = append(response.Notices, &pb.CartNotice{
+			ProductId: notice.ProductID,
+			Type:      notice.Type,
+			Message:   notice.Message,
+		})
+	}
 
 	log.Printf("Cart response: %v", response)
 	return response, nil
-}
\ No newline at end of file
+}
+
+// RedeemCoupon: order-service giữ coupon nhắc giỏ bỏ quên trước khi tạo đơn
+func (s *CartServer) RedeemCoupon(ctx context.Context, req *pb.CouponRequest) (*pb.CouponResponse, error) {
+	if req.UserId == "" || req.Code == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "User ID and coupon code are required")
+	}
+
+	coupon, err := s.reminderService.RedeemCoupon(ctx, req.UserId, req.Code)
+	if err != nil {
+		return nil, couponStatusError(err)
+	}
+	return &pb.CouponResponse{Code: coupon.Code, Percent: int32(coupon.Percent)}, nil
+}
+
+// ReleaseCoupon trả coupon khi tạo đơn hoặc thanh toán thất bại
+func (s *CartServer) ReleaseCoupon(ctx context.Context, req *pb.CouponRequest) (*pb.CouponResponse, error) {
+	if req.UserId == "" || req.Code == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "User ID and coupon code are required")
+	}
+
+	coupon, err := s.reminderService.ReleaseCoupon(ctx, req.UserId, req.Code)
+	if err != nil {
+		return nil, couponStatusError(err)
+	}
+	return &pb.CouponResponse{Code: coupon.Code, Percent: int32(coupon.Percent)}, nil
+}
+
+func couponStatusError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrCouponNotFound):
+		return status.Errorf(codes.NotFound, "Coupon not found")
+	case errors.Is(err, repository.ErrCouponInvalid):
+		return status.Errorf(codes.FailedPrecondition, "Coupon is not valid")
+	default:
+		log.Printf("Error handling coupon: %v", err)
+		return status.Errorf(codes.Internal, "Failed to handle coupon: %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// EmailProducer gửi message cho email-service, implement service.EmailPublisher
+type EmailProducer struct {
+	writer *kafka.Writer
+}
+
+func NewEmailProducer(brokers []string) *EmailProducer {
+	return &EmailProducer{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Balancer:     &kafka.LeastBytes{},
+			BatchTimeout: 50 * time.Millisecond,
+		},
+	}
+}
+
+func (p *EmailProducer) Publish(ctx context.Context, topic string, key string, payload interface{}) error {
+	value, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Topic: topic,
+		Key:   []byte(key),
+		Value: value,
+	})
+}
+
+func (p *EmailProducer) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,54 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	ProductEventTopic = "product-events"
+)
+
+type ProductEvent struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type ProductInvalidator interface {
+	Invalidate(id string)
+}
+
+// ConsumeProductEvents xoá cache sản phẩm phía cart mỗi khi product-service thay đổi sản phẩm
+func ConsumeProductEvents(brokers []string, cache ProductInvalidator) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: brokers,
+		Topic:   ProductEventTopic,
+		GroupID: "cart-service-product-cache",
+	})
+
+	go func() {
+		defer reader.Close()
+		for {
+			message, err := reader.ReadMessage(context.Background())
+			if err != nil {
+				log.Printf("Error reading product event: %v", err)
+				continue
+			}
+
+			var event ProductEvent
+			if err := json.Unmarshal(message.Value, &event); err != nil {
+				log.Printf("Error unmarshalling product event: %v", err)
+				continue
+			}
+
+			if event.ID != "" {
+				cache.Invalidate(event.ID)
+			}
+		}
+	}()
+
+	log.Printf("Product events consumer initialized with brokers: %v", brokers)
+}
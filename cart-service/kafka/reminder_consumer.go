@@ -0,0 +1,54 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type CheckoutHandler interface {
+	HandleCheckout(ctx context.Context, userID, orderID string, value float64, at time.Time) error
+}
+
+// ConsumeOrderSuccessForReminders dừng chuỗi nhắc giỏ bỏ quên và ghi nhận doanh thu recovered.
+// Group riêng để không tranh message với consumer xoá giỏ.
+func ConsumeOrderSuccessForReminders(brokers []string, handler CheckoutHandler) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: brokers,
+		Topic:   OrderSuccessTopic,
+		GroupID: "cart-service-reminders",
+	})
+
+	go func() {
+		defer reader.Close()
+		for {
+			message, err := reader.ReadMessage(context.Background())
+			if err != nil {
+				log.Printf("Error reading order success event: %v", err)
+				continue
+			}
+
+			var event OrderSuccessEvent
+			if err := json.Unmarshal(message.Value, &event); err != nil {
+				log.Printf("Error unmarshalling order success event: %v", err)
+				continue
+			}
+			if event.UserID == "" {
+				continue
+			}
+
+			at := message.Time
+			if at.IsZero() {
+				at = time.Now()
+			}
+			if err := handler.HandleCheckout(context.Background(), event.UserID, event.OrderID, event.TotalPrice, at); err != nil {
+				log.Printf("Error recording checkout for reminders of user %s: %v", event.UserID, err)
+			}
+		}
+	}()
+
+	log.Printf("Order success reminder consumer initialized with brokers: %v", brokers)
+}
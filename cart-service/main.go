@@ -6,8 +6,12 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
+	"time"
 
+	"cart-service/controller"
+	"cart-service/kafka"
 	logger "cart-service/log"
 	"cart-service/repository"
 	"cart-service/routes"
@@ -91,20 +95,96 @@ func main() {
 	}
 	log.Printf("Using DynamoDB table: %s", tableName)
 
+	// Cache thông tin sản phẩm từ product-service, PRODUCT_CACHE_TTL=0 để tắt
+	productCacheTTL := 30 * time.Second
+	if v := os.Getenv("PRODUCT_CACHE_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			productCacheTTL = d
+		}
+	}
+	productCache := service.NewProductCache(productCacheTTL)
+
+	// Giỏ guest: bảng riêng PK device_id, bật TTL trên expires_at
+	guestTableName := os.Getenv("DYNAMODB_GUEST_CART_TABLE")
+	if guestTableName == "" {
+		guestTableName = "guest-cart-table"
+	}
+	guestCartTTL := 7 * 24 * time.Hour
+	if v := os.Getenv("GUEST_CART_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			guestCartTTL = d
+		}
+	}
+
 	cartRepo := repository.NewCartRepository(dynamoClient, tableName)
-	cartSvc, err := service.NewCartService(cartRepo)
+	guestCartRepo := repository.NewGuestCartRepository(dynamoClient, guestTableName, guestCartTTL)
+	cartSvc, err := service.NewCartService(cartRepo, guestCartRepo, productCache)
 	if err != nil {
 		logger.Logger.Fatal("Failed to create CartService: " + err.Error())
 	}
+	guestCartSvc, err := service.NewGuestCartService(guestCartRepo, productCache)
+	if err != nil {
+		logger.Logger.Fatal("Failed to create guest CartService: " + err.Error())
+	}
+
+	kafkaHost := os.Getenv("KAFKA_URL")
+	brokers := []string{kafkaHost}
+	if kafkaHost == "" {
+		brokers = []string{"kafka:9092"}
+	}
+	if productCacheTTL > 0 {
+		kafka.ConsumeProductEvents(brokers, productCache)
+	}
+
+	// Nhắc nhở giỏ bỏ quên: bảng reminder PK user_id + SK cart_version, bảng coupon PK code
+	reminderTableName := os.Getenv("DYNAMODB_CART_REMINDER_TABLE")
+	if reminderTableName == "" {
+		reminderTableName = "cart-reminder-table"
+	}
+	couponTableName := os.Getenv("DYNAMODB_CART_COUPON_TABLE")
+	if couponTableName == "" {
+		couponTableName = "cart-coupon-table"
+	}
+	reminderSteps, err := service.ParseReminderSteps(getEnv("ABANDONED_CART_STEPS", "1h,24h,72h"))
+	if err != nil {
+		logger.Logger.Fatal("Invalid ABANDONED_CART_STEPS: " + err.Error())
+	}
+	reminderCfg := service.AbandonedCartConfig{
+		Steps:        reminderSteps,
+		ScanInterval: 15 * time.Minute,
+		CouponTTL:    72 * time.Hour,
+		CartURL:      getEnv("CART_DEEP_LINK_URL", "http://localhost:3000/cart"),
+	}
+	if d, err := time.ParseDuration(os.Getenv("ABANDONED_CART_SCAN_INTERVAL")); err == nil && d > 0 {
+		reminderCfg.ScanInterval = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("ABANDONED_CART_COUPON_TTL")); err == nil && d > 0 {
+		reminderCfg.CouponTTL = d
+	}
+	if v, err := strconv.Atoi(os.Getenv("ABANDONED_CART_COUPON_PERCENT")); err == nil && v > 0 && v < 100 {
+		reminderCfg.CouponPercent = v
+	}
+
+	emailProducer := kafka.NewEmailProducer(brokers)
+	defer emailProducer.Close()
+	reminderRepo := repository.NewReminderRepository(dynamoClient, reminderTableName, couponTableName)
+	reminderSvc := service.NewAbandonedCartService(cartRepo, reminderRepo, emailProducer, reminderCfg)
+	kafka.ConsumeOrderSuccessForReminders(brokers, reminderSvc)
+
+	jobCtx, stopJob := context.WithCancel(context.Background())
+	defer stopJob()
+	reminderSvc.Start(jobCtx)
 
 	// Setup dependencies
-	cartController, cartServer := routes.SetupCartDependencies(cartSvc)
+	cartController, cartServer := routes.SetupCartDependencies(cartSvc, reminderSvc)
 
 	// Khởi tạo router
 	router := gin.Default()
 
 	// Thiết lập HTTP routes
 	routes.CartRoutes(router, cartController)
+	routes.GuestCartRoutes(router, controller.NewGuestCartController(guestCartSvc))
+	routes.AbandonedCartRoutes(router, controller.NewAbandonedCartController(reminderSvc))
 
 	// Thiết lập gRPC server
 	grpcServer := grpc.NewServer()
@@ -153,3 +233,10 @@ func main() {
 	grpcServer.GracefulStop()
 	log.Println("Server exited")
 }
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
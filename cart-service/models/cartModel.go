@@ -43,6 +43,32 @@ type Cart struct {
     ID         string     `json:"id" dynamodbav:"cart_id"`
     UserID     string     `json:"user_id" dynamodbav:"user_id"`
     Items      []CartItem `json:"items" dynamodbav:"items"`
+    SavedItems []CartItem `json:"saved_items" dynamodbav:"saved_items,omitempty"` // "save for later", không đi vào checkout
     Created_at time.Time  `json:"created_at" dynamodbav:"created_at"`
     Updated_at time.Time  `json:"updated_at" dynamodbav:"updated_at"`
-}
\ No newline at end of file
+    ExpiresAt  int64      `json:"-" dynamodbav:"expires_at,omitempty"` // chỉ giỏ guest, unix seconds cho DynamoDB TTL
+}
+
+const (
+    NoticePriceIncreased = "price_increased"
+    NoticePriceDecreased = "price_decreased"
+    NoticeLowStock       = "low_stock"
+    NoticeUnavailable    = "unavailable"
+)
+
+// CartNotice báo cho user biết item trong giỏ đã thay đổi so với lúc thêm vào
+type CartNotice struct {
+    ProductID string  `json:"product_id"`
+    Name      string  `json:"name"`
+    Type      string  `json:"type"`
+    Message   string  `json:"message"`
+    OldPrice  float64 `json:"old_price,omitempty"`
+    NewPrice  float64 `json:"new_price,omitempty"`
+    Available int     `json:"available,omitempty"`
+}
+
+// CartMergeResult trả về sau khi gộp giỏ guest vào giỏ user lúc đăng nhập
+type CartMergeResult struct {
+    MergedItems int          `json:"merged_items"`
+    Conflicts   []CartNotice `json:"conflicts"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+const (
+	ReminderActive     = "active"
+	ReminderRecovered  = "recovered"   // user đặt hàng sau khi nhận nhắc nhở
+	ReminderCheckedOut = "checked_out" // user đặt hàng trước khi có nhắc nhở nào, chỉ để chặn gửi
+
+	CouponAvailable = "available"
+	CouponRedeemed  = "redeemed"
+)
+
+// CartReminder là một chuỗi nhắc nhở cho một phiên bản giỏ (updated_at của giỏ).
+// User sửa giỏ thì updated_at đổi, chuỗi nhắc nhở mới bắt đầu từ bước đầu.
+type CartReminder struct {
+	UserID           string     `json:"user_id" dynamodbav:"user_id"`
+	CartVersion      string     `json:"cart_version" dynamodbav:"cart_version"`
+	Step             int        `json:"step" dynamodbav:"step"` // số nhắc nhở đã gửi
+	Status           string     `json:"status" dynamodbav:"status"`
+	ItemCount        int        `json:"item_count" dynamodbav:"item_count"`
+	CartValue        float64    `json:"cart_value" dynamodbav:"cart_value"`
+	CouponCode       string     `json:"coupon_code,omitempty" dynamodbav:"coupon_code,omitempty"`
+	FirstSentAt      *time.Time `json:"first_sent_at,omitempty" dynamodbav:"first_sent_at,omitempty"`
+	LastSentAt       *time.Time `json:"last_sent_at,omitempty" dynamodbav:"last_sent_at,omitempty"`
+	RecoveredOrderID string     `json:"recovered_order_id,omitempty" dynamodbav:"recovered_order_id,omitempty"`
+	RecoveredValue   float64    `json:"recovered_value,omitempty" dynamodbav:"recovered_value,omitempty"`
+	RecoveredAt      *time.Time `json:"recovered_at,omitempty" dynamodbav:"recovered_at,omitempty"`
+	// bước đang gửi: claim trước khi gửi, gửi xong mới chuyển sang Step. Claim quá hạn thì job lấy lại gửi tiếp
+	PendingStep int        `json:"pending_step,omitempty" dynamodbav:"pending_step,omitempty"`
+	ClaimedAt   *time.Time `json:"claimed_at,omitempty" dynamodbav:"claimed_at,omitempty"`
+}
+
+// CartCoupon: mã giảm giá dùng một lần, chỉ user được phát mới dùng được
+type CartCoupon struct {
+	Code       string     `json:"code" dynamodbav:"code"`
+	UserID     string     `json:"user_id" dynamodbav:"user_id"`
+	Percent    int        `json:"percent" dynamodbav:"percent"`
+	Status     string     `json:"status" dynamodbav:"status"`
+	CreatedAt  time.Time  `json:"created_at" dynamodbav:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at" dynamodbav:"expires_at"`
+	RedeemedAt *time.Time `json:"redeemed_at,omitempty" dynamodbav:"redeemed_at,omitempty"`
+}
+
+type ReminderStepStats struct {
+	Step      int    `json:"step"`
+	After     string `json:"after"`
+	Sent      int    `json:"sent"`
+	Recovered int    `json:"recovered"` // đặt hàng khi đây là nhắc nhở cuối cùng đã nhận
+}
+
+type AbandonedCartStats struct {
+	From             time.Time           `json:"from"`
+	To               time.Time           `json:"to"`
+	RemindedCarts    int                 `json:"reminded_carts"`
+	RecoveredCarts   int                 `json:"recovered_carts"`
+	ConversionRate   float64             `json:"conversion_rate"`
+	RemindedValue    float64             `json:"reminded_value"`
+	RecoveredRevenue float64             `json:"recovered_revenue"`
+	CouponsIssued    int                 `json:"coupons_issued"`
+	CouponsRedeemed  int                 `json:"coupons_redeemed"`
+	Steps            []ReminderStepStats `json:"steps"`
+}
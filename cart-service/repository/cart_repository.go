@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"cart-service/models"
@@ -23,15 +24,59 @@ type CartRepository interface {
 	ClearCart(ctx context.Context, userID string) error
 	GetAllCarts(ctx context.Context, page, limit int) ([]models.Cart, int64, error)
 	GetCartItems(ctx context.Context, userID string) ([]models.CartItem, error)
+	UpdateQuantity(ctx context.Context, userID string, productID string, quantity int) (int64, error)
+	SaveForLater(ctx context.Context, userID string, productID string) (int64, error)
+	MoveToCart(ctx context.Context, userID string, productID string) (int64, error)
+	RemoveSavedItem(ctx context.Context, userID string, productID string) (int64, error)
+	SaveCart(ctx context.Context, userID string, cart *models.Cart) error
+	DeleteCart(ctx context.Context, userID string) error
+	ScanCarts(ctx context.Context, fn func(models.Cart) error) error
 }
 
+var ErrCartNotFound = errors.New("cart not found")
+
 type cartRepositoryImpl struct {
 	client    *dynamodb.Client
 	tableName string
+	keyAttr   string
+	ttl       time.Duration
 }
 
 func NewCartRepository(client *dynamodb.Client, tableName string) CartRepository {
-	return &cartRepositoryImpl{client: client, tableName: tableName}
+	return &cartRepositoryImpl{client: client, tableName: tableName, keyAttr: "user_id"}
+}
+
+// NewGuestCartRepository: giỏ của khách chưa đăng nhập, bảng riêng với PK device_id.
+// Mỗi lần ghi gia hạn expires_at, bảng bật DynamoDB TTL trên expires_at để tự xoá giỏ bỏ quên.
+func NewGuestCartRepository(client *dynamodb.Client, tableName string, ttl time.Duration) CartRepository {
+	return &cartRepositoryImpl{client: client, tableName: tableName, keyAttr: "device_id", ttl: ttl}
+}
+
+func (r *cartRepositoryImpl) key(id string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		r.keyAttr: &types.AttributeValueMemberS{Value: id},
+	}
+}
+
+func (r *cartRepositoryImpl) expiresAt() string {
+	return strconv.FormatInt(time.Now().Add(r.ttl).Unix(), 10)
+}
+
+func (r *cartRepositoryImpl) putCart(ctx context.Context, id string, cart *models.Cart) error {
+	cartItem, err := attributevalue.MarshalMap(cart)
+	if err != nil {
+		return err
+	}
+	cartItem[r.keyAttr] = &types.AttributeValueMemberS{Value: id}
+	if r.ttl > 0 {
+		cartItem["expires_at"] = &types.AttributeValueMemberN{Value: r.expiresAt()}
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      cartItem,
+	})
+	return err
 }
 
 func (r *cartRepositoryImpl) AddItem(ctx context.Context, userID string, item models.CartItem) error {
@@ -41,7 +86,7 @@ func (r *cartRepositoryImpl) AddItem(ctx context.Context, userID string, item mo
 	}
 
 	cart, err := r.FindByUserID(ctx, userID)
-	if err != nil {
+	if err != nil && !errors.Is(err, ErrCartNotFound) {
 		return err 
 	}
 
@@ -51,24 +96,34 @@ func (r *cartRepositoryImpl) AddItem(ctx context.Context, userID string, item mo
 				return errors.New("item already exists in cart")
 			}
 		}
+		for _, saved := range cart.SavedItems {
+			if saved.ProductID == item.ProductID {
+				return errors.New("item is saved for later, move it to cart instead")
+			}
+		}
+	}
+
+	updateExpr := "SET #items = list_append(if_not_exists(#items, :empty_list), :new_item),updated_at = :updated_at, created_at = if_not_exists(created_at, :created_at)"
+	values := map[string]types.AttributeValue{
+		":new_item":    &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberM{Value: itemAV}}},
+		":empty_list": &types.AttributeValueMemberL{Value: []types.AttributeValue{}},
+		":updated_at":  &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+		":created_at":  &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+	}
+	if r.ttl > 0 {
+		updateExpr += ", expires_at = :expires_at"
+		values[":expires_at"] = &types.AttributeValueMemberN{Value: r.expiresAt()}
 	}
 
 	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
 		TableName: aws.String(r.tableName),
-		Key: map[string]types.AttributeValue{
-			"user_id": &types.AttributeValueMemberS{Value: userID},
-		},
+		Key:       r.key(userID),
 
-		UpdateExpression: aws.String("SET #items = list_append(if_not_exists(#items, :empty_list), :new_item),updated_at = :updated_at, created_at = if_not_exists(created_at, :created_at)"),
+		UpdateExpression: aws.String(updateExpr),
 		ExpressionAttributeNames: map[string]string{
 			"#items": "items",
 		},
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":new_item":    &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberM{Value: itemAV}}},
-			":empty_list": &types.AttributeValueMemberL{Value: []types.AttributeValue{}},
-			":updated_at":  &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
-			":created_at":  &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
-		},
+		ExpressionAttributeValues: values,
 	})
 	return err 
 }
@@ -76,9 +131,7 @@ func (r *cartRepositoryImpl) AddItem(ctx context.Context, userID string, item mo
 func (r *cartRepositoryImpl) FindByUserID(ctx context.Context, userID string) (*models.Cart, error) {
 	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
 		TableName: aws.String(r.tableName),
-		Key: map[string]types.AttributeValue{
-			"user_id": &types.AttributeValueMemberS{Value: userID},
-		},
+		Key:       r.key(userID),
 	})
 
 	if err != nil {
@@ -86,13 +139,17 @@ func (r *cartRepositoryImpl) FindByUserID(ctx context.Context, userID string) (*
 	}
 
 	if result.Item == nil {
-		return nil, fmt.Errorf("cart not found for user_id: %s", userID)
+		return nil, fmt.Errorf("%w for %s: %s", ErrCartNotFound, r.keyAttr, userID)
 	}
 	var cart models.Cart
 	err = attributevalue.UnmarshalMap(result.Item, &cart)
 	if err != nil {
 		return nil, err 
 	}
+	// TTL của DynamoDB xoá trễ, giỏ đã hết hạn coi như không còn
+	if cart.ExpiresAt > 0 && time.Now().Unix() > cart.ExpiresAt {
+		return nil, fmt.Errorf("%w for %s: %s", ErrCartNotFound, r.keyAttr, userID)
+	}
 
 	return &cart, nil
 }
@@ -130,18 +187,7 @@ func (r *cartRepositoryImpl) RemoveItem(ctx context.Context, userID string, prod
 	}
 
 	cart.Items = newItems
-	cart.Updated_at = time.Now()
-
-	cartItem, err := attributevalue.MarshalMap(cart)
-	if err != nil {
-		return 0, err
-	}
-
-	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(r.tableName),
-		Item:      cartItem,
-	})
-	if err != nil {
+	if err := r.SaveCart(ctx, userID, cart); err != nil {
 		return 0, err 
 	}
 	return 1, nil
@@ -154,18 +200,7 @@ func (r *cartRepositoryImpl) ClearCart(ctx context.Context, userID string) error
 	}
 
 	cart.Items = []models.CartItem{}
-	cart.Updated_at = time.Now()
-
-	cartItem, err := attributevalue.MarshalMap(cart)
-	if err != nil {
-		return err 
-	}
-
-	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(r.tableName),
-		Item:      cartItem,
-	})
-	return err
+	return r.SaveCart(ctx, userID, cart)
 }
 
 func (r *cartRepositoryImpl) GetAllCarts(ctx context.Context, page, limit int) ([]models.Cart, int64, error) {
@@ -230,3 +265,141 @@ func (r *cartRepositoryImpl) GetCartItems(ctx context.Context, userID string) ([
 
     return cart.Items, nil
 }
+
+func (r *cartRepositoryImpl) SaveCart(ctx context.Context, userID string, cart *models.Cart) error {
+	cart.Updated_at = time.Now()
+	return r.putCart(ctx, userID, cart)
+}
+
+func (r *cartRepositoryImpl) DeleteCart(ctx context.Context, userID string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       r.key(userID),
+	})
+	return err
+}
+
+func (r *cartRepositoryImpl) UpdateQuantity(ctx context.Context, userID string, productID string, quantity int) (int64, error) {
+	cart, err := r.FindByUserID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := false
+	for i := range cart.Items {
+		if cart.Items[i].ProductID == productID {
+			cart.Items[i].Quantity = quantity
+			updated = true
+		}
+	}
+	if !updated {
+		return 0, nil
+	}
+
+	if err := r.SaveCart(ctx, userID, cart); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// SaveForLater chuyển item từ giỏ sang danh sách lưu để mua sau
+func (r *cartRepositoryImpl) SaveForLater(ctx context.Context, userID string, productID string) (int64, error) {
+	cart, err := r.FindByUserID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	remaining, moved := splitItem(cart.Items, productID)
+	if moved == nil {
+		return 0, nil
+	}
+	cart.Items = remaining
+	cart.SavedItems = append(cart.SavedItems, *moved)
+
+	if err := r.SaveCart(ctx, userID, cart); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func (r *cartRepositoryImpl) MoveToCart(ctx context.Context, userID string, productID string) (int64, error) {
+	cart, err := r.FindByUserID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	remaining, moved := splitItem(cart.SavedItems, productID)
+	if moved == nil {
+		return 0, nil
+	}
+	cart.SavedItems = remaining
+	cart.Items = append(cart.Items, *moved)
+
+	if err := r.SaveCart(ctx, userID, cart); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func (r *cartRepositoryImpl) RemoveSavedItem(ctx context.Context, userID string, productID string) (int64, error) {
+	cart, err := r.FindByUserID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	remaining, removed := splitItem(cart.SavedItems, productID)
+	if removed == nil {
+		return 0, nil
+	}
+	cart.SavedItems = remaining
+
+	if err := r.SaveCart(ctx, userID, cart); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func splitItem(items []models.CartItem, productID string) ([]models.CartItem, *models.CartItem) {
+	remaining := make([]models.CartItem, 0, len(items))
+	var found *models.CartItem
+	for _, item := range items {
+		if item.ProductID == productID && found == nil {
+			item := item
+			found = &item
+			continue
+		}
+		remaining = append(remaining, item)
+	}
+	return remaining, found
+}
+
+// ScanCarts duyệt toàn bộ giỏ còn item theo từng trang, dùng cho job nhắc giỏ hàng bị bỏ quên
+func (r *cartRepositoryImpl) ScanCarts(ctx context.Context, fn func(models.Cart) error) error {
+	paginator := dynamodb.NewScanPaginator(r.client, &dynamodb.ScanInput{
+		TableName:        aws.String(r.tableName),
+		FilterExpression: aws.String("size(#items) > :zero"),
+		ExpressionAttributeNames: map[string]string{
+			"#items": "items",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":zero": &types.AttributeValueMemberN{Value: "0"},
+		},
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, item := range page.Items {
+			var cart models.Cart
+			if err := attributevalue.UnmarshalMap(item, &cart); err != nil {
+				continue
+			}
+			if err := fn(cart); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
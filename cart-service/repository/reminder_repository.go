@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"cart-service/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var (
+	ErrReminderNotFound = errors.New("cart reminder not found")
+	// bước nhắc nhở đã được replica khác gửi, hoặc chuỗi đã dừng
+	ErrReminderClaimed = errors.New("cart reminder already claimed")
+	ErrCouponNotFound  = errors.New("coupon not found")
+	ErrCouponInvalid   = errors.New("coupon is not valid")
+)
+
+type ReminderRepository interface {
+	Get(ctx context.Context, userID, cartVersion string) (*models.CartReminder, error)
+	Claim(ctx context.Context, reminder models.CartReminder, prevStep int, staleBefore time.Time) error
+	MarkSent(ctx context.Context, reminder models.CartReminder) error
+	ListByUser(ctx context.Context, userID string, limit int) ([]models.CartReminder, error)
+	MarkRecovered(ctx context.Context, reminder models.CartReminder, orderID string, value float64, at time.Time) error
+	ScanReminders(ctx context.Context, fn func(models.CartReminder) error) error
+
+	CreateCoupon(ctx context.Context, coupon models.CartCoupon) error
+	RedeemCoupon(ctx context.Context, code, userID string, now time.Time) (*models.CartCoupon, error)
+	ReleaseCoupon(ctx context.Context, code, userID string) (*models.CartCoupon, error)
+	ScanCoupons(ctx context.Context, fn func(models.CartCoupon) error) error
+}
+
+type reminderRepositoryImpl struct {
+	client        *dynamodb.Client
+	reminderTable string
+	couponTable   string
+}
+
+// Bảng reminder: PK user_id, SK cart_version. Bảng coupon: PK code.
+func NewReminderRepository(client *dynamodb.Client, reminderTable, couponTable string) ReminderRepository {
+	return &reminderRepositoryImpl{client: client, reminderTable: reminderTable, couponTable: couponTable}
+}
+
+func (r *reminderRepositoryImpl) Get(ctx context.Context, userID, cartVersion string) (*models.CartReminder, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.reminderTable),
+		Key: map[string]types.AttributeValue{
+			"user_id":      &types.AttributeValueMemberS{Value: userID},
+			"cart_version": &types.AttributeValueMemberS{Value: cartVersion},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.Item == nil {
+		return nil, ErrReminderNotFound
+	}
+
+	var reminder models.CartReminder
+	if err := attributevalue.UnmarshalMap(result.Item, &reminder); err != nil {
+		return nil, err
+	}
+	return &reminder, nil
+}
+
+// Claim ghi bước nhắc nhở mới trước khi gửi email. Condition đảm bảo mỗi bước chỉ một replica gửi,
+// và chuỗi đã recovered / checked_out thì không gửi tiếp.
+// staleBefore khác zero thì claim còn treo từ trước mốc đó (gửi lỗi giữa chừng) cũng được lấy lại.
+func (r *reminderRepositoryImpl) Claim(ctx context.Context, reminder models.CartReminder, prevStep int, staleBefore time.Time) error {
+	item, err := attributevalue.MarshalMap(reminder)
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(r.reminderTable),
+		Item:      item,
+	}
+	condition := "attribute_not_exists(user_id)"
+	if prevStep > 0 {
+		condition = "step = :prev AND #status = :active"
+	}
+	if !staleBefore.IsZero() {
+		// lượt gửi trước bị lỗi sau khi claim: claim đã quá hạn thì được claim lại
+		condition = "(" + condition + " AND attribute_not_exists(claimed_at)) OR (step = :prev AND #status = :active AND claimed_at < :stale)"
+	}
+	input.ConditionExpression = aws.String(condition)
+	if prevStep > 0 || !staleBefore.IsZero() {
+		input.ExpressionAttributeNames = map[string]string{"#status": "status"}
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":prev":   &types.AttributeValueMemberN{Value: strconv.Itoa(prevStep)},
+			":active": &types.AttributeValueMemberS{Value: models.ReminderActive},
+		}
+	}
+	if !staleBefore.IsZero() {
+		stale, err := attributevalue.Marshal(staleBefore.UTC())
+		if err != nil {
+			return err
+		}
+		input.ExpressionAttributeValues[":stale"] = stale
+	}
+
+	_, err = r.client.PutItem(ctx, input)
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return ErrReminderClaimed
+	}
+	return err
+}
+
+// MarkSent chuyển bước đang claim thành đã gửi, coupon chỉ gắn vào reminder khi đã tạo được
+func (r *reminderRepositoryImpl) MarkSent(ctx context.Context, reminder models.CartReminder) error {
+	sentAt, err := attributevalue.Marshal(reminder.LastSentAt)
+	if err != nil {
+		return err
+	}
+	update := "SET step = :step, last_sent_at = :sent, first_sent_at = if_not_exists(first_sent_at, :sent) REMOVE pending_step, claimed_at"
+	values := map[string]types.AttributeValue{
+		":step": &types.AttributeValueMemberN{Value: strconv.Itoa(reminder.PendingStep)},
+		":sent": sentAt,
+	}
+	if reminder.CouponCode != "" {
+		update = "SET step = :step, last_sent_at = :sent, first_sent_at = if_not_exists(first_sent_at, :sent), coupon_code = :coupon REMOVE pending_step, claimed_at"
+		values[":coupon"] = &types.AttributeValueMemberS{Value: reminder.CouponCode}
+	}
+
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.reminderTable),
+		Key: map[string]types.AttributeValue{
+			"user_id":      &types.AttributeValueMemberS{Value: reminder.UserID},
+			"cart_version": &types.AttributeValueMemberS{Value: reminder.CartVersion},
+		},
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String("pending_step = :step"),
+		ExpressionAttributeValues: values,
+	})
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return ErrReminderClaimed
+	}
+	return err
+}
+
+func (r *reminderRepositoryImpl) ListByUser(ctx context.Context, userID string, limit int) ([]models.CartReminder, error) {
+	result, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(r.reminderTable),
+		KeyConditionExpression: aws.String("user_id = :uid"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":uid": &types.AttributeValueMemberS{Value: userID},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int32(int32(limit)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var reminders []models.CartReminder
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &reminders); err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+func (r *reminderRepositoryImpl) MarkRecovered(ctx context.Context, reminder models.CartReminder, orderID string, value float64, at time.Time) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.reminderTable),
+		Key: map[string]types.AttributeValue{
+			"user_id":      &types.AttributeValueMemberS{Value: reminder.UserID},
+			"cart_version": &types.AttributeValueMemberS{Value: reminder.CartVersion},
+		},
+		UpdateExpression:    aws.String("SET #status = :recovered, recovered_order_id = :oid, recovered_value = :value, recovered_at = :at"),
+		ConditionExpression: aws.String("#status = :active"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":recovered": &types.AttributeValueMemberS{Value: models.ReminderRecovered},
+			":active":    &types.AttributeValueMemberS{Value: models.ReminderActive},
+			":oid":       &types.AttributeValueMemberS{Value: orderID},
+			":value":     &types.AttributeValueMemberN{Value: strconv.FormatFloat(value, 'f', -1, 64)},
+			":at":        &types.AttributeValueMemberS{Value: at.Format(time.RFC3339Nano)},
+		},
+	})
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return ErrReminderClaimed
+	}
+	return err
+}
+
+func (r *reminderRepositoryImpl) ScanReminders(ctx context.Context, fn func(models.CartReminder) error) error {
+	paginator := dynamodb.NewScanPaginator(r.client, &dynamodb.ScanInput{
+		TableName: aws.String(r.reminderTable),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		var batch []models.CartReminder
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &batch); err != nil {
+			return err
+		}
+		for _, reminder := range batch {
+			if err := fn(reminder); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *reminderRepositoryImpl) CreateCoupon(ctx context.Context, coupon models.CartCoupon) error {
+	item, err := attributevalue.MarshalMap(coupon)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.couponTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(code)"),
+	})
+	return err
+}
+
+func (r *reminderRepositoryImpl) getCoupon(ctx context.Context, code string) (*models.CartCoupon, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.couponTable),
+		Key: map[string]types.AttributeValue{
+			"code": &types.AttributeValueMemberS{Value: code},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.Item == nil {
+		return nil, ErrCouponNotFound
+	}
+	var coupon models.CartCoupon
+	if err := attributevalue.UnmarshalMap(result.Item, &coupon); err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+// RedeemCoupon giữ coupon cho một đơn, hạn dùng kiểm tra ở đây còn trạng thái kiểm tra bằng condition
+func (r *reminderRepositoryImpl) RedeemCoupon(ctx context.Context, code, userID string, now time.Time) (*models.CartCoupon, error) {
+	coupon, err := r.getCoupon(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if coupon.UserID != userID || coupon.Status != models.CouponAvailable || now.After(coupon.ExpiresAt) {
+		return nil, ErrCouponInvalid
+	}
+
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.couponTable),
+		Key: map[string]types.AttributeValue{
+			"code": &types.AttributeValueMemberS{Value: code},
+		},
+		UpdateExpression:    aws.String("SET #status = :redeemed, redeemed_at = :at"),
+		ConditionExpression: aws.String("#status = :available AND user_id = :uid"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":redeemed":  &types.AttributeValueMemberS{Value: models.CouponRedeemed},
+			":available": &types.AttributeValueMemberS{Value: models.CouponAvailable},
+			":uid":       &types.AttributeValueMemberS{Value: userID},
+			":at":        &types.AttributeValueMemberS{Value: now.Format(time.RFC3339Nano)},
+		},
+	})
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return nil, ErrCouponInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	coupon.Status = models.CouponRedeemed
+	coupon.RedeemedAt = &now
+	return coupon, nil
+}
+
+// ReleaseCoupon trả coupon lại khi tạo đơn thất bại
+func (r *reminderRepositoryImpl) ReleaseCoupon(ctx context.Context, code, userID string) (*models.CartCoupon, error) {
+	coupon, err := r.getCoupon(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.couponTable),
+		Key: map[string]types.AttributeValue{
+			"code": &types.AttributeValueMemberS{Value: code},
+		},
+		UpdateExpression:    aws.String("SET #status = :available REMOVE redeemed_at"),
+		ConditionExpression: aws.String("#status = :redeemed AND user_id = :uid"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":redeemed":  &types.AttributeValueMemberS{Value: models.CouponRedeemed},
+			":available": &types.AttributeValueMemberS{Value: models.CouponAvailable},
+			":uid":       &types.AttributeValueMemberS{Value: userID},
+		},
+	})
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return nil, ErrCouponInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	coupon.Status = models.CouponAvailable
+	coupon.RedeemedAt = nil
+	return coupon, nil
+}
+
+func (r *reminderRepositoryImpl) ScanCoupons(ctx context.Context, fn func(models.CartCoupon) error) error {
+	paginator := dynamodb.NewScanPaginator(r.client, &dynamodb.ScanInput{
+		TableName: aws.String(r.couponTable),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		var batch []models.CartCoupon
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &batch); err != nil {
+			return err
+		}
+		for _, coupon := range batch {
+			if err := fn(coupon); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
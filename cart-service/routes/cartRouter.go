@@ -16,10 +16,10 @@ import (
 )
 
 // SetupCartDependencies thiết lập các dependencies theo mô hình 3 layer
-func SetupCartDependencies(cartSvc service.CartService) (*controller.CartController, *controller.CartServer) {
+func SetupCartDependencies(cartSvc service.CartService, reminderSvc *service.AbandonedCartService) (*controller.CartController, *controller.CartServer) {
     // Sử dụng cartSvc được pass từ main.go
     cartController := controller.NewCartController(cartSvc)
-    cartServer := controller.NewCartServer(cartSvc)
+    cartServer := controller.NewCartServer(cartSvc, reminderSvc)
     
     return cartController, cartServer
 }
@@ -33,5 +33,30 @@ func CartRoutes(router *gin.Engine, cartController *controller.CartController) {
 		routes.GET("/get", cartController.GetCartSeller())
 		routes.DELETE("/delete/:id", cartController.DeleteProductFromCart())
 		routes.DELETE("/clear", cartController.ClearCart())
+		routes.PUT("/update/:id", cartController.UpdateQuantity())
+		routes.POST("/save-for-later/:id", cartController.SaveForLater())
+		routes.POST("/move-to-cart/:id", cartController.MoveToCart())
+		routes.DELETE("/saved/:id", cartController.DeleteSavedItem())
+		routes.POST("/merge", cartController.MergeGuestCart())
+	}
+}
+
+// GuestCartRoutes: giỏ của khách chưa đăng nhập, định danh bằng X-Device-Id
+func GuestCartRoutes(router *gin.Engine, guestController *controller.CartController) {
+	routes := router.Group("/cart/guest")
+	{
+		routes.POST("/add/:id", guestController.AddToCart())
+		routes.GET("/get", guestController.GetCart())
+		routes.PUT("/update/:id", guestController.UpdateQuantity())
+		routes.DELETE("/delete/:id", guestController.DeleteProductFromCart())
+		routes.DELETE("/clear", guestController.ClearCart())
+	}
+}
+
+// AbandonedCartRoutes: thống kê nhắc nhở giỏ bỏ quên cho admin, gateway kiểm tra role
+func AbandonedCartRoutes(router *gin.Engine, reminderController *controller.AbandonedCartController) {
+	routes := router.Group("/cart/admin/abandoned")
+	{
+		routes.GET("/stats", reminderController.GetStats())
 	}
 }
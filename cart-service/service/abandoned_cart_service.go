@@ -0,0 +1,392 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"cart-service/models"
+	"cart-service/repository"
+)
+
+const (
+	EmailTopic            = "email_topic"
+	abandonedCartTemplate = "./template/abandoned_cart.html"
+
+	// đơn đặt trong khoảng này sau nhắc nhở cuối mới tính là recovered
+	recoveryWindow = 7 * 24 * time.Hour
+	// claim bước nhắc nhở quá thời gian này mà chưa gửi xong thì coi như lần gửi đó đã lỗi
+	reminderClaimLease = 15 * time.Minute
+	// updated_at có độ dài cố định để sort key so sánh đúng thứ tự thời gian
+	cartVersionLayout = "2006-01-02T15:04:05.000000000Z07:00"
+)
+
+// EmailPublisher gửi message lên email_topic, kafka package implement để tránh import vòng
+type EmailPublisher interface {
+	Publish(ctx context.Context, topic string, key string, payload interface{}) error
+}
+
+type AbandonedCartConfig struct {
+	Steps         []time.Duration // thời gian giỏ không đổi trước mỗi bước nhắc, tăng dần
+	ScanInterval  time.Duration
+	CouponPercent int // 0 = không phát coupon ở bước cuối
+	CouponTTL     time.Duration
+	CartURL       string
+}
+
+// ParseReminderSteps đọc danh sách bước kiểu "1h,24h,72h"
+func ParseReminderSteps(value string) ([]time.Duration, error) {
+	var steps []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		d, err := time.ParseDuration(part)
+		if err != nil {
+			return nil, err
+		}
+		if d <= 0 || (len(steps) > 0 && d <= steps[len(steps)-1]) {
+			return nil, fmt.Errorf("reminder steps must be positive and increasing: %s", value)
+		}
+		steps = append(steps, d)
+	}
+	if len(steps) == 0 {
+		return nil, errors.New("no reminder steps configured")
+	}
+	return steps, nil
+}
+
+type AbandonedCartService struct {
+	carts     repository.CartRepository
+	reminders repository.ReminderRepository
+	publisher EmailPublisher
+	cfg       AbandonedCartConfig
+}
+
+func NewAbandonedCartService(carts repository.CartRepository, reminders repository.ReminderRepository, publisher EmailPublisher, cfg AbandonedCartConfig) *AbandonedCartService {
+	return &AbandonedCartService{carts: carts, reminders: reminders, publisher: publisher, cfg: cfg}
+}
+
+func cartVersion(cart models.Cart) string {
+	return cart.Updated_at.UTC().Format(cartVersionLayout)
+}
+
+func cartValue(items []models.CartItem) float64 {
+	total := 0.0
+	for _, item := range items {
+		total += item.Price * float64(item.Quantity)
+	}
+	return math.Round(total*100) / 100
+}
+
+// Start chạy RunOnce định kỳ cho đến khi ctx bị huỷ
+func (s *AbandonedCartService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.cfg.ScanInterval)
+		defer ticker.Stop()
+		for {
+			if err := s.RunOnce(ctx); err != nil {
+				log.Printf("Abandoned cart scan failed: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	log.Printf("Abandoned cart job started, steps %v, every %s", s.cfg.Steps, s.cfg.ScanInterval)
+}
+
+// RunOnce quét toàn bộ giỏ còn item và gửi bước nhắc nhở đến hạn
+func (s *AbandonedCartService) RunOnce(ctx context.Context) error {
+	now := time.Now()
+	sent := 0
+	err := s.carts.ScanCarts(ctx, func(cart models.Cart) error {
+		ok, err := s.processCart(ctx, cart, now)
+		if err != nil {
+			log.Printf("Failed to process abandoned cart of user %s: %v", cart.UserID, err)
+			return nil
+		}
+		if ok {
+			sent++
+		}
+		return nil
+	})
+	if sent > 0 {
+		log.Printf("Sent %d abandoned cart reminders", sent)
+	}
+	return err
+}
+
+// dueStep: số bước nhắc đã đến hạn với thời gian giỏ không đổi idle
+func (s *AbandonedCartService) dueStep(idle time.Duration) int {
+	step := 0
+	for _, after := range s.cfg.Steps {
+		if idle >= after {
+			step++
+		}
+	}
+	return step
+}
+
+func (s *AbandonedCartService) processCart(ctx context.Context, cart models.Cart, now time.Time) (bool, error) {
+	if cart.UserID == "" || len(cart.Items) == 0 || cart.Updated_at.IsZero() {
+		return false, nil
+	}
+	due := s.dueStep(now.Sub(cart.Updated_at))
+	if due == 0 {
+		return false, nil
+	}
+
+	version := cartVersion(cart)
+	reminder := models.CartReminder{
+		UserID:      cart.UserID,
+		CartVersion: version,
+		Status:      models.ReminderActive,
+	}
+	prevStep := 0
+	existing, err := s.reminders.Get(ctx, cart.UserID, version)
+	switch {
+	case errors.Is(err, repository.ErrReminderNotFound):
+	case err != nil:
+		return false, err
+	case existing.Status != models.ReminderActive || existing.Step >= due:
+		return false, nil
+	case existing.ClaimedAt != nil && now.Sub(*existing.ClaimedAt) < reminderClaimLease:
+		// lần chạy khác đang gửi
+		return false, nil
+	default:
+		prevStep = existing.Step
+		reminder.FirstSentAt = existing.FirstSentAt
+		reminder.LastSentAt = existing.LastSentAt
+		reminder.CouponCode = existing.CouponCode
+	}
+
+	// job dừng lâu thì chỉ gửi bước mới nhất, không gửi bù các bước đã lỡ.
+	// Claim bước sắp gửi trước, Step chỉ tăng sau khi gửi xong nên gửi lỗi thì lần chạy sau gửi lại
+	reminder.Step = prevStep
+	reminder.PendingStep = due
+	// UTC để claimed_at so sánh được dạng chuỗi trong condition
+	claimedAt := now.UTC()
+	reminder.ClaimedAt = &claimedAt
+	reminder.ItemCount = len(cart.Items)
+	reminder.CartValue = cartValue(cart.Items)
+
+	if err := s.reminders.Claim(ctx, reminder, prevStep, now.Add(-reminderClaimLease)); err != nil {
+		if errors.Is(err, repository.ErrReminderClaimed) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	var coupon *models.CartCoupon
+	if due == len(s.cfg.Steps) && s.cfg.CouponPercent > 0 && reminder.CouponCode == "" {
+		code, err := newCouponCode()
+		if err != nil {
+			return false, err
+		}
+		coupon = &models.CartCoupon{
+			Code:      code,
+			UserID:    cart.UserID,
+			Percent:   s.cfg.CouponPercent,
+			Status:    models.CouponAvailable,
+			CreatedAt: now,
+			ExpiresAt: now.Add(s.cfg.CouponTTL),
+		}
+		if err := s.reminders.CreateCoupon(ctx, *coupon); err != nil {
+			log.Printf("Failed to create reminder coupon for user %s: %v", cart.UserID, err)
+			coupon = nil
+		} else {
+			reminder.CouponCode = code
+		}
+	}
+
+	// email và link dùng bước đang gửi
+	reminder.Step = due
+	reminder.LastSentAt = &now
+	if err := s.sendReminder(ctx, cart, reminder, coupon); err != nil {
+		return false, err
+	}
+	if err := s.reminders.MarkSent(ctx, reminder); err != nil {
+		// email đã đi, chỉ log: claim hết hạn thì bước này có thể bị gửi lại một lần
+		log.Printf("Failed to mark reminder step %d of user %s as sent: %v", due, cart.UserID, err)
+	}
+	return true, nil
+}
+
+func (s *AbandonedCartService) sendReminder(ctx context.Context, cart models.Cart, reminder models.CartReminder, coupon *models.CartCoupon) error {
+	items := make([]map[string]interface{}, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		items = append(items, map[string]interface{}{
+			"Name":     item.Name,
+			"Quantity": item.Quantity,
+			"Price":    fmt.Sprintf("%.2f", item.Price),
+			"ImageUrl": item.ImageUrl,
+		})
+	}
+
+	data := map[string]interface{}{
+		"Items":   items,
+		"Total":   fmt.Sprintf("%.2f", reminder.CartValue),
+		"CartURL": s.cartLink(reminder),
+		"Step":    reminder.Step,
+	}
+	subject := "Bạn còn sản phẩm trong giỏ hàng"
+	if coupon != nil {
+		data["CouponCode"] = coupon.Code
+		data["CouponPercent"] = coupon.Percent
+		data["CouponExpiresAt"] = coupon.ExpiresAt.Format("02/01/2006 15:04")
+		subject = fmt.Sprintf("Giảm %d%% cho giỏ hàng đang chờ bạn", coupon.Percent)
+	}
+
+	payload := map[string]interface{}{
+		"to":       "",
+		"user_id":  cart.UserID,
+		"subject":  subject,
+		"template": abandonedCartTemplate,
+		"data":     data,
+	}
+	return s.publisher.Publish(ctx, EmailTopic, cart.UserID, payload)
+}
+
+// cartLink: deep link về giỏ kèm utm để đo từng bước
+func (s *AbandonedCartService) cartLink(reminder models.CartReminder) string {
+	link, err := url.Parse(s.cfg.CartURL)
+	if err != nil {
+		return s.cfg.CartURL
+	}
+	query := link.Query()
+	query.Set("utm_source", "email")
+	query.Set("utm_medium", "abandoned_cart")
+	query.Set("utm_campaign", "step_"+strconv.Itoa(reminder.Step))
+	if reminder.CouponCode != "" {
+		query.Set("coupon", reminder.CouponCode)
+	}
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+func newCouponCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "CART-" + strings.ToUpper(hex.EncodeToString(b)), nil
+}
+
+// HandleCheckout gọi khi có order_success: gắn đơn cho chuỗi nhắc nhở gần nhất, và chặn nhắc nhở cho giỏ hiện tại
+func (s *AbandonedCartService) HandleCheckout(ctx context.Context, userID, orderID string, value float64, at time.Time) error {
+	reminders, err := s.reminders.ListByUser(ctx, userID, 5)
+	if err != nil {
+		return err
+	}
+	for _, reminder := range reminders {
+		if reminder.Status != models.ReminderActive || reminder.Step == 0 || reminder.LastSentAt == nil {
+			continue
+		}
+		if at.Sub(*reminder.LastSentAt) > recoveryWindow {
+			break
+		}
+		if err := s.reminders.MarkRecovered(ctx, reminder, orderID, value, at); err != nil && !errors.Is(err, repository.ErrReminderClaimed) {
+			return err
+		}
+		break
+	}
+
+	// giỏ có thể chưa được xoá sau khi đặt hàng, ghi checked_out để job không nhắc giỏ đã mua
+	cart, err := s.carts.FindByUserID(ctx, userID)
+	if errors.Is(err, repository.ErrCartNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(cart.Items) == 0 || cart.Updated_at.IsZero() {
+		return nil
+	}
+	err = s.reminders.Claim(ctx, models.CartReminder{
+		UserID:      userID,
+		CartVersion: cartVersion(*cart),
+		Status:      models.ReminderCheckedOut,
+		ItemCount:   len(cart.Items),
+		CartValue:   cartValue(cart.Items),
+	}, 0, time.Time{})
+	if errors.Is(err, repository.ErrReminderClaimed) {
+		return nil
+	}
+	return err
+}
+
+// Stats tổng hợp chuỗi nhắc nhở bắt đầu trong [now-days, now]
+func (s *AbandonedCartService) Stats(ctx context.Context, days int) (*models.AbandonedCartStats, error) {
+	to := time.Now()
+	from := to.AddDate(0, 0, -days)
+	stats := &models.AbandonedCartStats{From: from, To: to}
+	for i, after := range s.cfg.Steps {
+		stats.Steps = append(stats.Steps, models.ReminderStepStats{Step: i + 1, After: after.String()})
+	}
+
+	err := s.reminders.ScanReminders(ctx, func(reminder models.CartReminder) error {
+		if reminder.Step == 0 || reminder.FirstSentAt == nil {
+			return nil
+		}
+		if reminder.FirstSentAt.Before(from) || reminder.FirstSentAt.After(to) {
+			return nil
+		}
+		stats.RemindedCarts++
+		stats.RemindedValue += reminder.CartValue
+		for i := 0; i < reminder.Step && i < len(stats.Steps); i++ {
+			stats.Steps[i].Sent++
+		}
+		if reminder.Status == models.ReminderRecovered {
+			stats.RecoveredCarts++
+			stats.RecoveredRevenue += reminder.RecoveredValue
+			if reminder.Step <= len(stats.Steps) {
+				stats.Steps[reminder.Step-1].Recovered++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.reminders.ScanCoupons(ctx, func(coupon models.CartCoupon) error {
+		if coupon.CreatedAt.Before(from) || coupon.CreatedAt.After(to) {
+			return nil
+		}
+		stats.CouponsIssued++
+		if coupon.Status == models.CouponRedeemed {
+			stats.CouponsRedeemed++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if stats.RemindedCarts > 0 {
+		stats.ConversionRate = math.Round(float64(stats.RecoveredCarts)/float64(stats.RemindedCarts)*10000) / 10000
+	}
+	stats.RemindedValue = math.Round(stats.RemindedValue*100) / 100
+	stats.RecoveredRevenue = math.Round(stats.RecoveredRevenue*100) / 100
+	return stats, nil
+}
+
+func (s *AbandonedCartService) RedeemCoupon(ctx context.Context, userID, code string) (*models.CartCoupon, error) {
+	return s.reminders.RedeemCoupon(ctx, strings.ToUpper(strings.TrimSpace(code)), userID, time.Now())
+}
+
+func (s *AbandonedCartService) ReleaseCoupon(ctx context.Context, userID, code string) (*models.CartCoupon, error) {
+	return s.reminders.ReleaseCoupon(ctx, strings.ToUpper(strings.TrimSpace(code)), userID)
+}
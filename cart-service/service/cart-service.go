@@ -22,14 +22,24 @@ type CartService interface {
     DeleteProductFromCart(ctx context.Context, userID string, productID string) error
     ClearCart(ctx context.Context, userID string) error
     GetAllCarts(ctx context.Context, page, limit int) ([]models.Cart, int, int, bool, bool, error)
+    UpdateQuantity(ctx context.Context, userID string, productID string, quantity int) error
+    SaveForLater(ctx context.Context, userID string, productID string) error
+    MoveToCart(ctx context.Context, userID string, productID string) error
+    DeleteSavedItem(ctx context.Context, userID string, productID string) error
+    RevalidateCart(ctx context.Context, userID string) (*models.Cart, []models.CartNotice, error)
+    MergeGuestCart(ctx context.Context, userID string, deviceID string) (*models.CartMergeResult, error)
 }
 
 type cartServiceImpl struct {
 	repo repository.CartRepository
+	guestRepo repository.CartRepository
 	productClient pb.ProductServiceClient
+	productCache *ProductCache
+	// guest = true: giỏ của khách chưa đăng nhập, userID là device_id
+	guest bool
 }
 
-func NewCartService(repo repository.CartRepository) (CartService, error) {
+func newProductClient() (pb.ProductServiceClient, error) {
 	conn, err := grpc.NewClient("product-service:8089", grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		log.Printf("Failed to connect to product service: %v", err)
@@ -37,54 +47,106 @@ func NewCartService(repo repository.CartRepository) (CartService, error) {
 	}
 
 	log.Printf("Connected to product service")
-	productClient := pb.NewProductServiceClient(conn)
+	return pb.NewProductServiceClient(conn), nil
+}
+
+// NewCartService: guestRepo dùng để gộp giỏ guest vào giỏ user khi đăng nhập
+func NewCartService(repo repository.CartRepository, guestRepo repository.CartRepository, productCache *ProductCache) (CartService, error) {
+	productClient, err := newProductClient()
+	if err != nil {
+		return nil, err 
+	}
 
 	return &cartServiceImpl{
 		repo: repo,
+		guestRepo: guestRepo,
 		productClient: productClient,
+		productCache: productCache,
 	}, nil 
 }
 
+func NewGuestCartService(guestRepo repository.CartRepository, productCache *ProductCache) (CartService, error) {
+	productClient, err := newProductClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return &cartServiceImpl{
+		repo:          guestRepo,
+		productClient: productClient,
+		productCache:  productCache,
+		guest:         true,
+	}, nil
+}
+
+// checkOwnerID: user phải là UUID, device_id của guest do client gửi nên chỉ giới hạn độ dài
+func (s *cartServiceImpl) checkOwnerID(id string) error {
+	if s.guest {
+		if id == "" || len(id) > 128 {
+			return errors.New("Invalid Device ID format")
+		}
+		return nil
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return errors.New("Invalid User ID format")
+	}
+	return nil
+}
+
+// getProduct lấy name, price, vendor, status và tồn kho trong một lần gọi gRPC
+func (s *cartServiceImpl) getProduct(ctx context.Context, productID string) (*pb.ProductSummary, error) {
+	if product, ok := s.productCache.Get(productID); ok {
+		return product, nil
+	}
+
+	resp, err := s.productClient.GetProductsByIDs(ctx, &pb.ProductIDsRequest{Ids: []string{productID}})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Products) == 0 {
+		return nil, errors.New("product not found")
+	}
+
+	product := resp.Products[0]
+	s.productCache.Set(product)
+	return product, nil
+}
+
 func (s *cartServiceImpl) AddToCart(ctx context.Context, userID string, productID string, quantity int ) error {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	if _, err := uuid.Parse(userID); err != nil {
-		return errors.New("Invalid User ID format")
+	if err := s.checkOwnerID(userID); err != nil {
+		return err
 	}
 	if _, err := uuid.Parse(productID); err != nil {
 		return errors.New("Invalid Product ID format")
 	}
 
 
-	productReq := &pb.ProductRequest{
-		Id: productID,
-	}
-	basicInfo, err := s.productClient.GetBasicInfo(ctx, productReq)
+	product, err := s.getProduct(ctx, productID)
 	if err != nil {
 		log.Printf("Failed to get product info: %v", err)
 		return errors.New("failed to get product info")
 	}
 
-	if basicInfo.VendorId == userID {
+	if product.VendorId == userID {
 		return errors.New("cannot add your own product to cart")
 	}
 
-	checkStock, err := s.productClient.CheckStock(ctx, productReq)
-	if err != nil {
-		return errors.New("failed to check product stock")
+	if product.Status != "" && product.Status != "onsale" {
+		return errors.New("product is not available for sale")
 	}
-	avaiableQuantity := int(checkStock.AvailableQuantity)
-	if quantity > avaiableQuantity {
+
+	if quantity > int(product.AvailableQuantity) {
 		return errors.New("not enough stock available")
 	}
 
-
 	cartItem := models.CartItem{
-		VendorID: basicInfo.VendorId,
+		VendorID: product.VendorId,
 		ProductID: productID,
-		Name: basicInfo.Name,
-		Price: float64(basicInfo.Price),
+		Name: product.Name,
+		Price: float64(product.Price),
 		Quantity: quantity,
 	}
 
@@ -96,8 +158,8 @@ func (s *cartServiceImpl) GetUserCart(ctx context.Context, userID string) (*mode
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	if _, err := uuid.Parse(userID); err != nil {
-		return nil, errors.New("Invalid User ID format")
+	if err := s.checkOwnerID(userID); err != nil {
+		return nil, err
 	}
 
 	return s.repo.FindByUserID(ctx, userID)
@@ -109,8 +171,8 @@ func (s *cartServiceImpl) DeleteProductFromCart(ctx context.Context, userID stri
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	if _, err := uuid.Parse(userID); err != nil {
-		return errors.New("Invalid User ID format")
+	if err := s.checkOwnerID(userID); err != nil {
+		return err
 	}
 
 	if _, err := uuid.Parse(productID); err != nil {
@@ -128,13 +190,126 @@ func (s *cartServiceImpl) DeleteProductFromCart(ctx context.Context, userID stri
 	return nil
 }
 
+func (s *cartServiceImpl) UpdateQuantity(ctx context.Context, userID string, productID string, quantity int) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := s.checkOwnerID(userID); err != nil {
+		return err
+	}
+	if _, err := uuid.Parse(productID); err != nil {
+		return errors.New("Invalid Product ID format")
+	}
+	if quantity < 1 {
+		return errors.New("quantity must be at least 1")
+	}
+
+	product, err := s.getProduct(ctx, productID)
+	if err != nil {
+		log.Printf("Failed to get product info: %v", err)
+		return errors.New("failed to get product info")
+	}
+	if quantity > int(product.AvailableQuantity) {
+		return errors.New("not enough stock available")
+	}
+
+	modifiedCount, err := s.repo.UpdateQuantity(ctx, userID, productID, quantity)
+	if err != nil {
+		return errors.New("Failed to update cart item")
+	}
+	if modifiedCount == 0 {
+		return errors.New("product not found in cart")
+	}
+	return nil
+}
+
+func (s *cartServiceImpl) SaveForLater(ctx context.Context, userID string, productID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := s.checkOwnerID(userID); err != nil {
+		return err
+	}
+
+	modifiedCount, err := s.repo.SaveForLater(ctx, userID, productID)
+	if err != nil {
+		return errors.New("Failed to save item for later")
+	}
+	if modifiedCount == 0 {
+		return errors.New("product not found in cart")
+	}
+	return nil
+}
+
+// MoveToCart đưa item đã lưu về giỏ, kiểm tra lại hàng còn bán và đủ tồn kho như khi thêm mới
+func (s *cartServiceImpl) MoveToCart(ctx context.Context, userID string, productID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := s.checkOwnerID(userID); err != nil {
+		return err
+	}
+
+	cart, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	quantity := 0
+	for _, item := range cart.SavedItems {
+		if item.ProductID == productID {
+			quantity = item.Quantity
+		}
+	}
+	if quantity == 0 {
+		return errors.New("product not found in saved items")
+	}
+
+	product, err := s.getProduct(ctx, productID)
+	if err != nil {
+		log.Printf("Failed to get product info: %v", err)
+		return errors.New("failed to get product info")
+	}
+	if product.Status != "" && product.Status != "onsale" {
+		return errors.New("product is not available for sale")
+	}
+	if quantity > int(product.AvailableQuantity) {
+		return errors.New("not enough stock available")
+	}
+
+	modifiedCount, err := s.repo.MoveToCart(ctx, userID, productID)
+	if err != nil {
+		return errors.New("Failed to move item to cart")
+	}
+	if modifiedCount == 0 {
+		return errors.New("product not found in saved items")
+	}
+	return nil
+}
+
+func (s *cartServiceImpl) DeleteSavedItem(ctx context.Context, userID string, productID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := s.checkOwnerID(userID); err != nil {
+		return err
+	}
+
+	modifiedCount, err := s.repo.RemoveSavedItem(ctx, userID, productID)
+	if err != nil {
+		return errors.New("Failed to remove saved item")
+	}
+	if modifiedCount == 0 {
+		return errors.New("product not found in saved items")
+	}
+	return nil
+}
 
 func (s *cartServiceImpl) ClearCart(ctx context.Context, userID string) error {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	if _, err := uuid.Parse(userID); err != nil {
-		return errors.New("Invalid User ID format")
+	if err := s.checkOwnerID(userID); err != nil {
+		return err
 	}
 
 	return s.repo.ClearCart(ctx, userID)
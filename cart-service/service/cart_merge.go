@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"cart-service/models"
+	"cart-service/repository"
+
+	pb "github.com/Dattt2k2/golang-project/module/gRPC-Product/service"
+)
+
+// MergeGuestCart gộp giỏ guest của device vào giỏ user: cộng dồn số lượng, không vượt tồn kho,
+// item không gộp được thì trả về trong conflicts. Giỏ guest bị xoá sau khi gộp.
+func (s *cartServiceImpl) MergeGuestCart(ctx context.Context, userID string, deviceID string) (*models.CartMergeResult, error) {
+	if s.guest || s.guestRepo == nil {
+		return nil, errors.New("guest cart merge is not supported")
+	}
+	if err := s.checkOwnerID(userID); err != nil {
+		return nil, err
+	}
+	result := &models.CartMergeResult{Conflicts: []models.CartNotice{}}
+	if deviceID == "" {
+		return result, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	guestCart, err := s.guestRepo.FindByUserID(ctx, deviceID)
+	if errors.Is(err, repository.ErrCartNotFound) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(guestCart.Items) == 0 {
+		return result, s.guestRepo.DeleteCart(ctx, deviceID)
+	}
+
+	cart, err := s.repo.FindByUserID(ctx, userID)
+	if errors.Is(err, repository.ErrCartNotFound) {
+		cart = &models.Cart{UserID: userID, Items: []models.CartItem{}, Created_at: time.Now()}
+	} else if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(guestCart.Items))
+	for _, item := range guestCart.Items {
+		ids = append(ids, item.ProductID)
+	}
+	resp, err := s.productClient.GetProductsByIDs(ctx, &pb.ProductIDsRequest{Ids: ids})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product info: %w", err)
+	}
+	products := make(map[string]*pb.ProductSummary, len(resp.Products))
+	for _, p := range resp.Products {
+		products[p.Id] = p
+		s.productCache.Set(p)
+	}
+
+	for _, guestItem := range guestCart.Items {
+		product, ok := products[guestItem.ProductID]
+		switch {
+		case !ok || (product.Status != "" && product.Status != "onsale") || product.AvailableQuantity <= 0:
+			result.Conflicts = append(result.Conflicts, models.CartNotice{
+				ProductID: guestItem.ProductID,
+				Name:      guestItem.Name,
+				Type:      models.NoticeUnavailable,
+				Message:   fmt.Sprintf("%s is no longer available", guestItem.Name),
+			})
+			continue
+		case product.VendorId == userID:
+			result.Conflicts = append(result.Conflicts, models.CartNotice{
+				ProductID: guestItem.ProductID,
+				Name:      guestItem.Name,
+				Type:      models.NoticeUnavailable,
+				Message:   fmt.Sprintf("%s is your own product", guestItem.Name),
+			})
+			continue
+		}
+
+		// item đang nằm trong "save for later" thì đưa về giỏ rồi cộng dồn
+		existing := guestItem.Quantity
+		idx := -1
+		for i := range cart.Items {
+			if cart.Items[i].ProductID == guestItem.ProductID {
+				idx = i
+				existing += cart.Items[i].Quantity
+			}
+		}
+		for i := 0; i < len(cart.SavedItems); i++ {
+			if cart.SavedItems[i].ProductID == guestItem.ProductID {
+				existing += cart.SavedItems[i].Quantity
+				cart.SavedItems = append(cart.SavedItems[:i], cart.SavedItems[i+1:]...)
+				i--
+			}
+		}
+
+		quantity := existing
+		available := int(product.AvailableQuantity)
+		if quantity > available {
+			result.Conflicts = append(result.Conflicts, models.CartNotice{
+				ProductID: guestItem.ProductID,
+				Name:      product.Name,
+				Type:      models.NoticeLowStock,
+				Message:   fmt.Sprintf("only %d left of %s, quantity limited from %d", available, product.Name, quantity),
+				Available: available,
+			})
+			quantity = available
+		}
+
+		merged := models.CartItem{
+			VendorID:  product.VendorId,
+			ProductID: guestItem.ProductID,
+			Name:      product.Name,
+			Price:     float64(product.Price),
+			Quantity:  quantity,
+		}
+		if idx >= 0 {
+			merged.ImageUrl = cart.Items[idx].ImageUrl
+			merged.Description = cart.Items[idx].Description
+			cart.Items[idx] = merged
+		} else {
+			cart.Items = append(cart.Items, merged)
+		}
+		result.MergedItems++
+	}
+
+	if err := s.repo.SaveCart(ctx, userID, cart); err != nil {
+		return nil, err
+	}
+	if err := s.guestRepo.DeleteCart(ctx, deviceID); err != nil {
+		// giỏ user đã lưu, giỏ guest còn lại sẽ hết hạn theo TTL
+		log.Printf("Failed to delete guest cart %s after merge: %v", deviceID, err)
+	}
+	return result, nil
+}
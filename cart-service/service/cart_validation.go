@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"cart-service/models"
+
+	pb "github.com/Dattt2k2/golang-project/module/gRPC-Product/service"
+)
+
+// RevalidateCart đối chiếu giỏ với product-service (giá, trạng thái, tồn kho) trong một lần gọi batch,
+// cập nhật lại giỏ nếu có thay đổi và trả về notice cho từng item thay đổi.
+// Gọi khi user xem giỏ và khi order-service lấy giỏ để checkout.
+func (s *cartServiceImpl) RevalidateCart(ctx context.Context, userID string) (*models.Cart, []models.CartNotice, error) {
+	cart, err := s.GetUserCart(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(cart.Items) == 0 && len(cart.SavedItems) == 0 {
+		return cart, nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	ids := make([]string, 0, len(cart.Items)+len(cart.SavedItems))
+	for _, item := range cart.Items {
+		ids = append(ids, item.ProductID)
+	}
+	for _, item := range cart.SavedItems {
+		ids = append(ids, item.ProductID)
+	}
+
+	// không đọc cache: tồn kho trong cache có thể đã cũ
+	resp, err := s.productClient.GetProductsByIDs(ctx, &pb.ProductIDsRequest{Ids: ids})
+	if err != nil {
+		return cart, nil, fmt.Errorf("failed to refresh cart products: %w", err)
+	}
+	products := make(map[string]*pb.ProductSummary, len(resp.Products))
+	for _, p := range resp.Products {
+		products[p.Id] = p
+		s.productCache.Set(p)
+	}
+
+	var notices []models.CartNotice
+	changed := false
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		product, ok := products[item.ProductID]
+		if !ok || (product.Status != "" && product.Status != "onsale") {
+			notices = append(notices, models.CartNotice{
+				ProductID: item.ProductID,
+				Name:      item.Name,
+				Type:      models.NoticeUnavailable,
+				Message:   fmt.Sprintf("%s is no longer available", item.Name),
+			})
+			continue
+		}
+
+		if refreshItemInfo(item, product) {
+			changed = true
+		}
+		if notice, ok := priceNotice(item, product); ok {
+			notices = append(notices, notice)
+			item.Price = float64(product.Price)
+			changed = true
+		}
+
+		available := int(product.AvailableQuantity)
+		switch {
+		case available <= 0:
+			notices = append(notices, models.CartNotice{
+				ProductID: item.ProductID,
+				Name:      item.Name,
+				Type:      models.NoticeUnavailable,
+				Message:   fmt.Sprintf("%s is out of stock", item.Name),
+			})
+		case available < item.Quantity:
+			// giảm số lượng về mức còn hàng để user vẫn checkout được
+			notices = append(notices, models.CartNotice{
+				ProductID: item.ProductID,
+				Name:      item.Name,
+				Type:      models.NoticeLowStock,
+				Message:   fmt.Sprintf("only %d left of %s, quantity reduced from %d", available, item.Name, item.Quantity),
+				Available: available,
+			})
+			item.Quantity = available
+			changed = true
+		}
+	}
+
+	// item lưu để mua sau chỉ cập nhật giá, không báo notice
+	for i := range cart.SavedItems {
+		item := &cart.SavedItems[i]
+		product, ok := products[item.ProductID]
+		if !ok {
+			continue
+		}
+		if refreshItemInfo(item, product) {
+			changed = true
+		}
+		if _, ok := priceNotice(item, product); ok {
+			item.Price = float64(product.Price)
+			changed = true
+		}
+	}
+
+	if changed {
+		if err := s.repo.SaveCart(ctx, userID, cart); err != nil {
+			return cart, notices, err
+		}
+	}
+	return cart, notices, nil
+}
+
+func refreshItemInfo(item *models.CartItem, product *pb.ProductSummary) bool {
+	changed := false
+	if product.Name != "" && product.Name != item.Name {
+		item.Name = product.Name
+		changed = true
+	}
+	if product.VendorId != "" && product.VendorId != item.VendorID {
+		item.VendorID = product.VendorId
+		changed = true
+	}
+	return changed
+}
+
+// priceNotice so sánh theo đơn vị xu để tránh lệch do float32 từ gRPC
+func priceNotice(item *models.CartItem, product *pb.ProductSummary) (models.CartNotice, bool) {
+	oldPrice := math.Round(item.Price * 100)
+	newPrice := math.Round(float64(product.Price) * 100)
+	if oldPrice == newPrice {
+		return models.CartNotice{}, false
+	}
+
+	notice := models.CartNotice{
+		ProductID: item.ProductID,
+		Name:      item.Name,
+		OldPrice:  item.Price,
+		NewPrice:  float64(product.Price),
+	}
+	if newPrice > oldPrice {
+		notice.Type = models.NoticePriceIncreased
+		notice.Message = fmt.Sprintf("price of %s went up from %.2f to %.2f", item.Name, item.Price, product.Price)
+	} else {
+		notice.Type = models.NoticePriceDecreased
+		notice.Message = fmt.Sprintf("price of %s dropped from %.2f to %.2f", item.Name, item.Price, product.Price)
+	}
+	return notice, true
+}
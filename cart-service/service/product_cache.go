@@ -0,0 +1,64 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	pb "github.com/Dattt2k2/golang-project/module/gRPC-Product/service"
+)
+
+// ProductCache cache ngắn hạn thông tin sản phẩm lấy từ product-service.
+// Entry bị xoá khi nhận product-events, TTL chỉ là lưới an toàn cho thay đổi tồn kho
+// (order-service vẫn kiểm tra tồn kho thật bằng CheckStockBatch khi tạo đơn).
+type ProductCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[string]productCacheEntry
+}
+
+type productCacheEntry struct {
+	product   *pb.ProductSummary
+	expiresAt time.Time
+}
+
+// NewProductCache với ttl <= 0 thì tắt cache
+func NewProductCache(ttl time.Duration) *ProductCache {
+	return &ProductCache{
+		ttl:     ttl,
+		entries: make(map[string]productCacheEntry),
+	}
+}
+
+func (c *ProductCache) Get(id string) (*pb.ProductSummary, bool) {
+	if c == nil || c.ttl <= 0 {
+		return nil, false
+	}
+	c.mu.RLock()
+	entry, ok := c.entries[id]
+	c.mu.RUnlock()
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.product, true
+}
+
+func (c *ProductCache) Set(product *pb.ProductSummary) {
+	if c == nil || c.ttl <= 0 || product == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[product.Id] = productCacheEntry{
+		product:   product,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+}
+
+func (c *ProductCache) Invalidate(id string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, id)
+}
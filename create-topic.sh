@@ -1,7 +1,7 @@
 #!/bin/bash
 
 # Danh sách các topic cần tạo
-TOPICS=("payment" "payment_events" "order_success" "user.created" "order_returned" "vendor_payment_processed" "vendor_account_updates" "vendor_payments" "bank_payouts" "product-events" "email-events" "user.created.dlq" "product_rating_updates")
+TOPICS=("payment" "payment_events" "order_success" "user.created" "order_returned" "vendor_payment_processed" "vendor_account_updates" "vendor_payments" "bank_payouts" "product-events" "email-events" "user.created.dlq" "product_rating_updates" "product_views" "browsing_history_merge" "vendor-events")
 
 # Tạo các topic
 for TOPIC in "${TOPICS[@]}"; do
@@ -13,8 +13,11 @@ func main() {
 	logger.InitLogger()
 	defer logger.Sync()
 	emailService := service.NewEmailService()
+	userClient := service.NewUserClient()
 
-	err := service.StartKafkaConsumer(emailService)
+	go service.StartInventoryConsumer(emailService, userClient)
+
+	err := service.StartKafkaConsumer(emailService, userClient)
 	if err != nil {
 		log.Fatalf("Failed to start Kafka consumer: %v", err)
 	}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	logger "email-service/logger"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// InventoryEvent khớp với message product-service gửi lên topic inventory_alerts
+type InventoryEvent struct {
+	Type        string                `json:"type"`
+	SellerID    string                `json:"seller_id"`
+	ProductID   string                `json:"product_id,omitempty"`
+	ProductName string                `json:"product_name,omitempty"`
+	Quantity    int                   `json:"quantity"`
+	Threshold   int                   `json:"threshold"`
+	Status      string                `json:"status,omitempty"`
+	Items       []InventoryDigestItem `json:"items,omitempty"`
+	Timestamp   int64                 `json:"timestamp"`
+}
+
+type InventoryDigestItem struct {
+	ProductID string `json:"product_id"`
+	Name      string `json:"name"`
+	Quantity  int    `json:"quantity"`
+	Threshold int    `json:"threshold"`
+	Status    string `json:"status"`
+	SoldCount int    `json:"sold_count"`
+}
+
+const (
+	inventoryAlertTemplate  = "./template/inventory_alert.html"
+	inventoryDigestTemplate = "./template/inventory_digest.html"
+)
+
+func StartInventoryConsumer(emailService *EmailService, users *UserClient) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{os.Getenv("KAFKA_BROKER")},
+		Topic:   "inventory_alerts",
+		GroupID: "email_service_inventory_group",
+	})
+	defer r.Close()
+
+	for {
+		m, err := r.ReadMessage(context.Background())
+		if err != nil {
+			logger.Error("Failed to read inventory message from Kafka", logger.ErrField(err))
+			continue
+		}
+
+		var event InventoryEvent
+		if err := json.Unmarshal(m.Value, &event); err != nil {
+			logger.Error("Failed to unmarshal inventory event", logger.ErrField(err))
+			continue
+		}
+
+		if err := handleInventoryEvent(emailService, users, event); err != nil {
+			logger.Err("Failed to send inventory email", err, logger.Str("seller_id", event.SellerID), logger.Str("type", event.Type))
+		}
+	}
+}
+
+func handleInventoryEvent(emailService *EmailService, users *UserClient, event InventoryEvent) error {
+	var subject, templatePath string
+	switch event.Type {
+	case "low_stock":
+		subject = fmt.Sprintf("Sản phẩm %s sắp hết hàng", event.ProductName)
+		templatePath = inventoryAlertTemplate
+	case "out_of_stock":
+		subject = fmt.Sprintf("Sản phẩm %s đã hết hàng", event.ProductName)
+		templatePath = inventoryAlertTemplate
+	case "daily_digest":
+		if len(event.Items) == 0 {
+			return nil
+		}
+		subject = fmt.Sprintf("Báo cáo tồn kho ngày %s", time.Unix(event.Timestamp, 0).Format("02/01/2006"))
+		templatePath = inventoryDigestTemplate
+	default:
+		// back_in_stock chỉ đẩy qua websocket, không gửi mail
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	to, err := users.GetEmail(ctx, event.SellerID)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{
+		"Type":        event.Type,
+		"OutOfStock":  event.Type == "out_of_stock",
+		"ProductID":   event.ProductID,
+		"ProductName": event.ProductName,
+		"Quantity":    event.Quantity,
+		"Threshold":   event.Threshold,
+		"Items":       event.Items,
+	}
+	return emailService.SendEmail(to, subject, templatePath, data)
+}
@@ -11,12 +11,13 @@ import (
 
 type EmailKafkaMessage struct {
 	To           string      `json:"to"`
+	UserID       string      `json:"user_id,omitempty"` // dùng khi producer chỉ biết user id, email lấy từ user-service
 	Subject      string      `json:"subject"`
 	TemplatePath string      `json:"template"`
 	Data         interface{} `json:"data"`
 }
 
-func StartKafkaConsumer(emailService *EmailService) error {
+func StartKafkaConsumer(emailService *EmailService, users *UserClient) error {
 	r := kafka.NewReader(kafka.ReaderConfig{
 		Brokers: []string{os.Getenv("KAFKA_BROKER")},
 		Topic:   "email_topic",
@@ -37,6 +38,15 @@ func StartKafkaConsumer(emailService *EmailService) error {
 			continue
 		}
 
+		if msg.To == "" && msg.UserID != "" {
+			email, err := users.GetEmail(context.Background(), msg.UserID)
+			if err != nil {
+				logger.Err("Failed to resolve user email", err, logger.Str("user_id", msg.UserID))
+				continue
+			}
+			msg.To = email
+		}
+
 		if err := emailService.SendEmail(msg.To, msg.Subject, msg.TemplatePath, msg.Data); err != nil {
 			logger.Error("Failed to send email", logger.ErrField(err))
 		}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+type UserClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewUserClient() *UserClient {
+	baseURL := os.Getenv("USER_SERVICE_URL")
+	if baseURL == "" {
+		baseURL = "http://user-service:8095"
+	}
+	return &UserClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// GetEmail lấy email của user qua user-service (GET /me với header X-User-ID)
+func (c *UserClient) GetEmail(ctx context.Context, userID string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/me", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-User-ID", userID)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("user-service returned status %d for user %s", resp.StatusCode, userID)
+	}
+
+	var user struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return "", err
+	}
+	if user.Email == "" {
+		return "", fmt.Errorf("user %s has no email", userID)
+	}
+	return user.Email, nil
+}
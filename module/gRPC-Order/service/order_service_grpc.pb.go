@@ -19,9 +19,10 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	OrderService_CreateOrder_FullMethodName  = "/order.OrderService/CreateOrder"
-	OrderService_GetOrder_FullMethodName     = "/order.OrderService/GetOrder"
-	OrderService_HasPurchased_FullMethodName = "/order.OrderService/HasPurchased"
+	OrderService_CreateOrder_FullMethodName          = "/order.OrderService/CreateOrder"
+	OrderService_GetOrder_FullMethodName             = "/order.OrderService/GetOrder"
+	OrderService_HasPurchased_FullMethodName         = "/order.OrderService/HasPurchased"
+	OrderService_ListSuccessfulOrders_FullMethodName = "/order.OrderService/ListSuccessfulOrders"
 )
 
 // OrderServiceClient is the client API for OrderService service.
@@ -31,6 +32,8 @@ type OrderServiceClient interface {
 	CreateOrder(ctx context.Context, in *OrderRequest, opts ...grpc.CallOption) (*OrderResponse, error)
 	GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*OrderResponse, error)
 	HasPurchased(ctx context.Context, in *HasPurchasedRequest, opts ...grpc.CallOption) (*HasPurchasedResponse, error)
+	// đơn đã thành công (đã gửi order_success, chưa huỷ), product-service dùng để rebuild gợi ý
+	ListSuccessfulOrders(ctx context.Context, in *ListOrdersRequest, opts ...grpc.CallOption) (*ListOrdersResponse, error)
 }
 
 type orderServiceClient struct {
@@ -71,6 +74,16 @@ func (c *orderServiceClient) HasPurchased(ctx context.Context, in *HasPurchasedR
 	return out, nil
 }
 
+func (c *orderServiceClient) ListSuccessfulOrders(ctx context.Context, in *ListOrdersRequest, opts ...grpc.CallOption) (*ListOrdersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListOrdersResponse)
+	err := c.cc.Invoke(ctx, OrderService_ListSuccessfulOrders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // OrderServiceServer is the server API for OrderService service.
 // All implementations must embed UnimplementedOrderServiceServer
 // for forward compatibility.
@@ -78,6 +91,8 @@ type OrderServiceServer interface {
 	CreateOrder(context.Context, *OrderRequest) (*OrderResponse, error)
 	GetOrder(context.Context, *GetOrderRequest) (*OrderResponse, error)
 	HasPurchased(context.Context, *HasPurchasedRequest) (*HasPurchasedResponse, error)
+	// đơn đã thành công (đã gửi order_success, chưa huỷ), product-service dùng để rebuild gợi ý
+	ListSuccessfulOrders(context.Context, *ListOrdersRequest) (*ListOrdersResponse, error)
 	mustEmbedUnimplementedOrderServiceServer()
 }
 
@@ -97,6 +112,9 @@ func (UnimplementedOrderServiceServer) GetOrder(context.Context, *GetOrderReques
 func (UnimplementedOrderServiceServer) HasPurchased(context.Context, *HasPurchasedRequest) (*HasPurchasedResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HasPurchased not implemented")
 }
+func (UnimplementedOrderServiceServer) ListSuccessfulOrders(context.Context, *ListOrdersRequest) (*ListOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSuccessfulOrders not implemented")
+}
 func (UnimplementedOrderServiceServer) mustEmbedUnimplementedOrderServiceServer() {}
 func (UnimplementedOrderServiceServer) testEmbeddedByValue()                      {}
 
@@ -172,6 +190,24 @@ func _OrderService_HasPurchased_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrderService_ListSuccessfulOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ListSuccessfulOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_ListSuccessfulOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ListSuccessfulOrders(ctx, req.(*ListOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // OrderService_ServiceDesc is the grpc.ServiceDesc for OrderService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -191,6 +227,10 @@ var OrderService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "HasPurchased",
 			Handler:    _OrderService_HasPurchased_Handler,
 		},
+		{
+			MethodName: "ListSuccessfulOrders",
+			Handler:    _OrderService_ListSuccessfulOrders_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "order_service.proto",
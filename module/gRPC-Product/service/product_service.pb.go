@@ -135,16 +135,20 @@ func (x *BasicProductResponse) GetVendorId() string {
 }
 
 type ProductResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Price         float32                `protobuf:"fixed32,3,opt,name=price,proto3" json:"price,omitempty"`
-	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
-	Quantity      int32                  `protobuf:"varint,5,opt,name=quantity,proto3" json:"quantity,omitempty"`
-	ImageUrl      string                 `protobuf:"bytes,6,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
-	VendorId      string                 `protobuf:"bytes,7,opt,name=vendor_id,json=vendorId,proto3" json:"vendor_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name            string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Price           float32                `protobuf:"fixed32,3,opt,name=price,proto3" json:"price,omitempty"`
+	Description     string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Quantity        int32                  `protobuf:"varint,5,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	ImageUrl        string                 `protobuf:"bytes,6,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
+	VendorId        string                 `protobuf:"bytes,7,opt,name=vendor_id,json=vendorId,proto3" json:"vendor_id,omitempty"`
+	RegularPrice    float32                `protobuf:"fixed32,8,opt,name=regular_price,json=regularPrice,proto3" json:"regular_price,omitempty"` // giá gốc khi đang sale, bằng price nếu không sale
+	OnSale          bool                   `protobuf:"varint,9,opt,name=on_sale,json=onSale,proto3" json:"on_sale,omitempty"`
+	SaleEndAt       int64                  `protobuf:"varint,10,opt,name=sale_end_at,json=saleEndAt,proto3" json:"sale_end_at,omitempty"` // unix seconds, 0 nếu không sale
+	LowestPrice_30D float32                `protobuf:"fixed32,11,opt,name=lowest_price_30d,json=lowestPrice30d,proto3" json:"lowest_price_30d,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *ProductResponse) Reset() {
@@ -226,6 +230,34 @@ func (x *ProductResponse) GetVendorId() string {
 	return ""
 }
 
+func (x *ProductResponse) GetRegularPrice() float32 {
+	if x != nil {
+		return x.RegularPrice
+	}
+	return 0
+}
+
+func (x *ProductResponse) GetOnSale() bool {
+	if x != nil {
+		return x.OnSale
+	}
+	return false
+}
+
+func (x *ProductResponse) GetSaleEndAt() int64 {
+	if x != nil {
+		return x.SaleEndAt
+	}
+	return 0
+}
+
+func (x *ProductResponse) GetLowestPrice_30D() float32 {
+	if x != nil {
+		return x.LowestPrice_30D
+	}
+	return 0
+}
+
 type StockResponse struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
 	InStock           bool                   `protobuf:"varint,1,opt,name=in_stock,json=inStock,proto3" json:"in_stock,omitempty"`
@@ -291,6 +323,8 @@ type StockStatus struct {
 	ProductId         string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
 	InStock           bool                   `protobuf:"varint,2,opt,name=in_stock,json=inStock,proto3" json:"in_stock,omitempty"`
 	AvailableQuantity int32                  `protobuf:"varint,3,opt,name=available_quantity,json=availableQuantity,proto3" json:"available_quantity,omitempty"`
+	RequestedQuantity int32                  `protobuf:"varint,4,opt,name=requested_quantity,json=requestedQuantity,proto3" json:"requested_quantity,omitempty"`
+	Message           string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
 	unknownFields     protoimpl.UnknownFields
 	sizeCache         protoimpl.SizeCache
 }
@@ -346,6 +380,20 @@ func (x *StockStatus) GetAvailableQuantity() int32 {
 	return 0
 }
 
+func (x *StockStatus) GetRequestedQuantity() int32 {
+	if x != nil {
+		return x.RequestedQuantity
+	}
+	return 0
+}
+
+func (x *StockStatus) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
 type UpdateStockRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Items         []*StockItem           `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
@@ -726,67 +774,921 @@ func (x *ProductList) GetProducts() []*Product {
 	return nil
 }
 
-var File_product_service_proto protoreflect.FileDescriptor
+// Batch lookup cho cart/order, đọc một lần bằng BatchGetItem
+type ProductIDsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_product_service_proto_rawDesc = "" +
-	"\n" +
-	"\x15product_service.proto\x12\aproduct\" \n" +
-	"\x0eProductRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"m\n" +
-	"\x14BasicProductResponse\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
-	"\x05price\x18\x03 \x01(\x02R\x05price\x12\x1b\n" +
-	"\tvendor_id\x18\x04 \x01(\tR\bvendorId\"\xc3\x01\n" +
-	"\x0fProductResponse\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
-	"\x05price\x18\x03 \x01(\x02R\x05price\x12 \n" +
-	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x1a\n" +
-	"\bquantity\x18\x05 \x01(\x05R\bquantity\x12\x1b\n" +
-	"\timage_url\x18\x06 \x01(\tR\bimageUrl\x12\x1b\n" +
-	"\tvendor_id\x18\a \x01(\tR\bvendorId\"s\n" +
-	"\rStockResponse\x12\x19\n" +
-	"\bin_stock\x18\x01 \x01(\bR\ainStock\x12-\n" +
-	"\x12available_quantity\x18\x02 \x01(\x05R\x11availableQuantity\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\tR\amessage\"v\n" +
-	"\vStockStatus\x12\x1d\n" +
-	"\n" +
-	"product_id\x18\x01 \x01(\tR\tproductId\x12\x19\n" +
-	"\bin_stock\x18\x02 \x01(\bR\ainStock\x12-\n" +
-	"\x12available_quantity\x18\x03 \x01(\x05R\x11availableQuantity\">\n" +
-	"\x12UpdateStockRequest\x12(\n" +
-	"\x05items\x18\x01 \x03(\v2\x12.product.StockItemR\x05items\"F\n" +
-	"\tStockItem\x12\x1d\n" +
-	"\n" +
-	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
-	"\bquantity\x18\x02 \x01(\x05R\bquantity\"\x8a\x01\n" +
-	"\x13UpdateStockResponse\x12?\n" +
-	"\rupdate_status\x18\x01 \x03(\v2\x1a.product.StockUpdateStatusR\fupdateStatus\x12\x18\n" +
-	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\tR\amessage\"f\n" +
-	"\x11StockUpdateStatus\x12\x1d\n" +
-	"\n" +
-	"product_id\x18\x01 \x01(\tR\tproductId\x12\x18\n" +
-	"\aupdated\x18\x02 \x01(\bR\aupdated\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\tR\amessage\"\a\n" +
-	"\x05Empty\"\x9e\x01\n" +
-	"\aProduct\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
-	"\x05price\x18\x03 \x01(\x02R\x05price\x12\x1a\n" +
-	"\bcategory\x18\x04 \x01(\tR\bcategory\x12 \n" +
-	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x1b\n" +
-	"\timage_url\x18\x06 \x01(\tR\bimageUrl\";\n" +
-	"\vProductList\x12,\n" +
-	"\bproducts\x18\x01 \x03(\v2\x10.product.ProductR\bproducts2\xde\x02\n" +
+func (x *ProductIDsRequest) Reset() {
+	*x = ProductIDsRequest{}
+	mi := &file_product_service_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductIDsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductIDsRequest) ProtoMessage() {}
+
+func (x *ProductIDsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_product_service_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductIDsRequest.ProtoReflect.Descriptor instead.
+func (*ProductIDsRequest) Descriptor() ([]byte, []int) {
+	return file_product_service_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ProductIDsRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type ProductSummary struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name              string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Price             float32                `protobuf:"fixed32,3,opt,name=price,proto3" json:"price,omitempty"`
+	VendorId          string                 `protobuf:"bytes,4,opt,name=vendor_id,json=vendorId,proto3" json:"vendor_id,omitempty"`
+	Status            string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	AvailableQuantity int32                  `protobuf:"varint,6,opt,name=available_quantity,json=availableQuantity,proto3" json:"available_quantity,omitempty"`
+	ProductType       string                 `protobuf:"bytes,7,opt,name=product_type,json=productType,proto3" json:"product_type,omitempty"` // "" / simple, bundle, digital
+	UnlimitedStock    bool                   `protobuf:"varint,8,opt,name=unlimited_stock,json=unlimitedStock,proto3" json:"unlimited_stock,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ProductSummary) Reset() {
+	*x = ProductSummary{}
+	mi := &file_product_service_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductSummary) ProtoMessage() {}
+
+func (x *ProductSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_product_service_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductSummary.ProtoReflect.Descriptor instead.
+func (*ProductSummary) Descriptor() ([]byte, []int) {
+	return file_product_service_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ProductSummary) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ProductSummary) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ProductSummary) GetPrice() float32 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *ProductSummary) GetVendorId() string {
+	if x != nil {
+		return x.VendorId
+	}
+	return ""
+}
+
+func (x *ProductSummary) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ProductSummary) GetAvailableQuantity() int32 {
+	if x != nil {
+		return x.AvailableQuantity
+	}
+	return 0
+}
+
+func (x *ProductSummary) GetProductType() string {
+	if x != nil {
+		return x.ProductType
+	}
+	return ""
+}
+
+func (x *ProductSummary) GetUnlimitedStock() bool {
+	if x != nil {
+		return x.UnlimitedStock
+	}
+	return false
+}
+
+type ProductBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Products      []*ProductSummary      `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	NotFoundIds   []string               `protobuf:"bytes,2,rep,name=not_found_ids,json=notFoundIds,proto3" json:"not_found_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductBatchResponse) Reset() {
+	*x = ProductBatchResponse{}
+	mi := &file_product_service_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductBatchResponse) ProtoMessage() {}
+
+func (x *ProductBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_product_service_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductBatchResponse.ProtoReflect.Descriptor instead.
+func (*ProductBatchResponse) Descriptor() ([]byte, []int) {
+	return file_product_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ProductBatchResponse) GetProducts() []*ProductSummary {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+func (x *ProductBatchResponse) GetNotFoundIds() []string {
+	if x != nil {
+		return x.NotFoundIds
+	}
+	return nil
+}
+
+type StockBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*StockItem           `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"` // quantity là số lượng cần mua
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StockBatchRequest) Reset() {
+	*x = StockBatchRequest{}
+	mi := &file_product_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StockBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StockBatchRequest) ProtoMessage() {}
+
+func (x *StockBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_product_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StockBatchRequest.ProtoReflect.Descriptor instead.
+func (*StockBatchRequest) Descriptor() ([]byte, []int) {
+	return file_product_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *StockBatchRequest) GetItems() []*StockItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type StockBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Statuses      []*StockStatus         `protobuf:"bytes,1,rep,name=statuses,proto3" json:"statuses,omitempty"`
+	AllInStock    bool                   `protobuf:"varint,2,opt,name=all_in_stock,json=allInStock,proto3" json:"all_in_stock,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StockBatchResponse) Reset() {
+	*x = StockBatchResponse{}
+	mi := &file_product_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StockBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StockBatchResponse) ProtoMessage() {}
+
+func (x *StockBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_product_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StockBatchResponse.ProtoReflect.Descriptor instead.
+func (*StockBatchResponse) Descriptor() ([]byte, []int) {
+	return file_product_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *StockBatchResponse) GetStatuses() []*StockStatus {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *StockBatchResponse) GetAllInStock() bool {
+	if x != nil {
+		return x.AllInStock
+	}
+	return false
+}
+
+// Sản phẩm số: gán license key (nếu có) cho đơn đã thanh toán, gọi lại với cùng order_id thì trả về kết quả cũ
+type DigitalFulfillmentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Items         []*StockItem           `protobuf:"bytes,3,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DigitalFulfillmentRequest) Reset() {
+	*x = DigitalFulfillmentRequest{}
+	mi := &file_product_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DigitalFulfillmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DigitalFulfillmentRequest) ProtoMessage() {}
+
+func (x *DigitalFulfillmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_product_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DigitalFulfillmentRequest.ProtoReflect.Descriptor instead.
+func (*DigitalFulfillmentRequest) Descriptor() ([]byte, []int) {
+	return file_product_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *DigitalFulfillmentRequest) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *DigitalFulfillmentRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *DigitalFulfillmentRequest) GetItems() []*StockItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type DigitalItemFulfillment struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	ProductId           string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	LicenseKeys         []string               `protobuf:"bytes,2,rep,name=license_keys,json=licenseKeys,proto3" json:"license_keys,omitempty"`
+	HasFile             bool                   `protobuf:"varint,3,opt,name=has_file,json=hasFile,proto3" json:"has_file,omitempty"`
+	DownloadLimit       int32                  `protobuf:"varint,4,opt,name=download_limit,json=downloadLimit,proto3" json:"download_limit,omitempty"`
+	DownloadExpiryHours int32                  `protobuf:"varint,5,opt,name=download_expiry_hours,json=downloadExpiryHours,proto3" json:"download_expiry_hours,omitempty"`
+	MissingKeys         int32                  `protobuf:"varint,6,opt,name=missing_keys,json=missingKeys,proto3" json:"missing_keys,omitempty"` // số key chưa gán được (hết key / lỗi), gọi lại với cùng order_id để gán tiếp
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *DigitalItemFulfillment) Reset() {
+	*x = DigitalItemFulfillment{}
+	mi := &file_product_service_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DigitalItemFulfillment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DigitalItemFulfillment) ProtoMessage() {}
+
+func (x *DigitalItemFulfillment) ProtoReflect() protoreflect.Message {
+	mi := &file_product_service_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DigitalItemFulfillment.ProtoReflect.Descriptor instead.
+func (*DigitalItemFulfillment) Descriptor() ([]byte, []int) {
+	return file_product_service_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *DigitalItemFulfillment) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *DigitalItemFulfillment) GetLicenseKeys() []string {
+	if x != nil {
+		return x.LicenseKeys
+	}
+	return nil
+}
+
+func (x *DigitalItemFulfillment) GetHasFile() bool {
+	if x != nil {
+		return x.HasFile
+	}
+	return false
+}
+
+func (x *DigitalItemFulfillment) GetDownloadLimit() int32 {
+	if x != nil {
+		return x.DownloadLimit
+	}
+	return 0
+}
+
+func (x *DigitalItemFulfillment) GetDownloadExpiryHours() int32 {
+	if x != nil {
+		return x.DownloadExpiryHours
+	}
+	return 0
+}
+
+func (x *DigitalItemFulfillment) GetMissingKeys() int32 {
+	if x != nil {
+		return x.MissingKeys
+	}
+	return 0
+}
+
+type DigitalFulfillmentResponse struct {
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	Items         []*DigitalItemFulfillment `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DigitalFulfillmentResponse) Reset() {
+	*x = DigitalFulfillmentResponse{}
+	mi := &file_product_service_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DigitalFulfillmentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DigitalFulfillmentResponse) ProtoMessage() {}
+
+func (x *DigitalFulfillmentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_product_service_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DigitalFulfillmentResponse.ProtoReflect.Descriptor instead.
+func (*DigitalFulfillmentResponse) Descriptor() ([]byte, []int) {
+	return file_product_service_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DigitalFulfillmentResponse) GetItems() []*DigitalItemFulfillment {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type DigitalDownloadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DigitalDownloadRequest) Reset() {
+	*x = DigitalDownloadRequest{}
+	mi := &file_product_service_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DigitalDownloadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DigitalDownloadRequest) ProtoMessage() {}
+
+func (x *DigitalDownloadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_product_service_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DigitalDownloadRequest.ProtoReflect.Descriptor instead.
+func (*DigitalDownloadRequest) Descriptor() ([]byte, []int) {
+	return file_product_service_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *DigitalDownloadRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+type DigitalDownloadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	FileName      string                 `protobuf:"bytes,2,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	ExpiresAt     int64                  `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // unix seconds
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DigitalDownloadResponse) Reset() {
+	*x = DigitalDownloadResponse{}
+	mi := &file_product_service_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DigitalDownloadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DigitalDownloadResponse) ProtoMessage() {}
+
+func (x *DigitalDownloadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_product_service_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DigitalDownloadResponse.ProtoReflect.Descriptor instead.
+func (*DigitalDownloadResponse) Descriptor() ([]byte, []int) {
+	return file_product_service_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DigitalDownloadResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *DigitalDownloadResponse) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *DigitalDownloadResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+// Export toàn bộ sản phẩm cho search-service reindex, stream từng sản phẩm thay vì một message lớn
+type ExportProductsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportProductsRequest) Reset() {
+	*x = ExportProductsRequest{}
+	mi := &file_product_service_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportProductsRequest) ProtoMessage() {}
+
+func (x *ExportProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_product_service_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportProductsRequest.ProtoReflect.Descriptor instead.
+func (*ExportProductsRequest) Descriptor() ([]byte, []int) {
+	return file_product_service_proto_rawDescGZIP(), []int{22}
+}
+
+type ExportedProduct struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name           string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Price          float64                `protobuf:"fixed64,3,opt,name=price,proto3" json:"price,omitempty"`
+	Category       string                 `protobuf:"bytes,4,opt,name=category,proto3" json:"category,omitempty"`
+	Description    string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	ImageUrl       string                 `protobuf:"bytes,6,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
+	Quantity       int32                  `protobuf:"varint,7,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	SoldCount      int32                  `protobuf:"varint,8,opt,name=sold_count,json=soldCount,proto3" json:"sold_count,omitempty"`
+	Rating         float64                `protobuf:"fixed64,9,opt,name=rating,proto3" json:"rating,omitempty"`
+	RatingCount    int32                  `protobuf:"varint,10,opt,name=rating_count,json=ratingCount,proto3" json:"rating_count,omitempty"`
+	UserId         string                 `protobuf:"bytes,11,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Status         string                 `protobuf:"bytes,12,opt,name=status,proto3" json:"status,omitempty"`
+	AttributesJson string                 `protobuf:"bytes,13,opt,name=attributes_json,json=attributesJson,proto3" json:"attributes_json,omitempty"` // map thuộc tính dạng JSON
+	CreatedAt      int64                  `protobuf:"varint,14,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`               // unix milliseconds
+	UpdatedAt      int64                  `protobuf:"varint,15,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`               // unix milliseconds
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ExportedProduct) Reset() {
+	*x = ExportedProduct{}
+	mi := &file_product_service_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportedProduct) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportedProduct) ProtoMessage() {}
+
+func (x *ExportedProduct) ProtoReflect() protoreflect.Message {
+	mi := &file_product_service_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportedProduct.ProtoReflect.Descriptor instead.
+func (*ExportedProduct) Descriptor() ([]byte, []int) {
+	return file_product_service_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ExportedProduct) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ExportedProduct) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ExportedProduct) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *ExportedProduct) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *ExportedProduct) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ExportedProduct) GetImageUrl() string {
+	if x != nil {
+		return x.ImageUrl
+	}
+	return ""
+}
+
+func (x *ExportedProduct) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *ExportedProduct) GetSoldCount() int32 {
+	if x != nil {
+		return x.SoldCount
+	}
+	return 0
+}
+
+func (x *ExportedProduct) GetRating() float64 {
+	if x != nil {
+		return x.Rating
+	}
+	return 0
+}
+
+func (x *ExportedProduct) GetRatingCount() int32 {
+	if x != nil {
+		return x.RatingCount
+	}
+	return 0
+}
+
+func (x *ExportedProduct) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ExportedProduct) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ExportedProduct) GetAttributesJson() string {
+	if x != nil {
+		return x.AttributesJson
+	}
+	return ""
+}
+
+func (x *ExportedProduct) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *ExportedProduct) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+var File_product_service_proto protoreflect.FileDescriptor
+
+const file_product_service_proto_rawDesc = "" +
+	"\n" +
+	"\x15product_service.proto\x12\aproduct\" \n" +
+	"\x0eProductRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"m\n" +
+	"\x14BasicProductResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\x02R\x05price\x12\x1b\n" +
+	"\tvendor_id\x18\x04 \x01(\tR\bvendorId\"\xcb\x02\n" +
+	"\x0fProductResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\x02R\x05price\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bquantity\x18\x05 \x01(\x05R\bquantity\x12\x1b\n" +
+	"\timage_url\x18\x06 \x01(\tR\bimageUrl\x12\x1b\n" +
+	"\tvendor_id\x18\a \x01(\tR\bvendorId\x12#\n" +
+	"\rregular_price\x18\b \x01(\x02R\fregularPrice\x12\x17\n" +
+	"\aon_sale\x18\t \x01(\bR\x06onSale\x12\x1e\n" +
+	"\vsale_end_at\x18\n" +
+	" \x01(\x03R\tsaleEndAt\x12(\n" +
+	"\x10lowest_price_30d\x18\v \x01(\x02R\x0elowestPrice30d\"s\n" +
+	"\rStockResponse\x12\x19\n" +
+	"\bin_stock\x18\x01 \x01(\bR\ainStock\x12-\n" +
+	"\x12available_quantity\x18\x02 \x01(\x05R\x11availableQuantity\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"\xbf\x01\n" +
+	"\vStockStatus\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x19\n" +
+	"\bin_stock\x18\x02 \x01(\bR\ainStock\x12-\n" +
+	"\x12available_quantity\x18\x03 \x01(\x05R\x11availableQuantity\x12-\n" +
+	"\x12requested_quantity\x18\x04 \x01(\x05R\x11requestedQuantity\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\">\n" +
+	"\x12UpdateStockRequest\x12(\n" +
+	"\x05items\x18\x01 \x03(\v2\x12.product.StockItemR\x05items\"F\n" +
+	"\tStockItem\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\"\x8a\x01\n" +
+	"\x13UpdateStockResponse\x12?\n" +
+	"\rupdate_status\x18\x01 \x03(\v2\x1a.product.StockUpdateStatusR\fupdateStatus\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"f\n" +
+	"\x11StockUpdateStatus\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x18\n" +
+	"\aupdated\x18\x02 \x01(\bR\aupdated\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"\a\n" +
+	"\x05Empty\"\x9e\x01\n" +
+	"\aProduct\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\x02R\x05price\x12\x1a\n" +
+	"\bcategory\x18\x04 \x01(\tR\bcategory\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x1b\n" +
+	"\timage_url\x18\x06 \x01(\tR\bimageUrl\";\n" +
+	"\vProductList\x12,\n" +
+	"\bproducts\x18\x01 \x03(\v2\x10.product.ProductR\bproducts\"%\n" +
+	"\x11ProductIDsRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\"\xfa\x01\n" +
+	"\x0eProductSummary\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\x02R\x05price\x12\x1b\n" +
+	"\tvendor_id\x18\x04 \x01(\tR\bvendorId\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12-\n" +
+	"\x12available_quantity\x18\x06 \x01(\x05R\x11availableQuantity\x12!\n" +
+	"\fproduct_type\x18\a \x01(\tR\vproductType\x12'\n" +
+	"\x0funlimited_stock\x18\b \x01(\bR\x0eunlimitedStock\"o\n" +
+	"\x14ProductBatchResponse\x123\n" +
+	"\bproducts\x18\x01 \x03(\v2\x17.product.ProductSummaryR\bproducts\x12\"\n" +
+	"\rnot_found_ids\x18\x02 \x03(\tR\vnotFoundIds\"=\n" +
+	"\x11StockBatchRequest\x12(\n" +
+	"\x05items\x18\x01 \x03(\v2\x12.product.StockItemR\x05items\"h\n" +
+	"\x12StockBatchResponse\x120\n" +
+	"\bstatuses\x18\x01 \x03(\v2\x14.product.StockStatusR\bstatuses\x12 \n" +
+	"\fall_in_stock\x18\x02 \x01(\bR\n" +
+	"allInStock\"y\n" +
+	"\x19DigitalFulfillmentRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12(\n" +
+	"\x05items\x18\x03 \x03(\v2\x12.product.StockItemR\x05items\"\xf3\x01\n" +
+	"\x16DigitalItemFulfillment\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12!\n" +
+	"\flicense_keys\x18\x02 \x03(\tR\vlicenseKeys\x12\x19\n" +
+	"\bhas_file\x18\x03 \x01(\bR\ahasFile\x12%\n" +
+	"\x0edownload_limit\x18\x04 \x01(\x05R\rdownloadLimit\x122\n" +
+	"\x15download_expiry_hours\x18\x05 \x01(\x05R\x13downloadExpiryHours\x12!\n" +
+	"\fmissing_keys\x18\x06 \x01(\x05R\vmissingKeys\"S\n" +
+	"\x1aDigitalFulfillmentResponse\x125\n" +
+	"\x05items\x18\x01 \x03(\v2\x1f.product.DigitalItemFulfillmentR\x05items\"7\n" +
+	"\x16DigitalDownloadRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\"g\n" +
+	"\x17DigitalDownloadResponse\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x1b\n" +
+	"\tfile_name\x18\x02 \x01(\tR\bfileName\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\"\x17\n" +
+	"\x15ExportProductsRequest\"\xb4\x03\n" +
+	"\x0fExportedProduct\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\x01R\x05price\x12\x1a\n" +
+	"\bcategory\x18\x04 \x01(\tR\bcategory\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x1b\n" +
+	"\timage_url\x18\x06 \x01(\tR\bimageUrl\x12\x1a\n" +
+	"\bquantity\x18\a \x01(\x05R\bquantity\x12\x1d\n" +
+	"\n" +
+	"sold_count\x18\b \x01(\x05R\tsoldCount\x12\x16\n" +
+	"\x06rating\x18\t \x01(\x01R\x06rating\x12!\n" +
+	"\frating_count\x18\n" +
+	" \x01(\x05R\vratingCount\x12\x17\n" +
+	"\auser_id\x18\v \x01(\tR\x06userId\x12\x16\n" +
+	"\x06status\x18\f \x01(\tR\x06status\x12'\n" +
+	"\x0fattributes_json\x18\r \x01(\tR\x0eattributesJson\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x0e \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x0f \x01(\x03R\tupdatedAt2\x83\x06\n" +
 	"\x0eProductService\x12F\n" +
 	"\fGetBasicInfo\x12\x17.product.ProductRequest\x1a\x1d.product.BasicProductResponse\x12C\n" +
 	"\x0eGetProductInfo\x12\x17.product.ProductRequest\x1a\x18.product.ProductResponse\x12=\n" +
 	"\n" +
 	"CheckStock\x12\x17.product.ProductRequest\x1a\x16.product.StockResponse\x12H\n" +
 	"\vUpdateStock\x12\x1b.product.UpdateStockRequest\x1a\x1c.product.UpdateStockResponse\x126\n" +
-	"\x0eGetAllProducts\x12\x0e.product.Empty\x1a\x14.product.ProductListB\x1dZ\x1bmodule/gRPC-Product/serviceb\x06proto3"
+	"\x0eGetAllProducts\x12\x0e.product.Empty\x1a\x14.product.ProductList\x12M\n" +
+	"\x10GetProductsByIDs\x12\x1a.product.ProductIDsRequest\x1a\x1d.product.ProductBatchResponse\x12J\n" +
+	"\x0fCheckStockBatch\x12\x1a.product.StockBatchRequest\x1a\x1b.product.StockBatchResponse\x12^\n" +
+	"\x13FulfillDigitalItems\x12\".product.DigitalFulfillmentRequest\x1a#.product.DigitalFulfillmentResponse\x12Z\n" +
+	"\x15GetDigitalDownloadURL\x12\x1f.product.DigitalDownloadRequest\x1a .product.DigitalDownloadResponse\x12L\n" +
+	"\x0eExportProducts\x12\x1e.product.ExportProductsRequest\x1a\x18.product.ExportedProduct0\x01B\x1dZ\x1bmodule/gRPC-Product/serviceb\x06proto3"
 
 var (
 	file_product_service_proto_rawDescOnce sync.Once
@@ -800,40 +1702,67 @@ func file_product_service_proto_rawDescGZIP() []byte {
 	return file_product_service_proto_rawDescData
 }
 
-var file_product_service_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_product_service_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
 var file_product_service_proto_goTypes = []any{
-	(*ProductRequest)(nil),       // 0: product.ProductRequest
-	(*BasicProductResponse)(nil), // 1: product.BasicProductResponse
-	(*ProductResponse)(nil),      // 2: product.ProductResponse
-	(*StockResponse)(nil),        // 3: product.StockResponse
-	(*StockStatus)(nil),          // 4: product.StockStatus
-	(*UpdateStockRequest)(nil),   // 5: product.UpdateStockRequest
-	(*StockItem)(nil),            // 6: product.StockItem
-	(*UpdateStockResponse)(nil),  // 7: product.UpdateStockResponse
-	(*StockUpdateStatus)(nil),    // 8: product.StockUpdateStatus
-	(*Empty)(nil),                // 9: product.Empty
-	(*Product)(nil),              // 10: product.Product
-	(*ProductList)(nil),          // 11: product.ProductList
+	(*ProductRequest)(nil),             // 0: product.ProductRequest
+	(*BasicProductResponse)(nil),       // 1: product.BasicProductResponse
+	(*ProductResponse)(nil),            // 2: product.ProductResponse
+	(*StockResponse)(nil),              // 3: product.StockResponse
+	(*StockStatus)(nil),                // 4: product.StockStatus
+	(*UpdateStockRequest)(nil),         // 5: product.UpdateStockRequest
+	(*StockItem)(nil),                  // 6: product.StockItem
+	(*UpdateStockResponse)(nil),        // 7: product.UpdateStockResponse
+	(*StockUpdateStatus)(nil),          // 8: product.StockUpdateStatus
+	(*Empty)(nil),                      // 9: product.Empty
+	(*Product)(nil),                    // 10: product.Product
+	(*ProductList)(nil),                // 11: product.ProductList
+	(*ProductIDsRequest)(nil),          // 12: product.ProductIDsRequest
+	(*ProductSummary)(nil),             // 13: product.ProductSummary
+	(*ProductBatchResponse)(nil),       // 14: product.ProductBatchResponse
+	(*StockBatchRequest)(nil),          // 15: product.StockBatchRequest
+	(*StockBatchResponse)(nil),         // 16: product.StockBatchResponse
+	(*DigitalFulfillmentRequest)(nil),  // 17: product.DigitalFulfillmentRequest
+	(*DigitalItemFulfillment)(nil),     // 18: product.DigitalItemFulfillment
+	(*DigitalFulfillmentResponse)(nil), // 19: product.DigitalFulfillmentResponse
+	(*DigitalDownloadRequest)(nil),     // 20: product.DigitalDownloadRequest
+	(*DigitalDownloadResponse)(nil),    // 21: product.DigitalDownloadResponse
+	(*ExportProductsRequest)(nil),      // 22: product.ExportProductsRequest
+	(*ExportedProduct)(nil),            // 23: product.ExportedProduct
 }
 var file_product_service_proto_depIdxs = []int32{
 	6,  // 0: product.UpdateStockRequest.items:type_name -> product.StockItem
 	8,  // 1: product.UpdateStockResponse.update_status:type_name -> product.StockUpdateStatus
 	10, // 2: product.ProductList.products:type_name -> product.Product
-	0,  // 3: product.ProductService.GetBasicInfo:input_type -> product.ProductRequest
-	0,  // 4: product.ProductService.GetProductInfo:input_type -> product.ProductRequest
-	0,  // 5: product.ProductService.CheckStock:input_type -> product.ProductRequest
-	5,  // 6: product.ProductService.UpdateStock:input_type -> product.UpdateStockRequest
-	9,  // 7: product.ProductService.GetAllProducts:input_type -> product.Empty
-	1,  // 8: product.ProductService.GetBasicInfo:output_type -> product.BasicProductResponse
-	2,  // 9: product.ProductService.GetProductInfo:output_type -> product.ProductResponse
-	3,  // 10: product.ProductService.CheckStock:output_type -> product.StockResponse
-	7,  // 11: product.ProductService.UpdateStock:output_type -> product.UpdateStockResponse
-	11, // 12: product.ProductService.GetAllProducts:output_type -> product.ProductList
-	8,  // [8:13] is the sub-list for method output_type
-	3,  // [3:8] is the sub-list for method input_type
-	3,  // [3:3] is the sub-list for extension type_name
-	3,  // [3:3] is the sub-list for extension extendee
-	0,  // [0:3] is the sub-list for field type_name
+	13, // 3: product.ProductBatchResponse.products:type_name -> product.ProductSummary
+	6,  // 4: product.StockBatchRequest.items:type_name -> product.StockItem
+	4,  // 5: product.StockBatchResponse.statuses:type_name -> product.StockStatus
+	6,  // 6: product.DigitalFulfillmentRequest.items:type_name -> product.StockItem
+	18, // 7: product.DigitalFulfillmentResponse.items:type_name -> product.DigitalItemFulfillment
+	0,  // 8: product.ProductService.GetBasicInfo:input_type -> product.ProductRequest
+	0,  // 9: product.ProductService.GetProductInfo:input_type -> product.ProductRequest
+	0,  // 10: product.ProductService.CheckStock:input_type -> product.ProductRequest
+	5,  // 11: product.ProductService.UpdateStock:input_type -> product.UpdateStockRequest
+	9,  // 12: product.ProductService.GetAllProducts:input_type -> product.Empty
+	12, // 13: product.ProductService.GetProductsByIDs:input_type -> product.ProductIDsRequest
+	15, // 14: product.ProductService.CheckStockBatch:input_type -> product.StockBatchRequest
+	17, // 15: product.ProductService.FulfillDigitalItems:input_type -> product.DigitalFulfillmentRequest
+	20, // 16: product.ProductService.GetDigitalDownloadURL:input_type -> product.DigitalDownloadRequest
+	22, // 17: product.ProductService.ExportProducts:input_type -> product.ExportProductsRequest
+	1,  // 18: product.ProductService.GetBasicInfo:output_type -> product.BasicProductResponse
+	2,  // 19: product.ProductService.GetProductInfo:output_type -> product.ProductResponse
+	3,  // 20: product.ProductService.CheckStock:output_type -> product.StockResponse
+	7,  // 21: product.ProductService.UpdateStock:output_type -> product.UpdateStockResponse
+	11, // 22: product.ProductService.GetAllProducts:output_type -> product.ProductList
+	14, // 23: product.ProductService.GetProductsByIDs:output_type -> product.ProductBatchResponse
+	16, // 24: product.ProductService.CheckStockBatch:output_type -> product.StockBatchResponse
+	19, // 25: product.ProductService.FulfillDigitalItems:output_type -> product.DigitalFulfillmentResponse
+	21, // 26: product.ProductService.GetDigitalDownloadURL:output_type -> product.DigitalDownloadResponse
+	23, // 27: product.ProductService.ExportProducts:output_type -> product.ExportedProduct
+	18, // [18:28] is the sub-list for method output_type
+	8,  // [8:18] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_product_service_proto_init() }
@@ -847,7 +1776,7 @@ func file_product_service_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_product_service_proto_rawDesc), len(file_product_service_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   12,
+			NumMessages:   24,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,11 +19,16 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ProductService_GetBasicInfo_FullMethodName   = "/product.ProductService/GetBasicInfo"
-	ProductService_GetProductInfo_FullMethodName = "/product.ProductService/GetProductInfo"
-	ProductService_CheckStock_FullMethodName     = "/product.ProductService/CheckStock"
-	ProductService_UpdateStock_FullMethodName    = "/product.ProductService/UpdateStock"
-	ProductService_GetAllProducts_FullMethodName = "/product.ProductService/GetAllProducts"
+	ProductService_GetBasicInfo_FullMethodName          = "/product.ProductService/GetBasicInfo"
+	ProductService_GetProductInfo_FullMethodName        = "/product.ProductService/GetProductInfo"
+	ProductService_CheckStock_FullMethodName            = "/product.ProductService/CheckStock"
+	ProductService_UpdateStock_FullMethodName           = "/product.ProductService/UpdateStock"
+	ProductService_GetAllProducts_FullMethodName        = "/product.ProductService/GetAllProducts"
+	ProductService_GetProductsByIDs_FullMethodName      = "/product.ProductService/GetProductsByIDs"
+	ProductService_CheckStockBatch_FullMethodName       = "/product.ProductService/CheckStockBatch"
+	ProductService_FulfillDigitalItems_FullMethodName   = "/product.ProductService/FulfillDigitalItems"
+	ProductService_GetDigitalDownloadURL_FullMethodName = "/product.ProductService/GetDigitalDownloadURL"
+	ProductService_ExportProducts_FullMethodName        = "/product.ProductService/ExportProducts"
 )
 
 // ProductServiceClient is the client API for ProductService service.
@@ -37,6 +42,11 @@ type ProductServiceClient interface {
 	CheckStock(ctx context.Context, in *ProductRequest, opts ...grpc.CallOption) (*StockResponse, error)
 	UpdateStock(ctx context.Context, in *UpdateStockRequest, opts ...grpc.CallOption) (*UpdateStockResponse, error)
 	GetAllProducts(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ProductList, error)
+	GetProductsByIDs(ctx context.Context, in *ProductIDsRequest, opts ...grpc.CallOption) (*ProductBatchResponse, error)
+	CheckStockBatch(ctx context.Context, in *StockBatchRequest, opts ...grpc.CallOption) (*StockBatchResponse, error)
+	FulfillDigitalItems(ctx context.Context, in *DigitalFulfillmentRequest, opts ...grpc.CallOption) (*DigitalFulfillmentResponse, error)
+	GetDigitalDownloadURL(ctx context.Context, in *DigitalDownloadRequest, opts ...grpc.CallOption) (*DigitalDownloadResponse, error)
+	ExportProducts(ctx context.Context, in *ExportProductsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportedProduct], error)
 }
 
 type productServiceClient struct {
@@ -97,6 +107,65 @@ func (c *productServiceClient) GetAllProducts(ctx context.Context, in *Empty, op
 	return out, nil
 }
 
+func (c *productServiceClient) GetProductsByIDs(ctx context.Context, in *ProductIDsRequest, opts ...grpc.CallOption) (*ProductBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProductBatchResponse)
+	err := c.cc.Invoke(ctx, ProductService_GetProductsByIDs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) CheckStockBatch(ctx context.Context, in *StockBatchRequest, opts ...grpc.CallOption) (*StockBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StockBatchResponse)
+	err := c.cc.Invoke(ctx, ProductService_CheckStockBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) FulfillDigitalItems(ctx context.Context, in *DigitalFulfillmentRequest, opts ...grpc.CallOption) (*DigitalFulfillmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DigitalFulfillmentResponse)
+	err := c.cc.Invoke(ctx, ProductService_FulfillDigitalItems_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) GetDigitalDownloadURL(ctx context.Context, in *DigitalDownloadRequest, opts ...grpc.CallOption) (*DigitalDownloadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DigitalDownloadResponse)
+	err := c.cc.Invoke(ctx, ProductService_GetDigitalDownloadURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) ExportProducts(ctx context.Context, in *ExportProductsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportedProduct], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ProductService_ServiceDesc.Streams[0], ProductService_ExportProducts_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportProductsRequest, ExportedProduct]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ProductService_ExportProductsClient = grpc.ServerStreamingClient[ExportedProduct]
+
 // ProductServiceServer is the server API for ProductService service.
 // All implementations must embed UnimplementedProductServiceServer
 // for forward compatibility.
@@ -108,6 +177,11 @@ type ProductServiceServer interface {
 	CheckStock(context.Context, *ProductRequest) (*StockResponse, error)
 	UpdateStock(context.Context, *UpdateStockRequest) (*UpdateStockResponse, error)
 	GetAllProducts(context.Context, *Empty) (*ProductList, error)
+	GetProductsByIDs(context.Context, *ProductIDsRequest) (*ProductBatchResponse, error)
+	CheckStockBatch(context.Context, *StockBatchRequest) (*StockBatchResponse, error)
+	FulfillDigitalItems(context.Context, *DigitalFulfillmentRequest) (*DigitalFulfillmentResponse, error)
+	GetDigitalDownloadURL(context.Context, *DigitalDownloadRequest) (*DigitalDownloadResponse, error)
+	ExportProducts(*ExportProductsRequest, grpc.ServerStreamingServer[ExportedProduct]) error
 	mustEmbedUnimplementedProductServiceServer()
 }
 
@@ -133,6 +207,21 @@ func (UnimplementedProductServiceServer) UpdateStock(context.Context, *UpdateSto
 func (UnimplementedProductServiceServer) GetAllProducts(context.Context, *Empty) (*ProductList, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAllProducts not implemented")
 }
+func (UnimplementedProductServiceServer) GetProductsByIDs(context.Context, *ProductIDsRequest) (*ProductBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProductsByIDs not implemented")
+}
+func (UnimplementedProductServiceServer) CheckStockBatch(context.Context, *StockBatchRequest) (*StockBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckStockBatch not implemented")
+}
+func (UnimplementedProductServiceServer) FulfillDigitalItems(context.Context, *DigitalFulfillmentRequest) (*DigitalFulfillmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FulfillDigitalItems not implemented")
+}
+func (UnimplementedProductServiceServer) GetDigitalDownloadURL(context.Context, *DigitalDownloadRequest) (*DigitalDownloadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDigitalDownloadURL not implemented")
+}
+func (UnimplementedProductServiceServer) ExportProducts(*ExportProductsRequest, grpc.ServerStreamingServer[ExportedProduct]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportProducts not implemented")
+}
 func (UnimplementedProductServiceServer) mustEmbedUnimplementedProductServiceServer() {}
 func (UnimplementedProductServiceServer) testEmbeddedByValue()                        {}
 
@@ -244,6 +333,89 @@ func _ProductService_GetAllProducts_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProductService_GetProductsByIDs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProductIDsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).GetProductsByIDs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_GetProductsByIDs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).GetProductsByIDs(ctx, req.(*ProductIDsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_CheckStockBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StockBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).CheckStockBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_CheckStockBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).CheckStockBatch(ctx, req.(*StockBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_FulfillDigitalItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DigitalFulfillmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).FulfillDigitalItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_FulfillDigitalItems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).FulfillDigitalItems(ctx, req.(*DigitalFulfillmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_GetDigitalDownloadURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DigitalDownloadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).GetDigitalDownloadURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_GetDigitalDownloadURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).GetDigitalDownloadURL(ctx, req.(*DigitalDownloadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_ExportProducts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportProductsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ProductServiceServer).ExportProducts(m, &grpc.GenericServerStream[ExportProductsRequest, ExportedProduct]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ProductService_ExportProductsServer = grpc.ServerStreamingServer[ExportedProduct]
+
 // ProductService_ServiceDesc is the grpc.ServiceDesc for ProductService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -271,7 +443,29 @@ var ProductService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetAllProducts",
 			Handler:    _ProductService_GetAllProducts_Handler,
 		},
+		{
+			MethodName: "GetProductsByIDs",
+			Handler:    _ProductService_GetProductsByIDs_Handler,
+		},
+		{
+			MethodName: "CheckStockBatch",
+			Handler:    _ProductService_CheckStockBatch_Handler,
+		},
+		{
+			MethodName: "FulfillDigitalItems",
+			Handler:    _ProductService_FulfillDigitalItems_Handler,
+		},
+		{
+			MethodName: "GetDigitalDownloadURL",
+			Handler:    _ProductService_GetDigitalDownloadURL_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportProducts",
+			Handler:       _ProductService_ExportProducts_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "product_service.proto",
 }
@@ -22,8 +22,11 @@ const (
 )
 
 type CartRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// true: đối chiếu giỏ với product-service và ghi lại giá / bỏ item hết hàng (chỉ order-service lúc checkout bật),
+	// false: chỉ đọc giỏ hiện tại
+	Revalidate    bool `protobuf:"varint,2,opt,name=revalidate,proto3" json:"revalidate,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,9 +68,18 @@ func (x *CartRequest) GetUserId() string {
 	return ""
 }
 
+func (x *CartRequest) GetRevalidate() bool {
+	if x != nil {
+		return x.Revalidate
+	}
+	return false
+}
+
 type CartResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Items         []*CartItem            `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Items []*CartItem            `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	// thay đổi phát hiện khi đối chiếu giỏ với product-service (giá, tồn kho, ngừng bán)
+	Notices       []*CartNotice `protobuf:"bytes,2,rep,name=notices,proto3" json:"notices,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -109,6 +121,73 @@ func (x *CartResponse) GetItems() []*CartItem {
 	return nil
 }
 
+func (x *CartResponse) GetNotices() []*CartNotice {
+	if x != nil {
+		return x.Notices
+	}
+	return nil
+}
+
+type CartNotice struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CartNotice) Reset() {
+	*x = CartNotice{}
+	mi := &file_cart_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CartNotice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CartNotice) ProtoMessage() {}
+
+func (x *CartNotice) ProtoReflect() protoreflect.Message {
+	mi := &file_cart_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CartNotice.ProtoReflect.Descriptor instead.
+func (*CartNotice) Descriptor() ([]byte, []int) {
+	return file_cart_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CartNotice) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *CartNotice) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *CartNotice) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
 type CartItem struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
@@ -122,7 +201,7 @@ type CartItem struct {
 
 func (x *CartItem) Reset() {
 	*x = CartItem{}
-	mi := &file_cart_service_proto_msgTypes[2]
+	mi := &file_cart_service_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -134,7 +213,7 @@ func (x *CartItem) String() string {
 func (*CartItem) ProtoMessage() {}
 
 func (x *CartItem) ProtoReflect() protoreflect.Message {
-	mi := &file_cart_service_proto_msgTypes[2]
+	mi := &file_cart_service_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -147,7 +226,7 @@ func (x *CartItem) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CartItem.ProtoReflect.Descriptor instead.
 func (*CartItem) Descriptor() ([]byte, []int) {
-	return file_cart_service_proto_rawDescGZIP(), []int{2}
+	return file_cart_service_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *CartItem) GetProductId() string {
@@ -185,24 +264,146 @@ func (x *CartItem) GetVendorId() string {
 	return ""
 }
 
+type CouponRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CouponRequest) Reset() {
+	*x = CouponRequest{}
+	mi := &file_cart_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CouponRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CouponRequest) ProtoMessage() {}
+
+func (x *CouponRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cart_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CouponRequest.ProtoReflect.Descriptor instead.
+func (*CouponRequest) Descriptor() ([]byte, []int) {
+	return file_cart_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CouponRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CouponRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type CouponResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Percent       int32                  `protobuf:"varint,2,opt,name=percent,proto3" json:"percent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CouponResponse) Reset() {
+	*x = CouponResponse{}
+	mi := &file_cart_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CouponResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CouponResponse) ProtoMessage() {}
+
+func (x *CouponResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cart_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CouponResponse.ProtoReflect.Descriptor instead.
+func (*CouponResponse) Descriptor() ([]byte, []int) {
+	return file_cart_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CouponResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *CouponResponse) GetPercent() int32 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
 var File_cart_service_proto protoreflect.FileDescriptor
 
 const file_cart_service_proto_rawDesc = "" +
 	"\n" +
-	"\x12cart_service.proto\x12\x04cart\"&\n" +
+	"\x12cart_service.proto\x12\x04cart\"F\n" +
 	"\vCartRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\"4\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1e\n" +
+	"\n" +
+	"revalidate\x18\x02 \x01(\bR\n" +
+	"revalidate\"`\n" +
 	"\fCartResponse\x12$\n" +
-	"\x05items\x18\x01 \x03(\v2\x0e.cart.CartItemR\x05items\"\x8c\x01\n" +
+	"\x05items\x18\x01 \x03(\v2\x0e.cart.CartItemR\x05items\x12*\n" +
+	"\anotices\x18\x02 \x03(\v2\x10.cart.CartNoticeR\anotices\"Y\n" +
+	"\n" +
+	"CartNotice\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"\x8c\x01\n" +
 	"\bCartItem\x12\x1d\n" +
 	"\n" +
 	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
 	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12\x14\n" +
 	"\x05price\x18\x03 \x01(\x02R\x05price\x12\x12\n" +
 	"\x04name\x18\x04 \x01(\tR\x04name\x12\x1b\n" +
-	"\tvendor_id\x18\x05 \x01(\tR\bvendorId2D\n" +
+	"\tvendor_id\x18\x05 \x01(\tR\bvendorId\"<\n" +
+	"\rCouponRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\">\n" +
+	"\x0eCouponResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\apercent\x18\x02 \x01(\x05R\apercent2\xbb\x01\n" +
 	"\vCartService\x125\n" +
-	"\fGetCartItems\x12\x11.cart.CartRequest\x1a\x12.cart.CartResponseB\x1cZ\x1a./module/gRPC-cart/serviceb\x06proto3"
+	"\fGetCartItems\x12\x11.cart.CartRequest\x1a\x12.cart.CartResponse\x129\n" +
+	"\fRedeemCoupon\x12\x13.cart.CouponRequest\x1a\x14.cart.CouponResponse\x12:\n" +
+	"\rReleaseCoupon\x12\x13.cart.CouponRequest\x1a\x14.cart.CouponResponseB\x1cZ\x1a./module/gRPC-cart/serviceb\x06proto3"
 
 var (
 	file_cart_service_proto_rawDescOnce sync.Once
@@ -216,21 +417,29 @@ func file_cart_service_proto_rawDescGZIP() []byte {
 	return file_cart_service_proto_rawDescData
 }
 
-var file_cart_service_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_cart_service_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_cart_service_proto_goTypes = []any{
-	(*CartRequest)(nil),  // 0: cart.CartRequest
-	(*CartResponse)(nil), // 1: cart.CartResponse
-	(*CartItem)(nil),     // 2: cart.CartItem
+	(*CartRequest)(nil),    // 0: cart.CartRequest
+	(*CartResponse)(nil),   // 1: cart.CartResponse
+	(*CartNotice)(nil),     // 2: cart.CartNotice
+	(*CartItem)(nil),       // 3: cart.CartItem
+	(*CouponRequest)(nil),  // 4: cart.CouponRequest
+	(*CouponResponse)(nil), // 5: cart.CouponResponse
 }
 var file_cart_service_proto_depIdxs = []int32{
-	2, // 0: cart.CartResponse.items:type_name -> cart.CartItem
-	0, // 1: cart.CartService.GetCartItems:input_type -> cart.CartRequest
-	1, // 2: cart.CartService.GetCartItems:output_type -> cart.CartResponse
-	2, // [2:3] is the sub-list for method output_type
-	1, // [1:2] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	3, // 0: cart.CartResponse.items:type_name -> cart.CartItem
+	2, // 1: cart.CartResponse.notices:type_name -> cart.CartNotice
+	0, // 2: cart.CartService.GetCartItems:input_type -> cart.CartRequest
+	4, // 3: cart.CartService.RedeemCoupon:input_type -> cart.CouponRequest
+	4, // 4: cart.CartService.ReleaseCoupon:input_type -> cart.CouponRequest
+	1, // 5: cart.CartService.GetCartItems:output_type -> cart.CartResponse
+	5, // 6: cart.CartService.RedeemCoupon:output_type -> cart.CouponResponse
+	5, // 7: cart.CartService.ReleaseCoupon:output_type -> cart.CouponResponse
+	5, // [5:8] is the sub-list for method output_type
+	2, // [2:5] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_cart_service_proto_init() }
@@ -244,7 +453,7 @@ func file_cart_service_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cart_service_proto_rawDesc), len(file_cart_service_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,7 +19,9 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CartService_GetCartItems_FullMethodName = "/cart.CartService/GetCartItems"
+	CartService_GetCartItems_FullMethodName  = "/cart.CartService/GetCartItems"
+	CartService_RedeemCoupon_FullMethodName  = "/cart.CartService/RedeemCoupon"
+	CartService_ReleaseCoupon_FullMethodName = "/cart.CartService/ReleaseCoupon"
 )
 
 // CartServiceClient is the client API for CartService service.
@@ -27,6 +29,9 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type CartServiceClient interface {
 	GetCartItems(ctx context.Context, in *CartRequest, opts ...grpc.CallOption) (*CartResponse, error)
+	// coupon phát cho chiến dịch nhắc giỏ hàng bị bỏ quên, order-service giữ coupon khi tạo đơn
+	RedeemCoupon(ctx context.Context, in *CouponRequest, opts ...grpc.CallOption) (*CouponResponse, error)
+	ReleaseCoupon(ctx context.Context, in *CouponRequest, opts ...grpc.CallOption) (*CouponResponse, error)
 }
 
 type cartServiceClient struct {
@@ -47,11 +52,34 @@ func (c *cartServiceClient) GetCartItems(ctx context.Context, in *CartRequest, o
 	return out, nil
 }
 
+func (c *cartServiceClient) RedeemCoupon(ctx context.Context, in *CouponRequest, opts ...grpc.CallOption) (*CouponResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CouponResponse)
+	err := c.cc.Invoke(ctx, CartService_RedeemCoupon_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cartServiceClient) ReleaseCoupon(ctx context.Context, in *CouponRequest, opts ...grpc.CallOption) (*CouponResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CouponResponse)
+	err := c.cc.Invoke(ctx, CartService_ReleaseCoupon_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CartServiceServer is the server API for CartService service.
 // All implementations must embed UnimplementedCartServiceServer
 // for forward compatibility.
 type CartServiceServer interface {
 	GetCartItems(context.Context, *CartRequest) (*CartResponse, error)
+	// coupon phát cho chiến dịch nhắc giỏ hàng bị bỏ quên, order-service giữ coupon khi tạo đơn
+	RedeemCoupon(context.Context, *CouponRequest) (*CouponResponse, error)
+	ReleaseCoupon(context.Context, *CouponRequest) (*CouponResponse, error)
 	mustEmbedUnimplementedCartServiceServer()
 }
 
@@ -65,6 +93,12 @@ type UnimplementedCartServiceServer struct{}
 func (UnimplementedCartServiceServer) GetCartItems(context.Context, *CartRequest) (*CartResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetCartItems not implemented")
 }
+func (UnimplementedCartServiceServer) RedeemCoupon(context.Context, *CouponRequest) (*CouponResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RedeemCoupon not implemented")
+}
+func (UnimplementedCartServiceServer) ReleaseCoupon(context.Context, *CouponRequest) (*CouponResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseCoupon not implemented")
+}
 func (UnimplementedCartServiceServer) mustEmbedUnimplementedCartServiceServer() {}
 func (UnimplementedCartServiceServer) testEmbeddedByValue()                     {}
 
@@ -104,6 +138,42 @@ func _CartService_GetCartItems_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CartService_RedeemCoupon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CouponRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CartServiceServer).RedeemCoupon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CartService_RedeemCoupon_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CartServiceServer).RedeemCoupon(ctx, req.(*CouponRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CartService_ReleaseCoupon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CouponRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CartServiceServer).ReleaseCoupon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CartService_ReleaseCoupon_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CartServiceServer).ReleaseCoupon(ctx, req.(*CouponRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CartService_ServiceDesc is the grpc.ServiceDesc for CartService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,6 +185,14 @@ var CartService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetCartItems",
 			Handler:    _CartService_GetCartItems_Handler,
 		},
+		{
+			MethodName: "RedeemCoupon",
+			Handler:    _CartService_RedeemCoupon_Handler,
+		},
+		{
+			MethodName: "ReleaseCoupon",
+			Handler:    _CartService_ReleaseCoupon_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "cart_service.proto",
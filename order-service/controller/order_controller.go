@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"order-service/service"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type OrderController struct {
@@ -36,6 +38,7 @@ func (ctrl *OrderController) OrderFromCart() gin.HandlerFunc {
 			PaymentMethod      string   `json:"payment_method"`
 			ShippingAddress    string   `json:"shipping_address"`
 			SelectedProductIDs []string `json:"selected_product_ids"`
+			CouponCode         string   `json:"coupon_code"`
 		}
 
 		var requestBody OrderCartRequest
@@ -52,22 +55,21 @@ func (ctrl *OrderController) OrderFromCart() gin.HandlerFunc {
 		if requestBody.PaymentMethod == "" {
 			requestBody.PaymentMethod = "COD"
 		}
-		if requestBody.ShippingAddress == "" {
-			logger.Err("Shipping address is nil", nil, logger.Str("shipping_address", requestBody.ShippingAddress))
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Shipping address is required"})
-			return
-		}
-
+		// Địa chỉ giao hàng do service kiểm tra, đơn chỉ có sản phẩm số thì không cần
 		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 		defer cancel()
 
-		order, err := ctrl.orderService.CreateOrderFromCart(ctx, uid, requestBody.Source, requestBody.PaymentMethod, requestBody.ShippingAddress, requestBody.SelectedProductIDs)
+		order, err := ctrl.orderService.CreateOrderFromCart(ctx, uid, requestBody.Source, requestBody.PaymentMethod, requestBody.ShippingAddress, requestBody.SelectedProductIDs, requestBody.CouponCode)
 
 		if err != nil {
+			var serviceErr *service.ServiceError
 			if err == service.ErrCartServiceUnavailable {
 				logger.Err("Cart service unavailable", err, logger.Str("user_id", uid))
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Cart service unavailable"})
 				return
+			} else if errors.As(err, &serviceErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": serviceErr.Error()})
+				return
 			} else {
 				logger.Err("Failed to create order", err, logger.Str("user_id", uid))
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order"})
@@ -114,6 +116,11 @@ func (ctrl *OrderController) OrderDirectly() gin.HandlerFunc {
 		defer cancel()
 
 		order, err := ctrl.orderService.CreateOrderDirect(ctx, orderReq)
+		var serviceErr *service.ServiceError
+		if errors.As(err, &serviceErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": serviceErr.Error()})
+			return
+		}
 		if err != nil {
 			logger.Err("Failed to create order", err, logger.Str("user_id", userID))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order"})
@@ -622,3 +629,60 @@ func (ctrl *OrderController) ReleasePaymentManually() gin.HandlerFunc {
 		})
 	}
 }
+
+// GetOrderDownloads - buyer xem license key và số lượt tải còn lại của sản phẩm số trong đơn
+func (ctrl *OrderController) GetOrderDownloads() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		downloads, err := ctrl.orderService.GetOrderDownloads(ctx, c.Param("id"), userID)
+		if err != nil {
+			writeDownloadError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": downloads})
+	}
+}
+
+// CreateDownloadLink - mỗi lần gọi trừ một lượt tải và trả về presigned URL sống ngắn
+func (ctrl *OrderController) CreateDownloadLink() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		link, err := ctrl.orderService.CreateDownloadLink(ctx, c.Param("id"), c.Param("product_id"), userID)
+		if err != nil {
+			writeDownloadError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, link)
+	}
+}
+
+func writeDownloadError(c *gin.Context, err error) {
+	var serviceErr *service.ServiceError
+	switch {
+	case err == service.ErrOrderAccessDenied:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+	case errors.As(err, &serviceErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": serviceErr.Error()})
+	default:
+		logger.Err("Failed to handle order download", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get downloads"})
+	}
+}
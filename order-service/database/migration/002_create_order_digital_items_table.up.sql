@@ -0,0 +1,18 @@
+CREATE TABLE order_digital_items (
+    id SERIAL PRIMARY KEY,
+    order_id UUID NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    product_id VARCHAR(64) NOT NULL,
+    name TEXT,
+    license_keys JSONB,
+    has_file BOOLEAN NOT NULL DEFAULT FALSE,
+    download_limit INTEGER NOT NULL DEFAULT 0,
+    download_count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_order_digital_product ON order_digital_items (order_id, product_id);
+CREATE INDEX idx_order_digital_items_user_id ON order_digital_items (user_id);
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net"
 	"order-service/database"
@@ -36,7 +37,7 @@ func main() {
 	}
 
 	db := database.InitDB()
-	db.AutoMigrate(&models.Order{})
+	db.AutoMigrate(&models.Order{}, &models.OrderDigitalItem{})
 
 	port := os.Getenv("PORT")
 
@@ -115,6 +116,11 @@ func main() {
 	orderService := service.NewOrderService(orderRepo)
 	kafka.StartPaymentConsumer(brokers, orderRepo, orderService)
 
+	// gán lại license key cho các đơn sản phẩm số còn thiếu key
+	go orderService.RetryPendingFulfillments(context.Background(), 5*time.Minute)
+	// trả lại coupon của đơn huỷ / thanh toán lỗi khi lần gọi cart-service trước bị lỗi
+	go orderService.RetryCouponReleases(context.Background(), 5*time.Minute)
+
 	router := gin.Default()
 	routes.OrderRoutes(router)
 
@@ -157,7 +163,7 @@ func main() {
 
 // 	// Initialize database
 // 	db := database.InitDB()
-// 	db.AutoMigrate(&models.Order{})
+// 	db.AutoMigrate(&models.Order{}, &models.OrderDigitalItem{})
 
 // 	// Get port from environment variables
 // 	port := os.Getenv("PORT")
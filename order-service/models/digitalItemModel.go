@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	ProductTypeDigital = "digital"
+
+	// đơn chỉ có sản phẩm số thì không giao hàng
+	ShippingNotRequired = "NOT_REQUIRED"
+
+	// fulfillment_status của đơn có sản phẩm số: PENDING là còn key chưa gán được, job gán lại định kỳ
+	FulfillmentPending = "PENDING"
+	FulfillmentDone    = "FULFILLED"
+)
+
+// OrderDigitalItem lưu phần giao hàng của sản phẩm số sau khi đơn thanh toán thành công
+type OrderDigitalItem struct {
+	gorm.Model
+	OrderID       string         `gorm:"type:uuid;not null;uniqueIndex:idx_order_digital_product" json:"order_id"`
+	UserID        string         `gorm:"not null;index" json:"user_id"`
+	ProductID     string         `gorm:"not null;uniqueIndex:idx_order_digital_product" json:"product_id"`
+	Name          string         `json:"name"`
+	LicenseKeys   datatypes.JSON `gorm:"type:jsonb" json:"license_keys"`
+	HasFile       bool           `gorm:"not null;default:false" json:"has_file"`
+	DownloadLimit int            `gorm:"not null;default:0" json:"download_limit"`
+	DownloadCount int            `gorm:"not null;default:0" json:"download_count"`
+	ExpiresAt     time.Time      `gorm:"not null" json:"expires_at"`
+	// số license key chưa gán được, còn > 0 thì đơn ở trạng thái FulfillmentPending
+	MissingKeys int `gorm:"not null;default:0" json:"missing_keys"`
+}
+
+func (o *Order) RequiresShipping() bool {
+	return o.ShippingStatus != ShippingNotRequired
+}
@@ -48,6 +48,11 @@ type Order struct {
 	// VendorID           *string        `gorm:"column:vendor_id" json:"vendor_id,omitempty"`
 	PlatformFee        float64        `gorm:"not null;default:0"`
 	VendorAmount       float64        `gorm:"not null;default:0"`
+	CouponCode         string         `gorm:"column:coupon_code" json:"coupon_code,omitempty"`
+	DiscountAmount     float64        `gorm:"not null;default:0" json:"discount_amount"`
+	// coupon đã trả lại cho cart-service, tránh trả 2 lần (lần 2 có thể nhả coupon của đơn mới)
+	CouponReleasedAt   *time.Time     `gorm:"column:coupon_released_at" json:"-"`
+	FulfillmentStatus  string         `gorm:"column:fulfillment_status;index" json:"fulfillment_status,omitempty"`
 	DeliveryDate       *time.Time     `json:"delivery_date"`
 	PaymentReleaseDate *time.Time     `json:"payment_release_date"`
 }
@@ -58,4 +63,5 @@ type OrderItem struct {
 	Quantity  int     `json:"quantity"`
 	Price     float64 `json:"price"`
 	VendorID  string  `json:"vendor_id"`
+	Type      string  `json:"product_type,omitempty"`
 }
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"order-service/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SaveDigitalItems ghi đè key / hạn mức khi payment success bị gửi lặp nhưng giữ nguyên số lượt đã tải
+func (r *OrderRepository) SaveDigitalItems(ctx context.Context, items []models.OrderDigitalItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "order_id"}, {Name: "product_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"license_keys", "has_file", "download_limit", "missing_keys", "updated_at"}),
+		}).
+		Create(&items).Error
+}
+
+func (r *OrderRepository) FindDigitalItems(ctx context.Context, orderID string) ([]models.OrderDigitalItem, error) {
+	var items []models.OrderDigitalItem
+	err := r.db.WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Order("id").
+		Find(&items).Error
+	return items, err
+}
+
+// ConsumeDownload trừ một lượt tải trong một câu UPDATE, hai request đồng thời không thể vượt quá giới hạn
+func (r *OrderRepository) ConsumeDownload(ctx context.Context, orderID, productID string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.OrderDigitalItem{}).
+		Where("order_id = ? AND product_id = ? AND has_file = ? AND download_count < download_limit AND expires_at > ?",
+			orderID, productID, true, time.Now()).
+		Updates(map[string]interface{}{
+			"download_count": gorm.Expr("download_count + 1"),
+			"updated_at":     time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RefundDownload trả lại lượt tải khi không lấy được link từ product-service
+func (r *OrderRepository) RefundDownload(ctx context.Context, orderID, productID string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.OrderDigitalItem{}).
+		Where("order_id = ? AND product_id = ? AND download_count > 0", orderID, productID).
+		Update("download_count", gorm.Expr("download_count - 1")).Error
+}
+
+// FindPendingFulfillments lấy các đơn còn key chưa gán, bỏ qua đơn vừa cập nhật (lần gán đầu có thể đang chạy)
+func (r *OrderRepository) FindPendingFulfillments(ctx context.Context, updatedBefore time.Time, limit int) ([]models.Order, error) {
+	var orders []models.Order
+	err := r.db.WithContext(ctx).
+		Where("fulfillment_status = ? AND status <> ? AND updated_at < ?", models.FulfillmentPending, "CANCELED", updatedBefore).
+		Order("id").
+		Limit(limit).
+		Find(&orders).Error
+	return orders, err
+}
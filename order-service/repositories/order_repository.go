@@ -154,6 +154,23 @@ func (r *OrderRepository) GetUserOrderWithProductID(ctx context.Context, userID,
 	return order, nil
 }
 
+// ListSuccessfulOrders: các đơn đã gửi order_success (COD chưa lỗi thanh toán, Stripe đã HELD/RELEASED)
+// và chưa bị huỷ, phân trang theo id nội bộ tăng dần
+func (r *OrderRepository) ListSuccessfulOrders(ctx context.Context, afterID uint, limit int) ([]models.Order, error) {
+	var orders []models.Order
+	err := r.db.WithContext(ctx).
+		Where("id > ?", afterID).
+		Where("status <> ?", "CANCELED").
+		Where("(UPPER(payment_method) = 'COD' AND status <> 'PAYMENT_FAILED') OR payment_status IN ('HELD', 'RELEASED')").
+		Order("id ASC").
+		Limit(limit).
+		Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
 func (r *OrderRepository) GetByOrderID(ctx context.Context, orderID string) (*models.Order, error) {
 	var order models.Order
 	err := r.db.WithContext(ctx).Where("order_id = ?", orderID).First(&order).Error
@@ -226,6 +243,27 @@ func (r *OrderRepository) UpdatePaymentIntentID(ctx context.Context, orderID str
 		Update("payment_intent_id", paymentIntentID).Error
 }
 
+// MarkCouponReleased ghi lại coupon đã trả xong, chỉ trả true cho lần đầu
+func (r *OrderRepository) MarkCouponReleased(ctx context.Context, orderID string) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.Order{}).
+		Where("order_id = ? AND coupon_code <> '' AND coupon_released_at IS NULL", orderID).
+		Update("coupon_released_at", time.Now())
+	return res.RowsAffected == 1, res.Error
+}
+
+// FindUnreleasedCoupons: đơn đã huỷ / thanh toán lỗi mà coupon chưa trả được cho cart-service
+func (r *OrderRepository) FindUnreleasedCoupons(ctx context.Context, updatedBefore time.Time, limit int) ([]models.Order, error) {
+	var orders []models.Order
+	err := r.db.WithContext(ctx).
+		Where("coupon_code <> '' AND coupon_released_at IS NULL AND updated_at < ?", updatedBefore).
+		Where("(status IN ? OR payment_status = ?)", []string{"CANCELED", "PAYMENT_FAILED"}, "PAYMENT_FAILED").
+		Order("id").
+		Limit(limit).
+		Find(&orders).Error
+	return orders, err
+}
+
 func (r *OrderRepository) UpdateOrderFields(ctx context.Context, orderID string, updates map[string]interface{}) error {
 	return r.db.WithContext(ctx).
 		Model(&models.Order{}).
@@ -29,6 +29,8 @@ func OrderRoutes(incomming *gin.Engine) {
 	authorized.GET("orders", orderController.GetOrdersByVendor())
 	authorized.POST("order/cancel/:order_id", orderController.CancelOrder())
 	authorized.GET("order/:id", orderController.GetOrderByID())
+	authorized.GET("order/:id/downloads", orderController.GetOrderDownloads())
+	authorized.POST("order/:id/downloads/:product_id", orderController.CreateDownloadLink())
 	authorized.POST("orders/:id/update-status", orderController.UpdateOrderStatus())
 	authorized.POST("orders/:id/vendor-update-status", orderController.VendorUpdateOrderStatus())
 
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	logger "order-service/log"
+	"order-service/models"
+
+	productpb "module/gRPC-Product/service"
+
+	"gorm.io/datatypes"
+)
+
+var ErrOrderAccessDenied = NewServiceError("You do not have access to this order")
+
+// ErrFulfillmentIncomplete: còn sản phẩm số chưa gán đủ license key, đơn chờ job gán lại
+var ErrFulfillmentIncomplete = errors.New("digital fulfillment incomplete")
+
+const fulfillmentRetryBatch = 50
+
+type DigitalDownload struct {
+	ProductID          string    `json:"product_id"`
+	Name               string    `json:"name"`
+	LicenseKeys        []string  `json:"license_keys"`
+	HasFile            bool      `json:"has_file"`
+	DownloadLimit      int       `json:"download_limit"`
+	DownloadCount      int       `json:"download_count"`
+	RemainingDownloads int       `json:"remaining_downloads"`
+	ExpiresAt          time.Time `json:"expires_at"`
+	// key còn đang chờ gán, sẽ có sau khi seller nạp thêm
+	PendingKeys int `json:"pending_keys,omitempty"`
+}
+
+type DownloadLink struct {
+	URL                string    `json:"url"`
+	FileName           string    `json:"file_name"`
+	ExpiresAt          time.Time `json:"expires_at"`
+	RemainingDownloads int       `json:"remaining_downloads"`
+}
+
+func hasDigitalItems(items []OrderItem) bool {
+	for _, item := range items {
+		if item.Type == models.ProductTypeDigital {
+			return true
+		}
+	}
+	return false
+}
+
+// fulfillDigitalItems chạy sau khi thanh toán thành công: xin product-service gán license key rồi lưu vào đơn.
+// Key gán được bao nhiêu lưu bấy nhiêu, còn thiếu (hết key / lỗi) thì đơn ở FulfillmentPending
+// và RetryPendingFulfillments gán tiếp. Đơn toàn sản phẩm số coi như đã giao khi đủ key.
+func (s *OrderService) fulfillDigitalItems(ctx context.Context, order *models.Order) error {
+	var items []OrderItem
+	if err := json.Unmarshal(order.Items, &items); err != nil {
+		return err
+	}
+	if !hasDigitalItems(items) || order.FulfillmentStatus == models.FulfillmentDone {
+		return nil
+	}
+
+	// đánh dấu trước khi gọi product-service, lỗi giữa chừng thì job vẫn thấy đơn để gán lại
+	if order.FulfillmentStatus != models.FulfillmentPending {
+		if err := s.orderRepo.UpdateOrderFields(ctx, order.OrderID, map[string]interface{}{
+			"fulfillment_status": models.FulfillmentPending,
+			"updated_at":         time.Now(),
+		}); err != nil {
+			return err
+		}
+		order.FulfillmentStatus = models.FulfillmentPending
+	}
+
+	productClient := GetGRPCClients().ProductClient
+	if productClient == nil {
+		return ErrProductServiceUnavailable
+	}
+
+	req := &productpb.DigitalFulfillmentRequest{OrderId: order.OrderID, UserId: order.UserID}
+	names := make(map[string]string, len(items))
+	for _, item := range items {
+		if item.Type != models.ProductTypeDigital {
+			continue
+		}
+		req.Items = append(req.Items, &productpb.StockItem{ProductId: item.ProductID, Quantity: int32(item.Quantity)})
+		names[item.ProductID] = item.Name
+	}
+
+	resp, err := productClient.FulfillDigitalItems(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	missing := 0
+	rows := make([]models.OrderDigitalItem, 0, len(resp.Items))
+	for _, f := range resp.Items {
+		keys, _ := json.Marshal(f.LicenseKeys)
+		missing += int(f.MissingKeys)
+		rows = append(rows, models.OrderDigitalItem{
+			OrderID:       order.OrderID,
+			UserID:        order.UserID,
+			ProductID:     f.ProductId,
+			Name:          names[f.ProductId],
+			LicenseKeys:   datatypes.JSON(keys),
+			HasFile:       f.HasFile,
+			DownloadLimit: int(f.DownloadLimit),
+			ExpiresAt:     now.Add(time.Duration(f.DownloadExpiryHours) * time.Hour),
+			MissingKeys:   int(f.MissingKeys),
+		})
+	}
+	if err := s.orderRepo.SaveDigitalItems(ctx, rows); err != nil {
+		return err
+	}
+	if missing > 0 {
+		// updated_at để job chờ một lúc rồi mới thử lại
+		if err := s.orderRepo.UpdateOrderFields(ctx, order.OrderID, map[string]interface{}{"updated_at": now}); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %d license keys missing", ErrFulfillmentIncomplete, missing)
+	}
+
+	updates := map[string]interface{}{
+		"fulfillment_status": models.FulfillmentDone,
+		"updated_at":         now,
+	}
+	if !order.RequiresShipping() {
+		updates["status"] = "DELIVERED"
+		updates["delivery_date"] = now
+	}
+	if err := s.orderRepo.UpdateOrderFields(ctx, order.OrderID, updates); err != nil {
+		return err
+	}
+	order.FulfillmentStatus = models.FulfillmentDone
+	return nil
+}
+
+// RetryPendingFulfillments định kỳ gán tiếp license key cho các đơn còn thiếu (seller nạp thêm key, product-service hết lỗi)
+func (s *OrderService) RetryPendingFulfillments(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		orders, err := s.orderRepo.FindPendingFulfillments(ctx, time.Now().Add(-interval), fulfillmentRetryBatch)
+		if err != nil {
+			logger.Err("Failed to find pending digital fulfillments", err)
+			continue
+		}
+		for i := range orders {
+			if err := s.fulfillDigitalItems(ctx, &orders[i]); err != nil {
+				logger.Err("Failed to retry digital fulfillment", err, logger.Str("order_id", orders[i].OrderID))
+			}
+		}
+	}
+}
+
+func (s *OrderService) GetOrderDownloads(ctx context.Context, orderID, userID string) ([]DigitalDownload, error) {
+	order, err := s.orderRepo.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, ErrOrderAccessDenied
+	}
+
+	items, err := s.orderRepo.FindDigitalItems(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	downloads := make([]DigitalDownload, 0, len(items))
+	for _, item := range items {
+		var keys []string
+		if len(item.LicenseKeys) > 0 {
+			_ = json.Unmarshal(item.LicenseKeys, &keys)
+		}
+		remaining := 0
+		if item.HasFile && time.Now().Before(item.ExpiresAt) && item.DownloadCount < item.DownloadLimit {
+			remaining = item.DownloadLimit - item.DownloadCount
+		}
+		downloads = append(downloads, DigitalDownload{
+			ProductID:          item.ProductID,
+			Name:               item.Name,
+			LicenseKeys:        keys,
+			HasFile:            item.HasFile,
+			DownloadLimit:      item.DownloadLimit,
+			DownloadCount:      item.DownloadCount,
+			RemainingDownloads: remaining,
+			ExpiresAt:          item.ExpiresAt,
+			PendingKeys:        item.MissingKeys,
+		})
+	}
+	return downloads, nil
+}
+
+// CreateDownloadLink trừ một lượt tải rồi mới xin presigned URL, lỗi phía product-service thì hoàn lượt
+func (s *OrderService) CreateDownloadLink(ctx context.Context, orderID, productID, userID string) (*DownloadLink, error) {
+	order, err := s.orderRepo.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, ErrOrderAccessDenied
+	}
+	if order.Status == "CANCELED" {
+		return nil, NewServiceError("Order has been canceled")
+	}
+
+	ok, err := s.orderRepo.ConsumeDownload(ctx, orderID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, NewServiceError("Download limit reached or download period has expired")
+	}
+
+	productClient := GetGRPCClients().ProductClient
+	if productClient == nil {
+		_ = s.orderRepo.RefundDownload(ctx, orderID, productID)
+		return nil, ErrProductServiceUnavailable
+	}
+	resp, err := productClient.GetDigitalDownloadURL(ctx, &productpb.DigitalDownloadRequest{ProductId: productID})
+	if err != nil {
+		logger.Err("Failed to get digital download url", err, logger.Str("order_id", orderID), logger.Str("product_id", productID))
+		if refundErr := s.orderRepo.RefundDownload(ctx, orderID, productID); refundErr != nil {
+			logger.Err("Failed to refund download", refundErr)
+		}
+		return nil, NewServiceError("Failed to generate download link")
+	}
+
+	link := &DownloadLink{
+		URL:       resp.Url,
+		FileName:  resp.FileName,
+		ExpiresAt: time.Unix(resp.ExpiresAt, 0),
+	}
+	items, err := s.orderRepo.FindDigitalItems(ctx, orderID)
+	if err == nil {
+		for _, item := range items {
+			if item.ProductID == productID {
+				link.RemainingDownloads = item.DownloadLimit - item.DownloadCount
+			}
+		}
+	}
+	return link, nil
+}
+
+// digitalDelivered: đơn đã giao license key / link tải thì không huỷ được nữa
+func (s *OrderService) digitalDelivered(ctx context.Context, orderID string) bool {
+	items, err := s.orderRepo.FindDigitalItems(ctx, orderID)
+	if err != nil {
+		logger.Err("Failed to get digital items", err)
+	}
+	return len(items) > 0
+}
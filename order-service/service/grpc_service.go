@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	pb "module/gRPC-Order/service"
 	logger "order-service/log"
+	"order-service/models"
 	"order-service/repositories"
 	"time"
 
@@ -69,3 +71,52 @@ func (s *OrderServiceServer) HasPurchased(ctx context.Context, req *pb.HasPurcha
 		return &pb.HasPurchasedResponse{Purchased: res.purchased}, nil
 	}
 }
+
+const (
+	defaultListOrdersLimit = 200
+	maxListOrdersLimit     = 1000
+)
+
+// ListSuccessfulOrders trả từng trang đơn thành công cho lệnh rebuild gợi ý của product-service
+func (s *OrderServiceServer) ListSuccessfulOrders(ctx context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = defaultListOrdersLimit
+	}
+	if limit > maxListOrdersLimit {
+		limit = maxListOrdersLimit
+	}
+
+	orders, err := s.OrderRepo.ListSuccessfulOrders(ctx, uint(req.GetAfterId()), limit)
+	if err != nil {
+		logger.Err("Failed to list successful orders", err)
+		return nil, status.Error(codes.Internal, "failed to list orders")
+	}
+
+	resp := &pb.ListOrdersResponse{Orders: make([]*pb.OrderSummary, 0, len(orders))}
+	for _, order := range orders {
+		var items []models.OrderItem
+		if err := json.Unmarshal(order.Items, &items); err != nil {
+			logger.Err("Failed to decode order items", err)
+			continue
+		}
+		summary := &pb.OrderSummary{
+			OrderId:   order.OrderID,
+			UserId:    order.UserID,
+			CreatedAt: order.CreatedAt.Unix(),
+			Items:     make([]*pb.OrderItem, 0, len(items)),
+		}
+		for _, item := range items {
+			summary.Items = append(summary.Items, &pb.OrderItem{
+				ProductId: item.ProductID,
+				Quantity:  int32(item.Quantity),
+				Price:     float32(item.Price),
+			})
+		}
+		resp.Orders = append(resp.Orders, summary)
+	}
+	if len(orders) == limit {
+		resp.NextAfterId = uint64(orders[len(orders)-1].ID)
+	}
+	return resp, nil
+}
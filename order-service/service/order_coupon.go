@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	logger "order-service/log"
+	"order-service/models"
+
+	cartpb "module/gRPC-cart/service"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const couponRetryBatch = 50
+
+// redeemCartCoupon giữ coupon nhắc giỏ bỏ quên bên cart-service, trả về phần trăm giảm
+func redeemCartCoupon(ctx context.Context, cartClient cartpb.CartServiceClient, userID, code string) (int, error) {
+	resp, err := cartClient.RedeemCoupon(ctx, &cartpb.CouponRequest{UserId: userID, Code: code})
+	if err != nil {
+		switch status.Code(err) {
+		case codes.NotFound, codes.FailedPrecondition, codes.InvalidArgument:
+			return 0, NewServiceError("Coupon is invalid or expired")
+		}
+		logger.Err("Failed to redeem coupon", err, logger.Str("user_id", userID))
+		return 0, err
+	}
+	return int(resp.Percent), nil
+}
+
+// releaseCartCoupon trả coupon khi không tạo được đơn, lỗi chỉ log lại
+func releaseCartCoupon(cartClient cartpb.CartServiceClient, userID, code string) {
+	if code == "" {
+		return
+	}
+	if _, err := cartClient.ReleaseCoupon(context.Background(), &cartpb.CouponRequest{UserId: userID, Code: code}); err != nil {
+		logger.Err("Failed to release coupon", err, logger.Str("user_id", userID), logger.Str("code", code))
+	}
+}
+
+// releaseOrderCoupon trả coupon của đơn đã tạo (thanh toán lỗi, huỷ đơn). Chỉ đánh dấu đã trả sau khi
+// cart-service trả xong, lỗi thì RetryCouponReleases trả lại sau
+func (s *OrderService) releaseOrderCoupon(ctx context.Context, order *models.Order) {
+	if order.CouponCode == "" || order.CouponReleasedAt != nil {
+		return
+	}
+	cartClient := GetGRPCClients().CartClient
+	if cartClient == nil {
+		logger.Logger.Warnf("Cart service unavailable, coupon %s of order %s not released", order.CouponCode, order.OrderID)
+		return
+	}
+	_, err := cartClient.ReleaseCoupon(ctx, &cartpb.CouponRequest{UserId: order.UserID, Code: order.CouponCode})
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.FailedPrecondition, codes.NotFound:
+		// coupon không còn bị giữ: lần trước đã trả nhưng chưa kịp đánh dấu
+		logger.Logger.Warnf("Coupon %s of order %s is not held anymore: %v", order.CouponCode, order.OrderID, err)
+	default:
+		logger.Err("Failed to release coupon", err, logger.Str("order_id", order.OrderID), logger.Str("code", order.CouponCode))
+		return
+	}
+	if _, err := s.orderRepo.MarkCouponReleased(ctx, order.OrderID); err != nil {
+		logger.Err("Failed to mark coupon released", err, logger.Str("order_id", order.OrderID))
+	}
+}
+
+// RetryCouponReleases định kỳ trả coupon của các đơn huỷ / thanh toán lỗi mà lần trả trước bị lỗi
+func (s *OrderService) RetryCouponReleases(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		orders, err := s.orderRepo.FindUnreleasedCoupons(ctx, time.Now().Add(-interval), couponRetryBatch)
+		if err != nil {
+			logger.Err("Failed to find unreleased coupons", err)
+			continue
+		}
+		for i := range orders {
+			s.releaseOrderCoupon(ctx, &orders[i])
+		}
+	}
+}
+
+func couponDiscount(total float64, percent int) float64 {
+	return math.Round(total*float64(percent)) / 100
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"math"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"order-service/kafka"
@@ -25,6 +28,7 @@ type OrderItem struct {
 	Quantity  int     `json:"quantity"`
 	Price     float64 `json:"price"`
 	VendorID  string  `json:"vendor_id"`
+	Type      string  `json:"product_type,omitempty"`
 }
 type OrderService struct {
 	orderRepo *repositories.OrderRepository
@@ -36,7 +40,7 @@ func NewOrderService(orderRepo *repositories.OrderRepository) *OrderService {
 	}
 }
 
-func (s *OrderService) CreateOrderFromCart(ctx context.Context, userID string, source, paymentMethod, shippingAddress string, selectedProductIDs []string) (*models.Order, error) {
+func (s *OrderService) CreateOrderFromCart(ctx context.Context, userID string, source, paymentMethod, shippingAddress string, selectedProductIDs []string, couponCode string) (*models.Order, error) {
 	// Get cart items using gRPC
 	grpcClients := GetGRPCClients()
 
@@ -46,7 +50,8 @@ func (s *OrderService) CreateOrderFromCart(ctx context.Context, userID string, s
 	}
 
 	req := &cartpb.CartRequest{
-		UserId: userID,
+		UserId:     userID,
+		Revalidate: true,
 	}
 
 	resp, err := cartClient.GetCartItems(ctx, req)
@@ -75,33 +80,35 @@ func (s *OrderService) CreateOrderFromCart(ctx context.Context, userID string, s
 		filteredItems = temp
 	}
 
-	// Convert cart items to order items
-	var orderItems []OrderItem
-	var totalPrice float64 = 0
+	// cart-service đã đối chiếu lại giỏ, có thay đổi (giá, hết hàng, ngừng bán) thì bắt user xem lại giỏ trước khi đặt
+	if err := cartChangedError(resp.Notices, filteredItems); err != nil {
+		return nil, err
+	}
 
+	// Kiểm tra tồn kho toàn bộ giỏ hàng trong một lần gọi
+	stockItems := make([]*productpb.StockItem, 0, len(filteredItems))
+	productIDs := make([]string, 0, len(filteredItems))
 	for _, item := range filteredItems {
+		stockItems = append(stockItems, &productpb.StockItem{ProductId: item.ProductId, Quantity: item.Quantity})
+		productIDs = append(productIDs, item.ProductId)
+	}
 
-		stockReq := &productpb.ProductRequest{
-			Id: item.ProductId,
-		}
-
-		stockResp, err := productClient.CheckStock(ctx, stockReq)
-		if err != nil {
-			return nil, NewServiceError("Failed to check stock")
-		}
+	if err := checkStockBatch(ctx, productClient, stockItems); err != nil {
+		return nil, err
+	}
+	summaries, err := getProductSummaries(ctx, productClient, productIDs)
+	if err != nil {
+		return nil, err
+	}
 
-		if !stockResp.InStock || item.Quantity > int32(stockResp.AvailableQuantity) {
-			return nil, NewServiceError("Product is out of stock")
-		}
+	// Convert cart items to order items
+	var orderItems []OrderItem
+	var totalPrice float64 = 0
 
+	for _, item := range filteredItems {
 		vendorID := item.VendorId
 		if vendorID == "" {
-			productReq := &productpb.ProductRequest{Id: item.ProductId}
-			productResp, err := productClient.GetBasicInfo(ctx, productReq)
-			err = nil
-			if err == nil && productResp.VendorId != "" {
-				vendorID = productResp.VendorId
-			}
+			vendorID = summaries[item.ProductId].GetVendorId()
 		}
 
 		orderItem := OrderItem{
@@ -110,6 +117,7 @@ func (s *OrderService) CreateOrderFromCart(ctx context.Context, userID string, s
 			Name:      item.Name,
 			Quantity:  int(item.Quantity),
 			Price:     float64(item.Price),
+			Type:      summaries[item.ProductId].GetProductType(),
 		}
 
 		orderItems = append(orderItems, orderItem)
@@ -117,11 +125,28 @@ func (s *OrderService) CreateOrderFromCart(ctx context.Context, userID string, s
 		totalPrice = calculateTotalPrice(orderItems)
 	}
 
+	shippingStatus, err := shippingStatusFor(orderItems, paymentMethod, shippingAddress)
+	if err != nil {
+		return nil, err
+	}
+
 	itemsJSON, err := json.Marshal(orderItems)
 	if err != nil {
 		return nil, err
 	}
 
+	// coupon từ email nhắc giỏ bỏ quên, giữ trước khi tạo đơn và trả lại nếu tạo đơn lỗi
+	var discount float64
+	couponCode = strings.TrimSpace(couponCode)
+	if couponCode != "" {
+		percent, err := redeemCartCoupon(ctx, cartClient, userID, couponCode)
+		if err != nil {
+			return nil, err
+		}
+		discount = couponDiscount(totalPrice, percent)
+		totalPrice -= discount
+	}
+
 	initialStatus := "PENDING"
 	paymentStatus := "PENDING"
 
@@ -141,11 +166,15 @@ func (s *OrderService) CreateOrderFromCart(ctx context.Context, userID string, s
 		PaymentMethod:   paymentMethod,
 		PaymentStatus:   paymentStatus,
 		ShippingAddress: shippingAddress,
+		ShippingStatus:  shippingStatus,
+		CouponCode:      couponCode,
+		DiscountAmount:  discount,
 	}
 
 	// Save order to database
 	createdOrder, err := s.orderRepo.CreateOrder(ctx, newOrder)
 	if err != nil {
+		releaseCartCoupon(cartClient, userID, couponCode)
 		return nil, err
 	}
 
@@ -153,6 +182,7 @@ func (s *OrderService) CreateOrderFromCart(ctx context.Context, userID string, s
 		err = s.requestPayment(ctx, createdOrder, orderItems)
 		if err != nil {
 			s.orderRepo.UpdateOrderStatus(ctx, createdOrder.OrderID, "PAYMENT_FAILED")
+			s.releaseOrderCoupon(ctx, createdOrder)
 			return nil, NewServiceError("Failed to initiate payment")
 		}
 	} else if paymentMethod == "COD" {
@@ -258,19 +288,11 @@ func (s *OrderService) UpdateOrderStatusWithPayout(ctx context.Context, orderID
 	return nil
 }
 
-func determinePrimaryVendor(orderItems []OrderItem) string {
-	vendorAmounts := make(map[string]float64)
-
-	for _, item := range orderItems {
-		if item.VendorID != "" {
-			vendorAmounts[item.VendorID] += item.Price * float64(item.Quantity)
-		}
-	}
-
+func determinePrimaryVendor(vendorTotals map[string]float64) string {
 	var primaryVendor string
 	var maxAmount float64
 
-	for vendorID, amount := range vendorAmounts {
+	for vendorID, amount := range vendorTotals {
 		if amount > maxAmount {
 			maxAmount = amount
 			primaryVendor = vendorID
@@ -280,23 +302,53 @@ func determinePrimaryVendor(orderItems []OrderItem) string {
 	return primaryVendor
 }
 
-// Calculate vendor breakdown for multi-vendor orders
-func calculateVendorBreakdownWithFee(orderItems []OrderItem, platformFeeRate float64) map[string]map[string]float64 {
-	vendorBreakdown := make(map[string]map[string]float64)
-
+// calculateVendorTotals: tiền hàng của từng vendor, coupon được chia theo tỉ lệ tiền hàng
+// để tổng các vendor khớp với số tiền khách thực trả
+func calculateVendorTotals(orderItems []OrderItem, discount float64) map[string]float64 {
+	totals := make(map[string]float64)
+	var vendorGross float64
 	for _, item := range orderItems {
 		if item.VendorID != "" {
 			itemTotal := item.Price * float64(item.Quantity)
-			platformFee := itemTotal * platformFeeRate
-			vendorAmount := itemTotal - platformFee
+			totals[item.VendorID] += itemTotal
+			vendorGross += itemTotal
+		}
+	}
 
-			if _, exists := vendorBreakdown[item.VendorID]; !exists {
-				vendorBreakdown[item.VendorID] = make(map[string]float64)
-			}
+	gross := calculateTotalPrice(orderItems)
+	if discount <= 0 || gross <= 0 {
+		return totals
+	}
 
-			vendorBreakdown[item.VendorID]["total_amount"] += itemTotal
-			vendorBreakdown[item.VendorID]["platform_fee"] += platformFee
-			vendorBreakdown[item.VendorID]["vendor_amount"] += vendorAmount
+	vendorIDs := make([]string, 0, len(totals))
+	for vendorID := range totals {
+		vendorIDs = append(vendorIDs, vendorID)
+	}
+	sort.Strings(vendorIDs)
+
+	remaining := discount
+	for i, vendorID := range vendorIDs {
+		share := math.Round(discount*totals[vendorID]/gross*100) / 100
+		// vendor cuối nhận phần lẻ do làm tròn (khi mọi item đều có vendor)
+		if i == len(vendorIDs)-1 && vendorGross == gross {
+			share = remaining
+		}
+		remaining -= share
+		totals[vendorID] -= share
+	}
+	return totals
+}
+
+// Calculate vendor breakdown for multi-vendor orders
+func calculateVendorBreakdownWithFee(vendorTotals map[string]float64, platformFeeRate float64) map[string]map[string]float64 {
+	vendorBreakdown := make(map[string]map[string]float64)
+
+	for vendorID, total := range vendorTotals {
+		platformFee := total * platformFeeRate
+		vendorBreakdown[vendorID] = map[string]float64{
+			"total_amount":  total,
+			"platform_fee":  platformFee,
+			"vendor_amount": total - platformFee,
 		}
 	}
 
@@ -309,11 +361,12 @@ func (s *OrderService) requestPayment(ctx context.Context, order *models.Order,
 	vendorAmount := order.TotalPrice - platformFee
 
 	// Get detailed vendor breakdown
-	vendorBreakdownWithFee := calculateVendorBreakdownWithFee(orderItems, platformFeeRate)
+	vendorTotals := calculateVendorTotals(orderItems, order.DiscountAmount)
+	vendorBreakdownWithFee := calculateVendorBreakdownWithFee(vendorTotals, platformFeeRate)
 	vendorBreakdownJSON, _ := json.Marshal(vendorBreakdownWithFee)
 
 	// Determine primary vendor for Stripe Connect (vendor with highest amount)
-	primaryVendor := determinePrimaryVendor(orderItems)
+	primaryVendor := determinePrimaryVendor(vendorTotals)
 
 	// Payment-service will lookup VendorStripeAccountID from its database using VendorID
 	paymentReq := kafka.PaymentRequestEvent{
@@ -361,7 +414,7 @@ func (s *OrderService) ReleasePaymentToVendor(ctx context.Context, orderID strin
 
 	// Calculate vendor breakdown with fees
 	platformFeeRate := 0.05
-	vendorBreakdown := calculateVendorBreakdownWithFee(items, platformFeeRate)
+	vendorBreakdown := calculateVendorBreakdownWithFee(calculateVendorTotals(items, order.DiscountAmount), platformFeeRate)
 
 	// Capture the held payment in Stripe
 	if order.PaymentMethod == "STRIPE" && order.PaymentIntentID != nil {
@@ -495,7 +548,12 @@ func (s *OrderService) CancelOrder(ctx context.Context, orderID string, userID s
 		return NewServiceError("Order already delivered or delivering")
 	}
 
-	if order.Status == "CONFIRMED" {
+	if s.digitalDelivered(ctx, orderID) {
+		return NewServiceError("Digital items have already been delivered")
+	}
+
+	// Chỉ hoàn kho khi order_success đã được gửi (đã trừ kho), và chỉ gửi một lần
+	if stockCommitted(order) {
 		if err := kafka.ProduceOrderReturnedEvent(ctx, *order); err != nil {
 			logger.Err("Failed to produce order returned event", err)
 		}
@@ -514,16 +572,21 @@ func (s *OrderService) CancelOrder(ctx context.Context, orderID string, userID s
 		log.Printf("⚠️ Skip payment cancel: PaymentMethod=%s, HasPaymentIntent=%v", order.PaymentMethod, order.PaymentIntentID != nil)
 	}
 
-	err = s.orderRepo.UpdateOrderStatus(ctx, orderID, "CANCELED")
-	if err != nil {
+	if err := s.orderRepo.UpdateOrderStatus(ctx, orderID, "CANCELED"); err != nil {
 		return err
 	}
-
-	order, _ = s.orderRepo.GetOrderByID(ctx, orderID)
-	_ = kafka.ProduceOrderReturnedEvent(ctx, *order)
+	s.releaseOrderCoupon(ctx, order)
 	return nil
 }
 
+// stockCommitted: COD gửi order_success ngay lúc tạo đơn, Stripe chỉ gửi sau khi thanh toán thành công (HELD)
+func stockCommitted(order *models.Order) bool {
+	if strings.EqualFold(order.PaymentMethod, "COD") {
+		return order.Status != "PAYMENT_FAILED"
+	}
+	return order.PaymentStatus == "HELD"
+}
+
 type OrderDirectRequest struct {
 	UserID          string             `json:"user_id"`
 	Items           []OrderItemRequest `json:"items"`
@@ -551,33 +614,29 @@ func (s *OrderService) CreateOrderDirect(ctx context.Context, req OrderDirectReq
 	var orderItems []OrderItem
 	var totalPrice float64 = 0
 
+	stockItems := make([]*productpb.StockItem, 0, len(req.Items))
+	productIDs := make([]string, 0, len(req.Items))
 	for _, item := range req.Items {
+		stockItems = append(stockItems, &productpb.StockItem{ProductId: item.ProductID, Quantity: int32(item.Quantity)})
+		productIDs = append(productIDs, item.ProductID)
+	}
 
-		stockReq := &productpb.ProductRequest{
-			Id: item.ProductID,
-		}
-
-		stockResp, err := productClient.CheckStock(ctx, stockReq)
-		if err != nil {
-			return nil, NewServiceError("Failed to check stock")
-		}
-
-		if !stockResp.InStock || item.Quantity > int(stockResp.AvailableQuantity) {
-			return nil, NewServiceError("Product is out of stock")
-		}
+	if err := checkStockBatch(ctx, productClient, stockItems); err != nil {
+		return nil, err
+	}
+	summaries, err := getProductSummaries(ctx, productClient, productIDs)
+	if err != nil {
+		return nil, err
+	}
 
-		vendorID := ""
-		productReq := &productpb.ProductRequest{Id: item.ProductID}
-		productResp, err := productClient.GetBasicInfo(ctx, productReq)
-		if err == nil && productResp.VendorId != "" {
-			vendorID = productResp.VendorId
-		}
+	for _, item := range req.Items {
 		orderItem := OrderItem{
-			VendorID:  vendorID,
+			VendorID:  summaries[item.ProductID].GetVendorId(),
 			ProductID: item.ProductID,
 			Name:      item.Name,
 			Quantity:  item.Quantity,
 			Price:     item.Price,
+			Type:      summaries[item.ProductID].GetProductType(),
 		}
 
 		orderItems = append(orderItems, orderItem)
@@ -585,6 +644,11 @@ func (s *OrderService) CreateOrderDirect(ctx context.Context, req OrderDirectReq
 		totalPrice = calculateTotalPrice(orderItems)
 	}
 
+	shippingStatus, err := shippingStatusFor(orderItems, req.PaymentMethod, req.ShippingAddress)
+	if err != nil {
+		return nil, err
+	}
+
 	// Set payment details and status
 	initialStatus := "PENDING"
 	paymentStatus := "PENDING"
@@ -610,6 +674,7 @@ func (s *OrderService) CreateOrderDirect(ctx context.Context, req OrderDirectReq
 		PaymentMethod:   req.PaymentMethod,
 		PaymentStatus:   paymentStatus,
 		ShippingAddress: req.ShippingAddress,
+		ShippingStatus:  shippingStatus,
 		Source:          req.Source,
 	}
 
@@ -634,6 +699,69 @@ func (s *OrderService) CreateOrderDirect(ctx context.Context, req OrderDirectReq
 	return createdOrder, nil
 }
 
+// checkStockBatch kiểm tra tồn kho của tất cả item bằng một lần gọi CheckStockBatch
+func checkStockBatch(ctx context.Context, productClient productpb.ProductServiceClient, items []*productpb.StockItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	stockResp, err := productClient.CheckStockBatch(ctx, &productpb.StockBatchRequest{Items: items})
+	if err != nil {
+		logger.Err("Failed to check stock", err)
+		return NewServiceError("Failed to check stock")
+	}
+
+	if !stockResp.AllInStock {
+		for _, st := range stockResp.Statuses {
+			if !st.InStock {
+				logger.Logger.Warnf("Product %s out of stock: requested %d, available %d (%s)", st.ProductId, st.RequestedQuantity, st.AvailableQuantity, st.Message)
+			}
+		}
+		return NewServiceError("Product is out of stock")
+	}
+	return nil
+}
+
+// getProductSummaries lấy vendor + loại sản phẩm trong một lần gọi. Lỗi thì không tạo đơn được:
+// thiếu loại sản phẩm thì sản phẩm số bị coi như hàng thường (không giao key, cho cả COD)
+func getProductSummaries(ctx context.Context, productClient productpb.ProductServiceClient, productIDs []string) (map[string]*productpb.ProductSummary, error) {
+	summaries := make(map[string]*productpb.ProductSummary, len(productIDs))
+	if len(productIDs) == 0 {
+		return summaries, nil
+	}
+
+	resp, err := productClient.GetProductsByIDs(ctx, &productpb.ProductIDsRequest{Ids: productIDs})
+	if err != nil {
+		logger.Err("Failed to get product summaries", err)
+		return nil, NewServiceError("Failed to load product information")
+	}
+	for _, p := range resp.Products {
+		summaries[p.Id] = p
+	}
+	return summaries, nil
+}
+
+// shippingStatusFor: sản phẩm số phải thanh toán online, đơn toàn sản phẩm số thì không cần địa chỉ giao hàng
+func shippingStatusFor(items []OrderItem, paymentMethod, shippingAddress string) (string, error) {
+	digital := 0
+	for _, item := range items {
+		if item.Type == models.ProductTypeDigital {
+			digital++
+		}
+	}
+
+	if digital > 0 && strings.EqualFold(paymentMethod, "COD") {
+		return "", NewServiceError("Digital products require online payment")
+	}
+	if digital > 0 && digital == len(items) {
+		return models.ShippingNotRequired, nil
+	}
+	if strings.TrimSpace(shippingAddress) == "" {
+		return "", NewServiceError("Shipping address is required")
+	}
+	return "", nil
+}
+
 // AdminGetOrders retrieves all orders with pagination
 func (s *OrderService) AdminGetOrders(ctx context.Context, page, limit int) ([]models.Order, int64, int, bool, bool, error) {
 	orders, total, err := s.orderRepo.FindOrders(ctx, page, limit)
@@ -725,6 +853,12 @@ func (s *OrderService) HandlePaymentSuccess(ctx context.Context, orderID string,
 	}
 
 	log.Printf("✅ Successfully sent order_success event for order %s", orderID)
+
+	// Giao sản phẩm số: lỗi hay còn thiếu key thì đơn ở FulfillmentPending, RetryPendingFulfillments gán tiếp
+	// (product-service giữ key đã gán theo đơn nên gọi lại không gán trùng)
+	if err := s.fulfillDigitalItems(ctx, updatedOrder); err != nil {
+		logger.Err("Failed to fulfill digital items", err, logger.Str("order_id", orderID))
+	}
 	return nil
 }
 
@@ -737,6 +871,11 @@ func (s *OrderService) HandlePaymentFailure(ctx context.Context, orderID string,
 		return err
 	}
 
+	// Stripe từ chối thì trả coupon để khách đặt lại
+	if order, err := s.orderRepo.GetOrderByID(ctx, orderID); err == nil {
+		s.releaseOrderCoupon(ctx, order)
+	}
+
 	return nil
 }
 
@@ -841,6 +980,23 @@ var (
 	ErrProductServiceUnavailable = NewServiceError("Product service unavailable")
 )
 
+func cartChangedError(notices []*cartpb.CartNotice, items []*cartpb.CartItem) error {
+	selected := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		selected[item.ProductId] = struct{}{}
+	}
+	var messages []string
+	for _, notice := range notices {
+		if _, ok := selected[notice.ProductId]; ok {
+			messages = append(messages, notice.Message)
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return NewServiceError("Your cart has changed, please review it before checkout: " + strings.Join(messages, "; "))
+}
+
 // ServiceError represents a service-level error
 type ServiceError struct {
 	message string
@@ -0,0 +1,225 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// Namespace cho các danh sách. Danh sách chỉ lưu ID, dữ liệu sản phẩm lấy từ key theo entity;
+// khi thành phần hoặc thứ tự danh sách có thể đổi (thêm/xoá, seller sửa status, category...)
+// thì bump version của namespace.
+const (
+	NamespaceProducts    = "products"
+	NamespaceBestSelling = "bestselling"
+	NamespaceCategories  = "categories"
+)
+
+const (
+	ProductTTL = 30 * time.Minute
+	ListTTL    = time.Hour
+	// thời gian tối đa một lần load từ DB trong GetOrLoad
+	LoadTimeout = 10 * time.Second
+)
+
+func ProductKey(id string) string {
+	return "product:" + id
+}
+
+type Cache struct {
+	store   *fallbackStore
+	local   *lruStore
+	group   singleflight.Group
+	metrics metrics
+}
+
+// New tạo cache dùng Redis (có thể nil) với LRU trong process làm dự phòng
+func New(client *redis.Client, lruSize int) *Cache {
+	local := newLRUStore(lruSize)
+	var primary Store
+	if client != nil {
+		primary = newRedisStore(client)
+	}
+	return &Cache{
+		store: newFallbackStore(primary, local),
+		local: local,
+	}
+}
+
+var (
+	defaultCache *Cache
+	defaultOnce  sync.Once
+)
+
+// Init khởi tạo cache dùng chung của service, gọi một lần trong main sau khi kết nối Redis.
+// CACHE_LRU_SIZE là số entry tối đa của LRU dự phòng (mặc định 10000).
+func Init(client *redis.Client) *Cache {
+	defaultOnce.Do(func() {
+		size, _ := strconv.Atoi(os.Getenv("CACHE_LRU_SIZE"))
+		defaultCache = New(client, size)
+	})
+	return defaultCache
+}
+
+// Default trả về cache dùng chung; nếu chưa Init thì chạy chỉ với LRU
+func Default() *Cache {
+	return Init(nil)
+}
+
+// Get đọc key vào dest, trả về false nếu miss
+func (c *Cache) Get(ctx context.Context, key string, dest interface{}) bool {
+	counters := c.metrics.get(key)
+	data, err := c.store.Get(ctx, key)
+	if err != nil {
+		counters.misses.Add(1)
+		return false
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		counters.decodeError.Add(1)
+		counters.misses.Add(1)
+		_ = c.store.Delete(ctx, key)
+		return false
+	}
+	counters.hits.Add(1)
+	return true
+}
+
+// GetMany đọc nhiều key một lần, decode được gọi cho từng key hit. Trả về index các key bị miss.
+func (c *Cache) GetMany(ctx context.Context, keys []string, decode func(i int, data []byte) error) []int {
+	if len(keys) == 0 {
+		return nil
+	}
+	values, err := c.store.MGet(ctx, keys)
+	if err != nil {
+		values = make([][]byte, len(keys))
+	}
+
+	var missing []int
+	for i, key := range keys {
+		counters := c.metrics.get(key)
+		if values[i] == nil {
+			counters.misses.Add(1)
+			missing = append(missing, i)
+			continue
+		}
+		if err := decode(i, values[i]); err != nil {
+			counters.decodeError.Add(1)
+			counters.misses.Add(1)
+			missing = append(missing, i)
+			continue
+		}
+		counters.hits.Add(1)
+	}
+	return missing
+}
+
+// Set ghi đè key (write-through sau khi DB đã cập nhật)
+func (c *Cache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Error marshalling cache value for %s: %v", key, err)
+		return
+	}
+	_ = c.store.Set(ctx, key, data, ttl)
+}
+
+func (c *Cache) Delete(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	_ = c.store.Delete(ctx, keys...)
+}
+
+// GetOrLoad là read-through: hit thì decode vào dest, miss thì gọi load (singleflight theo key
+// để nhiều request cùng miss chỉ truy vấn DB một lần) rồi lưu kết quả. Trả về true nếu hit.
+// load chạy với context tách khỏi request dẫn đầu, request đó bị huỷ thì các request đang chờ
+// vẫn nhận được kết quả; mỗi request chỉ dừng chờ khi context của chính nó bị huỷ.
+func (c *Cache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, dest interface{}, load func(ctx context.Context) (interface{}, error)) (bool, error) {
+	if c.Get(ctx, key, dest) {
+		return true, nil
+	}
+
+	counters := c.metrics.get(key)
+	leader := false
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		leader = true
+		counters.loads.Add(1)
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), LoadTimeout)
+		defer cancel()
+
+		value, err := load(loadCtx)
+		if err != nil {
+			counters.loadErrors.Add(1)
+			return nil, err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		_ = c.store.Set(loadCtx, key, data, ttl)
+		return data, nil
+	})
+
+	var res singleflight.Result
+	select {
+	case res = <-ch:
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+	if res.Shared && !leader {
+		counters.shared.Add(1)
+	}
+	if res.Err != nil {
+		return false, res.Err
+	}
+	return false, json.Unmarshal(res.Val.([]byte), dest)
+}
+
+func versionKey(namespace string) string {
+	return "ns:" + namespace + ":version"
+}
+
+// ListKey tạo key cho một danh sách trong namespace, gắn version hiện tại của namespace
+func (c *Cache) ListKey(ctx context.Context, namespace, suffix string) string {
+	version, _ := c.store.GetInt(ctx, versionKey(namespace))
+	return fmt.Sprintf("%s:v%d:%s", namespace, version, suffix)
+}
+
+// BumpNamespace làm mất hiệu lực toàn bộ danh sách trong namespace bằng cách tăng version,
+// key cũ tự hết hạn theo TTL thay vì phải SCAN + DEL.
+func (c *Cache) BumpNamespace(ctx context.Context, namespaces ...string) {
+	for _, ns := range namespaces {
+		if _, err := c.store.Incr(ctx, versionKey(ns)); err != nil {
+			log.Printf("Error bumping cache namespace %s: %v", ns, err)
+		}
+	}
+}
+
+// InvalidateProduct xoá cache entity của sản phẩm, listChanged=true khi sản phẩm được thêm/xoá
+// khỏi danh sách (tạo mới, xoá, đổi thứ hạng bán chạy...).
+func (c *Cache) InvalidateProduct(ctx context.Context, id string, listChanged bool) {
+	c.Delete(ctx, ProductKey(id))
+	if listChanged {
+		c.BumpNamespace(ctx, NamespaceProducts, NamespaceBestSelling)
+	}
+}
+
+func (c *Cache) Stats() Stats {
+	backend := "redis"
+	if c.store.Degraded() {
+		backend = "lru"
+	}
+	return Stats{
+		Backend:    backend,
+		LocalItems: c.local.Len(),
+		Namespaces: c.metrics.snapshot(),
+	}
+}
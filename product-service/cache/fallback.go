@@ -0,0 +1,216 @@
+package cache
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	redisOpTimeout     = 300 * time.Millisecond
+	redisRetryInterval = 10 * time.Second
+	maxPendingKeys     = 10000
+)
+
+// fallbackStore dùng Redis làm cache chung giữa các instance. Khi Redis lỗi thì chuyển sang
+// LRU trong process, thử lại Redis sau redisRetryInterval. Các lệnh xoá/bump version không
+// gửi được trong lúc Redis down sẽ được gửi lại khi kết nối lại để Redis không giữ dữ liệu cũ.
+type fallbackStore struct {
+	primary Store
+	local   *lruStore
+
+	mu          sync.Mutex
+	downUntil   time.Time
+	probing     atomic.Bool
+	pendingDel  map[string]struct{}
+	pendingIncr map[string]struct{}
+}
+
+func newFallbackStore(primary Store, local *lruStore) *fallbackStore {
+	return &fallbackStore{
+		primary:     primary,
+		local:       local,
+		pendingDel:  make(map[string]struct{}),
+		pendingIncr: make(map[string]struct{}),
+	}
+}
+
+// Degraded cho biết đang phục vụ từ LRU trong process
+func (s *fallbackStore) Degraded() bool {
+	if s.primary == nil {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Now().Before(s.downUntil)
+}
+
+// acquire trả về true nếu request này được dùng Redis. Sau khi hết thời gian chờ,
+// chỉ một request được thử lại Redis, các request khác vẫn dùng LRU.
+func (s *fallbackStore) acquire() (useRedis bool, probe bool) {
+	if s.primary == nil {
+		return false, false
+	}
+	s.mu.Lock()
+	down := !s.downUntil.IsZero()
+	ready := time.Now().After(s.downUntil)
+	s.mu.Unlock()
+
+	if !down {
+		return true, false
+	}
+	if ready && s.probing.CompareAndSwap(false, true) {
+		return true, true
+	}
+	return false, false
+}
+
+func (s *fallbackStore) release(probe bool, err error) {
+	if err != nil {
+		s.mu.Lock()
+		if s.downUntil.IsZero() {
+			log.Printf("Redis cache unavailable, falling back to in-process LRU: %v", err)
+		}
+		s.downUntil = time.Now().Add(redisRetryInterval)
+		s.mu.Unlock()
+	} else if probe {
+		s.recover()
+	}
+	if probe {
+		s.probing.Store(false)
+	}
+}
+
+// recover gửi lại các invalidation bị lỡ và xoá LRU (dữ liệu trong lúc down không đồng bộ với instance khác)
+func (s *fallbackStore) recover() {
+	s.mu.Lock()
+	dels := make([]string, 0, len(s.pendingDel))
+	for k := range s.pendingDel {
+		dels = append(dels, k)
+	}
+	incrs := make([]string, 0, len(s.pendingIncr))
+	for k := range s.pendingIncr {
+		incrs = append(incrs, k)
+	}
+	s.pendingDel = make(map[string]struct{})
+	s.pendingIncr = make(map[string]struct{})
+	s.downUntil = time.Time{}
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if len(dels) > 0 {
+		if err := s.primary.Delete(ctx, dels...); err != nil {
+			log.Printf("Error replaying cache invalidations: %v", err)
+		}
+	}
+	for _, k := range incrs {
+		if _, err := s.primary.Incr(ctx, k); err != nil {
+			log.Printf("Error replaying namespace bump %s: %v", k, err)
+		}
+	}
+	s.local.Purge()
+	log.Printf("Redis cache recovered, replayed %d invalidations and %d namespace bumps", len(dels), len(incrs))
+}
+
+func (s *fallbackStore) remember(set map[string]struct{}, keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range keys {
+		if len(set) >= maxPendingKeys {
+			log.Printf("WARNING: too many pending cache invalidations, dropping %s", k)
+			continue
+		}
+		set[k] = struct{}{}
+	}
+}
+
+func (s *fallbackStore) Get(ctx context.Context, key string) ([]byte, error) {
+	if useRedis, probe := s.acquire(); useRedis {
+		opCtx, cancel := context.WithTimeout(ctx, redisOpTimeout)
+		data, err := s.primary.Get(opCtx, key)
+		cancel()
+		if err == nil || err == errMiss {
+			s.release(probe, nil)
+			return data, err
+		}
+		s.release(probe, err)
+	}
+	return s.local.Get(ctx, key)
+}
+
+func (s *fallbackStore) MGet(ctx context.Context, keys []string) ([][]byte, error) {
+	if useRedis, probe := s.acquire(); useRedis {
+		opCtx, cancel := context.WithTimeout(ctx, redisOpTimeout)
+		data, err := s.primary.MGet(opCtx, keys)
+		cancel()
+		s.release(probe, err)
+		if err == nil {
+			return data, nil
+		}
+	}
+	return s.local.MGet(ctx, keys)
+}
+
+func (s *fallbackStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if useRedis, probe := s.acquire(); useRedis {
+		opCtx, cancel := context.WithTimeout(ctx, redisOpTimeout)
+		err := s.primary.Set(opCtx, key, value, ttl)
+		cancel()
+		s.release(probe, err)
+		if err == nil {
+			return nil
+		}
+	}
+	return s.local.Set(ctx, key, value, ttl)
+}
+
+// Delete luôn xoá cả LRU để instance không đọc lại dữ liệu cũ khi chuyển qua lại giữa hai tầng
+func (s *fallbackStore) Delete(ctx context.Context, keys ...string) error {
+	_ = s.local.Delete(ctx, keys...)
+	if useRedis, probe := s.acquire(); useRedis {
+		opCtx, cancel := context.WithTimeout(ctx, redisOpTimeout)
+		err := s.primary.Delete(opCtx, keys...)
+		cancel()
+		s.release(probe, err)
+		if err == nil {
+			return nil
+		}
+	}
+	if s.primary != nil {
+		s.remember(s.pendingDel, keys...)
+	}
+	return nil
+}
+
+func (s *fallbackStore) Incr(ctx context.Context, key string) (int64, error) {
+	local, _ := s.local.Incr(ctx, key)
+	if useRedis, probe := s.acquire(); useRedis {
+		opCtx, cancel := context.WithTimeout(ctx, redisOpTimeout)
+		v, err := s.primary.Incr(opCtx, key)
+		cancel()
+		s.release(probe, err)
+		if err == nil {
+			return v, nil
+		}
+	}
+	if s.primary != nil {
+		s.remember(s.pendingIncr, key)
+	}
+	return local, nil
+}
+
+func (s *fallbackStore) GetInt(ctx context.Context, key string) (int64, error) {
+	if useRedis, probe := s.acquire(); useRedis {
+		opCtx, cancel := context.WithTimeout(ctx, redisOpTimeout)
+		v, err := s.primary.GetInt(opCtx, key)
+		cancel()
+		s.release(probe, err)
+		if err == nil {
+			return v, nil
+		}
+	}
+	return s.local.GetInt(ctx, key)
+}
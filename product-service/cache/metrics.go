@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type counters struct {
+	hits        atomic.Int64
+	misses      atomic.Int64
+	loads       atomic.Int64
+	loadErrors  atomic.Int64
+	shared      atomic.Int64
+	decodeError atomic.Int64
+}
+
+type NamespaceStats struct {
+	Namespace    string  `json:"namespace"`
+	Hits         int64   `json:"hits"`
+	Misses       int64   `json:"misses"`
+	HitRatio     float64 `json:"hit_ratio"`
+	Loads        int64   `json:"loads"`
+	LoadErrors   int64   `json:"load_errors"`
+	SharedLoads  int64   `json:"shared_loads"` // request dùng chung kết quả load nhờ singleflight
+	DecodeErrors int64   `json:"decode_errors"`
+}
+
+type Stats struct {
+	Backend    string           `json:"backend"` // redis | lru
+	LocalItems int              `json:"local_items"`
+	Namespaces []NamespaceStats `json:"namespaces"`
+}
+
+type metrics struct {
+	byNamespace sync.Map // namespace -> *counters
+}
+
+func (m *metrics) get(key string) *counters {
+	ns := namespaceOf(key)
+	if c, ok := m.byNamespace.Load(ns); ok {
+		return c.(*counters)
+	}
+	c, _ := m.byNamespace.LoadOrStore(ns, &counters{})
+	return c.(*counters)
+}
+
+func (m *metrics) snapshot() []NamespaceStats {
+	var out []NamespaceStats
+	m.byNamespace.Range(func(k, v interface{}) bool {
+		c := v.(*counters)
+		s := NamespaceStats{
+			Namespace:    k.(string),
+			Hits:         c.hits.Load(),
+			Misses:       c.misses.Load(),
+			Loads:        c.loads.Load(),
+			LoadErrors:   c.loadErrors.Load(),
+			SharedLoads:  c.shared.Load(),
+			DecodeErrors: c.decodeError.Load(),
+		}
+		if total := s.Hits + s.Misses; total > 0 {
+			s.HitRatio = float64(s.Hits) / float64(total)
+		}
+		out = append(out, s)
+		return true
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].Namespace < out[j].Namespace })
+	return out
+}
+
+// namespaceOf lấy phần trước dấu ":" đầu tiên, vd "product:123" -> "product"
+func namespaceOf(key string) string {
+	if i := strings.IndexByte(key, ':'); i > 0 {
+		return key[:i]
+	}
+	return key
+}
@@ -0,0 +1,109 @@
+// Lệnh rebuild dữ liệu gợi ý: đọc toàn bộ đơn thành công từ order-service rồi dựng lại
+// co-purchase và user affinity vào một generation mới. Trong lúc chạy gợi ý vẫn đọc dữ liệu cũ
+// và consumer vẫn cộng đơn mới vào cả 2 generation, xong mới chuyển sang generation mới.
+//
+//	go run ./cmd/rebuild-recommendations
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"product-service/models"
+	"product-service/repository"
+	"product-service/service"
+
+	orderpb "module/gRPC-Order/service"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/joho/godotenv"
+	"google.golang.org/grpc"
+)
+
+const orderPageSize = 500
+
+// orderServiceHistory đọc từng trang đơn thành công qua gRPC của order-service
+type orderServiceHistory struct {
+	client orderpb.OrderServiceClient
+}
+
+func (h orderServiceHistory) EachSuccessfulOrder(ctx context.Context, fn func(order models.OrderLine) error) error {
+	var after uint64
+	for {
+		resp, err := h.client.ListSuccessfulOrders(ctx, &orderpb.ListOrdersRequest{AfterId: after, Limit: orderPageSize})
+		if err != nil {
+			return err
+		}
+		for _, o := range resp.GetOrders() {
+			order := models.OrderLine{
+				OrderID:    o.GetOrderId(),
+				UserID:     o.GetUserId(),
+				ProductIDs: make([]string, 0, len(o.GetItems())),
+				Quantities: make(map[string]int, len(o.GetItems())),
+				OrderedAt:  time.Unix(o.GetCreatedAt(), 0),
+			}
+			for _, item := range o.GetItems() {
+				order.ProductIDs = append(order.ProductIDs, item.GetProductId())
+				order.Quantities[item.GetProductId()] += int(item.GetQuantity())
+			}
+			if err := fn(order); err != nil {
+				return err
+			}
+		}
+		if resp.GetNextAfterId() == 0 {
+			return nil
+		}
+		after = resp.GetNextAfterId()
+	}
+}
+
+func main() {
+	if err := godotenv.Load("./product-service/.env"); err != nil {
+		log.Println("Warning: Error loading .env file:", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	region := os.Getenv("DYNAMODB_REGION")
+	if region == "" {
+		region = "us-west-2"
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	if err != nil {
+		log.Fatalf("unable to load SDK config: %v", err)
+	}
+	dynamoClient := dynamodb.NewFromConfig(cfg)
+
+	coPurchaseTableName := os.Getenv("DYNAMODB_COPURCHASE_TABLE")
+	if coPurchaseTableName == "" {
+		coPurchaseTableName = "co-purchase-table"
+	}
+	affinityTableName := os.Getenv("DYNAMODB_USER_AFFINITY_TABLE")
+	if affinityTableName == "" {
+		affinityTableName = "user-affinity-table"
+	}
+
+	orderAddress := os.Getenv("ORDER_SERVICE_ADDRESS")
+	if orderAddress == "" {
+		orderAddress = "order-service:8100"
+	}
+	orderConn, err := grpc.Dial(orderAddress, grpc.WithInsecure())
+	if err != nil {
+		log.Fatalf("Failed to connect to order-service: %v", err)
+	}
+	defer orderConn.Close()
+
+	// Rebuild chỉ cần repo gợi ý, không cần product/cart
+	svc := service.NewRecommendationService(
+		repository.NewRecommendationRepository(dynamoClient, coPurchaseTableName, affinityTableName),
+		nil, nil, nil,
+	)
+	if err := svc.Rebuild(ctx, orderServiceHistory{client: orderpb.NewOrderServiceClient(orderConn)}); err != nil {
+		log.Fatalf("Rebuild failed: %v", err)
+	}
+	log.Printf("Recommendations rebuilt")
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	logger "product-service/log"
+	"product-service/models"
+	"product-service/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type BundleController struct {
+	service service.BundleService
+}
+
+func NewBundleController(service service.BundleService) *BundleController {
+	return &BundleController{service: service}
+}
+
+// CreateBundle: seller gộp nhiều sản phẩm của mình thành một bundle với giá riêng
+func (ctrl *BundleController) CreateBundle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		var req models.CreateBundleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+			return
+		}
+
+		if err := ctrl.service.CreateBundle(ctx, userID, req); err != nil {
+			var validationErr *service.ValidationError
+			if errors.As(err, &validationErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+				return
+			}
+			logger.Error("Error creating bundle", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create bundle"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Bundle added successfully"})
+	}
+}
+
+// GetBundleComponents trả về các sản phẩm thành phần của bundle, public
+func (ctrl *BundleController) GetBundleComponents() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		components, err := ctrl.service.GetBundleComponents(ctx, c.Param("id"))
+		if err != nil {
+			var validationErr *service.ValidationError
+			if errors.As(err, &validationErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+				return
+			}
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": components})
+	}
+}
@@ -0,0 +1,20 @@
+package controllers
+
+import (
+	"net/http"
+
+	"product-service/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheStats trả về hit/miss theo namespace và backend đang dùng (redis hoặc lru)
+func CacheStats() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isAdmin(c) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can view cache stats"})
+			return
+		}
+		c.JSON(http.StatusOK, cache.Default().Stats())
+	}
+}
@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	logger "product-service/log"
+	"product-service/models"
+	"product-service/repository"
+	"product-service/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type CategoryController struct {
+	service service.CategoryService
+}
+
+func NewCategoryController(service service.CategoryService) *CategoryController {
+	return &CategoryController{service: service}
+}
+
+// Chỉ tin X-Role do gateway gắn từ token, header user_type client tự gửi được
+func isAdmin(c *gin.Context) bool {
+	return c.GetHeader("X-Role") == "ADMIN"
+}
+
+func handleCategoryError(c *gin.Context, err error) {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+	case errors.Is(err, repository.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+	case errors.Is(err, service.ErrCategorySlugTaken),
+		errors.Is(err, service.ErrCategoryHasChildren),
+		errors.Is(err, service.ErrCategoryInUse),
+		errors.Is(err, service.ErrCategoryCycle):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		logger.Error("Category operation failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+func (ctrl *CategoryController) CreateCategory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isAdmin(c) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can manage categories"})
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		var req models.CreateCategoryRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+			return
+		}
+
+		category, err := ctrl.service.CreateCategory(ctx, req)
+		if err != nil {
+			handleCategoryError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, category)
+	}
+}
+
+func (ctrl *CategoryController) UpdateCategory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isAdmin(c) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can manage categories"})
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+		defer cancel()
+
+		var req models.UpdateCategoryRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+			return
+		}
+
+		category, err := ctrl.service.UpdateCategory(ctx, c.Param("id"), req)
+		if err != nil {
+			handleCategoryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, category)
+	}
+}
+
+func (ctrl *CategoryController) DeleteCategory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isAdmin(c) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can manage categories"})
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		if err := ctrl.service.DeleteCategory(ctx, c.Param("id")); err != nil {
+			handleCategoryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+	}
+}
+
+func (ctrl *CategoryController) GetCategoryTree() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		tree, err := ctrl.service.GetCategoryTree(ctx)
+		if err != nil {
+			handleCategoryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": tree})
+	}
+}
+
+func (ctrl *CategoryController) GetCategory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		category, err := ctrl.service.GetCategory(ctx, c.Param("slug"))
+		if err != nil {
+			handleCategoryError(c, err)
+			return
+		}
+
+		schema, err := ctrl.service.GetAttributeSchema(ctx, category)
+		if err != nil {
+			handleCategoryError(c, err)
+			return
+		}
+
+		breadcrumbs, err := ctrl.service.GetBreadcrumbs(ctx, category.ID)
+		if err != nil {
+			handleCategoryError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"category":         category,
+			"attribute_schema": schema,
+			"breadcrumbs":      breadcrumbs,
+		})
+	}
+}
+
+func (ctrl *CategoryController) GetBreadcrumbs() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		breadcrumbs, err := ctrl.service.GetBreadcrumbs(ctx, c.Param("slug"))
+		if err != nil {
+			handleCategoryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": breadcrumbs})
+	}
+}
+
+// MigrateLegacyCategories map category free-text của product cũ sang cây category.
+// ?dry_run=true chỉ trả về báo cáo, ?create_missing=true tạo category gốc cho giá trị chưa có.
+func (ctrl *CategoryController) MigrateLegacyCategories() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isAdmin(c) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can manage categories"})
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+		defer cancel()
+
+		dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "true"))
+		createMissing, _ := strconv.ParseBool(c.DefaultQuery("create_missing", "false"))
+
+		report, err := ctrl.service.MigrateLegacyCategories(ctx, dryRun, createMissing)
+		if err != nil {
+			handleCategoryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	}
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	logger "product-service/log"
+	"product-service/models"
+	"product-service/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type DigitalController struct {
+	service service.DigitalService
+}
+
+func NewDigitalController(service service.DigitalService) *DigitalController {
+	return &DigitalController{service: service}
+}
+
+// CreateUploadURL: seller lấy presigned URL để upload file sản phẩm số (private) trước khi tạo sản phẩm
+func (ctrl *DigitalController) CreateUploadURL() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		var req service.UploadRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+			return
+		}
+
+		resp, err := ctrl.service.CreateUploadURL(userID, req)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+func (ctrl *DigitalController) CreateDigitalProduct() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		var req models.CreateDigitalProductRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+			return
+		}
+
+		if err := ctrl.service.CreateDigitalProduct(ctx, userID, req); err != nil {
+			var validationErr *service.ValidationError
+			if errors.As(err, &validationErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+				return
+			}
+			logger.Error("Error creating digital product", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create digital product"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Digital product added successfully"})
+	}
+}
+
+// AddLicenseKeys nạp thêm key vào pool, tồn kho tăng theo số key thêm được
+func (ctrl *DigitalController) AddLicenseKeys() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+		defer cancel()
+
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		var req models.AddLicenseKeysRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+			return
+		}
+
+		added, err := ctrl.service.AddLicenseKeys(ctx, userID, c.Param("id"), req.Keys)
+		if err != nil {
+			var validationErr *service.ValidationError
+			if errors.As(err, &validationErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+				return
+			}
+			logger.Error("Error adding license keys", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add license keys", "added": added})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "License keys added successfully", "added": added})
+	}
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	logger "product-service/log"
+	"product-service/models"
+	"product-service/repository"
+	"product-service/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type DraftController struct {
+	service service.DraftService
+}
+
+func NewDraftController(service service.DraftService) *DraftController {
+	return &DraftController{service: service}
+}
+
+func handleDraftError(c *gin.Context, err error) {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+	case errors.Is(err, repository.ErrDraftNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Draft not found"})
+	default:
+		logger.Error("Draft operation failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+func (ctrl *DraftController) CreateDraft() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		var req models.SaveDraftRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+			return
+		}
+
+		draft, err := ctrl.service.CreateDraft(ctx, userID, req)
+		if err != nil {
+			handleDraftError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, draft)
+	}
+}
+
+func (ctrl *DraftController) UpdateDraft() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		var req models.SaveDraftRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+			return
+		}
+
+		draft, err := ctrl.service.UpdateDraft(ctx, userID, c.Param("id"), req)
+		if err != nil {
+			handleDraftError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, draft)
+	}
+}
+
+func (ctrl *DraftController) GetDraft() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		draft, err := ctrl.service.GetDraft(ctx, userID, c.Param("id"))
+		if err != nil {
+			handleDraftError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, draft)
+	}
+}
+
+func (ctrl *DraftController) ListDrafts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		drafts, err := ctrl.service.ListDrafts(ctx, userID)
+		if err != nil {
+			handleDraftError(c, err)
+			return
+		}
+		if drafts == nil {
+			drafts = []models.ProductDraft{}
+		}
+		c.JSON(http.StatusOK, gin.H{"drafts": drafts})
+	}
+}
+
+func (ctrl *DraftController) DeleteDraft() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		if err := ctrl.service.DeleteDraft(ctx, userID, c.Param("id")); err != nil {
+			handleDraftError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Draft deleted successfully"})
+	}
+}
+
+// PublishDraft tạo sản phẩm thật từ draft, lúc này mới kiểm tra đủ các field bắt buộc
+func (ctrl *DraftController) PublishDraft() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		productID, err := ctrl.service.PublishDraft(ctx, userID, c.Param("id"))
+		if err != nil {
+			if productID != "" {
+				// sản phẩm đã tạo xong, chỉ xoá draft lỗi
+				logger.Error("Error deleting published draft", zap.String("draft_id", c.Param("id")), zap.Error(err))
+				c.JSON(http.StatusOK, gin.H{"message": "Draft published successfully", "product_id": productID})
+				return
+			}
+			handleDraftError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Draft published successfully", "product_id": productID})
+	}
+}
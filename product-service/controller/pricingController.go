@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	logger "product-service/log"
+	"product-service/models"
+	"product-service/repository"
+	"product-service/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type PricingController struct {
+	service service.PricingService
+}
+
+func NewPricingController(service service.PricingService) *PricingController {
+	return &PricingController{service: service}
+}
+
+func handlePricingError(c *gin.Context, err error) {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+	case errors.Is(err, service.ErrScheduleNotOwner):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrScheduleOverlap), errors.Is(err, service.ErrScheduleFinished):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrPriceScheduleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Price schedule not found"})
+	case err.Error() == "product not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	default:
+		logger.Error("Pricing operation failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+func (ctrl *PricingController) SchedulePrice() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		id := c.Param("id")
+		if _, err := uuid.Parse(id); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Product ID"})
+			return
+		}
+
+		var req models.CreatePriceScheduleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+			return
+		}
+
+		schedule, err := ctrl.service.SchedulePrice(ctx, userID, id, req)
+		if err != nil {
+			handlePricingError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, schedule)
+	}
+}
+
+func (ctrl *PricingController) GetSchedules() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		schedules, err := ctrl.service.GetSchedules(ctx, c.Param("id"))
+		if err != nil {
+			handlePricingError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": schedules})
+	}
+}
+
+func (ctrl *PricingController) CancelSchedule() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		if err := ctrl.service.CancelSchedule(ctx, userID, c.Param("schedule_id")); err != nil {
+			handlePricingError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Price schedule cancelled"})
+	}
+}
+
+func (ctrl *PricingController) GetPriceHistory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+		if err != nil || days < 1 || days > 365 {
+			days = 30
+		}
+
+		summary, err := ctrl.service.GetPriceHistory(ctx, c.Param("id"), days)
+		if err != nil {
+			handlePricingError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, summary)
+	}
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net/http"
 
@@ -59,6 +60,7 @@ func (ctrl *ProductController) AddProduct() gin.HandlerFunc {
 		product := models.Product{
 			Name:        name,
 			Category:    category,
+			Attributes:  req.Attributes,
 			Description: description,
 			Price:       price,
 			Quantity:    quantity,
@@ -66,8 +68,16 @@ func (ctrl *ProductController) AddProduct() gin.HandlerFunc {
 			UserID:      userID,
 			Status:      status,
 		}
+		if req.LowStockThreshold != nil {
+			product.LowStockThreshold = *req.LowStockThreshold
+		}
 
 		if err := ctrl.service.AddProduct(ctx, product); err != nil {
+			var validationErr *service.ValidationError
+			if errors.As(err, &validationErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+				return
+			}
 			logger.Error("Error adding product", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add product"})
 			return
@@ -115,6 +125,9 @@ func (ctrl *ProductController) EditProduct() gin.HandlerFunc {
 		if req.Category != nil {
 			update["category"] = *req.Category
 		}
+		if req.Attributes != nil {
+			update["attributes"] = *req.Attributes
+		}
 		if req.Description != nil {
 			update["description"] = *req.Description
 		}
@@ -127,6 +140,9 @@ func (ctrl *ProductController) EditProduct() gin.HandlerFunc {
 		if req.Status != nil {
 			update["status"] = *req.Status
 		}
+		if req.LowStockThreshold != nil {
+			update["low_stock_threshold"] = *req.LowStockThreshold
+		}
 
 		if len(update) == 0 {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "No fields to update"})
@@ -134,7 +150,12 @@ func (ctrl *ProductController) EditProduct() gin.HandlerFunc {
 			return
 		}
 
-		if err := ctrl.service.EditProduct(ctx, id, update); err != nil {
+		if err := ctrl.service.EditProduct(ctx, id, c.GetHeader("X-User-ID"), update); err != nil {
+			var validationErr *service.ValidationError
+			if errors.As(err, &validationErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+				return
+			}
 			logger.Error("Error updating product", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product"})
 			return
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	logger "product-service/log"
+	"product-service/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type RecommendationController struct {
+	service service.RecommendationService
+}
+
+func NewRecommendationController(service service.RecommendationService) *RecommendationController {
+	return &RecommendationController{service: service}
+}
+
+// GetProductRecommendations: "thường được mua cùng" + sản phẩm tương tự, public
+func (ctrl *RecommendationController) GetProductRecommendations() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		id := c.Param("id")
+		if id == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Product ID not found"})
+			return
+		}
+		limit, _ := strconv.Atoi(c.Query("limit"))
+
+		recs, err := ctrl.service.GetProductRecommendations(ctx, id, limit)
+		if err != nil {
+			logger.Error("Get product recommendations failed", zap.String("product_id", id), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		if recs == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+			return
+		}
+		c.JSON(http.StatusOK, recs)
+	}
+}
+
+// GetForYou: gợi ý cá nhân hoá theo lịch sử mua và giỏ hàng của user
+func (ctrl *RecommendationController) GetForYou() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User ID not found"})
+			return
+		}
+		limit, _ := strconv.Atoi(c.Query("limit"))
+
+		products, err := ctrl.service.GetForUser(ctx, userID, limit)
+		if err != nil {
+			logger.Error("Get recommendations for user failed", zap.String("user_id", userID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": products, "count": len(products)})
+	}
+}
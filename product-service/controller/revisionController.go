@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	logger "product-service/log"
+	"product-service/models"
+	"product-service/repository"
+	"product-service/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type RevisionController struct {
+	service service.RevisionService
+}
+
+func NewRevisionController(service service.RevisionService) *RevisionController {
+	return &RevisionController{service: service}
+}
+
+func handleRevisionError(c *gin.Context, err error) {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+	case errors.Is(err, repository.ErrRevisionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+	case err.Error() == "product not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	default:
+		logger.Error("Revision operation failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+func (ctrl *RevisionController) ListRevisions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+		revisions, err := ctrl.service.ListRevisions(ctx, c.Param("id"), userID, limit)
+		if err != nil {
+			handleRevisionError(c, err)
+			return
+		}
+		if revisions == nil {
+			revisions = []models.ProductRevision{}
+		}
+		c.JSON(http.StatusOK, gin.H{"revisions": revisions})
+	}
+}
+
+// DiffRevisions: ?from=&to=, bỏ trống to là revision mới nhất, bỏ trống from là revision liền trước
+func (ctrl *RevisionController) DiffRevisions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		from, err := strconv.Atoi(c.DefaultQuery("from", "0"))
+		if err != nil || from < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from revision"})
+			return
+		}
+		to, err := strconv.Atoi(c.DefaultQuery("to", "0"))
+		if err != nil || to < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to revision"})
+			return
+		}
+
+		diff, err := ctrl.service.DiffRevisions(ctx, c.Param("id"), userID, from, to)
+		if err != nil {
+			handleRevisionError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, diff)
+	}
+}
+
+func (ctrl *RevisionController) Rollback() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User ID not found"})
+			return
+		}
+
+		revision, err := strconv.Atoi(c.Param("revision"))
+		if err != nil || revision < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision"})
+			return
+		}
+
+		if err := ctrl.service.Rollback(ctx, c.Param("id"), userID, revision); err != nil {
+			handleRevisionError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Product rolled back successfully", "restored_from": revision})
+	}
+}
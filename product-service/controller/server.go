@@ -2,11 +2,14 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"log"
 	"strings"
 
 	pb "module/gRPC-Product/service"
 	"product-service/log"
+	"product-service/models"
 	"product-service/service"
 
 	"google.golang.org/grpc/codes"
@@ -16,6 +19,8 @@ import (
 type ProductServer struct {
 	pb.UnimplementedProductServiceServer
 	service service.ProductService
+	pricing service.PricingService
+	digital service.DigitalService
 }
 
 // func (s *ProductServer) GetBasicInfo(ctx context.Context, req *pb.ProductRequest) (*pb.BasicProductResponse, error){
@@ -105,9 +110,11 @@ type ProductServer struct {
 
 
 
-func NewProductServer(service service.ProductService) *ProductServer {
+func NewProductServer(service service.ProductService, pricing service.PricingService, digital service.DigitalService) *ProductServer {
 	return &ProductServer{
 		service: service,
+		pricing: pricing,
+		digital: digital,
 	}
 }
 
@@ -139,14 +146,36 @@ func (s *ProductServer) GetProductInfo(ctx context.Context, req *pb.ProductReque
 		imageUrls = strings.Join(product.ImagePath, ",")
 	}
 
-	return &pb.ProductResponse{
+	resp := &pb.ProductResponse{
 		Id: product.ID,
 		Name: product.Name,
 		Price: float32(product.Price),
 		Description: product.Description,
 		ImageUrl: imageUrls,
 		Quantity: int32(product.Quantity),
-	}, nil 
+		VendorId: product.UserID,
+		RegularPrice: float32(product.Price),
+		LowestPrice_30D: float32(product.Price),
+	}
+
+	if product.SaleScheduleID != "" {
+		resp.OnSale = true
+		if product.RegularPrice > 0 {
+			resp.RegularPrice = float32(product.RegularPrice)
+		}
+		if product.SaleEndAt != nil {
+			resp.SaleEndAt = product.SaleEndAt.Unix()
+		}
+	}
+
+	summary, err := s.pricing.SummarizePriceHistory(ctx, product, 30)
+	if err != nil {
+		logger.Err("Failed to get price history", err)
+	} else {
+		resp.LowestPrice_30D = float32(summary.LowestPriorPrice)
+	}
+
+	return resp, nil
 }
 
 func (s *ProductServer) GetBasicInfo(ctx context.Context, req *pb.ProductRequest) (*pb.BasicProductResponse, error){
@@ -211,4 +240,188 @@ func (s *ProductServer) GetAllProducts(ctx context.Context, req *pb.Empty) (*pb.
 		})
 	}
 	return &pb.ProductList{Products: pbProducts}, nil
-}
\ No newline at end of file
+}
+// ExportProducts stream toàn bộ sản phẩm cho search-service dựng lại index
+func (s *ProductServer) ExportProducts(req *pb.ExportProductsRequest, stream pb.ProductService_ExportProductsServer) error {
+	err := s.service.ExportProducts(stream.Context(), func(p models.Product) error {
+		imageURL := ""
+		if len(p.ImagePath) > 0 {
+			imageURL = p.ImagePath[0]
+		}
+		attributes := ""
+		if len(p.Attributes) > 0 {
+			data, err := json.Marshal(p.Attributes)
+			if err != nil {
+				return err
+			}
+			attributes = string(data)
+		}
+		return stream.Send(&pb.ExportedProduct{
+			Id:             p.ID,
+			Name:           p.Name,
+			Price:          p.Price,
+			Category:       p.Category,
+			Description:    p.Description,
+			ImageUrl:       imageURL,
+			Quantity:       int32(p.Quantity),
+			SoldCount:      int32(p.SoldCount),
+			Rating:         p.Rating,
+			RatingCount:    int32(p.RatingCount),
+			UserId:         p.UserID,
+			Status:         p.Status,
+			AttributesJson: attributes,
+			CreatedAt:      p.Created_at.UnixMilli(),
+			UpdatedAt:      p.Updated_at.UnixMilli(),
+		})
+	})
+	if err != nil {
+		logger.Err("Failed to export products", err)
+		return status.Errorf(codes.Internal, "Failed to export products: %v", err)
+	}
+	return nil
+}
+
+// GetProductsByIDs trả về thông tin cơ bản + tồn kho cho nhiều sản phẩm trong một lần gọi
+func (s *ProductServer) GetProductsByIDs(ctx context.Context, req *pb.ProductIDsRequest) (*pb.ProductBatchResponse, error) {
+	if len(req.Ids) == 0 {
+		return &pb.ProductBatchResponse{}, nil
+	}
+
+	products, err := s.service.GetProductsByIDs(ctx, req.Ids)
+	if err != nil {
+		logger.Err("Failed to batch get products", err)
+		return nil, status.Errorf(codes.Internal, "Failed to get products: %v", err)
+	}
+
+	found := make(map[string]bool, len(products))
+	resp := &pb.ProductBatchResponse{}
+	for _, p := range products {
+		found[p.ID] = true
+		resp.Products = append(resp.Products, &pb.ProductSummary{
+			Id:                p.ID,
+			Name:              p.Name,
+			Price:             float32(p.Price),
+			VendorId:          p.UserID,
+			Status:            p.Status,
+			AvailableQuantity: int32(p.Quantity),
+			ProductType:       p.Type,
+			UnlimitedStock:    p.UnlimitedStock,
+		})
+	}
+	for _, id := range req.Ids {
+		if !found[id] {
+			resp.NotFoundIds = append(resp.NotFoundIds, id)
+			found[id] = true
+		}
+	}
+
+	return resp, nil
+}
+
+// CheckStockBatch kiểm tra tồn kho cho toàn bộ item của một đơn hàng
+func (s *ProductServer) CheckStockBatch(ctx context.Context, req *pb.StockBatchRequest) (*pb.StockBatchResponse, error) {
+	if len(req.Items) == 0 {
+		return &pb.StockBatchResponse{AllInStock: true}, nil
+	}
+
+	// cùng một sản phẩm có thể nằm ở nhiều dòng, kiểm tra trên tổng số lượng
+	requested := make(map[string]int32, len(req.Items))
+	ids := make([]string, 0, len(req.Items))
+	for _, item := range req.Items {
+		if _, ok := requested[item.ProductId]; !ok {
+			ids = append(ids, item.ProductId)
+		}
+		requested[item.ProductId] += item.Quantity
+	}
+
+	products, err := s.service.GetProductsByIDs(ctx, ids)
+	if err != nil {
+		logger.Err("Failed to batch get products", err)
+		return nil, status.Errorf(codes.Internal, "Failed to check stock: %v", err)
+	}
+
+	byID := make(map[string]int, len(products))
+	for i := range products {
+		byID[products[i].ID] = i
+	}
+
+	resp := &pb.StockBatchResponse{AllInStock: true}
+	for _, item := range req.Items {
+		stockStatus := &pb.StockStatus{
+			ProductId:         item.ProductId,
+			RequestedQuantity: item.Quantity,
+		}
+
+		idx, ok := byID[item.ProductId]
+		switch {
+		case !ok:
+			stockStatus.Message = "Product not found"
+		case products[idx].Status != "" && products[idx].Status != "onsale":
+			stockStatus.AvailableQuantity = int32(products[idx].Quantity)
+			stockStatus.Message = "Product is not on sale"
+		default:
+			stockStatus.AvailableQuantity = int32(products[idx].Quantity)
+			if products[idx].Quantity > 0 && int32(products[idx].Quantity) >= requested[item.ProductId] {
+				stockStatus.InStock = true
+				stockStatus.Message = "Product is in stock"
+			} else {
+				stockStatus.Message = "Product is out of stock"
+			}
+		}
+
+		if !stockStatus.InStock {
+			resp.AllInStock = false
+		}
+		resp.Statuses = append(resp.Statuses, stockStatus)
+	}
+
+	return resp, nil
+}
+
+// FulfillDigitalItems được order-service gọi sau khi đơn thanh toán thành công
+func (s *ProductServer) FulfillDigitalItems(ctx context.Context, req *pb.DigitalFulfillmentRequest) (*pb.DigitalFulfillmentResponse, error) {
+	if req.OrderId == "" {
+		return nil, status.Error(codes.InvalidArgument, "order_id is required")
+	}
+
+	items := make([]models.StockUpdateItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, models.StockUpdateItem{ProductID: item.ProductId, Quantity: int(item.Quantity)})
+	}
+
+	fulfillments, err := s.digital.Fulfill(ctx, req.OrderId, req.UserId, items)
+	if err != nil {
+		logger.Err("Failed to fulfill digital items", err)
+		return nil, status.Errorf(codes.Internal, "Failed to fulfill digital items: %v", err)
+	}
+
+	resp := &pb.DigitalFulfillmentResponse{}
+	for _, f := range fulfillments {
+		resp.Items = append(resp.Items, &pb.DigitalItemFulfillment{
+			ProductId:           f.ProductID,
+			LicenseKeys:         f.LicenseKeys,
+			HasFile:             f.HasFile,
+			DownloadLimit:       int32(f.DownloadLimit),
+			DownloadExpiryHours: int32(f.DownloadExpiryHours),
+			MissingKeys:         int32(f.MissingKeys),
+		})
+	}
+	return resp, nil
+}
+
+// GetDigitalDownloadURL chỉ cấp link, việc kiểm tra quyền và lượt tải do order-service làm
+func (s *ProductServer) GetDigitalDownloadURL(ctx context.Context, req *pb.DigitalDownloadRequest) (*pb.DigitalDownloadResponse, error) {
+	url, fileName, expiresAt, err := s.digital.GetDownloadURL(ctx, req.ProductId)
+	if err != nil {
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			return nil, status.Error(codes.FailedPrecondition, validationErr.Error())
+		}
+		return nil, status.Errorf(codes.NotFound, "Failed to get download url: %v", err)
+	}
+	return &pb.DigitalDownloadResponse{
+		Url:       url,
+		FileName:  fileName,
+		ExpiresAt: expiresAt.Unix(),
+	}, nil
+}
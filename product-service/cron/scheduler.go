@@ -0,0 +1,73 @@
+package cron
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"product-service/service"
+
+	"github.com/robfig/cron/v3"
+)
+
+type Scheduler struct {
+	cron      *cron.Cron
+	pricing   service.PricingService
+	inventory service.InventoryService
+}
+
+func NewScheduler(pricing service.PricingService, inventory service.InventoryService) *Scheduler {
+	c := cron.New(cron.WithSeconds())
+	return &Scheduler{
+		cron:      c,
+		pricing:   pricing,
+		inventory: inventory,
+	}
+}
+
+func (s *Scheduler) Start() {
+	// Mặc định mỗi phút kiểm tra giá sale đến giờ bật/tắt
+	spec := os.Getenv("PRICE_SCHEDULE_CRON")
+	if spec == "" {
+		spec = "0 * * * * *"
+	}
+
+	_, err := s.cron.AddFunc(spec, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		if err := s.pricing.RunDueSchedules(ctx, time.Now()); err != nil {
+			log.Printf("Price schedule job failed: %v", err)
+		}
+	})
+	if err != nil {
+		log.Printf("Invalid PRICE_SCHEDULE_CRON %q: %v", spec, err)
+	}
+
+	// Digest tồn kho gửi seller lúc 8h sáng
+	digestSpec := os.Getenv("INVENTORY_DIGEST_CRON")
+	if digestSpec == "" {
+		digestSpec = "0 0 8 * * *"
+	}
+
+	_, err = s.cron.AddFunc(digestSpec, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+
+		if err := s.inventory.SendDailyDigests(ctx); err != nil {
+			log.Printf("Inventory digest job failed: %v", err)
+		}
+	})
+	if err != nil {
+		log.Printf("Invalid INVENTORY_DIGEST_CRON %q: %v", digestSpec, err)
+	}
+
+	s.cron.Start()
+	log.Printf("Product scheduler started - price schedules: %s, inventory digest: %s", spec, digestSpec)
+}
+
+func (s *Scheduler) Stop() {
+	s.cron.Stop()
+	log.Println("Product scheduler stopped")
+}
@@ -37,6 +37,8 @@ func InitRedis() {
 		WriteTimeout: 30 * time.Second,
 		PoolSize:     10, // Số lượng kết nối trong pool
 		PoolTimeout:  30 * time.Second,
+		// cache đặt timeout ngắn theo context để fallback sang LRU nhanh khi Redis chậm/down
+		ContextTimeoutEnabled: true,
 	})
 
 	// Tạo context với timeout dài hơn
@@ -45,7 +45,7 @@ func (h *RatingUpdateHandler) HandleRatingUpdate(ctx context.Context, msg kafka.
 	log.Printf("Received rating update: product=%s count=%d sum=%.2f",
 		msg.ProductID, msg.NewReviewsCount, msg.NewReviewsSum)
 
-	if err := h.updateProductRating(ctx, msg.ProductID, msg.NewReviewsSum, msg.NewReviewsCount, time.Now().Format(time.RFC3339)); err != nil {
+	if err := h.updateProductRating(ctx, msg.ProductID, msg.NewReviewsSum, msg.NewReviewsCount, time.Now().Format(time.RFC3339Nano)); err != nil {
 		log.Printf("Error applying rating update for product %s: %v", msg.ProductID, err)
 		return err
 	}
@@ -0,0 +1,52 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"time"
+
+	"product-service/cache"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// ConsumeProductEventsForCache đọc lại product-events của chính service để xoá cache.
+// Mỗi instance dùng group riêng (theo hostname) để instance nào cũng nhận đủ event,
+// cần thiết khi đang chạy bằng LRU trong process. Chỉ đọc event mới, không replay topic.
+func ConsumeProductEventsForCache(brokers []string, c *cache.Cache) {
+	hostname, _ := os.Hostname()
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     brokers,
+		Topic:       ProductEventTopic,
+		GroupID:     "product-service-cache-" + hostname,
+		StartOffset: kafka.LastOffset,
+	})
+
+	go func() {
+		defer reader.Close()
+		for {
+			message, err := reader.ReadMessage(context.Background())
+			if err != nil {
+				log.Printf("Error reading product event for cache: %v", err)
+				continue
+			}
+
+			var event ProductEvent
+			if err := json.Unmarshal(message.Value, &event); err != nil {
+				log.Printf("Error unmarshalling product event: %v", err)
+				continue
+			}
+			if event.ID == "" || event.Type == "INITIAL_SYNC" {
+				continue
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			c.InvalidateProduct(ctx, event.ID, event.Type == "created" || event.Type == "deleted")
+			cancel()
+		}
+	}()
+
+	log.Printf("Product cache invalidation consumer initialized with brokers: %v", brokers)
+}
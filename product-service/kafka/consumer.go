@@ -3,7 +3,9 @@ package kafka
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
+	"time"
 
 	"product-service/models"
 
@@ -13,6 +15,8 @@ import (
 const (
 	OrderSuccessTopic  = "order_success"
 	OrderReturnedTopic = "order_returned"
+
+	maxRetryBackoff = 30 * time.Second
 )
 
 type OrderSuccessEvent struct {
@@ -35,7 +39,8 @@ type OrderReturnedEvent struct {
 	TotalPrice float64         `json:"total_price"`
 }
 
-func ConsumeOrderSuccess(brokers []string, updater models.ProductStockUpdater) {
+// bundles có thể nil, khi đó mọi item được coi là sản phẩm thường
+func ConsumeOrderSuccess(brokers []string, updater models.ProductStockUpdater, bundles models.BundleStockResolver) {
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:  brokers,
 		Topic:    OrderSuccessTopic,
@@ -46,7 +51,8 @@ func ConsumeOrderSuccess(brokers []string, updater models.ProductStockUpdater) {
 
 	go func() {
 		for {
-			message, err := reader.ReadMessage(context.Background())
+			// commit thủ công sau khi xử lý xong, lỗi giữa chừng thì event được đọc lại
+			message, err := reader.FetchMessage(context.Background())
 			if err != nil {
 				log.Printf("Error reading message: %v", err)
 				continue
@@ -55,6 +61,7 @@ func ConsumeOrderSuccess(brokers []string, updater models.ProductStockUpdater) {
 			var event OrderSuccessEvent
 			if err := json.Unmarshal(message.Value, &event); err != nil {
 				log.Printf("Error unmarshalling message: %v", err)
+				reader.CommitMessages(context.Background(), message)
 				continue
 			}
 
@@ -68,15 +75,33 @@ func ConsumeOrderSuccess(brokers []string, updater models.ProductStockUpdater) {
 				}
 			}
 
-			// Decrease stock (trừ số lượng tồn kho)
-			for _, item := range stockItems {
+			// Bundle không có tồn kho riêng, trừ vào từng sản phẩm thành phần.
+			// Lỗi DynamoDB thì thử lại chứ không bỏ qua, bỏ qua là mất luôn lượt trừ kho của cả đơn.
+			componentItems := stockItems
+			if bundles != nil {
+				componentItems = expandOrderItemsWithRetry(bundles, event.OrderID, stockItems)
+			}
+
+			// Decrease stock (trừ số lượng tồn kho).
+			// Lỗi thì thử lại tới khi được rồi mới commit offset; thành phần bundle nào trừ xong được ghi
+			// vào allocation ngay nên event đọc lại sau khi restart không trừ lặp thành phần đó
+			for _, item := range componentItems {
 				log.Printf("⬇️ Decreasing stock for product %s by %d", item.ProductID, item.Quantity)
-				if err := updater.UpdateProductStock(context.Background(), item.ProductID, item.Quantity); err != nil {
-					log.Printf("❌ Error updating product stock: %v", err)
-				} else {
-					log.Printf("✅ Stock decreased for product %s", item.ProductID)
+				retryWithBackoff(fmt.Sprintf("decreasing stock of %s for order %s", item.ProductID, event.OrderID), func() error {
+					return updater.UpdateProductStock(context.Background(), item.ProductID, item.Quantity)
+				})
+				log.Printf("✅ Stock decreased for product %s", item.ProductID)
+				if bundles != nil && item.BundleID != "" {
+					retryWithBackoff(fmt.Sprintf("recording bundle %s progress of order %s", item.BundleID, event.OrderID), func() error {
+						return bundles.MarkComponentApplied(context.Background(), event.OrderID, item)
+					})
 				}
 			}
+			if bundles != nil {
+				retryWithBackoff(fmt.Sprintf("marking bundle allocations of order %s", event.OrderID), func() error {
+					return bundles.MarkOrderAllocated(context.Background(), event.OrderID)
+				})
+			}
 
 			// Increase sold count (cộng số lượng đã bán)
 			for _, item := range stockItems {
@@ -88,6 +113,9 @@ func ConsumeOrderSuccess(brokers []string, updater models.ProductStockUpdater) {
 				}
 			}
 
+			if err := reader.CommitMessages(context.Background(), message); err != nil {
+				log.Printf("Error committing order_success offset: %v", err)
+			}
 			log.Printf("✅ Finished processing order_success: OrderID=%s", event.OrderID)
 		}
 	}()
@@ -95,7 +123,32 @@ func ConsumeOrderSuccess(brokers []string, updater models.ProductStockUpdater) {
 	log.Printf("Kafka consumer started for topic: %s", OrderSuccessTopic)
 }
 
-func ConsumerOrderReturned(brokers []string, updater models.ProductStockUpdater) {
+// expandOrderItemsWithRetry chặn partition cho tới khi expand được, lỗi thường chỉ là DynamoDB tạm thời
+func expandOrderItemsWithRetry(bundles models.BundleStockResolver, orderID string, items []models.StockUpdateItem) []models.StockUpdateItem {
+	var expanded []models.StockUpdateItem
+	retryWithBackoff(fmt.Sprintf("expanding bundle items for order %s", orderID), func() error {
+		var err error
+		expanded, err = bundles.ExpandOrderItems(context.Background(), orderID, items)
+		return err
+	})
+	return expanded
+}
+
+// retryWithBackoff thử lại fn tới khi thành công, dùng cho các bước không được bỏ qua của order_success
+func retryWithBackoff(what string, fn func() error) {
+	backoff := time.Second
+	for {
+		err := fn()
+		if err == nil {
+			return
+		}
+		log.Printf("❌ Error %s, retrying in %s: %v", what, backoff, err)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+func ConsumerOrderReturned(brokers []string, updater models.ProductStockUpdater, bundles models.BundleStockResolver, licenses models.LicenseKeyRevoker) {
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:  brokers,
 		Topic:    OrderReturnedTopic,
@@ -124,9 +177,45 @@ func ConsumerOrderReturned(brokers []string, updater models.ProductStockUpdater)
 					Quantity:  item.Quantity,
 				}
 			}
-			for _, item := range stockItems {
+			componentItems := stockItems
+			if bundles != nil {
+				expanded, err := bundles.ExpandReturnedItems(context.Background(), event.OrderID, stockItems)
+				if err != nil {
+					log.Printf("Error expanding bundle items for returned order %s: %v", event.OrderID, err)
+					componentItems = nil
+				} else {
+					componentItems = expanded
+				}
+			}
 
-				if err := updater.UpdateProductStock(context.Background(), item.ProductID, item.Quantity); err != nil {
+			// License key đã giao không bán lại, phần đó không được cộng lại kho
+			if licenses != nil {
+				revoked, err := licenses.RevokeOrderKeys(context.Background(), event.OrderID)
+				if err != nil {
+					log.Printf("Error revoking license keys for returned order %s: %v", event.OrderID, err)
+				}
+				if len(revoked) > 0 {
+					// copy vì componentItems có thể dùng chung mảng với stockItems (sold count vẫn trừ đủ)
+					restock := make([]models.StockUpdateItem, 0, len(componentItems))
+					for _, item := range componentItems {
+						n := revoked[item.ProductID]
+						if n > item.Quantity {
+							n = item.Quantity
+						}
+						revoked[item.ProductID] -= n
+						item.Quantity -= n
+						restock = append(restock, item)
+					}
+					componentItems = restock
+				}
+			}
+
+			// Hoàn hàng: cộng lại tồn kho (UpdateProductStock trừ quantity nên truyền số âm)
+			for _, item := range componentItems {
+				if item.Quantity <= 0 {
+					continue
+				}
+				if err := updater.UpdateProductStock(context.Background(), item.ProductID, -item.Quantity); err != nil {
 					log.Printf("Error updating product stock: %v", err)
 				}
 			}
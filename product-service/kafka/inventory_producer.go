@@ -0,0 +1,59 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	logger "product-service/log"
+	"product-service/models"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	InventoryAlertTopic = "inventory_alerts"
+)
+
+var (
+	inventoryAlertWriter *kafka.Writer
+)
+
+func InitInventoryAlertProducer(brokers []string) {
+	inventoryAlertWriter = &kafka.Writer{
+		Addr:     kafka.TCP(brokers...),
+		Topic:    InventoryAlertTopic,
+		Balancer: &kafka.LeastBytes{},
+	}
+}
+
+func ProduceInventoryEvent(ctx context.Context, event models.InventoryEvent) error {
+	if inventoryAlertWriter == nil {
+		logger.Err("Inventory alert writer is not initialized", nil)
+		return fmt.Errorf("inventory alert writer is not initialized")
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logger.Err("Failed to marshal inventory event", err)
+		return err
+	}
+
+	// key theo seller để các event của một seller giữ đúng thứ tự
+	message := kafka.Message{
+		Key:   []byte(event.SellerID),
+		Value: payload,
+	}
+
+	if err := inventoryAlertWriter.WriteMessages(ctx, message); err != nil {
+		logger.Err("Failed to write inventory event message", err)
+		return err
+	}
+	return nil
+}
+
+func CloseInventoryAlertProducer() {
+	if inventoryAlertWriter != nil {
+		inventoryAlertWriter.Close()
+	}
+}
@@ -30,7 +30,8 @@ func InitProductEventProducer(brokers []string) {
 	productEventWriter = &kafka.Writer{
 		Addr:     kafka.TCP(brokers...),
 		Topic:    ProductEventTopic,
-		Balancer: &kafka.LeastBytes{},
+		// cùng product id vào cùng partition để consumer nhận đúng thứ tự
+		Balancer: &kafka.Hash{},
 	}
 }
 
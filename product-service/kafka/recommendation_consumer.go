@@ -0,0 +1,66 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"product-service/models"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Group riêng với group "product-service" trừ tồn kho, để 2 luồng commit offset độc lập
+const recommendationGroupID = "product-service-recommendation"
+
+func toOrderLine(event OrderSuccessEvent, at time.Time) models.OrderLine {
+	order := models.OrderLine{
+		OrderID:    event.OrderID,
+		UserID:     event.UserID,
+		ProductIDs: make([]string, 0, len(event.Items)),
+		Quantities: make(map[string]int, len(event.Items)),
+		OrderedAt:  at,
+	}
+	for _, item := range event.Items {
+		order.ProductIDs = append(order.ProductIDs, item.ProductID)
+		order.Quantities[item.ProductID] += item.Quantity
+	}
+	return order
+}
+
+// ConsumeOrderSuccessForRecommendations cập nhật co-purchase / affinity theo từng đơn thành công
+func ConsumeOrderSuccessForRecommendations(brokers []string, recorder models.OrderRecorder) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:  brokers,
+		Topic:    OrderSuccessTopic,
+		GroupID:  recommendationGroupID,
+		MinBytes: 10e3, // 10KB
+		MaxBytes: 10e6, // 10MB
+	})
+
+	go func() {
+		defer reader.Close()
+		for {
+			message, err := reader.ReadMessage(context.Background())
+			if err != nil {
+				log.Printf("Error reading order_success for recommendations: %v", err)
+				continue
+			}
+
+			var event OrderSuccessEvent
+			if err := json.Unmarshal(message.Value, &event); err != nil {
+				log.Printf("Error unmarshalling order_success: %v", err)
+				continue
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if err := recorder.RecordOrder(ctx, toOrderLine(event, message.Time)); err != nil {
+				log.Printf("Error recording order %s for recommendations: %v", event.OrderID, err)
+			}
+			cancel()
+		}
+	}()
+
+	log.Printf("Kafka recommendation consumer started for topic: %s", OrderSuccessTopic)
+}
@@ -9,7 +9,9 @@ import (
 	"strings"
 	"syscall"
 
+	"product-service/cache"
 	controllers "product-service/controller"
+	"product-service/cron"
 	"product-service/database"
 	"product-service/handler"
 	"product-service/kafka"
@@ -28,6 +30,7 @@ import (
 	"github.com/joho/godotenv"
 	"google.golang.org/grpc"
 
+	cartpb "module/gRPC-cart/service"
 	pb "module/gRPC-Product/service"
 )
 
@@ -70,6 +73,7 @@ func main() {
 	database.InitRedis()
 	defer database.RedisClient.Close()
 	log.Printf("Connected to Redis")
+	cache.Init(database.RedisClient)
 
 	// Tạo ProductService chung cho cả gRPC và HTTP
 	tableName := os.Getenv("DYNAMODB_TABLE")
@@ -78,8 +82,81 @@ func main() {
 	}
 	log.Printf("Using DynamoDB table: %s", tableName)
 
+	categoryTableName := os.Getenv("DYNAMODB_CATEGORY_TABLE")
+	if categoryTableName == "" {
+		categoryTableName = "category-table"
+	}
+	log.Printf("Using DynamoDB category table: %s", categoryTableName)
+
+	priceScheduleTableName := os.Getenv("DYNAMODB_PRICE_SCHEDULE_TABLE")
+	if priceScheduleTableName == "" {
+		priceScheduleTableName = "price-schedule-table"
+	}
+	priceHistoryTableName := os.Getenv("DYNAMODB_PRICE_HISTORY_TABLE")
+	if priceHistoryTableName == "" {
+		priceHistoryTableName = "price-history-table"
+	}
+
 	repo := repository.NewProductRepository(dynamoClient, tableName)
-	productSvc := service.NewProductService(repo, service.NewS3Service())
+	categorySvc := service.NewCategoryService(repository.NewCategoryRepository(dynamoClient, categoryTableName), repo)
+	pricingSvc := service.NewPricingService(repo,
+		repository.NewPriceScheduleRepository(dynamoClient, priceScheduleTableName),
+		repository.NewPriceHistoryRepository(dynamoClient, priceHistoryTableName),
+	)
+	inventorySvc := service.NewInventoryService(repo)
+	revisionTableName := os.Getenv("DYNAMODB_PRODUCT_REVISION_TABLE")
+	if revisionTableName == "" {
+		revisionTableName = "product-revision-table"
+	}
+	revisionRepo := repository.NewRevisionRepository(dynamoClient, revisionTableName)
+	s3Svc := service.NewS3Service()
+	productSvc := service.NewProductService(repo, categorySvc, pricingSvc, inventorySvc, s3Svc, revisionRepo)
+
+	bundleAllocationTableName := os.Getenv("DYNAMODB_BUNDLE_ALLOCATION_TABLE")
+	if bundleAllocationTableName == "" {
+		bundleAllocationTableName = "bundle-allocation-table"
+	}
+	bundleSvc := service.NewBundleService(repo, repository.NewBundleAllocationRepository(dynamoClient, bundleAllocationTableName), productSvc)
+
+	licenseKeyTableName := os.Getenv("DYNAMODB_LICENSE_KEY_TABLE")
+	if licenseKeyTableName == "" {
+		licenseKeyTableName = "license-key-table"
+	}
+	digitalSvc := service.NewDigitalService(repo, repository.NewLicenseKeyRepository(dynamoClient, licenseKeyTableName), productSvc, s3Svc)
+
+	draftTableName := os.Getenv("DYNAMODB_PRODUCT_DRAFT_TABLE")
+	if draftTableName == "" {
+		draftTableName = "product-draft-table"
+	}
+	draftSvc := service.NewDraftService(repository.NewDraftRepository(dynamoClient, draftTableName), productSvc)
+	revisionSvc := service.NewRevisionService(repo, revisionRepo, productSvc)
+
+	coPurchaseTableName := os.Getenv("DYNAMODB_COPURCHASE_TABLE")
+	if coPurchaseTableName == "" {
+		coPurchaseTableName = "co-purchase-table"
+	}
+	affinityTableName := os.Getenv("DYNAMODB_USER_AFFINITY_TABLE")
+	if affinityTableName == "" {
+		affinityTableName = "user-affinity-table"
+	}
+
+	// Giỏ hàng chỉ dùng làm tín hiệu cho gợi ý, không kết nối được thì vẫn chạy bình thường
+	cartAddress := os.Getenv("CART_SERVICE_ADDRESS")
+	if cartAddress == "" {
+		cartAddress = "cart-service:8090"
+	}
+	var cartClient cartpb.CartServiceClient
+	cartConn, err := grpc.Dial(cartAddress, grpc.WithInsecure())
+	if err != nil {
+		log.Printf("Warning: could not connect to cart-service: %v", err)
+	} else {
+		defer cartConn.Close()
+		cartClient = cartpb.NewCartServiceClient(cartConn)
+	}
+	recommendationSvc := service.NewRecommendationService(
+		repository.NewRecommendationRepository(dynamoClient, coPurchaseTableName, affinityTableName),
+		repo, productSvc, cartClient,
+	)
 
 	grpcReady := make(chan bool)
 
@@ -94,7 +171,7 @@ func main() {
 		}
 
 		// Sử dụng productSvc chung
-		productServer := controllers.NewProductServer(productSvc)
+		productServer := controllers.NewProductServer(productSvc, pricingSvc, digitalSvc)
 		s := grpc.NewServer()
 
 		pb.RegisterProductServiceServer(s, productServer)
@@ -119,8 +196,16 @@ func main() {
 		brokers = []string{"kafka:9092"}
 	}
 	kafka.InitProductEventProducer(brokers)
-	go kafka.ConsumeOrderSuccess(brokers, productSvc)
-	go kafka.ConsumerOrderReturned(brokers, productSvc)
+	kafka.InitInventoryAlertProducer(brokers)
+	kafka.ConsumeProductEventsForCache(brokers, cache.Default())
+	go kafka.ConsumeOrderSuccess(brokers, productSvc, bundleSvc)
+	go kafka.ConsumerOrderReturned(brokers, productSvc, bundleSvc, digitalSvc)
+	kafka.ConsumeOrderSuccessForRecommendations(brokers, recommendationSvc)
+
+	// Cron: bật/tắt giá sale theo lịch, gửi digest tồn kho hằng ngày
+	scheduler := cron.NewScheduler(pricingSvc, inventorySvc)
+	scheduler.Start()
+	defer scheduler.Stop()
 
 	// Send initial product events for search-service indexing
 	go sendInitialProductEvents(productSvc)
@@ -169,6 +254,13 @@ func main() {
 
 	// Pass productSvc to routes
 	routes.ProductManagerRoutes(router, productSvc)
+	routes.CategoryRoutes(router, categorySvc)
+	routes.PricingRoutes(router, pricingSvc)
+	routes.RecommendationRoutes(router, recommendationSvc)
+	routes.BundleRoutes(router, bundleSvc)
+	routes.DigitalRoutes(router, digitalSvc)
+	routes.DraftRoutes(router, draftSvc)
+	routes.RevisionRoutes(router, revisionSvc)
 	routes.UploadRoutes(router)
 	routes.ProductUploadRoutes(router)
 
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	ProductTypeSimple = "simple"
+	ProductTypeBundle = "bundle"
+
+	MinBundleComponents = 2
+	MaxBundleComponents = 10
+)
+
+// BundleComponent: một sản phẩm thành phần của bundle và số lượng trong mỗi bundle
+type BundleComponent struct {
+	ProductID string `json:"product_id" dynamodbav:"product_id" binding:"required"`
+	Quantity  int    `json:"quantity" dynamodbav:"quantity" binding:"required,min=1,max=100"`
+}
+
+// CreateBundleRequest không có quantity, tồn kho của bundle tính từ các thành phần
+type CreateBundleRequest struct {
+	Name        string                 `json:"name" binding:"required,min=2,max=100"`
+	ImagePath   []string               `json:"image_path,omitempty"`
+	Category    string                 `json:"category" binding:"required"`
+	Attributes  map[string]interface{} `json:"attributes,omitempty"`
+	Description string                 `json:"description" binding:"required,min=2,max=500"`
+	Price       float64                `json:"price" binding:"required,gt=0"`
+	Status      string                 `json:"status" binding:"required,oneof=onsale offsale unavailable"`
+	Items       []BundleComponent      `json:"items" binding:"required,dive"`
+}
+
+// BundleAllocation lưu thành phần của bundle tại thời điểm đặt hàng,
+// hoàn/huỷ đơn sẽ cộng lại đúng các thành phần này kể cả khi bundle đã bị sửa hoặc xoá
+type BundleAllocation struct {
+	OrderID    string            `json:"order_id" dynamodbav:"order_id"`
+	BundleID   string            `json:"bundle_id" dynamodbav:"bundle_id"`
+	Quantity   int               `json:"quantity" dynamodbav:"quantity"`
+	Components []BundleComponent `json:"components" dynamodbav:"components"`
+	Restocked  bool              `json:"restocked" dynamodbav:"restocked"`
+	// Pending: đã lưu nhưng thành phần chưa trừ kho xong, event gửi lại thì trừ tiếp.
+	// Allocation cũ không có attribute này coi như đã trừ.
+	Pending bool `json:"pending,omitempty" dynamodbav:"pending,omitempty"`
+	// Applied: các thành phần đã trừ kho khi còn Pending, lần trừ tiếp bỏ qua và hoàn hàng chỉ cộng lại các thành phần này
+	Applied   []string  `json:"applied,omitempty" dynamodbav:"applied,stringset,omitempty"`
+	CreatedAt time.Time `json:"created_at" dynamodbav:"created_at"`
+}
+
+// BundleStockResolver đổi item bundle của đơn hàng thành các item thành phần để trừ / cộng tồn kho
+type BundleStockResolver interface {
+	ExpandOrderItems(ctx context.Context, orderID string, items []StockUpdateItem) ([]StockUpdateItem, error)
+	// MarkComponentApplied gọi ngay sau khi trừ kho từng thành phần của bundle
+	MarkComponentApplied(ctx context.Context, orderID string, item StockUpdateItem) error
+	// MarkOrderAllocated gọi sau khi đã trừ kho các thành phần trả về từ ExpandOrderItems
+	MarkOrderAllocated(ctx context.Context, orderID string) error
+	ExpandReturnedItems(ctx context.Context, orderID string, items []StockUpdateItem) ([]StockUpdateItem, error)
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// Các kiểu dữ liệu hợp lệ cho attribute của category
+const (
+	AttributeTypeString  = "string"
+	AttributeTypeNumber  = "number"
+	AttributeTypeBoolean = "boolean"
+	AttributeTypeEnum    = "enum"
+)
+
+type AttributeDefinition struct {
+	Key        string   `json:"key" dynamodbav:"key" binding:"required"`
+	Label      string   `json:"label" dynamodbav:"label"`
+	Type       string   `json:"type" dynamodbav:"type" binding:"required,oneof=string number boolean enum"`
+	Required   bool     `json:"required" dynamodbav:"required"`
+	Options    []string `json:"options,omitempty" dynamodbav:"options,omitempty"`
+	Unit       string   `json:"unit,omitempty" dynamodbav:"unit,omitempty"`
+	Filterable bool     `json:"filterable" dynamodbav:"filterable"`
+}
+
+type Category struct {
+	ID          string                `json:"id" dynamodbav:"id"`
+	Name        string                `json:"name" dynamodbav:"name"`
+	Slug        string                `json:"slug" dynamodbav:"slug"`
+	Description string                `json:"description" dynamodbav:"description"`
+	ParentID    string                `json:"parent_id" dynamodbav:"parent_id"`
+	Path        []string              `json:"path" dynamodbav:"path"` // ID của các category cha, từ root xuống
+	Level       int                   `json:"level" dynamodbav:"level"`
+	Attributes  []AttributeDefinition `json:"attributes" dynamodbav:"attributes"`
+	Created_at  time.Time             `json:"created_at" dynamodbav:"created_at"`
+	Updated_at  time.Time             `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+type CategoryNode struct {
+	Category
+	Children []*CategoryNode `json:"children"`
+}
+
+type Breadcrumb struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type CreateCategoryRequest struct {
+	Name        string                `json:"name" binding:"required,min=2,max=100"`
+	Slug        string                `json:"slug,omitempty"`
+	Description string                `json:"description,omitempty" binding:"omitempty,max=500"`
+	ParentID    string                `json:"parent_id,omitempty"`
+	Attributes  []AttributeDefinition `json:"attributes,omitempty" binding:"omitempty,dive"`
+}
+
+type UpdateCategoryRequest struct {
+	Name        *string                `json:"name,omitempty" binding:"omitempty,min=2,max=100"`
+	Slug        *string                `json:"slug,omitempty"`
+	Description *string                `json:"description,omitempty" binding:"omitempty,max=500"`
+	ParentID    *string                `json:"parent_id,omitempty"`
+	Attributes  *[]AttributeDefinition `json:"attributes,omitempty" binding:"omitempty,dive"`
+}
+
+// Kết quả chuyển đổi category dạng free-text cũ sang cây category
+type CategoryMigrationReport struct {
+	DryRun            bool              `json:"dry_run"`
+	Scanned           int               `json:"scanned"`
+	AlreadyMigrated   int               `json:"already_migrated"`
+	Migrated          int               `json:"migrated"`
+	CreatedCategories []string          `json:"created_categories"`
+	Unmatched         map[string]int    `json:"unmatched"`
+	Mapping           map[string]string `json:"mapping"`
+	Errors            []string          `json:"errors"`
+}
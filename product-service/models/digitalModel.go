@@ -0,0 +1,70 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	ProductTypeDigital = "digital"
+
+	DigitalStockUnlimited   = "unlimited"
+	DigitalStockLicenseKeys = "license_keys"
+
+	// Sản phẩm số không giới hạn lưu quantity cố định để các chỗ kiểm tra quantity > 0 vẫn đúng,
+	// UpdateStock bỏ qua các sản phẩm này nên con số không bao giờ bị trừ
+	UnlimitedStockQuantity = 999999
+
+	DefaultDownloadLimit       = 5
+	DefaultDownloadExpiryHours = 72
+
+	LicenseKeyAvailable = "available"
+	LicenseKeyAssigned  = "assigned"
+	LicenseKeyRevoked   = "revoked"
+)
+
+type CreateDigitalProductRequest struct {
+	Name                string                 `json:"name" binding:"required,min=2,max=100"`
+	ImagePath           []string               `json:"image_path,omitempty"`
+	Category            string                 `json:"category" binding:"required"`
+	Attributes          map[string]interface{} `json:"attributes,omitempty"`
+	Description         string                 `json:"description" binding:"required,min=2,max=500"`
+	Price               float64                `json:"price" binding:"required,gt=0"`
+	Status              string                 `json:"status" binding:"required,oneof=onsale offsale unavailable"`
+	StockMode           string                 `json:"stock_mode" binding:"required,oneof=unlimited license_keys"`
+	FileKey             string                 `json:"file_key,omitempty"` // s3_key trả về từ /products/digital/upload-url
+	FileName            string                 `json:"file_name,omitempty"`
+	DownloadLimit       int                    `json:"download_limit,omitempty" binding:"omitempty,min=1,max=100"`
+	DownloadExpiryHours int                    `json:"download_expiry_hours,omitempty" binding:"omitempty,min=1,max=720"`
+}
+
+type AddLicenseKeysRequest struct {
+	Keys []string `json:"keys" binding:"required,min=1,max=500,dive,min=4,max=256"`
+}
+
+type LicenseKey struct {
+	ProductID  string     `json:"product_id" dynamodbav:"product_id"`
+	KeyID      string     `json:"key_id" dynamodbav:"key_id"`
+	Key        string     `json:"key" dynamodbav:"license_key"`
+	Status     string     `json:"status" dynamodbav:"status"`
+	OrderID    string     `json:"order_id,omitempty" dynamodbav:"order_id,omitempty"`
+	UserID     string     `json:"user_id,omitempty" dynamodbav:"user_id,omitempty"`
+	AssignedAt *time.Time `json:"assigned_at,omitempty" dynamodbav:"assigned_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" dynamodbav:"created_at"`
+}
+
+// DigitalFulfillment là phần giao hàng của một sản phẩm số trong đơn, order-service lưu lại vào đơn
+type DigitalFulfillment struct {
+	ProductID           string   `json:"product_id"`
+	LicenseKeys         []string `json:"license_keys,omitempty"`
+	HasFile             bool     `json:"has_file"`
+	DownloadLimit       int      `json:"download_limit"`
+	DownloadExpiryHours int      `json:"download_expiry_hours"`
+	MissingKeys         int      `json:"missing_keys,omitempty"`
+}
+
+// LicenseKeyRevoker thu hồi license key của đơn bị huỷ / hoàn. Key đã giao cho buyer thì không bán lại,
+// nên trả về số key đã thu hồi theo product để consumer không cộng lại kho cho phần đó
+type LicenseKeyRevoker interface {
+	RevokeOrderKeys(ctx context.Context, orderID string) (map[string]int, error)
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+const MaxDraftsPerSeller = 50
+
+// ProductDraft là sản phẩm seller đang soạn dở, nằm ở bảng riêng nên không bao giờ xuất hiện với buyer
+// (không vào danh sách, cache hay product-events) cho tới khi publish
+type ProductDraft struct {
+	SellerID          string                 `json:"seller_id" dynamodbav:"seller_id"`
+	DraftID           string                 `json:"draft_id" dynamodbav:"draft_id"`
+	Name              string                 `json:"name" dynamodbav:"name"`
+	ImagePath         []string               `json:"image_path" dynamodbav:"image_path"`
+	Category          string                 `json:"category" dynamodbav:"category"`
+	Attributes        map[string]interface{} `json:"attributes,omitempty" dynamodbav:"attributes,omitempty"`
+	Description       string                 `json:"description" dynamodbav:"description"`
+	Quantity          int                    `json:"quantity" dynamodbav:"quantity"`
+	Price             float64                `json:"price" dynamodbav:"price"`
+	Status            string                 `json:"status" dynamodbav:"status"` // status sau khi publish
+	LowStockThreshold *int                   `json:"low_stock_threshold,omitempty" dynamodbav:"low_stock_threshold,omitempty"`
+	CreatedAt         time.Time              `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+// SaveDraftRequest: mọi field đều không bắt buộc, chỉ kiểm tra đủ khi publish
+type SaveDraftRequest struct {
+	Name              string                 `json:"name" binding:"omitempty,max=100"`
+	ImagePath         []string               `json:"image_path,omitempty"`
+	Category          string                 `json:"category,omitempty"`
+	Attributes        map[string]interface{} `json:"attributes,omitempty"`
+	Description       string                 `json:"description" binding:"omitempty,max=500"`
+	Quantity          int                    `json:"quantity" binding:"omitempty,min=0"`
+	Price             float64                `json:"price" binding:"omitempty,gte=0"`
+	Status            string                 `json:"status" binding:"omitempty,oneof=onsale offsale unavailable"`
+	LowStockThreshold *int                   `json:"low_stock_threshold,omitempty" binding:"omitempty,min=0"`
+}
@@ -0,0 +1,30 @@
+package models
+
+// Các loại event gửi lên topic inventory_alerts
+const (
+	InventoryLowStock    = "low_stock"
+	InventoryOutOfStock  = "out_of_stock"
+	InventoryBackInStock = "back_in_stock"
+	InventoryDailyDigest = "daily_digest"
+)
+
+type InventoryEvent struct {
+	Type        string                `json:"type"`
+	SellerID    string                `json:"seller_id"`
+	ProductID   string                `json:"product_id,omitempty"`
+	ProductName string                `json:"product_name,omitempty"`
+	Quantity    int                   `json:"quantity"`
+	Threshold   int                   `json:"threshold"`
+	Status      string                `json:"status,omitempty"`
+	Items       []InventoryDigestItem `json:"items,omitempty"`
+	Timestamp   int64                 `json:"timestamp"`
+}
+
+type InventoryDigestItem struct {
+	ProductID string `json:"product_id"`
+	Name      string `json:"name"`
+	Quantity  int    `json:"quantity"`
+	Threshold int    `json:"threshold"`
+	Status    string `json:"status"`
+	SoldCount int    `json:"sold_count"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+const (
+	PriceScheduleScheduled = "scheduled"
+	PriceScheduleActive    = "active"
+	PriceScheduleCompleted = "completed"
+	PriceScheduleCancelled = "cancelled"
+)
+
+// Lý do thay đổi giá, lưu trong price history
+const (
+	PriceChangeInitial   = "initial"
+	PriceChangeManual    = "manual"
+	PriceChangeSaleStart = "sale_start"
+	PriceChangeSaleEnd   = "sale_end"
+)
+
+type PriceSchedule struct {
+	ID         string    `json:"id" dynamodbav:"id"`
+	ProductID  string    `json:"product_id" dynamodbav:"product_id"`
+	UserID     string    `json:"user_id" dynamodbav:"user_id"`
+	SalePrice  float64   `json:"sale_price" dynamodbav:"sale_price"`
+	StartAt    time.Time `json:"start_at" dynamodbav:"start_at"`
+	EndAt      time.Time `json:"end_at" dynamodbav:"end_at"`
+	Status     string    `json:"status" dynamodbav:"status"`
+	Created_at time.Time `json:"created_at" dynamodbav:"created_at"`
+	Updated_at time.Time `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+type CreatePriceScheduleRequest struct {
+	SalePrice float64   `json:"sale_price" binding:"required,gt=0"`
+	StartAt   time.Time `json:"start_at" binding:"required"`
+	EndAt     time.Time `json:"end_at" binding:"required"`
+}
+
+type PriceHistory struct {
+	ProductID  string    `json:"product_id" dynamodbav:"product_id"`
+	ChangedAt  time.Time `json:"changed_at" dynamodbav:"changed_at"`
+	Price      float64   `json:"price" dynamodbav:"price"`
+	OldPrice   float64   `json:"old_price" dynamodbav:"old_price"`
+	Reason     string    `json:"reason" dynamodbav:"reason"`
+	ScheduleID string    `json:"schedule_id,omitempty" dynamodbav:"schedule_id,omitempty"`
+	ChangedBy  string    `json:"changed_by,omitempty" dynamodbav:"changed_by,omitempty"`
+}
+
+type PriceHistorySummary struct {
+	ProductID        string         `json:"product_id"`
+	CurrentPrice     float64        `json:"current_price"`
+	RegularPrice     float64        `json:"regular_price"`
+	Days             int            `json:"days"`
+	LowestPrice      float64        `json:"lowest_price"`
+	HighestPrice     float64        `json:"highest_price"`
+	LowestPriorPrice float64        `json:"lowest_prior_price"` // thấp nhất trong kỳ, không tính giá đang áp dụng
+	History          []PriceHistory `json:"history"`
+}
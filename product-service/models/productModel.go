@@ -117,10 +117,15 @@ type Product struct {
     ID          string    `json:"id" dynamodbav:"id"`                         // Thay đổi từ ObjectID sang string
     Name        string    `json:"name" dynamodbav:"name"`
     ImagePath   []string    `json:"image_path" dynamodbav:"image_path"`
-    Category    string    `json:"category" dynamodbav:"category"`
+    Category    string    `json:"category" dynamodbav:"category"`             // slug của category (dữ liệu cũ có thể là free-text)
+    CategoryID  string    `json:"category_id" dynamodbav:"category_id"`
+    Attributes  map[string]interface{} `json:"attributes,omitempty" dynamodbav:"attributes,omitempty"`
     Description string    `json:"description" dynamodbav:"description"`
     Quantity    int       `json:"quantity" dynamodbav:"quantity"`
-    Price       float64   `json:"price" dynamodbav:"price"`
+    Price       float64   `json:"price" dynamodbav:"price"`                   // giá đang áp dụng (giá sale nếu đang có sale)
+    RegularPrice float64  `json:"regular_price,omitempty" dynamodbav:"regular_price,omitempty"` // giá gốc khi đang sale
+    SaleScheduleID string `json:"sale_schedule_id,omitempty" dynamodbav:"sale_schedule_id,omitempty"`
+    SaleEndAt   *time.Time `json:"sale_end_at,omitempty" dynamodbav:"sale_end_at,omitempty"`
     SoldCount   int       `json:"sold_count" dynamodbav:"sold_count"`
     Created_at  time.Time `json:"created_at" dynamodbav:"created_at"`
     Updated_at  time.Time `json:"updated_at" dynamodbav:"updated_at"`
@@ -128,17 +133,38 @@ type Product struct {
     Status      string    `json:"status" dynamodbav:"status"`
     Rating      float64   `json:"rating" dynamodbav:"rating"`
     RatingCount int       `json:"rating_count" dynamodbav:"rating_count"`
+    LowStockThreshold int `json:"low_stock_threshold" dynamodbav:"low_stock_threshold"`
+    AutoUnavailable bool  `json:"auto_unavailable,omitempty" dynamodbav:"auto_unavailable,omitempty"` // true khi hệ thống tự chuyển sang unavailable vì hết hàng
+    Type        string    `json:"type,omitempty" dynamodbav:"product_type,omitempty"` // rỗng = simple
+    BundleItems []BundleComponent `json:"bundle_items,omitempty" dynamodbav:"bundle_items,omitempty"`
+    // Sản phẩm số: file private trên S3, key không bao giờ trả ra ngoài
+    DigitalFileKey  string `json:"-" dynamodbav:"digital_file_key,omitempty"`
+    DigitalFileName string `json:"digital_file_name,omitempty" dynamodbav:"digital_file_name,omitempty"`
+    UnlimitedStock  bool   `json:"unlimited_stock,omitempty" dynamodbav:"unlimited_stock,omitempty"`
+    UsesLicenseKeys bool   `json:"uses_license_keys,omitempty" dynamodbav:"uses_license_keys,omitempty"`
+    DownloadLimit   int    `json:"download_limit,omitempty" dynamodbav:"download_limit,omitempty"`
+    DownloadExpiryHours int `json:"download_expiry_hours,omitempty" dynamodbav:"download_expiry_hours,omitempty"`
+}
+
+func (p *Product) IsDigital() bool {
+    return p.Type == ProductTypeDigital
+}
+
+func (p *Product) IsBundle() bool {
+    return p.Type == ProductTypeBundle
 }
 
 // CreateProductRequest - Request struct cho tạo product mới
 type CreateProductRequest struct {
     Name        string  `json:"name" binding:"required,min=2,max=100"`
     ImagePath   []string  `json:"image_path,omitempty"` // Optional - có thể empty hoặc có URL từ presigned upload
-    Category    string  `json:"category" binding:"required"`
+    Category    string  `json:"category" binding:"required"` // id, slug hoặc tên category
+    Attributes  map[string]interface{} `json:"attributes,omitempty"`
     Description string  `json:"description" binding:"required,min=2,max=500"`
     Quantity    int     `json:"quantity" binding:"required,min=1"`
     Price       float64 `json:"price" binding:"required,gt=0"`
     Status      string  `json:"status" binding:"required,oneof=onsale offsale unavailable"` 
+    LowStockThreshold *int `json:"low_stock_threshold,omitempty" binding:"omitempty,min=0"`
 }
 
 // CreateProductWithImageRequest - Request struct khi upload ảnh cùng lúc
@@ -156,10 +182,12 @@ type UpdateProductRequest struct {
     Name        *string  `json:"name,omitempty" binding:"omitempty,min=2,max=100"`
     ImagePath   *[]string  `json:"image_path,omitempty"` // Optional update
     Category    *string  `json:"category,omitempty"`
+    Attributes  *map[string]interface{} `json:"attributes,omitempty"`
     Description *string  `json:"description,omitempty" binding:"omitempty,min=2,max=500"`
     Quantity    *int     `json:"quantity,omitempty" binding:"omitempty,min=1"`
     Price       *float64 `json:"price,omitempty" binding:"omitempty,gt=0"`
     Status      *string  `json:"status,omitempty" binding:"omitempty,oneof=onsale offsale unavailable"` 
+    LowStockThreshold *int `json:"low_stock_threshold,omitempty" binding:"omitempty,min=0"`
 }
 
 // ProductResponse - Response struct cho API
@@ -169,6 +197,8 @@ type ProductResponse struct {
     Name        string    `json:"name"`
     ImagePath   []string  `json:"image_path"`
     Category    string    `json:"category"`
+    CategoryID  string    `json:"category_id"`
+    Attributes  map[string]interface{} `json:"attributes,omitempty"`
     Description string    `json:"description"`
     Quantity    int       `json:"quantity"`
     Price       float64   `json:"price"`
@@ -183,6 +213,7 @@ type ProductResponse struct {
 type StockUpdateItem struct {
     ProductID string  // Thay đổi từ primitive.ObjectID sang string
     Quantity  int
+    BundleID  string  // item là thành phần của bundle nào trong đơn (rỗng = sản phẩm thường)
 }
 
 type ProductStockUpdater interface {
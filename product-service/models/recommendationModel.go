@@ -0,0 +1,68 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	RecommendBoughtTogether = "bought_together"
+	RecommendSimilar        = "similar"
+	RecommendForYou         = "for_you"
+	RecommendPopular        = "popular"
+)
+
+// CoPurchase: số đơn có cả product_id và related_id (lưu 2 chiều)
+type CoPurchase struct {
+	ProductID string    `json:"product_id" dynamodbav:"product_id"`
+	RelatedID string    `json:"related_id" dynamodbav:"related_id"`
+	Count     int       `json:"count" dynamodbav:"count"`
+	UpdatedAt time.Time `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+// UserAffinity: sản phẩm user đã mua, dùng làm seed cho "gợi ý cho bạn"
+type UserAffinity struct {
+	UserID          string    `json:"user_id" dynamodbav:"user_id"`
+	ProductID       string    `json:"product_id" dynamodbav:"product_id"`
+	Count           int       `json:"count" dynamodbav:"count"`
+	LastPurchasedAt time.Time `json:"last_purchased_at" dynamodbav:"last_purchased_at"`
+}
+
+// RecommendationGeneration: bộ dữ liệu gợi ý đang được đọc và bộ đang rebuild (Next = 0 khi không rebuild)
+type RecommendationGeneration struct {
+	Current   int       `dynamodbav:"current"`
+	Next      int       `dynamodbav:"next"`
+	UpdatedAt time.Time `dynamodbav:"updated_at"`
+}
+
+// ScoredProduct là kết quả đã tính điểm, cache chỉ lưu phần này rồi load product theo ID
+type ScoredProduct struct {
+	ProductID string  `json:"product_id"`
+	Score     float64 `json:"score"`
+	Reason    string  `json:"reason"`
+}
+
+type RecommendedProduct struct {
+	Product
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+type ProductRecommendations struct {
+	ProductID       string               `json:"product_id"`
+	BoughtTogether  []RecommendedProduct `json:"frequently_bought_together"`
+	SimilarProducts []RecommendedProduct `json:"similar_products"`
+}
+
+// OrderLine là dữ liệu tối thiểu của một đơn để cập nhật co-purchase
+type OrderLine struct {
+	OrderID    string
+	UserID     string
+	ProductIDs []string
+	Quantities map[string]int
+	OrderedAt  time.Time
+}
+
+type OrderRecorder interface {
+	RecordOrder(ctx context.Context, order OrderLine) error
+}
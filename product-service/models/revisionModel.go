@@ -0,0 +1,114 @@
+package models
+
+import (
+	"reflect"
+	"time"
+)
+
+const (
+	RevisionCreated  = "created"
+	RevisionUpdated  = "updated"
+	RevisionRollback = "rollback"
+	// sản phẩm có từ trước khi có lịch sử: lần sửa đầu tiên lưu trạng thái cũ làm mốc
+	RevisionBaseline = "baseline"
+)
+
+// ProductSnapshot là phần nội dung seller tự sửa được, lưu nguyên trạng thái sau mỗi lần thay đổi.
+// Price là giá seller đặt (giá gốc khi đang sale). Quantity chỉ để xem, rollback không khôi phục tồn kho.
+type ProductSnapshot struct {
+	Name              string                 `json:"name" dynamodbav:"name"`
+	ImagePath         []string               `json:"image_path" dynamodbav:"image_path"`
+	Category          string                 `json:"category" dynamodbav:"category"`
+	CategoryID        string                 `json:"category_id" dynamodbav:"category_id"`
+	Attributes        map[string]interface{} `json:"attributes,omitempty" dynamodbav:"attributes,omitempty"`
+	Description       string                 `json:"description" dynamodbav:"description"`
+	Price             float64                `json:"price" dynamodbav:"price"`
+	Quantity          int                    `json:"quantity" dynamodbav:"quantity"`
+	Status            string                 `json:"status" dynamodbav:"status"`
+	LowStockThreshold int                    `json:"low_stock_threshold" dynamodbav:"low_stock_threshold"`
+}
+
+type ProductRevision struct {
+	ProductID     string          `json:"product_id" dynamodbav:"product_id"`
+	Revision      int             `json:"revision" dynamodbav:"revision"`
+	Action        string          `json:"action" dynamodbav:"action"`
+	ChangedBy     string          `json:"changed_by" dynamodbav:"changed_by"`
+	ChangedAt     time.Time       `json:"changed_at" dynamodbav:"changed_at"`
+	ChangedFields []string        `json:"changed_fields" dynamodbav:"changed_fields"`
+	RestoredFrom  int             `json:"restored_from,omitempty" dynamodbav:"restored_from,omitempty"`
+	Snapshot      ProductSnapshot `json:"snapshot" dynamodbav:"snapshot"`
+}
+
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type RevisionDiff struct {
+	ProductID    string        `json:"product_id"`
+	FromRevision int           `json:"from_revision"`
+	ToRevision   int           `json:"to_revision"`
+	Changes      []FieldChange `json:"changes"`
+}
+
+func SnapshotOf(p *Product) ProductSnapshot {
+	price := p.Price
+	if p.SaleScheduleID != "" && p.RegularPrice > 0 {
+		price = p.RegularPrice
+	}
+	return ProductSnapshot{
+		Name:              p.Name,
+		ImagePath:         p.ImagePath,
+		Category:          p.Category,
+		CategoryID:        p.CategoryID,
+		Attributes:        p.Attributes,
+		Description:       p.Description,
+		Price:             price,
+		Quantity:          p.Quantity,
+		Status:            p.Status,
+		LowStockThreshold: p.LowStockThreshold,
+	}
+}
+
+// DiffSnapshots trả về các field khác nhau theo thứ tự cố định, dùng cho changed_fields và API diff
+func DiffSnapshots(from, to ProductSnapshot) []FieldChange {
+	fields := []struct {
+		name     string
+		from, to interface{}
+	}{
+		{"name", from.Name, to.Name},
+		{"image_path", from.ImagePath, to.ImagePath},
+		{"category", from.Category, to.Category},
+		{"attributes", from.Attributes, to.Attributes},
+		{"description", from.Description, to.Description},
+		{"price", from.Price, to.Price},
+		{"quantity", from.Quantity, to.Quantity},
+		{"status", from.Status, to.Status},
+		{"low_stock_threshold", from.LowStockThreshold, to.LowStockThreshold},
+	}
+
+	var changes []FieldChange
+	for _, f := range fields {
+		if !snapshotValueEqual(f.from, f.to) {
+			changes = append(changes, FieldChange{Field: f.name, From: f.from, To: f.to})
+		}
+	}
+	return changes
+}
+
+func snapshotValueEqual(a, b interface{}) bool {
+	switch av := a.(type) {
+	case []string:
+		bv := b.([]string)
+		if len(av) == 0 && len(bv) == 0 {
+			return true
+		}
+	case map[string]interface{}:
+		bv := b.(map[string]interface{})
+		if len(av) == 0 && len(bv) == 0 {
+			return true
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
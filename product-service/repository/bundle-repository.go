@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"product-service/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var (
+	ErrAllocationExists = fmt.Errorf("bundle allocation already exists")
+	ErrAlreadyRestocked = fmt.Errorf("bundle allocation already restocked")
+)
+
+type BundleAllocationRepository interface {
+	Insert(ctx context.Context, allocation models.BundleAllocation) error
+	FindByOrderID(ctx context.Context, orderID string) ([]models.BundleAllocation, error)
+	MarkRestocked(ctx context.Context, orderID, bundleID string) error
+	MarkApplied(ctx context.Context, orderID, bundleID string) error
+	MarkComponentApplied(ctx context.Context, orderID, bundleID, productID string) error
+}
+
+type BundleAllocationRepositoryImpl struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewBundleAllocationRepository(client *dynamodb.Client, tableName string) BundleAllocationRepository {
+	return &BundleAllocationRepositoryImpl{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+// Insert dùng condition để order_success bị gửi lại không trừ kho bundle lần nữa
+func (r *BundleAllocationRepositoryImpl) Insert(ctx context.Context, allocation models.BundleAllocation) error {
+	if allocation.CreatedAt.IsZero() {
+		allocation.CreatedAt = time.Now()
+	}
+	item, err := attributevalue.MarshalMap(allocation)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(order_id)"),
+	})
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return ErrAllocationExists
+	}
+	return err
+}
+
+func (r *BundleAllocationRepositoryImpl) FindByOrderID(ctx context.Context, orderID string) ([]models.BundleAllocation, error) {
+	out, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("order_id = :oid"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":oid": &types.AttributeValueMemberS{Value: orderID},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var allocations []models.BundleAllocation
+	if err := attributevalue.UnmarshalListOfMaps(out.Items, &allocations); err != nil {
+		return nil, err
+	}
+	return allocations, nil
+}
+
+// MarkRestocked chỉ thành công một lần cho mỗi bundle của đơn, tránh cộng kho 2 lần khi order_returned bị gửi lặp
+func (r *BundleAllocationRepositoryImpl) MarkRestocked(ctx context.Context, orderID, bundleID string) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"order_id":  &types.AttributeValueMemberS{Value: orderID},
+			"bundle_id": &types.AttributeValueMemberS{Value: bundleID},
+		},
+		UpdateExpression:    aws.String("SET restocked = :true"),
+		ConditionExpression: aws.String("attribute_exists(order_id) AND restocked = :false"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":true":  &types.AttributeValueMemberBOOL{Value: true},
+			":false": &types.AttributeValueMemberBOOL{Value: false},
+		},
+	})
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return ErrAlreadyRestocked
+	}
+	return err
+}
+
+// MarkApplied: thành phần của bundle đã trừ kho xong
+func (r *BundleAllocationRepositoryImpl) MarkApplied(ctx context.Context, orderID, bundleID string) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"order_id":  &types.AttributeValueMemberS{Value: orderID},
+			"bundle_id": &types.AttributeValueMemberS{Value: bundleID},
+		},
+		UpdateExpression: aws.String("REMOVE pending"),
+	})
+	return err
+}
+
+// MarkComponentApplied ghi nhận một thành phần đã trừ kho (string set nên gọi lặp không sao)
+func (r *BundleAllocationRepositoryImpl) MarkComponentApplied(ctx context.Context, orderID, bundleID, productID string) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"order_id":  &types.AttributeValueMemberS{Value: orderID},
+			"bundle_id": &types.AttributeValueMemberS{Value: bundleID},
+		},
+		UpdateExpression: aws.String("ADD applied :product"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":product": &types.AttributeValueMemberSS{Value: []string{productID}},
+		},
+	})
+	return err
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logger "product-service/log"
+	"product-service/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+var ErrCategoryNotFound = fmt.Errorf("category not found")
+
+type CategoryRepository interface {
+	Insert(ctx context.Context, category models.Category) (*models.Category, error)
+	Put(ctx context.Context, category models.Category) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*models.Category, error)
+	FindBySlug(ctx context.Context, slug string) (*models.Category, error)
+	FindAll(ctx context.Context) ([]models.Category, error)
+	FindChildren(ctx context.Context, parentID string) ([]models.Category, error)
+}
+
+type CategoryRepositoryImpl struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewCategoryRepository(client *dynamodb.Client, tableName string) CategoryRepository {
+	return &CategoryRepositoryImpl{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+func (r *CategoryRepositoryImpl) Insert(ctx context.Context, category models.Category) (*models.Category, error) {
+	if category.ID == "" {
+		category.ID = uuid.New().String()
+	}
+	now := time.Now()
+	category.Created_at = now
+	category.Updated_at = now
+
+	item, err := attributevalue.MarshalMap(category)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// Put ghi đè toàn bộ category (dùng khi update hoặc đổi parent)
+func (r *CategoryRepositoryImpl) Put(ctx context.Context, category models.Category) error {
+	category.Updated_at = time.Now()
+
+	item, err := attributevalue.MarshalMap(category)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	return err
+}
+
+func (r *CategoryRepositoryImpl) Delete(ctx context.Context, id string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	return err
+}
+
+func (r *CategoryRepositoryImpl) FindByID(ctx context.Context, id string) (*models.Category, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		logger.Err("DynamoDB GetItem error", err)
+		return nil, err
+	}
+
+	if result.Item == nil {
+		return nil, ErrCategoryNotFound
+	}
+
+	var category models.Category
+	if err := attributevalue.UnmarshalMap(result.Item, &category); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *CategoryRepositoryImpl) FindBySlug(ctx context.Context, slug string) (*models.Category, error) {
+	result, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("slug-index"),
+		KeyConditionExpression: aws.String("#slug = :slug"),
+		ExpressionAttributeNames: map[string]string{
+			"#slug": "slug",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":slug": &types.AttributeValueMemberS{Value: slug},
+		},
+		Limit: aws.Int32(1),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Items) == 0 {
+		return nil, ErrCategoryNotFound
+	}
+
+	var category models.Category
+	if err := attributevalue.UnmarshalMap(result.Items[0], &category); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// Số lượng category nhỏ nên scan toàn bộ bảng là chấp nhận được
+func (r *CategoryRepositoryImpl) FindAll(ctx context.Context) ([]models.Category, error) {
+	var categories []models.Category
+
+	paginator := dynamodb.NewScanPaginator(r.client, &dynamodb.ScanInput{
+		TableName: aws.String(r.tableName),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			var category models.Category
+			if err := attributevalue.UnmarshalMap(item, &category); err != nil {
+				logger.Err("unmarshal category", err)
+				continue
+			}
+			categories = append(categories, category)
+		}
+	}
+	return categories, nil
+}
+
+func (r *CategoryRepositoryImpl) FindChildren(ctx context.Context, parentID string) ([]models.Category, error) {
+	var categories []models.Category
+
+	paginator := dynamodb.NewScanPaginator(r.client, &dynamodb.ScanInput{
+		TableName:        aws.String(r.tableName),
+		FilterExpression: aws.String("#parent_id = :pid"),
+		ExpressionAttributeNames: map[string]string{
+			"#parent_id": "parent_id",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pid": &types.AttributeValueMemberS{Value: parentID},
+		},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			var category models.Category
+			if err := attributevalue.UnmarshalMap(item, &category); err != nil {
+				logger.Err("unmarshal category", err)
+				continue
+			}
+			categories = append(categories, category)
+		}
+	}
+	return categories, nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"product-service/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var ErrDraftNotFound = fmt.Errorf("draft not found")
+
+type DraftRepository interface {
+	Put(ctx context.Context, draft models.ProductDraft) error
+	Get(ctx context.Context, sellerID, draftID string) (*models.ProductDraft, error)
+	ListBySeller(ctx context.Context, sellerID string) ([]models.ProductDraft, error)
+	Delete(ctx context.Context, sellerID, draftID string) error
+}
+
+type DraftRepositoryImpl struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+// Bảng draft: PK seller_id, SK draft_id, seller chỉ đọc được draft của mình
+func NewDraftRepository(client *dynamodb.Client, tableName string) DraftRepository {
+	return &DraftRepositoryImpl{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+func (r *DraftRepositoryImpl) Put(ctx context.Context, draft models.ProductDraft) error {
+	item, err := attributevalue.MarshalMap(draft)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	return err
+}
+
+func (r *DraftRepositoryImpl) Get(ctx context.Context, sellerID, draftID string) (*models.ProductDraft, error) {
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"seller_id": &types.AttributeValueMemberS{Value: sellerID},
+			"draft_id":  &types.AttributeValueMemberS{Value: draftID},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.Item == nil {
+		return nil, ErrDraftNotFound
+	}
+	var draft models.ProductDraft
+	if err := attributevalue.UnmarshalMap(out.Item, &draft); err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+func (r *DraftRepositoryImpl) ListBySeller(ctx context.Context, sellerID string) ([]models.ProductDraft, error) {
+	paginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("seller_id = :sid"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":sid": &types.AttributeValueMemberS{Value: sellerID},
+		},
+	})
+
+	var drafts []models.ProductDraft
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var batch []models.ProductDraft
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &batch); err != nil {
+			return nil, err
+		}
+		drafts = append(drafts, batch...)
+	}
+	return drafts, nil
+}
+
+func (r *DraftRepositoryImpl) Delete(ctx context.Context, sellerID, draftID string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"seller_id": &types.AttributeValueMemberS{Value: sellerID},
+			"draft_id":  &types.AttributeValueMemberS{Value: draftID},
+		},
+		ConditionExpression: aws.String("attribute_exists(draft_id)"),
+	})
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return ErrDraftNotFound
+	}
+	return err
+}
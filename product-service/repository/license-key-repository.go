@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"product-service/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotEnoughLicenseKeys = fmt.Errorf("not enough license keys available")
+	ErrKeyAlreadyRevoked    = fmt.Errorf("license key already revoked")
+)
+
+type LicenseKeyRepository interface {
+	AddKeys(ctx context.Context, productID string, keys []string) (int, error)
+	AssignKeys(ctx context.Context, productID, orderID, userID string, quantity int) ([]models.LicenseKey, error)
+	FindByOrder(ctx context.Context, orderID string) ([]models.LicenseKey, error)
+	Revoke(ctx context.Context, key models.LicenseKey) error
+	CountAvailable(ctx context.Context, productID string) (int, error)
+}
+
+type LicenseKeyRepositoryImpl struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+// Bảng license key: PK product_id, SK key_id, GSI order_id-index (PK order_id) để tra key theo đơn
+func NewLicenseKeyRepository(client *dynamodb.Client, tableName string) LicenseKeyRepository {
+	return &LicenseKeyRepositoryImpl{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+func (r *LicenseKeyRepositoryImpl) AddKeys(ctx context.Context, productID string, keys []string) (int, error) {
+	added := 0
+	now := time.Now()
+	for _, key := range keys {
+		item, err := attributevalue.MarshalMap(models.LicenseKey{
+			ProductID: productID,
+			KeyID:     uuid.New().String(),
+			Key:       key,
+			Status:    models.LicenseKeyAvailable,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return added, err
+		}
+		_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName:           aws.String(r.tableName),
+			Item:                item,
+			ConditionExpression: aws.String("attribute_not_exists(key_id)"),
+		})
+		if err != nil {
+			return added, err
+		}
+		added++
+	}
+	return added, nil
+}
+
+// AssignKeys lấy key còn trống rồi giữ từng key bằng conditional update, key bị đơn khác giữ trước thì bỏ qua lấy key kế.
+// Đơn đã được gán key rồi (payment success gửi lặp) thì trả lại đúng các key cũ.
+func (r *LicenseKeyRepositoryImpl) AssignKeys(ctx context.Context, productID, orderID, userID string, quantity int) ([]models.LicenseKey, error) {
+	existing, err := r.FindByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	assigned := make([]models.LicenseKey, 0, quantity)
+	for _, k := range existing {
+		if k.ProductID == productID {
+			assigned = append(assigned, k)
+		}
+	}
+
+	var startKey map[string]types.AttributeValue
+	for len(assigned) < quantity {
+		out, err := r.client.Query(ctx, &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			ExclusiveStartKey:      startKey,
+			KeyConditionExpression: aws.String("product_id = :pid"),
+			FilterExpression:       aws.String("#status = :available"),
+			ExpressionAttributeNames: map[string]string{
+				"#status": "status",
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pid":       &types.AttributeValueMemberS{Value: productID},
+				":available": &types.AttributeValueMemberS{Value: models.LicenseKeyAvailable},
+			},
+		})
+		if err != nil {
+			return assigned, err
+		}
+
+		var candidates []models.LicenseKey
+		if err := attributevalue.UnmarshalListOfMaps(out.Items, &candidates); err != nil {
+			return assigned, err
+		}
+		for _, c := range candidates {
+			if len(assigned) >= quantity {
+				break
+			}
+			now := time.Now()
+			_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+				TableName: aws.String(r.tableName),
+				Key: map[string]types.AttributeValue{
+					"product_id": &types.AttributeValueMemberS{Value: c.ProductID},
+					"key_id":     &types.AttributeValueMemberS{Value: c.KeyID},
+				},
+				UpdateExpression:    aws.String("SET #status = :assigned, order_id = :oid, user_id = :uid, assigned_at = :time"),
+				ConditionExpression: aws.String("#status = :available"),
+				ExpressionAttributeNames: map[string]string{
+					"#status": "status",
+				},
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":assigned":  &types.AttributeValueMemberS{Value: models.LicenseKeyAssigned},
+					":available": &types.AttributeValueMemberS{Value: models.LicenseKeyAvailable},
+					":oid":       &types.AttributeValueMemberS{Value: orderID},
+					":uid":       &types.AttributeValueMemberS{Value: userID},
+					":time":      &types.AttributeValueMemberS{Value: now.Format(time.RFC3339Nano)},
+				},
+			})
+			var condErr *types.ConditionalCheckFailedException
+			if errors.As(err, &condErr) {
+				continue
+			}
+			if err != nil {
+				return assigned, err
+			}
+			c.Status = models.LicenseKeyAssigned
+			c.OrderID = orderID
+			c.UserID = userID
+			c.AssignedAt = &now
+			assigned = append(assigned, c)
+		}
+
+		startKey = out.LastEvaluatedKey
+		if startKey == nil && len(assigned) < quantity {
+			return assigned, ErrNotEnoughLicenseKeys
+		}
+	}
+	return assigned, nil
+}
+
+func (r *LicenseKeyRepositoryImpl) FindByOrder(ctx context.Context, orderID string) ([]models.LicenseKey, error) {
+	paginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("order_id-index"),
+		KeyConditionExpression: aws.String("order_id = :oid"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":oid": &types.AttributeValueMemberS{Value: orderID},
+		},
+	})
+
+	var keys []models.LicenseKey
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var batch []models.LicenseKey
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &batch); err != nil {
+			return nil, err
+		}
+		keys = append(keys, batch...)
+	}
+	return keys, nil
+}
+
+// Revoke chỉ thành công một lần cho mỗi key đang được gán cho đơn, order_returned gửi lặp thì trả ErrKeyAlreadyRevoked
+func (r *LicenseKeyRepositoryImpl) Revoke(ctx context.Context, key models.LicenseKey) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"product_id": &types.AttributeValueMemberS{Value: key.ProductID},
+			"key_id":     &types.AttributeValueMemberS{Value: key.KeyID},
+		},
+		UpdateExpression:    aws.String("SET #status = :revoked"),
+		ConditionExpression: aws.String("order_id = :oid AND #status = :assigned"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":revoked":  &types.AttributeValueMemberS{Value: models.LicenseKeyRevoked},
+			":assigned": &types.AttributeValueMemberS{Value: models.LicenseKeyAssigned},
+			":oid":      &types.AttributeValueMemberS{Value: key.OrderID},
+		},
+	})
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return ErrKeyAlreadyRevoked
+	}
+	return err
+}
+
+func (r *LicenseKeyRepositoryImpl) CountAvailable(ctx context.Context, productID string) (int, error) {
+	paginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("product_id = :pid"),
+		FilterExpression:       aws.String("#status = :available"),
+		Select:                 types.SelectCount,
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pid":       &types.AttributeValueMemberS{Value: productID},
+			":available": &types.AttributeValueMemberS{Value: models.LicenseKeyAvailable},
+		},
+	})
+
+	count := 0
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += int(page.Count)
+	}
+	return count, nil
+}
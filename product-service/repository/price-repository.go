@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logger "product-service/log"
+	"product-service/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+var ErrPriceScheduleNotFound = fmt.Errorf("price schedule not found")
+
+type PriceScheduleRepository interface {
+	Insert(ctx context.Context, schedule models.PriceSchedule) (*models.PriceSchedule, error)
+	UpdateStatus(ctx context.Context, id, status string) error
+	FindByID(ctx context.Context, id string) (*models.PriceSchedule, error)
+	FindByProductID(ctx context.Context, productID string) ([]models.PriceSchedule, error)
+	FindPending(ctx context.Context) ([]models.PriceSchedule, error)
+}
+
+type PriceScheduleRepositoryImpl struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewPriceScheduleRepository(client *dynamodb.Client, tableName string) PriceScheduleRepository {
+	return &PriceScheduleRepositoryImpl{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+func (r *PriceScheduleRepositoryImpl) Insert(ctx context.Context, schedule models.PriceSchedule) (*models.PriceSchedule, error) {
+	if schedule.ID == "" {
+		schedule.ID = uuid.New().String()
+	}
+	now := time.Now()
+	schedule.Created_at = now
+	schedule.Updated_at = now
+
+	item, err := attributevalue.MarshalMap(schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (r *PriceScheduleRepositoryImpl) UpdateStatus(ctx context.Context, id, status string) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression: aws.String("SET #status = :status, updated_at = :time"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: status},
+			":time":   &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339Nano)},
+		},
+	})
+	return err
+}
+
+func (r *PriceScheduleRepositoryImpl) FindByID(ctx context.Context, id string) (*models.PriceSchedule, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		logger.Err("DynamoDB GetItem error", err)
+		return nil, err
+	}
+
+	if result.Item == nil {
+		return nil, ErrPriceScheduleNotFound
+	}
+
+	var schedule models.PriceSchedule
+	if err := attributevalue.UnmarshalMap(result.Item, &schedule); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (r *PriceScheduleRepositoryImpl) FindByProductID(ctx context.Context, productID string) ([]models.PriceSchedule, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("product_id-index"),
+		KeyConditionExpression: aws.String("#product_id = :pid"),
+		ExpressionAttributeNames: map[string]string{
+			"#product_id": "product_id",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pid": &types.AttributeValueMemberS{Value: productID},
+		},
+	}
+
+	var schedules []models.PriceSchedule
+	paginator := dynamodb.NewQueryPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			var schedule models.PriceSchedule
+			if err := attributevalue.UnmarshalMap(item, &schedule); err != nil {
+				logger.Err("unmarshal price schedule", err)
+				continue
+			}
+			schedules = append(schedules, schedule)
+		}
+	}
+	return schedules, nil
+}
+
+// FindPending lấy các schedule chưa kết thúc (scheduled hoặc active) để scheduler xử lý
+func (r *PriceScheduleRepositoryImpl) FindPending(ctx context.Context) ([]models.PriceSchedule, error) {
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(r.tableName),
+		FilterExpression: aws.String("#status IN (:scheduled, :active)"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":scheduled": &types.AttributeValueMemberS{Value: models.PriceScheduleScheduled},
+			":active":    &types.AttributeValueMemberS{Value: models.PriceScheduleActive},
+		},
+	}
+
+	var schedules []models.PriceSchedule
+	paginator := dynamodb.NewScanPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			var schedule models.PriceSchedule
+			if err := attributevalue.UnmarshalMap(item, &schedule); err != nil {
+				logger.Err("unmarshal price schedule", err)
+				continue
+			}
+			schedules = append(schedules, schedule)
+		}
+	}
+	return schedules, nil
+}
+
+type PriceHistoryRepository interface {
+	Insert(ctx context.Context, entry models.PriceHistory) error
+	FindRecent(ctx context.Context, productID string, since time.Time) ([]models.PriceHistory, error)
+}
+
+const historyPageSize = 50
+
+// Bảng price history: partition key product_id, sort key changed_at (RFC3339Nano)
+type PriceHistoryRepositoryImpl struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewPriceHistoryRepository(client *dynamodb.Client, tableName string) PriceHistoryRepository {
+	return &PriceHistoryRepositoryImpl{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+func (r *PriceHistoryRepositoryImpl) Insert(ctx context.Context, entry models.PriceHistory) error {
+	if entry.ChangedAt.IsZero() {
+		entry.ChangedAt = time.Now()
+	}
+
+	item, err := attributevalue.MarshalMap(entry)
+	if err != nil {
+		return err
+	}
+	item["changed_at"] = &types.AttributeValueMemberS{Value: entry.ChangedAt.UTC().Format(time.RFC3339Nano)}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	return err
+}
+
+// FindRecent đọc từ mới đến cũ trong một query: các lần đổi giá từ since trở đi cùng lần
+// gần nhất trước since (giá đang áp dụng đầu kỳ), đọc tới đó thì dừng
+func (r *PriceHistoryRepositoryImpl) FindRecent(ctx context.Context, productID string, since time.Time) ([]models.PriceHistory, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("#product_id = :pid"),
+		ExpressionAttributeNames: map[string]string{
+			"#product_id": "product_id",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pid": &types.AttributeValueMemberS{Value: productID},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int32(historyPageSize),
+	}
+
+	var history []models.PriceHistory
+	paginator := dynamodb.NewQueryPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			var entry models.PriceHistory
+			if err := attributevalue.UnmarshalMap(item, &entry); err != nil {
+				logger.Err("unmarshal price history", err)
+				continue
+			}
+			history = append(history, entry)
+			if entry.ChangedAt.Before(since) {
+				return history, nil
+			}
+		}
+	}
+	return history, nil
+}
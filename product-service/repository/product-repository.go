@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -17,19 +18,33 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrUnlimitedStock: sản phẩm số không giới hạn thì không trừ / cộng kho
+var ErrUnlimitedStock = fmt.Errorf("product has unlimited stock")
+
+// ErrProductExists: Insert với ID đã có sản phẩm (publish draft bị gọi lại)
+var ErrProductExists = fmt.Errorf("product already exists")
+
+// ErrProductChanged: giá hoặc sale của sản phẩm đã bị ghi khác đi kể từ lúc đọc
+var ErrProductChanged = fmt.Errorf("product was changed concurrently")
+
 type ProductRepository interface {
 	Insert(ctx context.Context, product models.Product) error
 	Update(ctx context.Context, id string, update map[string]interface{}) error
+	UpdateIfUnchanged(ctx context.Context, current *models.Product, update map[string]interface{}) error
 	Delete(ctx context.Context, id, userID string) error
 	FindByID(ctx context.Context, id string) (*models.Product, error)
+	FindByIDs(ctx context.Context, ids []string) ([]models.Product, error)
 	// FindByName(ctx context.Context, name string) ([]models.Product, error)
 	FindAll(ctx context.Context, skip, limit int64) ([]models.Product, int64, error)
 	FindByUserID(ctx context.Context, userID string, skip, limit int64) ([]models.Product, int64, error)
-	UpdateStock(ctx context.Context, id string, quantity int) error
+	FindBundlesByComponent(ctx context.Context, sellerID, componentID string) ([]models.Product, error)
+	UpdateStock(ctx context.Context, id string, quantity int) (*models.Product, error)
 	IncrementSoldCount(ctx context.Context, productID string, quantity int) error
 	GetBestSellingProduct(ctx context.Context, limit int) ([]models.Product, error)
 	DecrementSoldCount(ctx context.Context, productID string, quantity int) error
 	GetProductByCategory(ctx context.Context, category string, skip, limit int64) ([]models.Product, int64, error)
+	CountProductsByCategory(ctx context.Context, category string) (int64, error)
+	ScanAll(ctx context.Context, fn func(product models.Product) error) error
 }
 
 type ProductRepositoryImpl struct {
@@ -59,25 +74,97 @@ func (r *ProductRepositoryImpl) Insert(ctx context.Context, product models.Produ
 		"price":       &types.AttributeValueMemberN{Value: strconv.FormatFloat(product.Price, 'f', 2, 64)},
 		"quantity":    &types.AttributeValueMemberN{Value: strconv.FormatInt(int64(product.Quantity), 10)},
 		"category":    &types.AttributeValueMemberS{Value: product.Category},
+		"category_id": &types.AttributeValueMemberS{Value: product.CategoryID},
 		"image_path":  &types.AttributeValueMemberSS{Value: product.ImagePath},
-		"created_at":  &types.AttributeValueMemberS{Value: now.Format(time.RFC3339)},
-		"updated_at":  &types.AttributeValueMemberS{Value: now.Format(time.RFC3339)},
+		"created_at":  &types.AttributeValueMemberS{Value: now.Format(time.RFC3339Nano)},
+		"updated_at":  &types.AttributeValueMemberS{Value: now.Format(time.RFC3339Nano)},
 		"user_id":     &types.AttributeValueMemberS{Value: product.UserID},
 		"sold_count":  &types.AttributeValueMemberN{Value: "0"},
 		"status":      &types.AttributeValueMemberS{Value: product.Status},
+		"low_stock_threshold": &types.AttributeValueMemberN{Value: strconv.Itoa(product.LowStockThreshold)},
 	}
 
 	if len(product.ImagePath) > 0 {
 		item["image_path"] = &types.AttributeValueMemberSS{Value: product.ImagePath}
 	}
 
+	if len(product.Attributes) > 0 {
+		attrs, err := attributevalue.Marshal(product.Attributes)
+		if err != nil {
+			return err
+		}
+		item["attributes"] = attrs
+	}
+
+	if product.Type != "" {
+		item["product_type"] = &types.AttributeValueMemberS{Value: product.Type}
+	}
+	if len(product.BundleItems) > 0 {
+		components, err := attributevalue.Marshal(product.BundleItems)
+		if err != nil {
+			return err
+		}
+		item["bundle_items"] = components
+	}
+	if product.DigitalFileKey != "" {
+		item["digital_file_key"] = &types.AttributeValueMemberS{Value: product.DigitalFileKey}
+		item["digital_file_name"] = &types.AttributeValueMemberS{Value: product.DigitalFileName}
+	}
+	if product.UnlimitedStock {
+		item["unlimited_stock"] = &types.AttributeValueMemberBOOL{Value: true}
+	}
+	if product.UsesLicenseKeys {
+		item["uses_license_keys"] = &types.AttributeValueMemberBOOL{Value: true}
+	}
+	if product.DownloadLimit > 0 {
+		item["download_limit"] = &types.AttributeValueMemberN{Value: strconv.Itoa(product.DownloadLimit)}
+	}
+	if product.DownloadExpiryHours > 0 {
+		item["download_expiry_hours"] = &types.AttributeValueMemberN{Value: strconv.Itoa(product.DownloadExpiryHours)}
+	}
+
+	// không ghi đè sản phẩm đã có (mất sold_count, rating...)
 	_, err := r.client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(r.tableName),
-		Item:      item,
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
 	})
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return ErrProductExists
+	}
 	return err
 }
 func (r *ProductRepositoryImpl) Update(ctx context.Context, id string, update map[string]interface{}) error {
+	return r.update(ctx, id, update, "", nil, nil)
+}
+
+// UpdateIfUnchanged chỉ ghi khi price và sale_schedule_id vẫn như bản current đã đọc,
+// để hai lượt scheduler (hoặc seller sửa giá cùng lúc) không ghi đè lẫn nhau
+func (r *ProductRepositoryImpl) UpdateIfUnchanged(ctx context.Context, current *models.Product, update map[string]interface{}) error {
+	price, err := attributevalue.Marshal(current.Price)
+	if err != nil {
+		return err
+	}
+	condition := "#price = :expected_price AND #sale_schedule_id = :expected_sale"
+	if current.SaleScheduleID == "" {
+		condition = "#price = :expected_price AND (attribute_not_exists(#sale_schedule_id) OR #sale_schedule_id = :expected_sale)"
+	}
+	values := map[string]types.AttributeValue{
+		":expected_price": price,
+		":expected_sale":  &types.AttributeValueMemberS{Value: current.SaleScheduleID},
+	}
+
+	names := map[string]string{"#price": "price", "#sale_schedule_id": "sale_schedule_id"}
+	err = r.update(ctx, current.ID, update, condition, names, values)
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return ErrProductChanged
+	}
+	return err
+}
+
+func (r *ProductRepositoryImpl) update(ctx context.Context, id string, update map[string]interface{}, condition string, conditionNames map[string]string, conditionValues map[string]types.AttributeValue) error {
 	if update == nil {
 		update = map[string]interface{}{}
 	}
@@ -116,9 +203,9 @@ func (r *ProductRepositoryImpl) Update(ctx context.Context, id string, update ma
 		clauses = append(clauses, fmt.Sprintf("%s = %s", nameKey, valKey))
 	}
 
-	// Always update updated_at
+	// Always update updated_at (RFC3339Nano: search-service lấy updated_at theo ms làm version)
 	exprNames["#updated_at"] = "updated_at"
-	updatedAtVal, err := attributevalue.Marshal(time.Now().Format(time.RFC3339))
+	updatedAtVal, err := attributevalue.Marshal(time.Now().Format(time.RFC3339Nano))
 	if err != nil {
 		return err
 	}
@@ -127,7 +214,7 @@ func (r *ProductRepositoryImpl) Update(ctx context.Context, id string, update ma
 
 	updateExpr := "SET " + strings.Join(clauses, ", ")
 
-	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+	input := &dynamodb.UpdateItemInput{
 		TableName: aws.String(r.tableName),
 		Key: map[string]types.AttributeValue{
 			"id": &types.AttributeValueMemberS{Value: id},
@@ -135,7 +222,18 @@ func (r *ProductRepositoryImpl) Update(ctx context.Context, id string, update ma
 		ExpressionAttributeNames:  exprNames,
 		ExpressionAttributeValues: exprValues,
 		UpdateExpression:          aws.String(updateExpr),
-	})
+	}
+	if condition != "" {
+		for k, v := range conditionNames {
+			exprNames[k] = v
+		}
+		for k, v := range conditionValues {
+			exprValues[k] = v
+		}
+		input.ConditionExpression = aws.String(condition)
+	}
+
+	_, err = r.client.UpdateItem(ctx, input)
 	return err
 }
 
@@ -206,6 +304,66 @@ func (r *ProductRepositoryImpl) FindByID(ctx context.Context, id string) (*model
 	return &prod, nil
 }
 
+// BatchGetItem giới hạn 100 key mỗi request, key chưa xử lý (throttle) thì gửi lại
+const batchGetLimit = 100
+
+func (r *ProductRepositoryImpl) FindByIDs(ctx context.Context, ids []string) ([]models.Product, error) {
+	seen := make(map[string]struct{}, len(ids))
+	keys := make([]map[string]types.AttributeValue, 0, len(ids))
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		keys = append(keys, map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		})
+	}
+
+	products := make([]models.Product, 0, len(keys))
+	for start := 0; start < len(keys); start += batchGetLimit {
+		end := start + batchGetLimit
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		request := map[string]types.KeysAndAttributes{
+			r.tableName: {Keys: keys[start:end]},
+		}
+		for attempt := 0; len(request) > 0; attempt++ {
+			if attempt > 0 {
+				if attempt > 5 {
+					return nil, fmt.Errorf("batch get products: unprocessed keys after %d retries", attempt-1)
+				}
+				time.Sleep(time.Duration(attempt*50) * time.Millisecond)
+			}
+
+			result, err := r.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+				RequestItems: request,
+			})
+			if err != nil {
+				logger.Err("DynamoDB BatchGetItem error", err)
+				return nil, err
+			}
+
+			for _, item := range result.Responses[r.tableName] {
+				prod, err := decodeProduct(item)
+				if err != nil {
+					logger.Err("Failed to decode product", err)
+					continue
+				}
+				products = append(products, prod)
+			}
+			request = result.UnprocessedKeys
+		}
+	}
+
+	return products, nil
+}
+
 // func (r *productRepositoryImpl) FindByName(ctx context.Context, name string) ([]models.Product, error) {
 // 	var products []models.Product
 // 	filter := bson.M{"name":bson.M{"$regex": name, "$options": "i"}}
@@ -273,7 +431,8 @@ func (r *ProductRepositoryImpl) FindAll(ctx context.Context, skip, limit int64)
 	return products, total, nil
 }
 
-func (r *ProductRepositoryImpl) UpdateStock(ctx context.Context, id string, quantity int) error {
+// UpdateStock trả về product sau khi cập nhật để service kiểm tra ngưỡng tồn kho
+func (r *ProductRepositoryImpl) UpdateStock(ctx context.Context, id string, quantity int) (*models.Product, error) {
 	// Trừ stock khi order thành công (quantity dương = giảm stock)
 	logger.Info(fmt.Sprintf("UpdateStock called: productID=%s, quantity=%d, actualValue=%d", id, quantity, -quantity))
 
@@ -282,27 +441,32 @@ func (r *ProductRepositoryImpl) UpdateStock(ctx context.Context, id string, quan
 		Key: map[string]types.AttributeValue{
 			"id": &types.AttributeValueMemberS{Value: id},
 		},
-		UpdateExpression: aws.String("ADD quantity :qty SET updated_at = :time"),
+		UpdateExpression:    aws.String("ADD quantity :qty SET updated_at = :time"),
+		ConditionExpression: aws.String("attribute_not_exists(unlimited_stock) OR unlimited_stock = :false"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":qty":  &types.AttributeValueMemberN{Value: strconv.Itoa(-quantity)}, // Âm để trừ đi
-			":time": &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+			":qty":   &types.AttributeValueMemberN{Value: strconv.Itoa(-quantity)}, // Âm để trừ đi
+			":time":  &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339Nano)},
+			":false": &types.AttributeValueMemberBOOL{Value: false},
 		},
 		ReturnValues: types.ReturnValueAllNew,
 	})
 
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return nil, ErrUnlimitedStock
+	}
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to update stock: productID=%s, error=%v", id, err))
-		return err
+		return nil, err
 	}
 
-	// Log giá trị mới sau khi update
-	if qtyAttr, ok := result.Attributes["quantity"]; ok {
-		if qtyN, ok := qtyAttr.(*types.AttributeValueMemberN); ok {
-			logger.Info(fmt.Sprintf("Stock updated successfully: productID=%s, new_quantity=%s", id, qtyN.Value))
-		}
+	product, err := decodeProduct(result.Attributes)
+	if err != nil {
+		return nil, err
 	}
+	logger.Info(fmt.Sprintf("Stock updated successfully: productID=%s, new_quantity=%d", id, product.Quantity))
 
-	return nil
+	return &product, nil
 }
 
 func (r *ProductRepositoryImpl) IncrementSoldCount(ctx context.Context, productID string, quantity int) error {
@@ -316,7 +480,7 @@ func (r *ProductRepositoryImpl) IncrementSoldCount(ctx context.Context, productI
 		UpdateExpression: aws.String("ADD sold_count :qty SET updated_at = :time"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":qty":  &types.AttributeValueMemberN{Value: strconv.Itoa(quantity)},
-			":time": &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+			":time": &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339Nano)},
 		},
 		ReturnValues: types.ReturnValueAllNew,
 	})
@@ -347,7 +511,7 @@ func (r *ProductRepositoryImpl) DecrementSoldCount(ctx context.Context, productI
 		UpdateExpression: aws.String("ADD sold_count :qty SET updated_at = :time"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":qty":  &types.AttributeValueMemberN{Value: strconv.Itoa(-quantity)}, // Âm để trừ
-			":time": &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+			":time": &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339Nano)},
 		},
 	})
 
@@ -442,8 +606,52 @@ func (r *ProductRepositoryImpl) FindByUserID(ctx context.Context, userID string,
 	return products, total, nil
 }
 
-func (r *ProductRepositoryImpl) GetProductByCategory(ctx context.Context, category string, skip, limit int64) ([]models.Product, int64, error) {
-	getItemInput := &dynamodb.QueryInput{
+// FindBundlesByComponent: bundle chỉ gồm sản phẩm của chính seller nên query theo user_id-index
+// rồi lọc các bundle có chứa thành phần
+func (r *ProductRepositoryImpl) FindBundlesByComponent(ctx context.Context, sellerID, componentID string) ([]models.Product, error) {
+	paginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("user_id-index"),
+		KeyConditionExpression: aws.String("#user_id = :uid"),
+		FilterExpression:       aws.String("#type = :bundle"),
+		ExpressionAttributeNames: map[string]string{
+			"#user_id": "user_id",
+			"#type":    "product_type",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":uid":    &types.AttributeValueMemberS{Value: sellerID},
+			":bundle": &types.AttributeValueMemberS{Value: models.ProductTypeBundle},
+		},
+	})
+
+	var bundles []models.Product
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			product, err := decodeProduct(item)
+			if err != nil {
+				logger.Err("unmarshal product", err)
+				continue
+			}
+			for _, c := range product.BundleItems {
+				if c.ProductID == componentID {
+					bundles = append(bundles, product)
+					break
+				}
+			}
+		}
+	}
+	return bundles, nil
+}
+
+// số item tối đa mỗi lần query category
+const maxCategoryPage = 1000
+
+func (r *ProductRepositoryImpl) categoryQuery(category string) *dynamodb.QueryInput {
+	return &dynamodb.QueryInput{
 		TableName:              aws.String(r.tableName),
 		IndexName:              aws.String("category-index"),
 		KeyConditionExpression: aws.String("#category = :cat"),
@@ -454,44 +662,103 @@ func (r *ProductRepositoryImpl) GetProductByCategory(ctx context.Context, catego
 			":cat": &types.AttributeValueMemberS{Value: category},
 		},
 	}
+}
 
-	var products []models.Product
-	var total int64 = 0
+// CountProductsByCategory đếm bằng Select COUNT, không đọc item về
+func (r *ProductRepositoryImpl) CountProductsByCategory(ctx context.Context, category string) (int64, error) {
+	input := r.categoryQuery(category)
+	input.Select = types.SelectCount
 
-	paginator := dynamodb.NewQueryPaginator(r.client, getItemInput)
+	var total int64
+	paginator := dynamodb.NewQueryPaginator(r.client, input)
 	for paginator.HasMorePages() {
 		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		total += int64(page.Count)
+	}
+	return total, nil
+}
+
+// GetProductByCategory phân trang ngay trong query theo thứ tự của category-index,
+// limit <= 0 thì lấy hết (job nội bộ như recommendation)
+func (r *ProductRepositoryImpl) GetProductByCategory(ctx context.Context, category string, skip, limit int64) ([]models.Product, int64, error) {
+	total, err := r.CountProductsByCategory(ctx, category)
+	if err != nil {
+		return nil, 0, err
+	}
+	products := []models.Product{}
+	if skip >= total {
+		return products, total, nil
+	}
+
+	// bỏ qua skip bản ghi bằng query COUNT, chỉ cần LastEvaluatedKey để đọc tiếp
+	var startKey map[string]types.AttributeValue
+	for skip > 0 {
+		input := r.categoryQuery(category)
+		input.Select = types.SelectCount
+		input.Limit = aws.Int32(int32(min(skip, maxCategoryPage)))
+		input.ExclusiveStartKey = startKey
+		out, err := r.client.Query(ctx, input)
 		if err != nil {
 			return nil, 0, err
 		}
-		for _, item := range page.Items {
+		skip -= int64(out.Count)
+		startKey = out.LastEvaluatedKey
+		if startKey == nil {
+			return products, total, nil
+		}
+	}
+
+	for {
+		input := r.categoryQuery(category)
+		input.ExclusiveStartKey = startKey
+		if limit > 0 {
+			input.Limit = aws.Int32(int32(min(limit-int64(len(products)), maxCategoryPage)))
+		}
+		out, err := r.client.Query(ctx, input)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, item := range out.Items {
 			product, err := decodeProduct(item)
 			if err != nil {
 				logger.Err("unmarshal product", err)
 				continue
 			}
 			products = append(products, product)
-			total++
+		}
+		startKey = out.LastEvaluatedKey
+		if startKey == nil || (limit > 0 && int64(len(products)) >= limit) {
+			break
 		}
 	}
+	return products, total, nil
+}
 
-	for i := 0; i < len(products)-1; i++ {
-		for j := 0; j < len(products)-i-1; j++ {
-			if products[j].SoldCount < products[j+1].SoldCount {
-				products[j], products[j+1] = products[j+1], products[j]
+// ScanAll duyệt toàn bộ bảng product, dùng cho các job migrate/reindex
+func (r *ProductRepositoryImpl) ScanAll(ctx context.Context, fn func(product models.Product) error) error {
+	paginator := dynamodb.NewScanPaginator(r.client, &dynamodb.ScanInput{
+		TableName: aws.String(r.tableName),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, item := range page.Items {
+			product, err := decodeProduct(item)
+			if err != nil {
+				logger.Err("unmarshal product", err)
+				continue
+			}
+			if err := fn(product); err != nil {
+				return err
 			}
 		}
 	}
-
-	if limit > int64(len(products)) {
-		limit = int64(len(products))
-	}
-
-	if len(products) == 0 {
-		return []models.Product{}, total, nil
-	}
-
-	return products, total, nil
+	return nil
 }
 
 func decodeProduct(item map[string]types.AttributeValue) (models.Product, error) {
@@ -0,0 +1,475 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	logger "product-service/log"
+	"product-service/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type RecommendationRepository interface {
+	AddCoPurchase(ctx context.Context, productID, relatedID string, delta int) error
+	TopCoPurchased(ctx context.Context, productID string, limit int) ([]models.CoPurchase, error)
+	AddUserAffinity(ctx context.Context, userID, productID string, delta int, at time.Time) error
+	GetUserAffinity(ctx context.Context, userID string) ([]models.UserAffinity, error)
+
+	// Rebuild ghi vào generation mới trong khi consumer vẫn ghi song song vào cả 2 generation,
+	// xong thì chuyển đọc sang generation mới rồi xoá generation cũ
+	BeginRebuild(ctx context.Context) (int, error)
+	AddRebuildCoPurchase(ctx context.Context, generation int, productID, relatedID string, delta int) error
+	AddRebuildUserAffinity(ctx context.Context, generation int, userID, productID string, delta int, at time.Time) error
+	SwitchGeneration(ctx context.Context, generation int) error
+	AbortRebuild(ctx context.Context, generation int) error
+	DeleteOtherGenerations(ctx context.Context, keep int) error
+}
+
+var ErrRebuildRunning = errors.New("recommendation rebuild is already running")
+
+const (
+	// item lưu generation, nằm trong bảng co-purchase
+	generationMetaKey = "#generation"
+	// consumer đọc lại generation sau khoảng này, rebuild chờ đủ lâu để mọi replica bắt đầu ghi song song
+	RecommendationGenerationTTL = 5 * time.Second
+	// rebuild bị kill giữa chừng thì sau khoảng này được bắt đầu lại
+	staleRebuildAfter = 2 * time.Hour
+)
+
+// generationKey: partition key theo generation, generation 0 là dữ liệu cũ không có tiền tố
+func generationKey(generation int, id string) string {
+	if generation == 0 {
+		return id
+	}
+	return strconv.Itoa(generation) + "#" + id
+}
+
+type RecommendationRepositoryImpl struct {
+	client          *dynamodb.Client
+	coPurchaseTable string
+	affinityTable   string
+
+	genMu       sync.Mutex
+	gen         models.RecommendationGeneration
+	genLoadedAt time.Time
+}
+
+func NewRecommendationRepository(client *dynamodb.Client, coPurchaseTable, affinityTable string) RecommendationRepository {
+	return &RecommendationRepositoryImpl{
+		client:          client,
+		coPurchaseTable: coPurchaseTable,
+		affinityTable:   affinityTable,
+	}
+}
+
+// AddCoPurchase ghi vào generation đang đọc, và cả generation đang rebuild nếu có
+func (r *RecommendationRepositoryImpl) AddCoPurchase(ctx context.Context, productID, relatedID string, delta int) error {
+	gens, err := r.writeGenerations(ctx)
+	if err != nil {
+		return err
+	}
+	for _, g := range gens {
+		if err := r.addCoPurchase(ctx, g, productID, relatedID, delta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RecommendationRepositoryImpl) AddRebuildCoPurchase(ctx context.Context, generation int, productID, relatedID string, delta int) error {
+	return r.addCoPurchase(ctx, generation, productID, relatedID, delta)
+}
+
+func (r *RecommendationRepositoryImpl) addCoPurchase(ctx context.Context, generation int, productID, relatedID string, delta int) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.coPurchaseTable),
+		Key: map[string]types.AttributeValue{
+			"product_id": &types.AttributeValueMemberS{Value: generationKey(generation, productID)},
+			"related_id": &types.AttributeValueMemberS{Value: relatedID},
+		},
+		UpdateExpression: aws.String("ADD #count :delta SET updated_at = :time"),
+		ExpressionAttributeNames: map[string]string{
+			"#count": "count",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":delta": &types.AttributeValueMemberN{Value: strconv.Itoa(delta)},
+			":time":  &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339Nano)},
+		},
+	})
+	return err
+}
+
+// TopCoPurchased đọc toàn bộ related của một sản phẩm rồi sort theo count.
+// Số related mỗi sản phẩm thường nhỏ nên không cần index theo count.
+func (r *RecommendationRepositoryImpl) TopCoPurchased(ctx context.Context, productID string, limit int) ([]models.CoPurchase, error) {
+	gen, err := r.generation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	paginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.coPurchaseTable),
+		KeyConditionExpression: aws.String("product_id = :pid"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pid": &types.AttributeValueMemberS{Value: generationKey(gen.Current, productID)},
+		},
+	})
+
+	var items []models.CoPurchase
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var batch []models.CoPurchase
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &batch); err != nil {
+			return nil, err
+		}
+		for _, item := range batch {
+			if item.Count > 0 {
+				item.ProductID = productID
+				items = append(items, item)
+			}
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Count > items[j].Count
+	})
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (r *RecommendationRepositoryImpl) AddUserAffinity(ctx context.Context, userID, productID string, delta int, at time.Time) error {
+	gens, err := r.writeGenerations(ctx)
+	if err != nil {
+		return err
+	}
+	for _, g := range gens {
+		if err := r.addUserAffinity(ctx, g, userID, productID, delta, at, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddRebuildUserAffinity không lùi last_purchased_at mà consumer đã ghi cho đơn mới hơn
+func (r *RecommendationRepositoryImpl) AddRebuildUserAffinity(ctx context.Context, generation int, userID, productID string, delta int, at time.Time) error {
+	err := r.addUserAffinity(ctx, generation, userID, productID, delta, at, true)
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return r.addUserAffinityCount(ctx, generation, userID, productID, delta)
+	}
+	return err
+}
+
+func (r *RecommendationRepositoryImpl) affinityKey(generation int, userID, productID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"user_id":    &types.AttributeValueMemberS{Value: generationKey(generation, userID)},
+		"product_id": &types.AttributeValueMemberS{Value: productID},
+	}
+}
+
+func (r *RecommendationRepositoryImpl) addUserAffinity(ctx context.Context, generation int, userID, productID string, delta int, at time.Time, onlyNewer bool) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName:        aws.String(r.affinityTable),
+		Key:              r.affinityKey(generation, userID, productID),
+		UpdateExpression: aws.String("ADD #count :delta SET last_purchased_at = :time"),
+		ExpressionAttributeNames: map[string]string{
+			"#count": "count",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":delta": &types.AttributeValueMemberN{Value: strconv.Itoa(delta)},
+			":time":  &types.AttributeValueMemberS{Value: at.UTC().Format(time.RFC3339Nano)},
+		},
+	}
+	if onlyNewer {
+		input.ConditionExpression = aws.String("attribute_not_exists(last_purchased_at) OR last_purchased_at < :time")
+	}
+	_, err := r.client.UpdateItem(ctx, input)
+	return err
+}
+
+func (r *RecommendationRepositoryImpl) addUserAffinityCount(ctx context.Context, generation int, userID, productID string, delta int) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String(r.affinityTable),
+		Key:              r.affinityKey(generation, userID, productID),
+		UpdateExpression: aws.String("ADD #count :delta"),
+		ExpressionAttributeNames: map[string]string{
+			"#count": "count",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":delta": &types.AttributeValueMemberN{Value: strconv.Itoa(delta)},
+		},
+	})
+	return err
+}
+
+func (r *RecommendationRepositoryImpl) GetUserAffinity(ctx context.Context, userID string) ([]models.UserAffinity, error) {
+	gen, err := r.generation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	paginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.affinityTable),
+		KeyConditionExpression: aws.String("user_id = :uid"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":uid": &types.AttributeValueMemberS{Value: generationKey(gen.Current, userID)},
+		},
+	})
+
+	var items []models.UserAffinity
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var batch []models.UserAffinity
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &batch); err != nil {
+			return nil, err
+		}
+		for i := range batch {
+			batch[i].UserID = userID
+		}
+		items = append(items, batch...)
+	}
+	return items, nil
+}
+
+func (r *RecommendationRepositoryImpl) metaKey() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"product_id": &types.AttributeValueMemberS{Value: generationMetaKey},
+		"related_id": &types.AttributeValueMemberS{Value: generationMetaKey},
+	}
+}
+
+func (r *RecommendationRepositoryImpl) loadGeneration(ctx context.Context) (models.RecommendationGeneration, bool, error) {
+	var gen models.RecommendationGeneration
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(r.coPurchaseTable),
+		Key:            r.metaKey(),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return gen, false, err
+	}
+	if out.Item == nil {
+		return gen, false, nil
+	}
+	err = attributevalue.UnmarshalMap(out.Item, &gen)
+	return gen, true, err
+}
+
+// generation được cache RecommendationGenerationTTL, đọc lỗi thì không đoán generation để khỏi ghi nhầm chỗ
+func (r *RecommendationRepositoryImpl) generation(ctx context.Context) (models.RecommendationGeneration, error) {
+	r.genMu.Lock()
+	if !r.genLoadedAt.IsZero() && time.Since(r.genLoadedAt) < RecommendationGenerationTTL {
+		gen := r.gen
+		r.genMu.Unlock()
+		return gen, nil
+	}
+	r.genMu.Unlock()
+
+	gen, _, err := r.loadGeneration(ctx)
+	if err != nil {
+		return gen, err
+	}
+
+	r.genMu.Lock()
+	defer r.genMu.Unlock()
+	r.gen = gen
+	r.genLoadedAt = time.Now()
+	return gen, nil
+}
+
+func (r *RecommendationRepositoryImpl) writeGenerations(ctx context.Context) ([]int, error) {
+	gen, err := r.generation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gens := []int{gen.Current}
+	if gen.Next != 0 && gen.Next != gen.Current {
+		gens = append(gens, gen.Next)
+	}
+	return gens, nil
+}
+
+// BeginRebuild tạo generation mới để consumer bắt đầu ghi song song, trả về số generation
+func (r *RecommendationRepositoryImpl) BeginRebuild(ctx context.Context) (int, error) {
+	current, exists, err := r.loadGeneration(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if current.Next != 0 && time.Since(current.UpdatedAt) < staleRebuildAfter {
+		return 0, ErrRebuildRunning
+	}
+
+	next := current.Current + 1
+	if current.Next >= next {
+		next = current.Next + 1
+	}
+	item, err := attributevalue.MarshalMap(models.RecommendationGeneration{
+		Current:   current.Current,
+		Next:      next,
+		UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	for k, v := range r.metaKey() {
+		item[k] = v
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(r.coPurchaseTable),
+		Item:      item,
+	}
+	if exists {
+		input.ConditionExpression = aws.String("#current = :current AND #next = :next")
+		input.ExpressionAttributeNames = map[string]string{"#current": "current", "#next": "next"}
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":current": &types.AttributeValueMemberN{Value: strconv.Itoa(current.Current)},
+			":next":    &types.AttributeValueMemberN{Value: strconv.Itoa(current.Next)},
+		}
+	} else {
+		input.ConditionExpression = aws.String("attribute_not_exists(product_id)")
+	}
+
+	_, err = r.client.PutItem(ctx, input)
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return 0, ErrRebuildRunning
+	}
+	if err != nil {
+		return 0, err
+	}
+	return next, nil
+}
+
+// SwitchGeneration chuyển đọc sang generation đã rebuild xong và dừng ghi song song
+func (r *RecommendationRepositoryImpl) SwitchGeneration(ctx context.Context, generation int) error {
+	return r.finishRebuild(ctx, generation, "SET #current = :gen, #next = :zero, updated_at = :time")
+}
+
+// AbortRebuild dừng ghi song song, dữ liệu dở dang của generation đó bị xoá ở lần rebuild sau
+func (r *RecommendationRepositoryImpl) AbortRebuild(ctx context.Context, generation int) error {
+	return r.finishRebuild(ctx, generation, "SET #next = :zero, updated_at = :time")
+}
+
+func (r *RecommendationRepositoryImpl) finishRebuild(ctx context.Context, generation int, update string) error {
+	names := map[string]string{"#next": "next"}
+	if strings.Contains(update, "#current") {
+		names["#current"] = "current"
+	}
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                aws.String(r.coPurchaseTable),
+		Key:                      r.metaKey(),
+		UpdateExpression:         aws.String(update),
+		ConditionExpression:      aws.String("#next = :gen"),
+		ExpressionAttributeNames: names,
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":gen":  &types.AttributeValueMemberN{Value: strconv.Itoa(generation)},
+			":zero": &types.AttributeValueMemberN{Value: "0"},
+			":time": &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339Nano)},
+		},
+	})
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return fmt.Errorf("generation %d is no longer being rebuilt", generation)
+	}
+	return err
+}
+
+// DeleteOtherGenerations xoá mọi item không thuộc generation keep (trừ item generation)
+func (r *RecommendationRepositoryImpl) DeleteOtherGenerations(ctx context.Context, keep int) error {
+	if err := r.deleteOtherGenerations(ctx, r.coPurchaseTable, "product_id", "related_id", keep); err != nil {
+		return err
+	}
+	return r.deleteOtherGenerations(ctx, r.affinityTable, "user_id", "product_id", keep)
+}
+
+func (r *RecommendationRepositoryImpl) deleteOtherGenerations(ctx context.Context, table, hashKey, rangeKey string, keep int) error {
+	paginator := dynamodb.NewScanPaginator(r.client, &dynamodb.ScanInput{
+		TableName:            aws.String(table),
+		ProjectionExpression: aws.String("#h, #r"),
+		ExpressionAttributeNames: map[string]string{
+			"#h": hashKey,
+			"#r": rangeKey,
+		},
+	})
+
+	prefix := generationKey(keep, "")
+	deleted := 0
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+
+		var requests []types.WriteRequest
+		for _, key := range page.Items {
+			h, _ := key[hashKey].(*types.AttributeValueMemberS)
+			if h == nil || h.Value == generationMetaKey || keepsGeneration(h.Value, keep, prefix) {
+				continue
+			}
+			requests = append(requests, types.WriteRequest{
+				DeleteRequest: &types.DeleteRequest{Key: key},
+			})
+		}
+
+		// BatchWriteItem tối đa 25 request mỗi lần
+		for start := 0; start < len(requests); start += 25 {
+			end := start + 25
+			if end > len(requests) {
+				end = len(requests)
+			}
+			if err := r.batchWrite(ctx, table, requests[start:end]); err != nil {
+				return err
+			}
+			deleted += end - start
+		}
+	}
+	logger.Info("Deleted old recommendation generations from " + table + ", deleted " + strconv.Itoa(deleted) + " items")
+	return nil
+}
+
+// generation 0 không có tiền tố: key nào không có dạng "<số>#" là của generation 0
+func keepsGeneration(key string, keep int, prefix string) bool {
+	if keep != 0 {
+		return strings.HasPrefix(key, prefix)
+	}
+	i := strings.IndexByte(key, '#')
+	if i <= 0 {
+		return true
+	}
+	_, err := strconv.Atoi(key[:i])
+	return err != nil
+}
+
+func (r *RecommendationRepositoryImpl) batchWrite(ctx context.Context, table string, requests []types.WriteRequest) error {
+	pending := map[string][]types.WriteRequest{table: requests}
+	for attempt := 0; attempt < 5 && len(pending[table]) > 0; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt*100) * time.Millisecond)
+		}
+		out, err := r.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: pending,
+		})
+		if err != nil {
+			return err
+		}
+		pending = out.UnprocessedItems
+	}
+	return nil
+}
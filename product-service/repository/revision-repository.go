@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"product-service/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var (
+	ErrRevisionNotFound = fmt.Errorf("revision not found")
+	ErrRevisionExists   = fmt.Errorf("revision already exists")
+)
+
+type RevisionRepository interface {
+	Insert(ctx context.Context, revision models.ProductRevision) error
+	Latest(ctx context.Context, productID string) (*models.ProductRevision, error)
+	Get(ctx context.Context, productID string, revision int) (*models.ProductRevision, error)
+	List(ctx context.Context, productID string, limit int) ([]models.ProductRevision, error)
+}
+
+type RevisionRepositoryImpl struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+// Bảng revision: PK product_id, SK revision (number)
+func NewRevisionRepository(client *dynamodb.Client, tableName string) RevisionRepository {
+	return &RevisionRepositoryImpl{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+// Insert dùng condition để hai lần sửa đồng thời không ghi đè cùng số revision
+func (r *RevisionRepositoryImpl) Insert(ctx context.Context, revision models.ProductRevision) error {
+	item, err := attributevalue.MarshalMap(revision)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(revision)"),
+	})
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return ErrRevisionExists
+	}
+	return err
+}
+
+func (r *RevisionRepositoryImpl) Latest(ctx context.Context, productID string) (*models.ProductRevision, error) {
+	revisions, err := r.List(ctx, productID, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, ErrRevisionNotFound
+	}
+	return &revisions[0], nil
+}
+
+func (r *RevisionRepositoryImpl) Get(ctx context.Context, productID string, revision int) (*models.ProductRevision, error) {
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"product_id": &types.AttributeValueMemberS{Value: productID},
+			"revision":   &types.AttributeValueMemberN{Value: strconv.Itoa(revision)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.Item == nil {
+		return nil, ErrRevisionNotFound
+	}
+	var rev models.ProductRevision
+	if err := attributevalue.UnmarshalMap(out.Item, &rev); err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// List trả về revision mới nhất trước
+func (r *RevisionRepositoryImpl) List(ctx context.Context, productID string, limit int) ([]models.ProductRevision, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("product_id = :pid"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pid": &types.AttributeValueMemberS{Value: productID},
+		},
+		ScanIndexForward: aws.Bool(false),
+	}
+	if limit > 0 {
+		input.Limit = aws.Int32(int32(limit))
+	}
+
+	out, err := r.client.Query(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	var revisions []models.ProductRevision
+	if err := attributevalue.UnmarshalListOfMaps(out.Items, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
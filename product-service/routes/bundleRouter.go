@@ -0,0 +1,18 @@
+package routes
+
+import (
+	controller "product-service/controller"
+	"product-service/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func BundleRoutes(incomingRoutes *gin.Engine, bundleSvc service.BundleService) {
+	bundleController := controller.NewBundleController(bundleSvc)
+
+	// seller
+	incomingRoutes.POST("/products/bundles/add", bundleController.CreateBundle())
+
+	// public
+	incomingRoutes.GET("/products/get/:id/components", bundleController.GetBundleComponents())
+}
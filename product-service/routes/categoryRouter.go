@@ -0,0 +1,23 @@
+package routes
+
+import (
+	controller "product-service/controller"
+	"product-service/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func CategoryRoutes(incomingRoutes *gin.Engine, categorySvc service.CategoryService) {
+	categoryController := controller.NewCategoryController(categorySvc)
+
+	// public
+	incomingRoutes.GET("/categories/tree", categoryController.GetCategoryTree())
+	incomingRoutes.GET("/categories/:slug", categoryController.GetCategory())
+	incomingRoutes.GET("/categories/:slug/breadcrumbs", categoryController.GetBreadcrumbs())
+
+	// admin
+	incomingRoutes.POST("/categories", categoryController.CreateCategory())
+	incomingRoutes.PUT("/categories/:id", categoryController.UpdateCategory())
+	incomingRoutes.DELETE("/categories/:id", categoryController.DeleteCategory())
+	incomingRoutes.POST("/categories/migrate", categoryController.MigrateLegacyCategories())
+}
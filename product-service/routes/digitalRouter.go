@@ -0,0 +1,17 @@
+package routes
+
+import (
+	controller "product-service/controller"
+	"product-service/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func DigitalRoutes(incomingRoutes *gin.Engine, digitalSvc service.DigitalService) {
+	digitalController := controller.NewDigitalController(digitalSvc)
+
+	// seller
+	incomingRoutes.POST("/products/digital/upload-url", digitalController.CreateUploadURL())
+	incomingRoutes.POST("/products/digital/add", digitalController.CreateDigitalProduct())
+	incomingRoutes.POST("/products/digital/:id/license-keys", digitalController.AddLicenseKeys())
+}
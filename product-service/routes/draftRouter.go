@@ -0,0 +1,20 @@
+package routes
+
+import (
+	controller "product-service/controller"
+	"product-service/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func DraftRoutes(incomingRoutes *gin.Engine, draftSvc service.DraftService) {
+	draftController := controller.NewDraftController(draftSvc)
+
+	// seller
+	incomingRoutes.POST("/products/drafts/add", draftController.CreateDraft())
+	incomingRoutes.GET("/products/drafts", draftController.ListDrafts())
+	incomingRoutes.GET("/products/drafts/:id", draftController.GetDraft())
+	incomingRoutes.PUT("/products/drafts/:id", draftController.UpdateDraft())
+	incomingRoutes.DELETE("/products/drafts/:id", draftController.DeleteDraft())
+	incomingRoutes.POST("/products/drafts/:id/publish", draftController.PublishDraft())
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	controller "product-service/controller"
+	"product-service/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func PricingRoutes(incomingRoutes *gin.Engine, pricingSvc service.PricingService) {
+	pricingController := controller.NewPricingController(pricingSvc)
+
+	// seller
+	incomingRoutes.POST("/products/pricing/:id/schedules", pricingController.SchedulePrice())
+	incomingRoutes.GET("/products/pricing/:id/schedules", pricingController.GetSchedules())
+	incomingRoutes.DELETE("/products/pricing/schedules/:schedule_id", pricingController.CancelSchedule())
+
+	// public
+	incomingRoutes.GET("/products/get/:id/price-history", pricingController.GetPriceHistory())
+}
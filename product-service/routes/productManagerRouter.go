@@ -34,8 +34,33 @@ func NewProductService() service.ProductService {
 		tableName = "product-table"
 	}
 
+	categoryTableName := os.Getenv("DYNAMODB_CATEGORY_TABLE")
+	if categoryTableName == "" {
+		categoryTableName = "category-table"
+	}
+
+	priceScheduleTableName := os.Getenv("DYNAMODB_PRICE_SCHEDULE_TABLE")
+	if priceScheduleTableName == "" {
+		priceScheduleTableName = "price-schedule-table"
+	}
+	priceHistoryTableName := os.Getenv("DYNAMODB_PRICE_HISTORY_TABLE")
+	if priceHistoryTableName == "" {
+		priceHistoryTableName = "price-history-table"
+	}
+
+	revisionTableName := os.Getenv("DYNAMODB_PRODUCT_REVISION_TABLE")
+	if revisionTableName == "" {
+		revisionTableName = "product-revision-table"
+	}
+
 	productRepo := repository.NewProductRepository(dynamoClient, tableName)
-	return service.NewProductService(productRepo, service.NewS3Service())
+	categorySvc := service.NewCategoryService(repository.NewCategoryRepository(dynamoClient, categoryTableName), productRepo)
+	pricingSvc := service.NewPricingService(productRepo,
+		repository.NewPriceScheduleRepository(dynamoClient, priceScheduleTableName),
+		repository.NewPriceHistoryRepository(dynamoClient, priceHistoryTableName),
+	)
+	return service.NewProductService(productRepo, categorySvc, pricingSvc, service.NewInventoryService(productRepo), service.NewS3Service(),
+		repository.NewRevisionRepository(dynamoClient, revisionTableName))
 }
 
 // Sửa function này để nhận productSvc từ main.go
@@ -63,6 +88,8 @@ func ProductManagerRoutes(incomingRoutes *gin.Engine, productSvc service.Product
 
 	authorized.GET("/products/get/category/:category", productController.GetProductByCategory())
 
+	authorized.GET("/products/cache/stats", controller.CacheStats())
+
 	// Static file server for images
 	incomingRoutes.StaticFS("/static-images", gin.Dir("product-service/uploads/images", true))
 }
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	controller "product-service/controller"
+	"product-service/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RecommendationRoutes(incomingRoutes *gin.Engine, recommendationSvc service.RecommendationService) {
+	recommendationController := controller.NewRecommendationController(recommendationSvc)
+
+	// public
+	incomingRoutes.GET("/products/:id/recommendations", recommendationController.GetProductRecommendations())
+
+	// user
+	incomingRoutes.GET("/recommendations/for-you", recommendationController.GetForYou())
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	controller "product-service/controller"
+	"product-service/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RevisionRoutes(incomingRoutes *gin.Engine, revisionSvc service.RevisionService) {
+	revisionController := controller.NewRevisionController(revisionSvc)
+
+	// seller
+	incomingRoutes.GET("/products/revisions/:id", revisionController.ListRevisions())
+	incomingRoutes.GET("/products/revisions/:id/diff", revisionController.DiffRevisions())
+	incomingRoutes.POST("/products/revisions/:id/rollback/:revision", revisionController.Rollback())
+}
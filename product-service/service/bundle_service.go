@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"product-service/models"
+	"product-service/repository"
+)
+
+type BundleService interface {
+	CreateBundle(ctx context.Context, sellerID string, req models.CreateBundleRequest) error
+	GetBundleComponents(ctx context.Context, bundleID string) ([]models.Product, error)
+	models.BundleStockResolver
+}
+
+type bundleServiceImpl struct {
+	repo        repository.ProductRepository
+	allocations repository.BundleAllocationRepository
+	products    ProductService
+}
+
+func NewBundleService(repo repository.ProductRepository, allocations repository.BundleAllocationRepository, products ProductService) BundleService {
+	return &bundleServiceImpl{repo: repo, allocations: allocations, products: products}
+}
+
+// CreateBundle: thành phần phải là sản phẩm thường của chính seller, không lồng bundle trong bundle
+func (s *bundleServiceImpl) CreateBundle(ctx context.Context, sellerID string, req models.CreateBundleRequest) error {
+	if len(req.Items) < models.MinBundleComponents || len(req.Items) > models.MaxBundleComponents {
+		return &ValidationError{Field: "items", Message: fmt.Sprintf("a bundle needs between %d and %d products", models.MinBundleComponents, models.MaxBundleComponents)}
+	}
+
+	ids := make([]string, 0, len(req.Items))
+	seen := make(map[string]bool, len(req.Items))
+	for _, item := range req.Items {
+		if seen[item.ProductID] {
+			return &ValidationError{Field: "items", Message: fmt.Sprintf("product %s is listed more than once", item.ProductID)}
+		}
+		seen[item.ProductID] = true
+		ids = append(ids, item.ProductID)
+	}
+
+	components, err := s.repo.FindByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]models.Product, len(components))
+	for _, c := range components {
+		byID[c.ID] = c
+	}
+	for _, id := range ids {
+		c, ok := byID[id]
+		switch {
+		case !ok:
+			return &ValidationError{Field: "items", Message: fmt.Sprintf("product %s not found", id)}
+		case c.UserID != sellerID:
+			return &ValidationError{Field: "items", Message: fmt.Sprintf("product %s does not belong to you", id)}
+		case c.IsBundle():
+			return &ValidationError{Field: "items", Message: fmt.Sprintf("product %s is already a bundle", id)}
+		}
+	}
+
+	bundle := models.Product{
+		Name:        req.Name,
+		ImagePath:   req.ImagePath,
+		Category:    req.Category,
+		Attributes:  req.Attributes,
+		Description: req.Description,
+		Price:       req.Price,
+		Status:      req.Status,
+		UserID:      sellerID,
+		Type:        models.ProductTypeBundle,
+		BundleItems: req.Items,
+		// giá trị lúc tạo, khi đọc sẽ được tính lại từ tồn kho thành phần
+		Quantity: bundleAvailability(req.Items, byID),
+	}
+	return s.products.AddProduct(ctx, bundle)
+}
+
+func (s *bundleServiceImpl) GetBundleComponents(ctx context.Context, bundleID string) ([]models.Product, error) {
+	bundle, err := s.products.GetProductByID(ctx, bundleID)
+	if err != nil {
+		return nil, err
+	}
+	if !bundle.IsBundle() {
+		return nil, &ValidationError{Field: "id", Message: "product is not a bundle"}
+	}
+
+	ids := make([]string, 0, len(bundle.BundleItems))
+	for _, c := range bundle.BundleItems {
+		ids = append(ids, c.ProductID)
+	}
+	return s.products.GetProductsCached(ctx, ids)
+}
+
+// ExpandOrderItems chạy khi nhận order_success: lưu thành phần của từng bundle theo đơn
+// rồi trả về danh sách item cần trừ kho (bundle được thay bằng các thành phần)
+func (s *bundleServiceImpl) ExpandOrderItems(ctx context.Context, orderID string, items []models.StockUpdateItem) ([]models.StockUpdateItem, error) {
+	bundles, err := s.findBundles(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+	if len(bundles) == 0 {
+		return items, nil
+	}
+
+	var existing map[string]models.BundleAllocation
+	expanded := make([]models.StockUpdateItem, 0, len(items))
+	for _, item := range items {
+		bundle, ok := bundles[item.ProductID]
+		if !ok {
+			expanded = append(expanded, item)
+			continue
+		}
+
+		err := s.allocations.Insert(ctx, models.BundleAllocation{
+			OrderID:    orderID,
+			BundleID:   bundle.ID,
+			Quantity:   item.Quantity,
+			Components: bundle.BundleItems,
+			Pending:    true,
+		})
+		if errors.Is(err, repository.ErrAllocationExists) {
+			if existing == nil {
+				if existing, err = s.orderAllocations(ctx, orderID); err != nil {
+					return nil, err
+				}
+			}
+			allocation := existing[bundle.ID]
+			if !allocation.Pending {
+				// event bị gửi lại, thành phần đã được trừ kho ở lần trước
+				log.Printf("Bundle %s of order %s already allocated, skipping", bundle.ID, orderID)
+				continue
+			}
+			// lần trước lỗi giữa chừng, chỉ trừ tiếp các thành phần chưa trừ
+			_, remaining := splitApplied(allocation)
+			expanded = append(expanded, componentItems(bundle.ID, remaining, allocation.Quantity)...)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, componentItems(bundle.ID, bundle.BundleItems, item.Quantity)...)
+	}
+	return expanded, nil
+}
+
+func (s *bundleServiceImpl) orderAllocations(ctx context.Context, orderID string) (map[string]models.BundleAllocation, error) {
+	allocations, err := s.allocations.FindByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	byBundle := make(map[string]models.BundleAllocation, len(allocations))
+	for _, a := range allocations {
+		byBundle[a.BundleID] = a
+	}
+	return byBundle, nil
+}
+
+func (s *bundleServiceImpl) MarkComponentApplied(ctx context.Context, orderID string, item models.StockUpdateItem) error {
+	if item.BundleID == "" {
+		return nil
+	}
+	return s.allocations.MarkComponentApplied(ctx, orderID, item.BundleID, item.ProductID)
+}
+
+func (s *bundleServiceImpl) MarkOrderAllocated(ctx context.Context, orderID string) error {
+	allocations, err := s.allocations.FindByOrderID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	for _, a := range allocations {
+		if !a.Pending {
+			continue
+		}
+		if err := s.allocations.MarkApplied(ctx, orderID, a.BundleID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExpandReturnedItems dùng thành phần đã lưu lúc đặt hàng, không phụ thuộc bundle hiện tại
+func (s *bundleServiceImpl) ExpandReturnedItems(ctx context.Context, orderID string, items []models.StockUpdateItem) ([]models.StockUpdateItem, error) {
+	byBundle, err := s.orderAllocations(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	expanded := make([]models.StockUpdateItem, 0, len(items))
+	for _, item := range items {
+		allocation, ok := byBundle[item.ProductID]
+		if !ok {
+			expanded = append(expanded, item)
+			continue
+		}
+
+		err := s.allocations.MarkRestocked(ctx, orderID, allocation.BundleID)
+		if errors.Is(err, repository.ErrAlreadyRestocked) {
+			log.Printf("Bundle %s of order %s already restocked, skipping", allocation.BundleID, orderID)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		components := allocation.Components
+		if allocation.Pending {
+			// đơn chưa trừ kho xong, chỉ cộng lại những thành phần đã thực sự bị trừ
+			components, _ = splitApplied(allocation)
+		}
+		expanded = append(expanded, componentItems(allocation.BundleID, components, allocation.Quantity)...)
+	}
+	return expanded, nil
+}
+
+func (s *bundleServiceImpl) findBundles(ctx context.Context, items []models.StockUpdateItem) (map[string]models.Product, error) {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+	products, err := s.repo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	bundles := make(map[string]models.Product)
+	for _, p := range products {
+		if p.IsBundle() {
+			bundles[p.ID] = p
+		}
+	}
+	return bundles, nil
+}
+
+func componentItems(bundleID string, components []models.BundleComponent, bundleQuantity int) []models.StockUpdateItem {
+	items := make([]models.StockUpdateItem, 0, len(components))
+	for _, c := range components {
+		items = append(items, models.StockUpdateItem{
+			ProductID: c.ProductID,
+			Quantity:  c.Quantity * bundleQuantity,
+			BundleID:  bundleID,
+		})
+	}
+	return items
+}
+
+// splitApplied tách thành phần của allocation thành phần đã trừ kho và phần chưa trừ
+func splitApplied(allocation models.BundleAllocation) (applied, remaining []models.BundleComponent) {
+	done := make(map[string]bool, len(allocation.Applied))
+	for _, id := range allocation.Applied {
+		done[id] = true
+	}
+	for _, c := range allocation.Components {
+		if done[c.ProductID] {
+			applied = append(applied, c)
+		} else {
+			remaining = append(remaining, c)
+		}
+	}
+	return applied, remaining
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"product-service/kafka"
+	"product-service/models"
+)
+
+// bundleAvailability: số bundle bán được = min(tồn kho thành phần / số lượng trong bundle).
+// Thiếu thành phần hoặc thành phần không còn mở bán thì bundle hết hàng
+func bundleAvailability(components []models.BundleComponent, stock map[string]models.Product) int {
+	if len(components) == 0 {
+		return 0
+	}
+	available := -1
+	for _, c := range components {
+		p, ok := stock[c.ProductID]
+		if !ok || c.Quantity <= 0 || (p.Status != "" && p.Status != "onsale") {
+			return 0
+		}
+		n := p.Quantity / c.Quantity
+		if available < 0 || n < available {
+			available = n
+		}
+	}
+	if available < 0 {
+		return 0
+	}
+	return available
+}
+
+// applyBundleStock ghi đè quantity của các bundle trong danh sách bằng tồn kho tính từ thành phần.
+// fresh = true thì đọc thẳng DB (dùng cho check tồn kho khi đặt hàng), ngược lại đọc qua cache
+func (s *productServiceImpl) applyBundleStock(ctx context.Context, products []models.Product, fresh bool) error {
+	seen := make(map[string]bool)
+	var componentIDs []string
+	for i := range products {
+		if !products[i].IsBundle() {
+			continue
+		}
+		for _, c := range products[i].BundleItems {
+			if !seen[c.ProductID] {
+				seen[c.ProductID] = true
+				componentIDs = append(componentIDs, c.ProductID)
+			}
+		}
+	}
+	if len(componentIDs) == 0 {
+		return nil
+	}
+
+	var components []models.Product
+	var err error
+	if fresh {
+		components, err = s.repo.FindByIDs(ctx, componentIDs)
+	} else {
+		components, err = s.loadProductsCached(ctx, componentIDs)
+	}
+	if err != nil {
+		return err
+	}
+
+	stock := make(map[string]models.Product, len(components))
+	for _, c := range components {
+		stock[c.ID] = c
+	}
+	for i := range products {
+		if products[i].IsBundle() {
+			products[i].Quantity = bundleAvailability(products[i].BundleItems, stock)
+		}
+	}
+	return nil
+}
+
+// syncBundles chạy sau khi tồn kho / trạng thái của một sản phẩm thường đổi: số bundle bán được
+// cũng đổi theo nên ghi lại quantity của các bundle chứa nó và bắn product-events "updated"
+// (search-service không tự tính lại bundle khi thành phần đổi)
+func (s *productServiceImpl) syncBundles(component models.Product) {
+	if component.IsBundle() || component.UserID == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	bundles, err := s.repo.FindBundlesByComponent(ctx, component.UserID, component.ID)
+	if err != nil {
+		log.Printf("Error finding bundles of product %s: %v", component.ID, err)
+		return
+	}
+	if len(bundles) == 0 {
+		return
+	}
+	stored := make([]int, len(bundles))
+	for i := range bundles {
+		stored[i] = bundles[i].Quantity
+	}
+	if err := s.applyBundleStock(ctx, bundles, true); err != nil {
+		log.Printf("Error computing stock of bundles containing %s: %v", component.ID, err)
+		return
+	}
+
+	for i, bundle := range bundles {
+		if bundle.Quantity == stored[i] {
+			continue
+		}
+		if err := s.repo.Update(ctx, bundle.ID, map[string]interface{}{"quantity": bundle.Quantity}); err != nil {
+			log.Printf("Error syncing stock of bundle %s: %v", bundle.ID, err)
+			continue
+		}
+		s.cache.InvalidateProduct(ctx, bundle.ID, false)
+
+		// đọc lại để event mang updated_at của lần ghi này
+		updated, err := s.repo.FindByID(ctx, bundle.ID)
+		if err != nil {
+			log.Printf("Error reloading bundle %s: %v", bundle.ID, err)
+			continue
+		}
+		updated.Quantity = bundle.Quantity
+		_ = kafka.ProduceProductEvent(context.Background(), "updated", updated, updated.ID)
+	}
+}
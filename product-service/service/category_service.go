@@ -0,0 +1,594 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"product-service/cache"
+	"product-service/helper"
+	"product-service/kafka"
+	"product-service/models"
+	"product-service/repository"
+)
+
+var (
+	ErrCategorySlugTaken   = errors.New("category slug already exists")
+	ErrCategoryHasChildren = errors.New("category still has child categories")
+	ErrCategoryInUse       = errors.New("category still has products")
+	ErrCategoryCycle       = errors.New("category cannot be moved under itself or its descendants")
+)
+
+const categoryTreeTTL = 30 * time.Minute
+
+// ValidationError được controller trả về dưới dạng 400
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+type CategoryService interface {
+	CreateCategory(ctx context.Context, req models.CreateCategoryRequest) (*models.Category, error)
+	UpdateCategory(ctx context.Context, id string, req models.UpdateCategoryRequest) (*models.Category, error)
+	DeleteCategory(ctx context.Context, id string) error
+	GetCategory(ctx context.Context, idOrSlug string) (*models.Category, error)
+	ResolveCategory(ctx context.Context, value string) (*models.Category, error)
+	GetCategoryTree(ctx context.Context) ([]*models.CategoryNode, error)
+	GetBreadcrumbs(ctx context.Context, idOrSlug string) ([]models.Breadcrumb, error)
+	GetDescendants(ctx context.Context, id string) ([]models.Category, error)
+	GetAttributeSchema(ctx context.Context, category *models.Category) ([]models.AttributeDefinition, error)
+	ValidateAttributes(ctx context.Context, category *models.Category, attrs map[string]interface{}) (map[string]interface{}, error)
+	MigrateLegacyCategories(ctx context.Context, dryRun, createMissing bool) (*models.CategoryMigrationReport, error)
+}
+
+type categoryServiceImpl struct {
+	repo        repository.CategoryRepository
+	productRepo repository.ProductRepository
+	cache       *cache.Cache
+}
+
+func NewCategoryService(repo repository.CategoryRepository, productRepo repository.ProductRepository) CategoryService {
+	return &categoryServiceImpl{repo: repo, productRepo: productRepo, cache: cache.Default()}
+}
+
+func (s *categoryServiceImpl) CreateCategory(ctx context.Context, req models.CreateCategoryRequest) (*models.Category, error) {
+	slug := helper.Slugify(req.Slug)
+	if slug == "" {
+		slug = helper.Slugify(req.Name)
+	}
+	if slug == "" {
+		return nil, &ValidationError{Field: "slug", Message: "cannot build slug from name"}
+	}
+	if _, err := s.repo.FindBySlug(ctx, slug); err == nil {
+		return nil, ErrCategorySlugTaken
+	} else if !errors.Is(err, repository.ErrCategoryNotFound) {
+		return nil, err
+	}
+
+	if err := validateAttributeDefinitions(req.Attributes); err != nil {
+		return nil, err
+	}
+
+	category := models.Category{
+		Name:        strings.TrimSpace(req.Name),
+		Slug:        slug,
+		Description: req.Description,
+		Path:        []string{},
+		Attributes:  req.Attributes,
+	}
+	if category.Attributes == nil {
+		category.Attributes = []models.AttributeDefinition{}
+	}
+
+	if req.ParentID != "" {
+		parent, err := s.repo.FindByID(ctx, req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		category.ParentID = parent.ID
+		category.Path = append(append([]string{}, parent.Path...), parent.ID)
+		category.Level = parent.Level + 1
+	}
+
+	created, err := s.repo.Insert(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateTreeCache()
+	return created, nil
+}
+
+func (s *categoryServiceImpl) UpdateCategory(ctx context.Context, id string, req models.UpdateCategoryRequest) (*models.Category, error) {
+	category, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		category.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		category.Description = *req.Description
+	}
+	if req.Attributes != nil {
+		if err := validateAttributeDefinitions(*req.Attributes); err != nil {
+			return nil, err
+		}
+		category.Attributes = *req.Attributes
+	}
+	// Đổi slug sẽ không tự cập nhật product cũ, cần chạy lại migrate
+	if req.Slug != nil {
+		slug := helper.Slugify(*req.Slug)
+		if slug == "" {
+			return nil, &ValidationError{Field: "slug", Message: "invalid slug"}
+		}
+		if slug != category.Slug {
+			if _, err := s.repo.FindBySlug(ctx, slug); err == nil {
+				return nil, ErrCategorySlugTaken
+			} else if !errors.Is(err, repository.ErrCategoryNotFound) {
+				return nil, err
+			}
+			category.Slug = slug
+		}
+	}
+
+	moved := false
+	if req.ParentID != nil && *req.ParentID != category.ParentID {
+		newPath := []string{}
+		newLevel := 0
+		if *req.ParentID != "" {
+			parent, err := s.repo.FindByID(ctx, *req.ParentID)
+			if err != nil {
+				return nil, err
+			}
+			if parent.ID == category.ID || containsString(parent.Path, category.ID) {
+				return nil, ErrCategoryCycle
+			}
+			newPath = append(append(newPath, parent.Path...), parent.ID)
+			newLevel = parent.Level + 1
+		}
+		category.ParentID = *req.ParentID
+		category.Path = newPath
+		category.Level = newLevel
+		moved = true
+	}
+
+	if err := s.repo.Put(ctx, *category); err != nil {
+		return nil, err
+	}
+
+	if moved {
+		if err := s.rebuildDescendantPaths(ctx, category); err != nil {
+			return nil, err
+		}
+	}
+
+	s.invalidateTreeCache()
+	return category, nil
+}
+
+// rebuildDescendantPaths cập nhật path/level cho toàn bộ cây con sau khi đổi parent
+func (s *categoryServiceImpl) rebuildDescendantPaths(ctx context.Context, root *models.Category) error {
+	all, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	children := groupByParent(all)
+
+	queue := []models.Category{*root}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		for _, child := range children[parent.ID] {
+			child.Path = append(append([]string{}, parent.Path...), parent.ID)
+			child.Level = parent.Level + 1
+			if err := s.repo.Put(ctx, child); err != nil {
+				return err
+			}
+			queue = append(queue, child)
+		}
+	}
+	return nil
+}
+
+func (s *categoryServiceImpl) DeleteCategory(ctx context.Context, id string) error {
+	category, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	children, err := s.repo.FindChildren(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(children) > 0 {
+		return ErrCategoryHasChildren
+	}
+
+	total, err := s.productRepo.CountProductsByCategory(ctx, category.Slug)
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		return ErrCategoryInUse
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.invalidateTreeCache()
+	return nil
+}
+
+func (s *categoryServiceImpl) GetCategory(ctx context.Context, idOrSlug string) (*models.Category, error) {
+	category, err := s.repo.FindBySlug(ctx, idOrSlug)
+	if err == nil {
+		return category, nil
+	}
+	if !errors.Is(err, repository.ErrCategoryNotFound) {
+		return nil, err
+	}
+	return s.repo.FindByID(ctx, idOrSlug)
+}
+
+// ResolveCategory nhận id, slug hoặc tên hiển thị (không phân biệt hoa thường, có dấu hay không)
+func (s *categoryServiceImpl) ResolveCategory(ctx context.Context, value string) (*models.Category, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, repository.ErrCategoryNotFound
+	}
+
+	category, err := s.GetCategory(ctx, value)
+	if err == nil {
+		return category, nil
+	}
+	if !errors.Is(err, repository.ErrCategoryNotFound) {
+		return nil, err
+	}
+
+	slug := helper.Slugify(value)
+	if slug == "" || slug == value {
+		return nil, repository.ErrCategoryNotFound
+	}
+	return s.repo.FindBySlug(ctx, slug)
+}
+
+func (s *categoryServiceImpl) GetCategoryTree(ctx context.Context) ([]*models.CategoryNode, error) {
+	key := s.cache.ListKey(ctx, cache.NamespaceCategories, "tree")
+
+	var tree []*models.CategoryNode
+	_, err := s.cache.GetOrLoad(ctx, key, categoryTreeTTL, &tree, func(ctx context.Context) (interface{}, error) {
+		return s.buildCategoryTree(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+func (s *categoryServiceImpl) buildCategoryTree(ctx context.Context) ([]*models.CategoryNode, error) {
+	all, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*models.CategoryNode, len(all))
+	for _, c := range all {
+		nodes[c.ID] = &models.CategoryNode{Category: c, Children: []*models.CategoryNode{}}
+	}
+
+	roots := []*models.CategoryNode{}
+	for _, c := range all {
+		node := nodes[c.ID]
+		if parent, ok := nodes[c.ParentID]; ok && c.ParentID != "" {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	sortCategoryNodes(roots)
+
+	return roots, nil
+}
+
+func (s *categoryServiceImpl) GetBreadcrumbs(ctx context.Context, idOrSlug string) ([]models.Breadcrumb, error) {
+	category, err := s.GetCategory(ctx, idOrSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	breadcrumbs := make([]models.Breadcrumb, 0, len(category.Path)+1)
+	for _, ancestorID := range category.Path {
+		ancestor, err := s.repo.FindByID(ctx, ancestorID)
+		if err != nil {
+			return nil, err
+		}
+		breadcrumbs = append(breadcrumbs, models.Breadcrumb{ID: ancestor.ID, Name: ancestor.Name, Slug: ancestor.Slug})
+	}
+	breadcrumbs = append(breadcrumbs, models.Breadcrumb{ID: category.ID, Name: category.Name, Slug: category.Slug})
+	return breadcrumbs, nil
+}
+
+func (s *categoryServiceImpl) GetDescendants(ctx context.Context, id string) ([]models.Category, error) {
+	all, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var descendants []models.Category
+	for _, c := range all {
+		if containsString(c.Path, id) {
+			descendants = append(descendants, c)
+		}
+	}
+	return descendants, nil
+}
+
+// GetAttributeSchema trả về schema attribute của category, kế thừa từ các category cha
+func (s *categoryServiceImpl) GetAttributeSchema(ctx context.Context, category *models.Category) ([]models.AttributeDefinition, error) {
+	schema := []models.AttributeDefinition{}
+	seen := map[string]int{}
+
+	add := func(defs []models.AttributeDefinition) {
+		for _, def := range defs {
+			if idx, ok := seen[def.Key]; ok {
+				// category con được override định nghĩa của cha
+				schema[idx] = def
+				continue
+			}
+			seen[def.Key] = len(schema)
+			schema = append(schema, def)
+		}
+	}
+
+	for _, ancestorID := range category.Path {
+		ancestor, err := s.repo.FindByID(ctx, ancestorID)
+		if err != nil {
+			return nil, err
+		}
+		add(ancestor.Attributes)
+	}
+	add(category.Attributes)
+	return schema, nil
+}
+
+// ValidateAttributes kiểm tra attribute của product theo schema và chuẩn hoá kiểu dữ liệu
+func (s *categoryServiceImpl) ValidateAttributes(ctx context.Context, category *models.Category, attrs map[string]interface{}) (map[string]interface{}, error) {
+	schema, err := s.GetAttributeSchema(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+
+	defs := make(map[string]models.AttributeDefinition, len(schema))
+	for _, def := range schema {
+		defs[def.Key] = def
+	}
+
+	result := make(map[string]interface{}, len(attrs))
+	for key, raw := range attrs {
+		def, ok := defs[key]
+		if !ok {
+			return nil, &ValidationError{Field: "attributes." + key, Message: fmt.Sprintf("unknown attribute for category %s", category.Slug)}
+		}
+		value, err := normalizeAttributeValue(def, raw)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+
+	for _, def := range schema {
+		if _, ok := result[def.Key]; def.Required && !ok {
+			return nil, &ValidationError{Field: "attributes." + def.Key, Message: "is required"}
+		}
+	}
+	return result, nil
+}
+
+func (s *categoryServiceImpl) MigrateLegacyCategories(ctx context.Context, dryRun, createMissing bool) (*models.CategoryMigrationReport, error) {
+	report := &models.CategoryMigrationReport{
+		DryRun:            dryRun,
+		CreatedCategories: []string{},
+		Unmatched:         map[string]int{},
+		Mapping:           map[string]string{},
+		Errors:            []string{},
+	}
+
+	all, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bySlug := make(map[string]models.Category, len(all))
+	byName := make(map[string]models.Category, len(all))
+	for _, c := range all {
+		bySlug[c.Slug] = c
+		byName[strings.ToLower(c.Name)] = c
+	}
+
+	var pending []models.Product
+	err = s.productRepo.ScanAll(ctx, func(p models.Product) error {
+		report.Scanned++
+		if p.CategoryID != "" {
+			report.AlreadyMigrated++
+			return nil
+		}
+		pending = append(pending, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range pending {
+		raw := strings.TrimSpace(p.Category)
+		slug := helper.Slugify(raw)
+
+		category, ok := bySlug[slug]
+		if !ok {
+			category, ok = byName[strings.ToLower(raw)]
+		}
+		if !ok && createMissing && slug != "" {
+			category = models.Category{ID: "", Name: raw, Slug: slug, Path: []string{}, Attributes: []models.AttributeDefinition{}}
+			if !dryRun {
+				created, err := s.repo.Insert(ctx, category)
+				if err != nil {
+					report.Errors = append(report.Errors, fmt.Sprintf("create category %q: %v", raw, err))
+					continue
+				}
+				category = *created
+			}
+			bySlug[slug] = category
+			byName[strings.ToLower(raw)] = category
+			report.CreatedCategories = append(report.CreatedCategories, slug)
+			ok = true
+		}
+		if !ok {
+			report.Unmatched[raw]++
+			continue
+		}
+
+		report.Mapping[raw] = category.Slug
+		if dryRun {
+			report.Migrated++
+			continue
+		}
+
+		update := map[string]interface{}{
+			"category":    category.Slug,
+			"category_id": category.ID,
+		}
+		if err := s.productRepo.Update(ctx, p.ID, update); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("update product %s: %v", p.ID, err))
+			continue
+		}
+		report.Migrated++
+
+		p.Category = category.Slug
+		p.CategoryID = category.ID
+		p.Updated_at = time.Now()
+		s.cache.Set(ctx, cache.ProductKey(p.ID), p, cache.ProductTTL)
+		go func(p models.Product) {
+			_ = kafka.ProduceProductEvent(context.Background(), "updated", &p, p.ID)
+		}(p)
+	}
+
+	if !dryRun && (report.Migrated > 0 || len(report.CreatedCategories) > 0) {
+		s.invalidateTreeCache()
+	}
+
+	return report, nil
+}
+
+func (s *categoryServiceImpl) invalidateTreeCache() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.cache.BumpNamespace(ctx, cache.NamespaceCategories)
+}
+
+func validateAttributeDefinitions(defs []models.AttributeDefinition) error {
+	seen := map[string]bool{}
+	for _, def := range defs {
+		if def.Key == "" {
+			return &ValidationError{Field: "attributes", Message: "attribute key is required"}
+		}
+		if seen[def.Key] {
+			return &ValidationError{Field: "attributes." + def.Key, Message: "duplicated attribute key"}
+		}
+		seen[def.Key] = true
+
+		switch def.Type {
+		case models.AttributeTypeString, models.AttributeTypeNumber, models.AttributeTypeBoolean:
+		case models.AttributeTypeEnum:
+			if len(def.Options) == 0 {
+				return &ValidationError{Field: "attributes." + def.Key, Message: "enum attribute needs options"}
+			}
+		default:
+			return &ValidationError{Field: "attributes." + def.Key, Message: "unsupported type " + def.Type}
+		}
+	}
+	return nil
+}
+
+func normalizeAttributeValue(def models.AttributeDefinition, raw interface{}) (interface{}, error) {
+	field := "attributes." + def.Key
+	switch def.Type {
+	case models.AttributeTypeNumber:
+		switch v := raw.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		case string:
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, &ValidationError{Field: field, Message: "must be a number"}
+			}
+			return f, nil
+		}
+		return nil, &ValidationError{Field: field, Message: "must be a number"}
+	case models.AttributeTypeBoolean:
+		switch v := raw.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, &ValidationError{Field: field, Message: "must be a boolean"}
+			}
+			return b, nil
+		}
+		return nil, &ValidationError{Field: field, Message: "must be a boolean"}
+	case models.AttributeTypeEnum:
+		v, ok := raw.(string)
+		if !ok {
+			return nil, &ValidationError{Field: field, Message: "must be a string"}
+		}
+		for _, opt := range def.Options {
+			if strings.EqualFold(opt, v) {
+				return opt, nil
+			}
+		}
+		return nil, &ValidationError{Field: field, Message: "must be one of " + strings.Join(def.Options, ", ")}
+	default:
+		v, ok := raw.(string)
+		if !ok {
+			return nil, &ValidationError{Field: field, Message: "must be a string"}
+		}
+		return strings.TrimSpace(v), nil
+	}
+}
+
+func groupByParent(categories []models.Category) map[string][]models.Category {
+	children := make(map[string][]models.Category)
+	for _, c := range categories {
+		children[c.ParentID] = append(children[c.ParentID], c)
+	}
+	return children
+}
+
+func sortCategoryNodes(nodes []*models.CategoryNode) {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	for _, n := range nodes {
+		sortCategoryNodes(n.Children)
+	}
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
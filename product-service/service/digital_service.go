@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"product-service/models"
+	"product-service/repository"
+)
+
+// link tải file chỉ sống ngắn, mỗi lần tải order-service xin link mới và trừ lượt tải
+const digitalDownloadTTL = 15 * time.Minute
+
+type DigitalService interface {
+	CreateUploadURL(sellerID string, req UploadRequest) (models.PresignedUploadResponse, error)
+	CreateDigitalProduct(ctx context.Context, sellerID string, req models.CreateDigitalProductRequest) error
+	AddLicenseKeys(ctx context.Context, sellerID, productID string, keys []string) (int, error)
+	Fulfill(ctx context.Context, orderID, userID string, items []models.StockUpdateItem) ([]models.DigitalFulfillment, error)
+	GetDownloadURL(ctx context.Context, productID string) (string, string, time.Time, error)
+	models.LicenseKeyRevoker
+}
+
+type digitalServiceImpl struct {
+	repo     repository.ProductRepository
+	keys     repository.LicenseKeyRepository
+	products ProductService
+	s3       *S3Service
+}
+
+func NewDigitalService(repo repository.ProductRepository, keys repository.LicenseKeyRepository, products ProductService, s3 *S3Service) DigitalService {
+	return &digitalServiceImpl{repo: repo, keys: keys, products: products, s3: s3}
+}
+
+func (s *digitalServiceImpl) CreateUploadURL(sellerID string, req UploadRequest) (models.PresignedUploadResponse, error) {
+	if req.Filename == "" {
+		return models.PresignedUploadResponse{}, &ValidationError{Field: "filename", Message: "filename is required"}
+	}
+	return s.s3.GeneratePresignedDigitalUploadURL(sellerID, req.Filename, req.ContentType)
+}
+
+func (s *digitalServiceImpl) CreateDigitalProduct(ctx context.Context, sellerID string, req models.CreateDigitalProductRequest) error {
+	// seller chỉ được gắn file nằm trong thư mục của chính mình
+	if req.FileKey != "" && !strings.HasPrefix(req.FileKey, DigitalFilePrefix(sellerID)) {
+		return &ValidationError{Field: "file_key", Message: "file does not belong to you"}
+	}
+	if req.StockMode == models.DigitalStockUnlimited && req.FileKey == "" {
+		return &ValidationError{Field: "file_key", Message: "unlimited digital products need a file"}
+	}
+
+	product := models.Product{
+		Name:                req.Name,
+		ImagePath:           req.ImagePath,
+		Category:            req.Category,
+		Attributes:          req.Attributes,
+		Description:         req.Description,
+		Price:               req.Price,
+		Status:              req.Status,
+		UserID:              sellerID,
+		Type:                models.ProductTypeDigital,
+		DigitalFileKey:      req.FileKey,
+		DigitalFileName:     req.FileName,
+		DownloadLimit:       req.DownloadLimit,
+		DownloadExpiryHours: req.DownloadExpiryHours,
+	}
+	if product.DigitalFileName == "" && req.FileKey != "" {
+		product.DigitalFileName = req.FileKey[strings.LastIndex(req.FileKey, "/")+1:]
+	}
+	if product.DownloadLimit == 0 {
+		product.DownloadLimit = models.DefaultDownloadLimit
+	}
+	if product.DownloadExpiryHours == 0 {
+		product.DownloadExpiryHours = models.DefaultDownloadExpiryHours
+	}
+
+	switch req.StockMode {
+	case models.DigitalStockUnlimited:
+		product.UnlimitedStock = true
+		product.Quantity = models.UnlimitedStockQuantity
+	case models.DigitalStockLicenseKeys:
+		// kho bằng số key còn trống, seller nạp key qua /products/digital/:id/license-keys
+		product.UsesLicenseKeys = true
+		product.Quantity = 0
+	}
+	return s.products.AddProduct(ctx, product)
+}
+
+func (s *digitalServiceImpl) AddLicenseKeys(ctx context.Context, sellerID, productID string, keys []string) (int, error) {
+	product, err := s.repo.FindByID(ctx, productID)
+	if err != nil {
+		return 0, err
+	}
+	if product.UserID != sellerID {
+		return 0, &ValidationError{Field: "id", Message: "product does not belong to you"}
+	}
+	if !product.IsDigital() || !product.UsesLicenseKeys {
+		return 0, &ValidationError{Field: "id", Message: "product does not use license keys"}
+	}
+
+	unique := make([]string, 0, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		k = strings.TrimSpace(k)
+		if k == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		unique = append(unique, k)
+	}
+
+	added, err := s.keys.AddKeys(ctx, productID, unique)
+	if added > 0 {
+		// UpdateProductStock trừ quantity nên truyền số âm để cộng kho
+		if stockErr := s.products.UpdateProductStock(ctx, productID, -added); stockErr != nil {
+			log.Printf("Error increasing stock for product %s after adding %d keys: %v", productID, added, stockErr)
+		}
+	}
+	return added, err
+}
+
+// Fulfill chạy sau khi đơn thanh toán thành công: gán license key và trả thông tin tải file cho từng sản phẩm số.
+// Sản phẩm thường trong đơn bị bỏ qua. Gọi lại với cùng đơn thì giữ key đã gán và chỉ gán thêm phần còn thiếu.
+func (s *digitalServiceImpl) Fulfill(ctx context.Context, orderID, userID string, items []models.StockUpdateItem) ([]models.DigitalFulfillment, error) {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+	products, err := s.repo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	var result []models.DigitalFulfillment
+	for _, item := range items {
+		p, ok := byID[item.ProductID]
+		if !ok || !p.IsDigital() {
+			continue
+		}
+		f := models.DigitalFulfillment{
+			ProductID:           p.ID,
+			HasFile:             p.DigitalFileKey != "",
+			DownloadLimit:       p.DownloadLimit,
+			DownloadExpiryHours: p.DownloadExpiryHours,
+		}
+		if p.UsesLicenseKeys {
+			// lỗi / hết key ở một sản phẩm không chặn các sản phẩm khác, key đã gán vẫn trả về
+			// và báo số key còn thiếu để order-service gọi lại sau
+			keys, err := s.keys.AssignKeys(ctx, p.ID, orderID, userID, item.Quantity)
+			if err != nil {
+				log.Printf("Error assigning license keys for product %s of order %s: %v", p.ID, orderID, err)
+			}
+			for _, k := range keys {
+				f.LicenseKeys = append(f.LicenseKeys, k.Key)
+			}
+			f.MissingKeys = max(item.Quantity-len(keys), 0)
+		}
+		result = append(result, f)
+	}
+	return result, nil
+}
+
+// GetDownloadURL đọc thẳng DB vì file key không được lưu trong cache
+func (s *digitalServiceImpl) GetDownloadURL(ctx context.Context, productID string) (string, string, time.Time, error) {
+	product, err := s.repo.FindByID(ctx, productID)
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+	if !product.IsDigital() || product.DigitalFileKey == "" {
+		return "", "", time.Time{}, &ValidationError{Field: "product_id", Message: "product has no downloadable file"}
+	}
+
+	url, err := s.s3.GeneratePresignedDownloadURL(product.DigitalFileKey, digitalDownloadTTL)
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+	return url, product.DigitalFileName, time.Now().Add(digitalDownloadTTL), nil
+}
+
+func (s *digitalServiceImpl) RevokeOrderKeys(ctx context.Context, orderID string) (map[string]int, error) {
+	keys, err := s.keys.FindByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	revoked := make(map[string]int)
+	for _, k := range keys {
+		if k.Status != models.LicenseKeyAssigned {
+			// đã thu hồi ở lần xử lý trước, vẫn tính để không cộng kho lại
+			revoked[k.ProductID]++
+			continue
+		}
+		err := s.keys.Revoke(ctx, k)
+		if err != nil && !errors.Is(err, repository.ErrKeyAlreadyRevoked) {
+			return revoked, err
+		}
+		revoked[k.ProductID]++
+	}
+	return revoked, nil
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"product-service/models"
+	"product-service/repository"
+
+	"github.com/google/uuid"
+)
+
+type DraftService interface {
+	CreateDraft(ctx context.Context, sellerID string, req models.SaveDraftRequest) (*models.ProductDraft, error)
+	UpdateDraft(ctx context.Context, sellerID, draftID string, req models.SaveDraftRequest) (*models.ProductDraft, error)
+	GetDraft(ctx context.Context, sellerID, draftID string) (*models.ProductDraft, error)
+	ListDrafts(ctx context.Context, sellerID string) ([]models.ProductDraft, error)
+	DeleteDraft(ctx context.Context, sellerID, draftID string) error
+	PublishDraft(ctx context.Context, sellerID, draftID string) (string, error)
+}
+
+type draftServiceImpl struct {
+	drafts   repository.DraftRepository
+	products ProductService
+}
+
+func NewDraftService(drafts repository.DraftRepository, products ProductService) DraftService {
+	return &draftServiceImpl{drafts: drafts, products: products}
+}
+
+func applyDraftRequest(draft *models.ProductDraft, req models.SaveDraftRequest) {
+	draft.Name = strings.TrimSpace(req.Name)
+	draft.ImagePath = req.ImagePath
+	draft.Category = req.Category
+	draft.Attributes = req.Attributes
+	draft.Description = req.Description
+	draft.Quantity = req.Quantity
+	draft.Price = req.Price
+	draft.Status = req.Status
+	draft.LowStockThreshold = req.LowStockThreshold
+	draft.UpdatedAt = time.Now()
+}
+
+func (s *draftServiceImpl) CreateDraft(ctx context.Context, sellerID string, req models.SaveDraftRequest) (*models.ProductDraft, error) {
+	existing, err := s.drafts.ListBySeller(ctx, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= models.MaxDraftsPerSeller {
+		return nil, &ValidationError{Field: "draft", Message: fmt.Sprintf("you can keep at most %d drafts", models.MaxDraftsPerSeller)}
+	}
+
+	draft := &models.ProductDraft{
+		SellerID:  sellerID,
+		DraftID:   uuid.New().String(),
+		CreatedAt: time.Now(),
+	}
+	applyDraftRequest(draft, req)
+	if err := s.drafts.Put(ctx, *draft); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// UpdateDraft ghi đè toàn bộ nội dung draft bằng request (client gửi lại cả form)
+func (s *draftServiceImpl) UpdateDraft(ctx context.Context, sellerID, draftID string, req models.SaveDraftRequest) (*models.ProductDraft, error) {
+	draft, err := s.drafts.Get(ctx, sellerID, draftID)
+	if err != nil {
+		return nil, err
+	}
+	applyDraftRequest(draft, req)
+	if err := s.drafts.Put(ctx, *draft); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+func (s *draftServiceImpl) GetDraft(ctx context.Context, sellerID, draftID string) (*models.ProductDraft, error) {
+	return s.drafts.Get(ctx, sellerID, draftID)
+}
+
+func (s *draftServiceImpl) ListDrafts(ctx context.Context, sellerID string) ([]models.ProductDraft, error) {
+	return s.drafts.ListBySeller(ctx, sellerID)
+}
+
+func (s *draftServiceImpl) DeleteDraft(ctx context.Context, sellerID, draftID string) error {
+	return s.drafts.Delete(ctx, sellerID, draftID)
+}
+
+// PublishDraft kiểm tra draft đủ điều kiện như khi tạo sản phẩm mới rồi chuyển sang bảng product.
+// Sản phẩm dùng luôn draft ID làm ID: lần trước tạo xong mà xoá draft lỗi thì gọi lại chỉ xoá draft, không tạo trùng
+func (s *draftServiceImpl) PublishDraft(ctx context.Context, sellerID, draftID string) (string, error) {
+	draft, err := s.drafts.Get(ctx, sellerID, draftID)
+	if err != nil {
+		return "", err
+	}
+	if err := validateDraftForPublish(draft); err != nil {
+		return "", err
+	}
+
+	product := models.Product{
+		ID:          draft.DraftID,
+		Name:        draft.Name,
+		ImagePath:   draft.ImagePath,
+		Category:    draft.Category,
+		Attributes:  draft.Attributes,
+		Description: draft.Description,
+		Price:       draft.Price,
+		Quantity:    draft.Quantity,
+		UserID:      sellerID,
+		Status:      draft.Status,
+	}
+	if draft.LowStockThreshold != nil {
+		product.LowStockThreshold = *draft.LowStockThreshold
+	}
+	err = s.products.AddProduct(ctx, product)
+	if err != nil && !errors.Is(err, repository.ErrProductExists) {
+		return "", err
+	}
+
+	if err := s.drafts.Delete(ctx, sellerID, draftID); err != nil {
+		return product.ID, err
+	}
+	return product.ID, nil
+}
+
+// validateDraftForPublish: cùng điều kiện với binding của CreateProductRequest
+func validateDraftForPublish(draft *models.ProductDraft) error {
+	switch {
+	case len(draft.Name) < 2 || len(draft.Name) > 100:
+		return &ValidationError{Field: "name", Message: "name must be between 2 and 100 characters"}
+	case draft.Category == "":
+		return &ValidationError{Field: "category", Message: "category is required"}
+	case len(draft.Description) < 2 || len(draft.Description) > 500:
+		return &ValidationError{Field: "description", Message: "description must be between 2 and 500 characters"}
+	case draft.Quantity < 1:
+		return &ValidationError{Field: "quantity", Message: "quantity must be at least 1"}
+	case draft.Price <= 0:
+		return &ValidationError{Field: "price", Message: "price must be greater than 0"}
+	case draft.Status != "onsale" && draft.Status != "offsale" && draft.Status != "unavailable":
+		return &ValidationError{Field: "status", Message: "status must be one of onsale, offsale, unavailable"}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"product-service/cache"
+	"product-service/kafka"
+	"product-service/models"
+	"product-service/repository"
+)
+
+type InventoryService interface {
+	HandleStockChange(ctx context.Context, product *models.Product, oldQuantity int)
+	SendDailyDigests(ctx context.Context) error
+	Threshold(product *models.Product) int
+}
+
+type inventoryServiceImpl struct {
+	repo             repository.ProductRepository
+	defaultThreshold int
+	cache            *cache.Cache
+}
+
+func NewInventoryService(repo repository.ProductRepository) InventoryService {
+	threshold := 5
+	if v, err := strconv.Atoi(os.Getenv("LOW_STOCK_THRESHOLD")); err == nil && v >= 0 {
+		threshold = v
+	}
+	return &inventoryServiceImpl{repo: repo, defaultThreshold: threshold, cache: cache.Default()}
+}
+
+func (s *inventoryServiceImpl) Threshold(product *models.Product) int {
+	if product.LowStockThreshold > 0 {
+		return product.LowStockThreshold
+	}
+	return s.defaultThreshold
+}
+
+// HandleStockChange được gọi sau mỗi lần tồn kho thay đổi (đơn hàng, hoàn hàng, seller sửa số lượng).
+// Chỉ gửi cảnh báo khi vượt ngưỡng để seller không bị spam mỗi lần bán thêm một sản phẩm.
+func (s *inventoryServiceImpl) HandleStockChange(ctx context.Context, product *models.Product, oldQuantity int) {
+	if product == nil {
+		return
+	}
+	newQuantity := product.Quantity
+	threshold := s.Threshold(product)
+
+	switch {
+	case newQuantity <= 0 && oldQuantity > 0:
+		s.publish(product, models.InventoryOutOfStock, threshold)
+		if product.Status == "onsale" {
+			s.setStatus(ctx, product, "unavailable", true)
+		}
+	case newQuantity > 0 && oldQuantity <= 0:
+		if product.Status == "unavailable" && product.AutoUnavailable {
+			s.setStatus(ctx, product, "onsale", false)
+		}
+		s.publish(product, models.InventoryBackInStock, threshold)
+		if newQuantity <= threshold {
+			s.publish(product, models.InventoryLowStock, threshold)
+		}
+	case newQuantity > 0 && newQuantity <= threshold && oldQuantity > threshold:
+		s.publish(product, models.InventoryLowStock, threshold)
+	}
+}
+
+func (s *inventoryServiceImpl) setStatus(ctx context.Context, product *models.Product, status string, auto bool) {
+	update := map[string]interface{}{
+		"status":           status,
+		"auto_unavailable": auto,
+	}
+	if err := s.repo.Update(ctx, product.ID, update); err != nil {
+		log.Printf("Error switching product %s to %s: %v", product.ID, status, err)
+		return
+	}
+	log.Printf("Product %s switched from %s to %s (quantity=%d)", product.ID, product.Status, status, product.Quantity)
+	product.Status = status
+	product.AutoUnavailable = auto
+	// search-service dùng updated_at làm version, event phải mang thời điểm của lần ghi này
+	product.Updated_at = time.Now()
+
+	s.cache.InvalidateProduct(ctx, product.ID, false)
+
+	go func(p models.Product) {
+		_ = kafka.ProduceProductEvent(context.Background(), "updated", &p, p.ID)
+	}(*product)
+}
+
+func (s *inventoryServiceImpl) publish(product *models.Product, eventType string, threshold int) {
+	event := models.InventoryEvent{
+		Type:        eventType,
+		SellerID:    product.UserID,
+		ProductID:   product.ID,
+		ProductName: product.Name,
+		Quantity:    product.Quantity,
+		Threshold:   threshold,
+		Status:      product.Status,
+		Timestamp:   time.Now().Unix(),
+	}
+	go func() {
+		if err := kafka.ProduceInventoryEvent(context.Background(), event); err != nil {
+			log.Printf("Error producing %s event for product %s: %v", event.Type, event.ProductID, err)
+		}
+	}()
+}
+
+// SendDailyDigests gửi cho mỗi seller danh sách sản phẩm hết hàng / sắp hết hàng
+func (s *inventoryServiceImpl) SendDailyDigests(ctx context.Context) error {
+	digests := make(map[string][]models.InventoryDigestItem)
+
+	err := s.repo.ScanAll(ctx, func(p models.Product) error {
+		if p.UserID == "" || p.Status == "offsale" {
+			return nil
+		}
+		threshold := s.Threshold(&p)
+		if p.Quantity > threshold {
+			return nil
+		}
+		digests[p.UserID] = append(digests[p.UserID], models.InventoryDigestItem{
+			ProductID: p.ID,
+			Name:      p.Name,
+			Quantity:  p.Quantity,
+			Threshold: threshold,
+			Status:    p.Status,
+			SoldCount: p.SoldCount,
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	for sellerID, items := range digests {
+		event := models.InventoryEvent{
+			Type:      models.InventoryDailyDigest,
+			SellerID:  sellerID,
+			Items:     items,
+			Timestamp: now,
+		}
+		if err := kafka.ProduceInventoryEvent(ctx, event); err != nil {
+			log.Printf("Error producing inventory digest for seller %s: %v", sellerID, err)
+		}
+	}
+	log.Printf("Sent inventory digest to %d sellers", len(digests))
+	return nil
+}
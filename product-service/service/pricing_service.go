@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"product-service/cache"
+	"product-service/kafka"
+	"product-service/models"
+	"product-service/repository"
+)
+
+var (
+	ErrScheduleOverlap  = errors.New("product already has a sale scheduled in this period")
+	ErrScheduleNotOwner = errors.New("unauthorized: user does not own the product")
+	ErrScheduleFinished = errors.New("price schedule already finished")
+)
+
+const defaultHistoryWindow = 30
+
+type PricingService interface {
+	SchedulePrice(ctx context.Context, userID, productID string, req models.CreatePriceScheduleRequest) (*models.PriceSchedule, error)
+	CancelSchedule(ctx context.Context, userID, scheduleID string) error
+	GetSchedules(ctx context.Context, productID string) ([]models.PriceSchedule, error)
+	RunDueSchedules(ctx context.Context, now time.Time) error
+	RecordPriceChange(ctx context.Context, entry models.PriceHistory) error
+	GetPriceHistory(ctx context.Context, productID string, days int) (*models.PriceHistorySummary, error)
+	SummarizePriceHistory(ctx context.Context, product *models.Product, days int) (*models.PriceHistorySummary, error)
+}
+
+type pricingServiceImpl struct {
+	productRepo  repository.ProductRepository
+	scheduleRepo repository.PriceScheduleRepository
+	historyRepo  repository.PriceHistoryRepository
+	cache        *cache.Cache
+}
+
+func NewPricingService(productRepo repository.ProductRepository, scheduleRepo repository.PriceScheduleRepository, historyRepo repository.PriceHistoryRepository) PricingService {
+	return &pricingServiceImpl{
+		productRepo:  productRepo,
+		scheduleRepo: scheduleRepo,
+		historyRepo:  historyRepo,
+		cache:        cache.Default(),
+	}
+}
+
+func (s *pricingServiceImpl) SchedulePrice(ctx context.Context, userID, productID string, req models.CreatePriceScheduleRequest) (*models.PriceSchedule, error) {
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.UserID != userID {
+		return nil, ErrScheduleNotOwner
+	}
+
+	regularPrice := product.Price
+	if product.SaleScheduleID != "" && product.RegularPrice > 0 {
+		regularPrice = product.RegularPrice
+	}
+
+	if !req.EndAt.After(req.StartAt) {
+		return nil, &ValidationError{Field: "end_at", Message: "must be after start_at"}
+	}
+	if req.EndAt.Before(time.Now()) {
+		return nil, &ValidationError{Field: "end_at", Message: "must be in the future"}
+	}
+	if req.SalePrice >= regularPrice {
+		return nil, &ValidationError{Field: "sale_price", Message: fmt.Sprintf("must be lower than regular price %.2f", regularPrice)}
+	}
+
+	existing, err := s.scheduleRepo.FindByProductID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range existing {
+		if e.Status != models.PriceScheduleScheduled && e.Status != models.PriceScheduleActive {
+			continue
+		}
+		if req.StartAt.Before(e.EndAt) && e.StartAt.Before(req.EndAt) {
+			return nil, ErrScheduleOverlap
+		}
+	}
+
+	schedule, err := s.scheduleRepo.Insert(ctx, models.PriceSchedule{
+		ProductID: productID,
+		UserID:    userID,
+		SalePrice: req.SalePrice,
+		StartAt:   req.StartAt,
+		EndAt:     req.EndAt,
+		Status:    models.PriceScheduleScheduled,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Sale bắt đầu ngay thì không cần chờ lượt chạy tiếp theo của scheduler
+	if !schedule.StartAt.After(time.Now()) {
+		if err := s.startSale(ctx, schedule); err != nil {
+			return nil, err
+		}
+		schedule.Status = models.PriceScheduleActive
+	}
+	return schedule, nil
+}
+
+func (s *pricingServiceImpl) CancelSchedule(ctx context.Context, userID, scheduleID string) error {
+	schedule, err := s.scheduleRepo.FindByID(ctx, scheduleID)
+	if err != nil {
+		return err
+	}
+	if schedule.UserID != userID {
+		return ErrScheduleNotOwner
+	}
+
+	switch schedule.Status {
+	case models.PriceScheduleActive:
+		return s.endSale(ctx, schedule, models.PriceScheduleCancelled)
+	case models.PriceScheduleScheduled:
+		return s.scheduleRepo.UpdateStatus(ctx, schedule.ID, models.PriceScheduleCancelled)
+	default:
+		return ErrScheduleFinished
+	}
+}
+
+func (s *pricingServiceImpl) GetSchedules(ctx context.Context, productID string) ([]models.PriceSchedule, error) {
+	schedules, err := s.scheduleRepo.FindByProductID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if schedules == nil {
+		schedules = []models.PriceSchedule{}
+	}
+	return schedules, nil
+}
+
+// RunDueSchedules được cron gọi định kỳ: bật sale đến giờ và trả giá gốc cho sale đã hết hạn
+func (s *pricingServiceImpl) RunDueSchedules(ctx context.Context, now time.Time) error {
+	schedules, err := s.scheduleRepo.FindPending(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range schedules {
+		schedule := &schedules[i]
+		var err error
+		switch {
+		case schedule.Status == models.PriceScheduleScheduled && !now.Before(schedule.EndAt):
+			// bỏ lỡ toàn bộ khung giờ (service down), không áp dụng nữa
+			err = s.scheduleRepo.UpdateStatus(ctx, schedule.ID, models.PriceScheduleCompleted)
+		case schedule.Status == models.PriceScheduleScheduled && !now.Before(schedule.StartAt):
+			err = s.startSale(ctx, schedule)
+		case schedule.Status == models.PriceScheduleActive && !now.Before(schedule.EndAt):
+			err = s.endSale(ctx, schedule, models.PriceScheduleCompleted)
+		}
+		if err != nil {
+			log.Printf("Error processing price schedule %s: %v", schedule.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *pricingServiceImpl) startSale(ctx context.Context, schedule *models.PriceSchedule) error {
+	product, err := s.productRepo.FindByID(ctx, schedule.ProductID)
+	if err != nil {
+		return err
+	}
+
+	regularPrice := product.Price
+	if product.SaleScheduleID != "" {
+		if product.SaleScheduleID != schedule.ID {
+			return ErrScheduleOverlap
+		}
+		regularPrice = product.RegularPrice
+	}
+
+	update := map[string]interface{}{
+		"price":            schedule.SalePrice,
+		"regular_price":    regularPrice,
+		"sale_schedule_id": schedule.ID,
+		"sale_end_at":      schedule.EndAt,
+	}
+	// lượt khác đã bật sale hoặc giá vừa bị sửa: bỏ qua, lần chạy sau đọc lại
+	if err := s.productRepo.UpdateIfUnchanged(ctx, product, update); err != nil {
+		return err
+	}
+	if err := s.scheduleRepo.UpdateStatus(ctx, schedule.ID, models.PriceScheduleActive); err != nil {
+		return err
+	}
+
+	s.afterPriceChange(ctx, models.PriceHistory{
+		ProductID:  product.ID,
+		Price:      schedule.SalePrice,
+		OldPrice:   product.Price,
+		Reason:     models.PriceChangeSaleStart,
+		ScheduleID: schedule.ID,
+		ChangedBy:  schedule.UserID,
+	})
+	return nil
+}
+
+func (s *pricingServiceImpl) endSale(ctx context.Context, schedule *models.PriceSchedule, finalStatus string) error {
+	product, err := s.productRepo.FindByID(ctx, schedule.ProductID)
+	if err != nil {
+		return err
+	}
+
+	if product.SaleScheduleID == schedule.ID {
+		regularPrice := product.RegularPrice
+		if regularPrice <= 0 {
+			regularPrice = product.Price
+		}
+
+		update := map[string]interface{}{
+			"price":            regularPrice,
+			"regular_price":    0,
+			"sale_schedule_id": "",
+			"sale_end_at":      nil,
+		}
+		if err := s.productRepo.UpdateIfUnchanged(ctx, product, update); err != nil {
+			return err
+		}
+
+		s.afterPriceChange(ctx, models.PriceHistory{
+			ProductID:  product.ID,
+			Price:      regularPrice,
+			OldPrice:   product.Price,
+			Reason:     models.PriceChangeSaleEnd,
+			ScheduleID: schedule.ID,
+			ChangedBy:  schedule.UserID,
+		})
+	}
+
+	return s.scheduleRepo.UpdateStatus(ctx, schedule.ID, finalStatus)
+}
+
+// afterPriceChange ghi lịch sử giá, xoá cache và bắn product-events "updated"
+func (s *pricingServiceImpl) afterPriceChange(ctx context.Context, entry models.PriceHistory) {
+	if err := s.RecordPriceChange(ctx, entry); err != nil {
+		log.Printf("Error recording price history for product %s: %v", entry.ProductID, err)
+	}
+
+	s.cache.InvalidateProduct(ctx, entry.ProductID, false)
+
+	go func(id string) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		product, err := s.productRepo.FindByID(ctx, id)
+		if err == nil && product != nil {
+			_ = kafka.ProduceProductEvent(context.Background(), "updated", product, id)
+		}
+	}(entry.ProductID)
+}
+
+func (s *pricingServiceImpl) RecordPriceChange(ctx context.Context, entry models.PriceHistory) error {
+	if entry.ChangedAt.IsZero() {
+		entry.ChangedAt = time.Now()
+	}
+	return s.historyRepo.Insert(ctx, entry)
+}
+
+func (s *pricingServiceImpl) GetPriceHistory(ctx context.Context, productID string, days int) (*models.PriceHistorySummary, error) {
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	return s.SummarizePriceHistory(ctx, product, days)
+}
+
+// SummarizePriceHistory dùng product đã đọc sẵn, chỉ thêm một query lịch sử giá
+func (s *pricingServiceImpl) SummarizePriceHistory(ctx context.Context, product *models.Product, days int) (*models.PriceHistorySummary, error) {
+	if days <= 0 {
+		days = defaultHistoryWindow
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	// mới nhất trước, phần tử cuối có thể là giá đang áp dụng trước since
+	recent, err := s.historyRepo.FindRecent(ctx, product.ID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	regularPrice := product.Price
+	if product.SaleScheduleID != "" && product.RegularPrice > 0 {
+		regularPrice = product.RegularPrice
+	}
+
+	history := make([]models.PriceHistory, 0, len(recent))
+	for i := len(recent) - 1; i >= 0; i-- {
+		if !recent[i].ChangedAt.Before(since) {
+			history = append(history, recent[i])
+		}
+	}
+
+	summary := &models.PriceHistorySummary{
+		ProductID:        product.ID,
+		CurrentPrice:     product.Price,
+		RegularPrice:     regularPrice,
+		Days:             days,
+		LowestPrice:      product.Price,
+		HighestPrice:     product.Price,
+		LowestPriorPrice: regularPrice,
+		History:          history,
+	}
+
+	// Giá đang áp dụng ở đầu kỳ cũng tính vào min/max
+	for _, h := range recent {
+		if h.Price < summary.LowestPrice {
+			summary.LowestPrice = h.Price
+		}
+		if h.Price > summary.HighestPrice {
+			summary.HighestPrice = h.Price
+		}
+	}
+
+	// Giá thấp nhất trước lần đổi giá hiện tại: bỏ bản ghi mới nhất nếu đó chính là giá đang bán
+	prior := recent
+	if len(prior) > 0 && prior[0].Price == product.Price {
+		prior = prior[1:]
+	}
+	for i, h := range prior {
+		if i == 0 || h.Price < summary.LowestPriorPrice {
+			summary.LowestPriorPrice = h.Price
+		}
+	}
+
+	return summary, nil
+}
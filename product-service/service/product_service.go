@@ -2,12 +2,13 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"time"
 
-	"product-service/helper"
+	"product-service/cache"
 	"product-service/kafka"
 	"product-service/models"
 	"product-service/repository"
@@ -17,9 +18,11 @@ import (
 
 type ProductService interface {
 	AddProduct(ctx context.Context, product models.Product) error
-	EditProduct(ctx context.Context, id string, update map[string]interface{}) error
+	EditProduct(ctx context.Context, id, userID string, update map[string]interface{}) error
 	DeleteProduct(ctx context.Context, id, userID string) error
 	GetProductByID(ctx context.Context, id string) (*models.Product, error)
+	GetProductsByIDs(ctx context.Context, ids []string) ([]models.Product, error)
+	GetProductsCached(ctx context.Context, ids []string) ([]models.Product, error)
 	// GetProductByName(ctx context.Context, name string) ([]models.Product, error)
 	GetAllProducts(ctx context.Context, page, limit int64) ([]models.Product, int64, int, bool, bool, bool, error)
 	UpdateProductStock(ctx context.Context, id string, quantity int) error
@@ -27,60 +30,176 @@ type ProductService interface {
 	GetBestSellingProducts(ctx context.Context, limit int) ([]models.Product, error)
 	DecrementSoldCount(ctx context.Context, productID string, quantity int) error
 	GetAllProductForIndex(ctx context.Context) ([]models.Product, error)
+	ExportProducts(ctx context.Context, fn func(product models.Product) error) error
 	GetProductByUserID(ctx context.Context, userID string, page, limit int64) ([]models.Product, int64, int, bool, bool, error)
 	GetProductByCategory(ctx context.Context, category string, page, limit int64) ([]models.Product, int64, int, bool, bool, error)
 }
 
 type productServiceImpl struct {
 	repo repository.ProductRepository
+	categories CategoryService
+	pricing PricingService
+	inventory InventoryService
 	S3Service *S3Service
+	revisions repository.RevisionRepository
+	cache *cache.Cache
 }
 
-func NewProductService(repo repository.ProductRepository, s3Service *S3Service ) ProductService {
-	return &productServiceImpl{repo: repo, S3Service : s3Service}
+func NewProductService(repo repository.ProductRepository, categories CategoryService, pricing PricingService, inventory InventoryService, s3Service *S3Service, revisions repository.RevisionRepository) ProductService {
+	return &productServiceImpl{repo: repo, categories: categories, pricing: pricing, inventory: inventory, S3Service : s3Service, revisions: revisions, cache: cache.Default()}
+}
+
+// productPage là index của một trang danh sách trong cache, sản phẩm lấy theo key entity
+type productPage struct {
+	IDs   []string `json:"ids"`
+	Total int64    `json:"total"`
+}
+
+// applyCategory gán slug/category_id chuẩn và validate attributes theo schema của category
+func (s *productServiceImpl) applyCategory(ctx context.Context, categoryValue string, attrs map[string]interface{}) (*models.Category, map[string]interface{}, error) {
+	category, err := s.categories.ResolveCategory(ctx, categoryValue)
+	if err != nil {
+		if errors.Is(err, repository.ErrCategoryNotFound) {
+			return nil, nil, &ValidationError{Field: "category", Message: fmt.Sprintf("unknown category %q", categoryValue)}
+		}
+		return nil, nil, err
+	}
+
+	normalized, err := s.categories.ValidateAttributes(ctx, category, attrs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return category, normalized, nil
 }
 
 func (s *productServiceImpl) AddProduct(ctx context.Context, product models.Product) error {
-	product.ID = uuid.New().String()
+	category, attrs, err := s.applyCategory(ctx, product.Category, product.Attributes)
+	if err != nil {
+		return err
+	}
+	product.Category = category.Slug
+	product.CategoryID = category.ID
+	product.Attributes = attrs
+
+	if product.ID == "" {
+		product.ID = uuid.New().String()
+	}
 	product.Created_at = time.Now()
 	product.Updated_at = time.Now()
-	err := s.repo.Insert(ctx, product)
+	err = s.repo.Insert(ctx, product)
 	if err == nil {
-		go func() {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			if err := helper.InvalidateProductCache(ctx, "products:*"); err != nil {
-				log.Printf("Error invalidating product cache: %v", err)
-			}
-		}()
+		s.cache.Set(ctx, cache.ProductKey(product.ID), product, cache.ProductTTL)
+		s.cache.BumpNamespace(ctx, cache.NamespaceProducts, cache.NamespaceBestSelling)
 
 		go func(p models.Product) {
 			_ = kafka.ProduceProductEvent(context.Background(), "created", &p, p.ID)
 		}(product)
+
+		s.recordRevision(ctx, nil, &product, product.UserID)
+
+		if err := s.pricing.RecordPriceChange(ctx, models.PriceHistory{
+			ProductID: product.ID,
+			Price:     product.Price,
+			Reason:    models.PriceChangeInitial,
+			ChangedBy: product.UserID,
+		}); err != nil {
+			log.Printf("Error recording initial price for product %s: %v", product.ID, err)
+		}
 	}
 	
 	return err
 }
 
-func (s *productServiceImpl) EditProduct(ctx context.Context, id string, update map[string]interface{}) error {
+func (s *productServiceImpl) EditProduct(ctx context.Context, id, userID string, update map[string]interface{}) error {
+	_, hasCategory := update["category"]
+	_, hasAttributes := update["attributes"]
+	newPrice, hasPrice := update["price"].(float64)
+	_, hasQuantity := update["quantity"]
+	_, hasStatus := update["status"]
+
+	// luôn đọc bản cũ để lưu revision
+	existing, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Kho sản phẩm số do pool license key quyết định (hoặc không giới hạn), không sửa tay được
+	if hasQuantity && existing.IsDigital() {
+		return &ValidationError{Field: "quantity", Message: "stock of digital products is managed by license keys"}
+	}
+
+	// Đang trong đợt sale: giá seller sửa là giá gốc, được áp dụng lại khi sale kết thúc
+	if hasPrice && existing.SaleScheduleID != "" {
+		update["regular_price"] = newPrice
+		delete(update, "price")
+		hasPrice = false
+	}
+
+	if hasCategory || hasAttributes {
+		categoryValue := existing.Category
+		if v, ok := update["category"].(string); ok {
+			categoryValue = v
+		}
+		attrs := existing.Attributes
+		if v, ok := update["attributes"].(map[string]interface{}); ok {
+			attrs = v
+		}
+
+		category, normalized, err := s.applyCategory(ctx, categoryValue, attrs)
+		if err != nil {
+			return err
+		}
+		update["category"] = category.Slug
+		update["category_id"] = category.ID
+		update["attributes"] = normalized
+	}
+
+	// Seller tự đổi status thì không còn là trạng thái do hệ thống tự chuyển
+	if _, ok := update["status"]; ok {
+		update["auto_unavailable"] = false
+	}
+
 	update["updated_at"] = time.Now()
-	err := s.repo.Update(ctx, id, update)
+	err = s.repo.Update(ctx, id, update)
 	if err == nil {
-		go func() {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			productKey := fmt.Sprintf("products:%s", id)
-			if err := helper.InvalidateProductCache(ctx, productKey); err != nil {
-				log.Printf("Error invalidating product cache: %v", err)
-			}
-		}()
-		
-		go func(id string) {
-			product, err := s.repo.FindByID(context.Background(), id)
-			if err == nil && product != nil {
+		// Update chỉ ghi một phần field nên xoá key, lần đọc sau sẽ load lại từ DB.
+		// Sửa status/category... có thể đổi thành phần các trang nên bump cả danh sách
+		s.cache.InvalidateProduct(ctx, id, true)
+
+		if product, findErr := s.repo.FindByID(ctx, id); findErr == nil {
+			s.recordRevision(ctx, existing, product, userID)
+			go func(product *models.Product) {
 				_ = kafka.ProduceProductEvent(context.Background(), "updated", product, id)
+			}(product)
+		} else {
+			log.Printf("Error reloading product %s after update: %v", id, findErr)
+		}
+
+		if hasQuantity || hasStatus {
+			go func(oldQuantity int) {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				product, err := s.repo.FindByID(ctx, id)
+				if err == nil && product != nil {
+					if hasQuantity {
+						s.inventory.HandleStockChange(ctx, product, oldQuantity)
+					}
+					s.syncBundles(*product)
+				}
+			}(existing.Quantity)
+		}
+
+		if hasPrice && newPrice != existing.Price {
+			if err := s.pricing.RecordPriceChange(ctx, models.PriceHistory{
+				ProductID: id,
+				Price:     newPrice,
+				OldPrice:  existing.Price,
+				Reason:    models.PriceChangeManual,
+				ChangedBy: existing.UserID,
+			}); err != nil {
+				log.Printf("Error recording price history for product %s: %v", id, err)
 			}
-		} (id)
+		}
 	}
 	return err
 }
@@ -88,69 +207,140 @@ func (s *productServiceImpl) EditProduct(ctx context.Context, id string, update
 func (s *productServiceImpl) DeleteProduct(ctx context.Context, id, userID string) error {
 	err := s.repo.Delete(ctx, id, userID)
 	if err == nil {
-		go func() {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			productKey := fmt.Sprintf("product:%s", id)
-			if err := helper.InvalidateProductCache(ctx, productKey); err != nil {
-				log.Printf("Error invalidating product cache: %v", err)
-			}
-
-			if err := helper.InvalidateProductCache(ctx, "products:*"); err != nil {
-				log.Printf("Error invalidating product cache: %v", err)
-			}
-		}()
+		s.cache.InvalidateProduct(ctx, id, true)
 
 		go func(id string) {
 			_ = kafka.ProduceProductEvent(context.Background(), "deleted", nil, id)
 		} (id)
+		// bundle chứa sản phẩm này hết hàng theo
+		go s.syncBundles(models.Product{ID: id, UserID: userID})
 	}
 	return err
 }
 
-func (s *productServiceImpl) GetProductByID(ctx context.Context, id string) (*models.Product, error) {
-	cacheKey := fmt.Sprintf("product:%s", id) // Đổi thành "product:" để nhất quán
+// GetProductsByIDs đọc thẳng từ DynamoDB (không qua cache) vì cart/order cần tồn kho mới nhất
+func (s *productServiceImpl) GetProductsByIDs(ctx context.Context, ids []string) ([]models.Product, error) {
+	products, err := s.repo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyBundleStock(ctx, products, true); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
 
+func (s *productServiceImpl) GetProductByID(ctx context.Context, id string) (*models.Product, error) {
 	var product models.Product
-	found, err := helper.GetCachedProductData(ctx, cacheKey, &product)
-	if err == nil && found {
-		log.Printf("Cache hit for product: %s", id)
-		if len(product.ImagePath) > 0 {
-			var urls []string
-			for _, key := range product.ImagePath {
-				if key == "" {
-					continue
-				}
-				url, err := s.GetS3PathIfExist(key, 100*time.Minute)
-				if err == nil && url != "" {
-					urls = append(urls, url)
-				} else {
-					// fallback to original key if presign fails
-					urls = append(urls, key)
-				}
-			}
-			product.ImagePath = urls
-		}
-		return &product, nil
+	_, err := s.cache.GetOrLoad(ctx, cache.ProductKey(id), cache.ProductTTL, &product, func(ctx context.Context) (interface{}, error) {
+		return s.repo.FindByID(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	products := []models.Product{product}
+	if err := s.applyBundleStock(ctx, products, false); err != nil {
+		return nil, err
+	}
+	product = products[0]
+
+	// Cache lưu S3 key, presign mỗi lần đọc vì URL có hạn
+	s.presignImages(&product)
+	return &product, nil
+}
+
+// GetProductsCached giống GetProductsByIDs nhưng đọc qua cache và presign ảnh, dùng cho các trang hiển thị
+func (s *productServiceImpl) GetProductsCached(ctx context.Context, ids []string) ([]models.Product, error) {
+	products, err := s.getProductsCached(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range products {
+		s.presignImages(&products[i])
 	}
+	return products, nil
+}
 
-	productPtr, err := s.repo.FindByID(ctx, id)
+// getProductsCached lấy sản phẩm theo danh sách ID (giữ thứ tự), tồn kho bundle được tính lại từ thành phần
+func (s *productServiceImpl) getProductsCached(ctx context.Context, ids []string) ([]models.Product, error) {
+	products, err := s.loadProductsCached(ctx, ids)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.applyBundleStock(ctx, products, false); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// loadProductsCached: key nào miss thì BatchGetItem rồi ghi lại cache
+func (s *productServiceImpl) loadProductsCached(ctx context.Context, ids []string) ([]models.Product, error) {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = cache.ProductKey(id)
+	}
 
-	if productPtr != nil {
-		go func(p *models.Product) {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			if err := helper.CacheProductData(ctx, cacheKey, p, 30*time.Minute); err != nil { 
-				log.Printf("Error caching product data: %v", err)
-			} else {
-				log.Printf("Cached product data with key: %s", cacheKey)
+	products := make([]models.Product, len(ids))
+	found := make([]bool, len(ids))
+	missing := s.cache.GetMany(ctx, keys, func(i int, data []byte) error {
+		if err := json.Unmarshal(data, &products[i]); err != nil {
+			return err
+		}
+		found[i] = true
+		return nil
+	})
+
+	if len(missing) > 0 {
+		missingIDs := make([]string, len(missing))
+		for i, idx := range missing {
+			missingIDs[i] = ids[idx]
+		}
+		loaded, err := s.repo.FindByIDs(ctx, missingIDs)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[string]models.Product, len(loaded))
+		for _, p := range loaded {
+			byID[p.ID] = p
+			s.cache.Set(ctx, cache.ProductKey(p.ID), p, cache.ProductTTL)
+		}
+		for _, idx := range missing {
+			if p, ok := byID[ids[idx]]; ok {
+				products[idx] = p
+				found[idx] = true
 			}
-		}(productPtr)
+		}
+	}
+
+	// sản phẩm đã bị xoá thì bỏ qua
+	result := make([]models.Product, 0, len(ids))
+	for i := range products {
+		if found[i] {
+			result = append(result, products[i])
+		}
 	}
-	return productPtr, nil
+	return result, nil
+}
+
+func (s *productServiceImpl) presignImages(product *models.Product) {
+	if len(product.ImagePath) == 0 {
+		return
+	}
+	var urls []string
+	for _, key := range product.ImagePath {
+		if key == "" {
+			continue
+		}
+		url, err := s.GetS3PathIfExist(key, 100*time.Minute)
+		if err == nil && url != "" {
+			urls = append(urls, url)
+		} else {
+			// fallback to original key if presign fails
+			urls = append(urls, key)
+		}
+	}
+	product.ImagePath = urls
 }
 
 // func (s *productServiceImpl) GetProductByName(ctx context.Context, name string) ([]models.Product, error) {
@@ -166,63 +356,61 @@ func (s *productServiceImpl) GetAllProducts(ctx context.Context, page, limit int
 		limit = 10
 	}
 
-	// Try to fetch from repository (skip is offset)
 	skip := (page - 1) * limit
-	products, total, err := s.repo.FindAll(ctx, skip, limit)
+	key := s.cache.ListKey(ctx, cache.NamespaceProducts, fmt.Sprintf("page=%d&limit=%d", page, limit))
+
+	var index productPage
+	cached, err := s.cache.GetOrLoad(ctx, key, cache.ListTTL, &index, func(ctx context.Context) (interface{}, error) {
+		products, total, err := s.repo.FindAll(ctx, skip, limit)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(products))
+		for _, p := range products {
+			ids = append(ids, p.ID)
+			s.cache.Set(ctx, cache.ProductKey(p.ID), p, cache.ProductTTL)
+		}
+		return productPage{IDs: ids, Total: total}, nil
+	})
+	if err != nil {
+		return nil, 0, 0, false, false, false, err
+	}
+
+	products, err := s.getProductsCached(ctx, index.IDs)
 	if err != nil {
 		return nil, 0, 0, false, false, false, err
 	}
 
 	// Convert image keys to presigned URLs if ImagePath is a slice of keys.
-	// This follows the pattern used in GetProductByID where ImagePath is iterated.
 	for i := range products {
-		if len(products[i].ImagePath) > 0 {
-			var urls []string
-			for _, key := range products[i].ImagePath {
-				if key == "" {
-					continue
-				}
-				url, err := s.GetS3PathIfExist(key, 100*time.Minute)
-				if err == nil && url != "" {
-					urls = append(urls, url)
-				} else {
-					// fallback to original key if presign fails
-					urls = append(urls, key)
-				}
-			}
-			products[i].ImagePath = urls
-		}
+		s.presignImages(&products[i])
 	}
 
+	total := index.Total
 	pages := int((total + limit - 1) / limit)
 	hasNext := page < int64(pages)
 	hasPrev := page > 1
 
-	// Cache the result asynchronously
-	go func(prods []models.Product, tot int64, pgs int, hn, hp bool) {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		if err := helper.CacheAllProducts(ctx, page, limit, prods, tot, pgs, hn, hp); err != nil {
-			log.Printf("Error caching all products: %v", err)
-		} else {
-			log.Printf("Cached all products for page=%d, limit=%d", page, limit)
-		}
-	}(products, total, pages, hasNext, hasPrev)
-
-	return products, total, pages, hasNext, hasPrev, false, nil
+	return products, total, pages, hasNext, hasPrev, cached, nil
 }
 
 func (s *productServiceImpl) UpdateProductStock(ctx context.Context, id string, quantity int) error {
-	err := s.repo.UpdateStock(ctx, id, quantity)
+	product, err := s.repo.UpdateStock(ctx, id, quantity)
 	if err == nil {
-		go func() {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			productKey := fmt.Sprintf("products:%s", id)
-			if err := helper.InvalidateProductCache(ctx, productKey); err != nil {
-				log.Printf("Error invalidating product cache: %v", err)
-			}
-		}()
+		// UpdateStock trả về item mới nên ghi thẳng vào cache
+		s.cache.Set(ctx, cache.ProductKey(id), product, cache.ProductTTL)
+
+		// repo trừ quantity nên số lượng trước khi cập nhật là new + quantity
+		s.inventory.HandleStockChange(ctx, product, product.Quantity+quantity)
+		go s.syncBundles(*product)
+
+		// search-service lấy quantity/sold_count từ document đầy đủ (có version), không tự trừ theo order_success
+		go func(p models.Product) {
+			_ = kafka.ProduceProductEvent(context.Background(), "updated", &p, p.ID)
+		}(*product)
+	}
+	if errors.Is(err, repository.ErrUnlimitedStock) {
+		return nil
 	}
 	return err
 }
@@ -230,66 +418,58 @@ func (s *productServiceImpl) UpdateProductStock(ctx context.Context, id string,
 func (s *productServiceImpl) IncrementSoldCount(ctx context.Context, productID string, quantity int) error {
 	err := s.repo.IncrementSoldCount(ctx, productID, quantity)
 	if err == nil {
-		go func() {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			productKey := fmt.Sprintf("products:%s", productID)
-			if err := helper.InvalidateProductCache(ctx, productKey); err != nil {
-				log.Printf("Error invalidating product cache: %v", err)
-			}
-			if err := helper.InvalidateProductCache(ctx, "bestselling:*"); err != nil {
-				log.Printf("Error invalidating best selling product cache: %v", err)
-			}
-		}()
+		s.cache.Delete(ctx, cache.ProductKey(productID))
+		s.cache.BumpNamespace(ctx, cache.NamespaceBestSelling)
+		s.publishStats(ctx, productID)
 	}
 	return err
 }
 
+// publishStats đọc lại sản phẩm sau khi đổi sold_count và bắn "updated" cho search-service
+func (s *productServiceImpl) publishStats(ctx context.Context, productID string) {
+	product, err := s.repo.FindByID(ctx, productID)
+	if err != nil {
+		log.Printf("Error reloading product %s after sold count change: %v", productID, err)
+		return
+	}
+	go func(product *models.Product) {
+		_ = kafka.ProduceProductEvent(context.Background(), "updated", product, product.ID)
+	}(product)
+}
+
 func (s *productServiceImpl) GetBestSellingProducts(ctx context.Context, limit int) ([]models.Product, error) {
 	if limit <= 0 {
 		limit = 10
 	}
 
-	cacheKey := fmt.Sprintf("bestselling:limit=%d", limit)
-	var products []models.Product
-	found, err := helper.GetCachedProductData(ctx, cacheKey, &products)
-	if err == nil && found {
-		log.Printf("Cache hit for best selling products: limit=%d", limit)
-		return products, nil
-	}
+	key := s.cache.ListKey(ctx, cache.NamespaceBestSelling, fmt.Sprintf("limit=%d", limit))
 
-	products, err = s.repo.GetBestSellingProduct(ctx, limit)
+	var index productPage
+	_, err := s.cache.GetOrLoad(ctx, key, 30*time.Minute, &index, func(ctx context.Context) (interface{}, error) {
+		products, err := s.repo.GetBestSellingProduct(ctx, limit)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(products))
+		for _, p := range products {
+			ids = append(ids, p.ID)
+			s.cache.Set(ctx, cache.ProductKey(p.ID), p, cache.ProductTTL)
+		}
+		return productPage{IDs: ids, Total: int64(len(ids))}, nil
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	go func(prods []models.Product) {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		if err := helper.CacheProductData(ctx, cacheKey, prods, 30*time.Minute); err != nil { // Tăng TTL lên 30 phút
-			log.Printf("Error caching best selling products: %v", err)
-		} else {
-			log.Printf("Cached best selling products with key: %s", cacheKey)
-		}
-	}(products)
-	return products, nil
+	return s.getProductsCached(ctx, index.IDs)
 }
 
 func (s *productServiceImpl) DecrementSoldCount(ctx context.Context, productID string, quantity int) error {
 
 	err := s.repo.DecrementSoldCount(ctx, productID, quantity)
 	if err == nil {
-		go func() {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			productKey := fmt.Sprintf("products:%s", productID)
-			if err := helper.InvalidateProductCache(ctx, productKey); err != nil {
-				log.Printf("Error invalidating product cache: %v", err)
-			}
-			if err := helper.InvalidateProductCache(ctx, "bestselling:*"); err != nil {
-				log.Printf("Error invalidating best selling product cache: %v", err)
-			}
-		}()
+		s.cache.Delete(ctx, cache.ProductKey(productID))
+		s.cache.BumpNamespace(ctx, cache.NamespaceBestSelling)
+		s.publishStats(ctx, productID)
 	}
 	return err
 }
@@ -304,6 +484,11 @@ func (s *productServiceImpl) GetAllProductForIndex(ctx context.Context) ([]model
 	return products, nil
 }
 
+// ExportProducts duyệt toàn bộ sản phẩm, không giới hạn như GetAllProductForIndex
+func (s *productServiceImpl) ExportProducts(ctx context.Context, fn func(product models.Product) error) error {
+	return s.repo.ScanAll(ctx, fn)
+}
+
 func (s *productServiceImpl) GetS3PathIfExist(key string, expiration time.Duration) (string, error) {
 	if key == "" {
 		return "", errors.New("image key is empty")
@@ -317,6 +502,9 @@ func (s *productServiceImpl) GetProductByUserID(ctx context.Context, userID stri
 	if err != nil {
 		return nil, 0, 0, false, false, err
 	}
+	if err := s.applyBundleStock(ctx, products, false); err != nil {
+		return nil, 0, 0, false, false, err
+	}
 
 	for i := range products {
 		if len(products[i].ImagePath) > 0 {
@@ -345,9 +533,71 @@ func (s *productServiceImpl) GetProductByUserID(ctx context.Context, userID stri
 }
 
 func (s *productServiceImpl) GetProductByCategory(ctx context.Context, category string, page, limit int64) ([]models.Product, int64, int, bool, bool, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	// Category trong cây: lấy cả sản phẩm của các category con và sản phẩm cũ còn lưu tên free-text
+	values := []string{category}
+	if resolved, err := s.categories.ResolveCategory(ctx, category); err == nil {
+		values = []string{resolved.Slug, resolved.Name}
+		descendants, err := s.categories.GetDescendants(ctx, resolved.ID)
+		if err != nil {
+			return nil, 0, 0, false, false, err
+		}
+		for _, d := range descendants {
+			values = append(values, d.Slug, d.Name)
+		}
+	} else if !errors.Is(err, repository.ErrCategoryNotFound) {
+		return nil, 0, 0, false, false, err
+	}
+
+	// mỗi sản phẩm chỉ có một category nên các tập không trùng nhau: đếm từng tập rồi chỉ
+	// query trang cần lấy, không tải cả cây về bộ nhớ
+	type categoryCount struct {
+		value string
+		count int64
+	}
+	seenValues := make(map[string]bool, len(values))
+	var counts []categoryCount
+	var total int64
+	for _, v := range values {
+		if v == "" || seenValues[v] {
+			continue
+		}
+		seenValues[v] = true
+
+		n, err := s.repo.CountProductsByCategory(ctx, v)
+		if err != nil {
+			return nil, 0, 0, false, false, err
+		}
+		counts = append(counts, categoryCount{value: v, count: n})
+		total += n
+	}
+
 	skip := (page - 1) * limit
-	products, total, err := s.repo.GetProductByCategory(ctx, category, skip, limit)
-	if err != nil {
+	products := []models.Product{}
+	for _, cc := range counts {
+		need := limit - int64(len(products))
+		if need <= 0 {
+			break
+		}
+		if skip >= cc.count {
+			skip -= cc.count
+			continue
+		}
+		found, _, err := s.repo.GetProductByCategory(ctx, cc.value, skip, need)
+		if err != nil {
+			return nil, 0, 0, false, false, err
+		}
+		skip = 0
+		products = append(products, found...)
+	}
+
+	if err := s.applyBundleStock(ctx, products, false); err != nil {
 		return nil, 0, 0, false, false, err
 	}
 
@@ -375,4 +625,4 @@ func (s *productServiceImpl) GetProductByCategory(ctx context.Context, category
 	hasPrev := page > 1
 	
 	return products, total, pages, hasNext, hasPrev, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,534 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"product-service/cache"
+	"product-service/models"
+	"product-service/repository"
+
+	cartpb "module/gRPC-cart/service"
+)
+
+const (
+	// Đơn có quá nhiều sản phẩm thì chỉ lấy từng này sản phẩm đầu để tính cặp, tránh n^2 quá lớn
+	maxCoPurchaseItems = 20
+
+	recommendationTTL        = 15 * time.Minute
+	userRecommendationTTL    = 10 * time.Minute
+	defaultRecommendationLen = 10
+	maxRecommendationLen     = 50
+)
+
+type RecommendationService interface {
+	RecordOrder(ctx context.Context, order models.OrderLine) error
+	GetProductRecommendations(ctx context.Context, productID string, limit int) (*models.ProductRecommendations, error)
+	GetForUser(ctx context.Context, userID string, limit int) ([]models.RecommendedProduct, error)
+	Rebuild(ctx context.Context, history OrderHistory) error
+}
+
+type recommendationServiceImpl struct {
+	repo        repository.RecommendationRepository
+	productRepo repository.ProductRepository
+	products    ProductService
+	cart        cartpb.CartServiceClient
+	cache       *cache.Cache
+}
+
+// cart có thể nil (vd lệnh rebuild), khi đó "gợi ý cho bạn" chỉ dựa trên lịch sử mua
+func NewRecommendationService(repo repository.RecommendationRepository, productRepo repository.ProductRepository, products ProductService, cart cartpb.CartServiceClient) RecommendationService {
+	return &recommendationServiceImpl{
+		repo:        repo,
+		productRepo: productRepo,
+		products:    products,
+		cart:        cart,
+		cache:       cache.Default(),
+	}
+}
+
+func recommendationKey(productID string, limit int) string {
+	return fmt.Sprintf("recommendations:product:%s:%d", productID, limit)
+}
+
+func userRecommendationKey(userID string, limit int) string {
+	return fmt.Sprintf("recommendations:user:%s:%d", userID, limit)
+}
+
+func clampRecommendationLimit(limit int) int {
+	if limit <= 0 {
+		return defaultRecommendationLen
+	}
+	if limit > maxRecommendationLen {
+		return maxRecommendationLen
+	}
+	return limit
+}
+
+// distinctProducts bỏ trùng, giữ thứ tự xuất hiện trong đơn
+func distinctProducts(ids []string) []string {
+	seen := make(map[string]struct{}, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
+
+// RecordOrder cộng co-purchase cho mọi cặp sản phẩm trong đơn và affinity của user.
+// Kafka giao at-least-once nên một đơn có thể bị cộng 2 lần khi consumer restart, chấp nhận được cho gợi ý.
+func (s *recommendationServiceImpl) RecordOrder(ctx context.Context, order models.OrderLine) error {
+	ids := distinctProducts(order.ProductIDs)
+	if len(ids) > maxCoPurchaseItems {
+		ids = ids[:maxCoPurchaseItems]
+	}
+
+	for i, a := range ids {
+		for j, b := range ids {
+			if i == j {
+				continue
+			}
+			if err := s.repo.AddCoPurchase(ctx, a, b, 1); err != nil {
+				return err
+			}
+		}
+	}
+
+	if order.UserID != "" {
+		at := order.OrderedAt
+		if at.IsZero() {
+			at = time.Now()
+		}
+		for _, id := range ids {
+			qty := order.Quantities[id]
+			if qty <= 0 {
+				qty = 1
+			}
+			if err := s.repo.AddUserAffinity(ctx, order.UserID, id, qty, at); err != nil {
+				return err
+			}
+		}
+		s.cache.Delete(ctx, userRecommendationKey(order.UserID, defaultRecommendationLen))
+	}
+	return nil
+}
+
+func (s *recommendationServiceImpl) GetProductRecommendations(ctx context.Context, productID string, limit int) (*models.ProductRecommendations, error) {
+	limit = clampRecommendationLimit(limit)
+
+	product, err := s.products.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil || product.ID == "" {
+		return nil, nil
+	}
+
+	var scored []models.ScoredProduct
+	_, err = s.cache.GetOrLoad(ctx, recommendationKey(productID, limit), recommendationTTL, &scored, func(ctx context.Context) (interface{}, error) {
+		together, err := s.boughtTogether(ctx, []string{productID}, limit)
+		if err != nil {
+			return nil, err
+		}
+		similar, err := s.similar(ctx, product, limit)
+		if err != nil {
+			return nil, err
+		}
+		return append(together, similar...), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hydrated, err := s.hydrate(ctx, scored, map[string]struct{}{productID: {}})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ProductRecommendations{
+		ProductID:       productID,
+		BoughtTogether:  []models.RecommendedProduct{},
+		SimilarProducts: []models.RecommendedProduct{},
+	}
+	for _, p := range hydrated {
+		if p.Reason == models.RecommendBoughtTogether {
+			result.BoughtTogether = append(result.BoughtTogether, p)
+		} else {
+			result.SimilarProducts = append(result.SimilarProducts, p)
+		}
+	}
+	return result, nil
+}
+
+// boughtTogether gộp co-purchase của các seed, điểm = tổng count
+func (s *recommendationServiceImpl) boughtTogether(ctx context.Context, seeds []string, limit int) ([]models.ScoredProduct, error) {
+	scores := make(map[string]float64)
+	for _, seed := range seeds {
+		related, err := s.repo.TopCoPurchased(ctx, seed, limit*2)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range related {
+			scores[r.RelatedID] += float64(r.Count)
+		}
+	}
+	return topScored(scores, limit, models.RecommendBoughtTogether), nil
+}
+
+// similar chấm điểm các sản phẩm cùng category theo số attribute trùng và độ gần của giá
+func (s *recommendationServiceImpl) similar(ctx context.Context, product *models.Product, limit int) ([]models.ScoredProduct, error) {
+	if product.Category == "" {
+		return nil, nil
+	}
+	candidates, _, err := s.productRepo.GetProductByCategory(ctx, product.Category, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(map[string]float64)
+	for i := range candidates {
+		c := &candidates[i]
+		if c.ID == product.ID || !isRecommendable(c) {
+			continue
+		}
+		scores[c.ID] = similarityScore(product, c)
+	}
+	return topScored(scores, limit, models.RecommendSimilar), nil
+}
+
+func similarityScore(base, other *models.Product) float64 {
+	attrScore := 0.0
+	if len(base.Attributes) > 0 {
+		matches := 0
+		for k, v := range base.Attributes {
+			if ov, ok := other.Attributes[k]; ok && fmt.Sprint(ov) == fmt.Sprint(v) {
+				matches++
+			}
+		}
+		attrScore = float64(matches) / float64(len(base.Attributes))
+	}
+
+	priceScore := 0.0
+	if base.Price > 0 {
+		priceScore = 1 - math.Min(math.Abs(other.Price-base.Price)/base.Price, 1)
+	}
+
+	// sản phẩm bán chạy / đánh giá cao được ưu tiên nhẹ khi điểm gần bằng nhau
+	popularity := math.Log1p(float64(other.SoldCount))/10 + other.Rating/50
+	return 2*attrScore + priceScore + popularity
+}
+
+func isRecommendable(p *models.Product) bool {
+	return p.Quantity > 0 && (p.Status == "" || p.Status == "onsale")
+}
+
+func topScored(scores map[string]float64, limit int, reason string) []models.ScoredProduct {
+	out := make([]models.ScoredProduct, 0, len(scores))
+	for id, score := range scores {
+		out = append(out, models.ScoredProduct{ProductID: id, Score: score, Reason: reason})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].ProductID < out[j].ProductID
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// hydrate load product theo ID (qua cache), bỏ sản phẩm đã xoá / hết hàng / nằm trong exclude
+func (s *recommendationServiceImpl) hydrate(ctx context.Context, scored []models.ScoredProduct, exclude map[string]struct{}) ([]models.RecommendedProduct, error) {
+	ids := make([]string, 0, len(scored))
+	for _, sp := range scored {
+		ids = append(ids, sp.ProductID)
+	}
+	if len(ids) == 0 {
+		return []models.RecommendedProduct{}, nil
+	}
+
+	products, err := s.products.GetProductsCached(ctx, distinctProducts(ids))
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	out := make([]models.RecommendedProduct, 0, len(scored))
+	seen := make(map[string]struct{}, len(scored))
+	for _, sp := range scored {
+		p, ok := byID[sp.ProductID]
+		if !ok || !isRecommendable(&p) {
+			continue
+		}
+		if _, skip := exclude[sp.ProductID]; skip {
+			continue
+		}
+		if _, dup := seen[sp.ProductID]; dup {
+			continue
+		}
+		seen[sp.ProductID] = struct{}{}
+		out = append(out, models.RecommendedProduct{Product: p, Score: sp.Score, Reason: sp.Reason})
+	}
+	return out, nil
+}
+
+// GetForUser: seed là sản phẩm đã mua (ưu tiên mua gần đây) và sản phẩm trong giỏ.
+// Gợi ý = co-purchase của các seed + sản phẩm tương tự, bỏ những thứ user đã mua / đang có trong giỏ.
+// User chưa có lịch sử thì trả về sản phẩm bán chạy.
+func (s *recommendationServiceImpl) GetForUser(ctx context.Context, userID string, limit int) ([]models.RecommendedProduct, error) {
+	limit = clampRecommendationLimit(limit)
+
+	affinity, err := s.repo.GetUserAffinity(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	cartIDs := s.cartProductIDs(ctx, userID)
+
+	exclude := make(map[string]struct{}, len(affinity)+len(cartIDs))
+	for _, a := range affinity {
+		exclude[a.ProductID] = struct{}{}
+	}
+	for _, id := range cartIDs {
+		exclude[id] = struct{}{}
+	}
+
+	if len(affinity) == 0 && len(cartIDs) == 0 {
+		return s.popular(ctx, limit)
+	}
+
+	var scored []models.ScoredProduct
+	_, err = s.cache.GetOrLoad(ctx, userRecommendationKey(userID, limit), userRecommendationTTL, &scored, func(ctx context.Context) (interface{}, error) {
+		return s.scoreForUser(ctx, affinity, cartIDs, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.hydrate(ctx, scored, exclude)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return s.popular(ctx, limit)
+	}
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (s *recommendationServiceImpl) scoreForUser(ctx context.Context, affinity []models.UserAffinity, cartIDs []string, limit int) ([]models.ScoredProduct, error) {
+	sort.Slice(affinity, func(i, j int) bool {
+		return affinity[i].LastPurchasedAt.After(affinity[j].LastPurchasedAt)
+	})
+
+	// Giỏ hàng thể hiện ý định hiện tại nên đứng trước, tối đa 10 seed
+	seeds := append([]string{}, cartIDs...)
+	for _, a := range affinity {
+		seeds = append(seeds, a.ProductID)
+	}
+	seeds = distinctProducts(seeds)
+	if len(seeds) > 10 {
+		seeds = seeds[:10]
+	}
+
+	candidateLimit := limit * 3
+	together, err := s.boughtTogether(ctx, seeds, candidateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(map[string]float64)
+	for _, t := range together {
+		scores[t.ProductID] += t.Score
+	}
+
+	// Thêm sản phẩm tương tự của 3 seed đầu, trọng số thấp hơn co-purchase
+	similarSeeds := seeds
+	if len(similarSeeds) > 3 {
+		similarSeeds = similarSeeds[:3]
+	}
+	seedProducts, err := s.products.GetProductsCached(ctx, similarSeeds)
+	if err != nil {
+		return nil, err
+	}
+	for i := range seedProducts {
+		similar, err := s.similar(ctx, &seedProducts[i], candidateLimit)
+		if err != nil {
+			log.Printf("Error computing similar products for %s: %v", seedProducts[i].ID, err)
+			continue
+		}
+		for _, sp := range similar {
+			scores[sp.ProductID] += sp.Score / 2
+		}
+	}
+	for _, id := range seeds {
+		delete(scores, id)
+	}
+
+	return topScored(scores, candidateLimit, models.RecommendForYou), nil
+}
+
+func (s *recommendationServiceImpl) cartProductIDs(ctx context.Context, userID string) []string {
+	if s.cart == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	resp, err := s.cart.GetCartItems(ctx, &cartpb.CartRequest{UserId: userID})
+	if err != nil {
+		// Giỏ hàng chỉ là tín hiệu phụ, lỗi thì bỏ qua
+		log.Printf("Error getting cart for recommendations (user %s): %v", userID, err)
+		return nil
+	}
+	ids := make([]string, 0, len(resp.GetItems()))
+	for _, item := range resp.GetItems() {
+		ids = append(ids, item.GetProductId())
+	}
+	return ids
+}
+
+func (s *recommendationServiceImpl) popular(ctx context.Context, limit int) ([]models.RecommendedProduct, error) {
+	products, err := s.products.GetBestSellingProducts(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.RecommendedProduct, 0, len(products))
+	for _, p := range products {
+		if !isRecommendable(&p) {
+			continue
+		}
+		out = append(out, models.RecommendedProduct{Product: p, Score: float64(p.SoldCount), Reason: models.RecommendPopular})
+	}
+	return out, nil
+}
+
+// OrderHistory: nguồn toàn bộ đơn thành công để rebuild (order-service)
+type OrderHistory interface {
+	EachSuccessfulOrder(ctx context.Context, fn func(order models.OrderLine) error) error
+}
+
+// Rebuild tính lại toàn bộ từ lịch sử đơn vào một generation mới. Trong lúc đó consumer vẫn cộng
+// các đơn mới vào cả generation đang đọc lẫn generation mới, đọc vẫn dùng dữ liệu cũ cho tới khi
+// rebuild xong thì chuyển sang, nên không có lúc nào gợi ý bị rỗng hay mất đơn.
+// Đơn vừa thành công lúc bắt đầu có thể bị đếm 2 lần (như consumer at-least-once), chấp nhận được.
+func (s *recommendationServiceImpl) Rebuild(ctx context.Context, history OrderHistory) error {
+	generation, err := s.repo.BeginRebuild(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.rebuildGeneration(ctx, generation, history); err != nil {
+		abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		if abortErr := s.repo.AbortRebuild(abortCtx, generation); abortErr != nil {
+			log.Printf("Error aborting recommendation rebuild %d: %v", generation, abortErr)
+		}
+		return err
+	}
+	if err := s.repo.SwitchGeneration(ctx, generation); err != nil {
+		return err
+	}
+
+	// replica còn cache generation cũ vẫn đọc bảng cũ thêm một lúc, chờ rồi mới xoá
+	if err := sleepContext(ctx, repository.RecommendationGenerationTTL); err != nil {
+		return err
+	}
+	return s.repo.DeleteOtherGenerations(ctx, generation)
+}
+
+func (s *recommendationServiceImpl) rebuildGeneration(ctx context.Context, generation int, history OrderHistory) error {
+	// chờ consumer ở mọi replica thấy generation mới và bắt đầu ghi song song rồi mới đọc lịch sử
+	if err := sleepContext(ctx, repository.RecommendationGenerationTTL); err != nil {
+		return err
+	}
+
+	type pairKey struct{ a, b string }
+	type affinityKey struct{ user, product string }
+
+	pairs := make(map[pairKey]int)
+	affinity := make(map[affinityKey]*models.UserAffinity)
+	orders := 0
+
+	err := history.EachSuccessfulOrder(ctx, func(order models.OrderLine) error {
+		orders++
+		ids := distinctProducts(order.ProductIDs)
+		if len(ids) > maxCoPurchaseItems {
+			ids = ids[:maxCoPurchaseItems]
+		}
+		for i, a := range ids {
+			for j, b := range ids {
+				if i != j {
+					pairs[pairKey{a, b}]++
+				}
+			}
+		}
+		if order.UserID == "" {
+			return nil
+		}
+		for _, id := range ids {
+			qty := order.Quantities[id]
+			if qty <= 0 {
+				qty = 1
+			}
+			k := affinityKey{order.UserID, id}
+			a, ok := affinity[k]
+			if !ok {
+				a = &models.UserAffinity{UserID: order.UserID, ProductID: id}
+				affinity[k] = a
+			}
+			a.Count += qty
+			if order.OrderedAt.After(a.LastPurchasedAt) {
+				a.LastPurchasedAt = order.OrderedAt
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Rebuilding recommendations generation %d from %d orders: %d co-purchase pairs, %d user affinities", generation, orders, len(pairs), len(affinity))
+
+	// ADD chứ không ghi đè, để giữ phần consumer đã cộng vào generation mới
+	now := time.Now()
+	for k, count := range pairs {
+		if err := s.repo.AddRebuildCoPurchase(ctx, generation, k.a, k.b, count); err != nil {
+			return err
+		}
+	}
+	for _, a := range affinity {
+		if a.LastPurchasedAt.IsZero() {
+			a.LastPurchasedAt = now
+		}
+		if err := s.repo.AddRebuildUserAffinity(ctx, generation, a.UserID, a.ProductID, a.Count, a.LastPurchasedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
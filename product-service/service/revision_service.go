@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"product-service/models"
+	"product-service/repository"
+)
+
+type RevisionService interface {
+	ListRevisions(ctx context.Context, productID, sellerID string, limit int) ([]models.ProductRevision, error)
+	DiffRevisions(ctx context.Context, productID, sellerID string, from, to int) (*models.RevisionDiff, error)
+	Rollback(ctx context.Context, productID, sellerID string, revision int) error
+}
+
+type revisionServiceImpl struct {
+	repo      repository.ProductRepository
+	revisions repository.RevisionRepository
+	products  ProductService
+}
+
+func NewRevisionService(repo repository.ProductRepository, revisions repository.RevisionRepository, products ProductService) RevisionService {
+	return &revisionServiceImpl{repo: repo, revisions: revisions, products: products}
+}
+
+// rollback đi qua EditProduct như một lần sửa bình thường (cache, product-events, price history),
+// context mang theo action để revision mới được ghi là rollback
+type revisionActionKey struct{}
+
+type revisionAction struct {
+	action       string
+	restoredFrom int
+}
+
+func withRevisionAction(ctx context.Context, action string, restoredFrom int) context.Context {
+	return context.WithValue(ctx, revisionActionKey{}, revisionAction{action: action, restoredFrom: restoredFrom})
+}
+
+// recordRevision lưu trạng thái sau khi thay đổi, lỗi chỉ log để không chặn việc sửa sản phẩm
+func (s *productServiceImpl) recordRevision(ctx context.Context, before, after *models.Product, actor string) {
+	if s.revisions == nil {
+		return
+	}
+
+	meta, _ := ctx.Value(revisionActionKey{}).(revisionAction)
+	rev := models.ProductRevision{
+		ProductID:    after.ID,
+		Action:       meta.action,
+		ChangedBy:    actor,
+		ChangedAt:    time.Now(),
+		RestoredFrom: meta.restoredFrom,
+		Snapshot:     models.SnapshotOf(after),
+	}
+	if before == nil {
+		rev.Action = models.RevisionCreated
+		for _, c := range models.DiffSnapshots(models.ProductSnapshot{}, rev.Snapshot) {
+			rev.ChangedFields = append(rev.ChangedFields, c.Field)
+		}
+	} else {
+		for _, c := range models.DiffSnapshots(models.SnapshotOf(before), rev.Snapshot) {
+			rev.ChangedFields = append(rev.ChangedFields, c.Field)
+		}
+		// sửa mà không đổi gì thì không tạo revision
+		if len(rev.ChangedFields) == 0 {
+			return
+		}
+	}
+	if rev.Action == "" {
+		rev.Action = models.RevisionUpdated
+	}
+	if rev.ChangedBy == "" {
+		rev.ChangedBy = after.UserID
+	}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		next, err := s.nextRevision(ctx, after.ID, before)
+		if err != nil {
+			log.Printf("Error reading revisions of product %s: %v", after.ID, err)
+			return
+		}
+		rev.Revision = next
+		err = s.revisions.Insert(ctx, rev)
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, repository.ErrRevisionExists) {
+			log.Printf("Error recording revision of product %s: %v", after.ID, err)
+			return
+		}
+	}
+	log.Printf("Error recording revision of product %s: too many concurrent edits", after.ID)
+}
+
+// nextRevision: sản phẩm tạo trước khi có lịch sử thì lưu trạng thái cũ làm revision 1 (baseline)
+func (s *productServiceImpl) nextRevision(ctx context.Context, productID string, before *models.Product) (int, error) {
+	latest, err := s.revisions.Latest(ctx, productID)
+	if err == nil {
+		return latest.Revision + 1, nil
+	}
+	if !errors.Is(err, repository.ErrRevisionNotFound) {
+		return 0, err
+	}
+	if before == nil {
+		return 1, nil
+	}
+
+	baseline := models.ProductRevision{
+		ProductID: before.ID,
+		Revision:  1,
+		Action:    models.RevisionBaseline,
+		ChangedBy: before.UserID,
+		ChangedAt: before.Updated_at,
+		Snapshot:  models.SnapshotOf(before),
+	}
+	if err := s.revisions.Insert(ctx, baseline); err != nil && !errors.Is(err, repository.ErrRevisionExists) {
+		return 0, err
+	}
+	return 2, nil
+}
+
+func (s *revisionServiceImpl) ownedProduct(ctx context.Context, productID, sellerID string) (*models.Product, error) {
+	product, err := s.repo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.UserID != sellerID {
+		return nil, &ValidationError{Field: "id", Message: "product does not belong to you"}
+	}
+	return product, nil
+}
+
+func (s *revisionServiceImpl) ListRevisions(ctx context.Context, productID, sellerID string, limit int) ([]models.ProductRevision, error) {
+	if _, err := s.ownedProduct(ctx, productID, sellerID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	return s.revisions.List(ctx, productID, limit)
+}
+
+// DiffRevisions so sánh 2 revision, to = 0 là revision mới nhất, from = 0 là revision ngay trước to
+func (s *revisionServiceImpl) DiffRevisions(ctx context.Context, productID, sellerID string, from, to int) (*models.RevisionDiff, error) {
+	if _, err := s.ownedProduct(ctx, productID, sellerID); err != nil {
+		return nil, err
+	}
+
+	var toRev *models.ProductRevision
+	var err error
+	if to <= 0 {
+		toRev, err = s.revisions.Latest(ctx, productID)
+	} else {
+		toRev, err = s.revisions.Get(ctx, productID, to)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if from <= 0 {
+		from = toRev.Revision - 1
+	}
+
+	diff := &models.RevisionDiff{ProductID: productID, FromRevision: from, ToRevision: toRev.Revision}
+	if from < 1 {
+		// revision đầu tiên: so với sản phẩm rỗng
+		diff.FromRevision = 0
+		diff.Changes = models.DiffSnapshots(models.ProductSnapshot{}, toRev.Snapshot)
+		return diff, nil
+	}
+
+	fromRev, err := s.revisions.Get(ctx, productID, from)
+	if err != nil {
+		return nil, err
+	}
+	diff.Changes = models.DiffSnapshots(fromRev.Snapshot, toRev.Snapshot)
+	return diff, nil
+}
+
+// Rollback đưa nội dung sản phẩm về revision cũ. Tồn kho không khôi phục vì đã thay đổi theo đơn hàng.
+func (s *revisionServiceImpl) Rollback(ctx context.Context, productID, sellerID string, revision int) error {
+	product, err := s.ownedProduct(ctx, productID, sellerID)
+	if err != nil {
+		return err
+	}
+
+	target, err := s.revisions.Get(ctx, productID, revision)
+	if err != nil {
+		return err
+	}
+
+	current := models.SnapshotOf(product)
+	update := make(map[string]interface{})
+	for _, c := range models.DiffSnapshots(current, target.Snapshot) {
+		switch c.Field {
+		case "quantity":
+			continue
+		case "category":
+			// EditProduct resolve lại category theo slug rồi validate attributes
+			update["category"] = target.Snapshot.Category
+			update["attributes"] = attributesOrEmpty(target.Snapshot.Attributes)
+		case "attributes":
+			update["attributes"] = attributesOrEmpty(target.Snapshot.Attributes)
+		default:
+			update[c.Field] = c.To
+		}
+	}
+	if len(update) == 0 {
+		return &ValidationError{Field: "revision", Message: fmt.Sprintf("product already matches revision %d", revision)}
+	}
+
+	return s.products.EditProduct(withRevisionAction(ctx, models.RevisionRollback, revision), productID, sellerID, update)
+}
+
+func attributesOrEmpty(attrs map[string]interface{}) map[string]interface{} {
+	if attrs == nil {
+		return map[string]interface{}{}
+	}
+	return attrs
+}
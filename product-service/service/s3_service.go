@@ -257,3 +257,42 @@ func (s *S3Service) GeneratePresignedDownloadURL(key string, expiration time.Dur
 
 	return presignedURL, nil
 }
+
+// GeneratePresignedDigitalUploadURL - file của sản phẩm số (ebook, phần mềm...) không giới hạn đuôi như ảnh,
+// để riêng dưới digital/<seller_id>/ và luôn private, buyer chỉ tải được qua presigned download URL
+func (s *S3Service) GeneratePresignedDigitalUploadURL(sellerID, filename, contentType string) (models.PresignedUploadResponse, error) {
+	base := filepath.Base(strings.TrimSpace(filename))
+	if base == "" || base == "." || base == "/" {
+		return models.PresignedUploadResponse{}, fmt.Errorf("invalid file name")
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	uniqueFilename := fmt.Sprintf("%d_%s", time.Now().UnixNano(), base)
+	key := fmt.Sprintf("%s%s", DigitalFilePrefix(sellerID), uniqueFilename)
+
+	req, _ := s.client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket:      aws.String(s.config.BucketName),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	})
+
+	expiration := 15 * time.Minute
+	presignedURL, err := req.Presign(expiration)
+	if err != nil {
+		return models.PresignedUploadResponse{}, err
+	}
+
+	return models.PresignedUploadResponse{
+		PresignedURL: presignedURL,
+		S3Key:        key,
+		Filename:     uniqueFilename,
+		ExpiresAt:    time.Now().Add(expiration).Unix(),
+		ExpiresIn:    int(expiration.Seconds()),
+	}, nil
+}
+
+func DigitalFilePrefix(sellerID string) string {
+	return "digital/" + sellerID + "/"
+}
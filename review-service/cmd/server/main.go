@@ -15,6 +15,7 @@ import (
 	"google.golang.org/grpc"
 
 	pb "module/gRPC-Order/service"
+	productpb "module/gRPC-Product/service"
 	"review-service/config"
 	"review-service/internal/cron"
 	"review-service/internal/handlers"
@@ -65,6 +66,14 @@ func main() {
     defer conn.Close()
 	orderClient := pb.NewOrderServiceClient(conn)
 
+	productServiceAddress := config.Get("PRODUCT_SERVICE_ADDRESS", "product-service:8089")
+	productConn, err := grpc.Dial(productServiceAddress, grpc.WithInsecure())
+	if err != nil {
+		logger.Logger.Fatal("Failed to connect to product-service: " + err.Error())
+	}
+	defer productConn.Close()
+	productClient := productpb.NewProductServiceClient(productConn)
+
 	h := handlers.NewReviewHandler(service, orderClient)
 
 	// Initialize Kafka Producer
@@ -72,6 +81,18 @@ func main() {
 	kafkaProducer := kafka.NewProducer(kafkaBrokers, config.Get("KAFKA_RATING_TOPIC", "product_rating_updates"))
 	defer kafkaProducer.Close()
 
+	emailProducer := kafka.NewProducer(kafkaBrokers, config.Get("KAFKA_EMAIL_TOPIC", "email_topic"))
+	defer emailProducer.Close()
+
+	// Q&A
+	questionRepo := repository.NewQuestionRepository(dynamoClient,
+		config.Get("DYNAMODB_QUESTION_TABLE", "question-table"),
+		config.Get("DYNAMODB_ANSWER_TABLE", "answer-table"),
+		config.Get("DYNAMODB_ANSWER_VOTE_TABLE", "answer-vote-table"),
+	)
+	questionService := services.NewQuestionService(questionRepo, productClient, orderClient, emailProducer)
+	qh := handlers.NewQuestionHandler(questionService)
+
 	// Initialize Review Aggregator (sử dụng AWS SDK v2)
 	aggregator := cron.NewPendingReviewAggregator(dynamoClient, kafkaProducer, pendingTable)
 
@@ -81,7 +102,7 @@ func main() {
 	defer scheduler.Stop()
 
 	r := gin.Default()
-	routes.RegisterRoutes(r, h)
+	routes.RegisterRoutes(r, h, qh)
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -105,5 +126,6 @@ func main() {
 	log.Println("Shutting down review service...")
 	scheduler.Stop()
 	kafkaProducer.Close()
+	emailProducer.Close()
 	log.Println("Review service stopped")
 }
@@ -10,7 +10,9 @@ require (
 	github.com/google/uuid v1.6.0
 	github.com/joho/godotenv v1.5.1
 	github.com/robfig/cron/v3 v3.0.1
+	google.golang.org/grpc v1.73.0
 	module/gRPC-Order v0.0.0-00010101000000-000000000000
+	module/gRPC-Product v0.0.0-00010101000000-000000000000
 )
 
 require (
@@ -25,7 +27,6 @@ require (
 	github.com/pierrec/lz4/v4 v4.1.15 // indirect
 	go.uber.org/multierr v1.10.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250324211829-b45e905df463 // indirect
-	google.golang.org/grpc v1.73.0 // indirect
 )
 
 require (
@@ -69,3 +70,5 @@ require (
 replace github.com/Dattt2k2/golang-project/review-service => ../review-service
 
 replace module/gRPC-Order => ../module/gRPC-Order
+
+replace module/gRPC-Product => ../module/gRPC-Product
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"review-service/internal/models"
+	"review-service/internal/repository"
+	"review-service/internal/services"
+	logger "review-service/log"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type QuestionHandler struct {
+	service services.QuestionService
+}
+
+func NewQuestionHandler(service services.QuestionService) *QuestionHandler {
+	return &QuestionHandler{service: service}
+}
+
+func (h *QuestionHandler) AskQuestion() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		productID := c.Param("product_id")
+
+		var input models.CreateQuestionRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload: " + err.Error()})
+			return
+		}
+
+		q, err := h.service.Ask(c.Request.Context(), productID, userID, input.Body)
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrProductNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			case errors.Is(err, services.ErrOwnProduct):
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			default:
+				logger.Error("Failed to save question", zap.Error(err))
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "save failed"})
+			}
+			return
+		}
+		c.JSON(http.StatusCreated, q)
+	}
+}
+
+func (h *QuestionHandler) AnswerQuestion() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		questionID := c.Param("question_id")
+
+		var input models.CreateAnswerRequest
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload: " + err.Error()})
+			return
+		}
+
+		a, err := h.service.Answer(c.Request.Context(), questionID, userID, input.Body)
+		if err != nil {
+			switch {
+			case errors.Is(err, repository.ErrQuestionNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			case errors.Is(err, services.ErrNotAllowedAnswer):
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			default:
+				logger.Error("Failed to save answer", zap.Error(err))
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "save failed"})
+			}
+			return
+		}
+		c.JSON(http.StatusCreated, a)
+	}
+}
+
+func (h *QuestionHandler) UpvoteAnswer() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		upvotes, err := h.service.Upvote(c.Request.Context(), c.Param("question_id"), c.Param("answer_id"), userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, repository.ErrAnswerNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			case errors.Is(err, repository.ErrAlreadyVoted):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				logger.Error("Failed to upvote answer", zap.Error(err))
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "upvote failed"})
+			}
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"upvotes": upvotes})
+	}
+}
+
+func (h *QuestionHandler) ListQuestions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		productID := c.Param("product_id")
+		if productID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "product_id required"})
+			return
+		}
+
+		limit := 10
+		if q := c.Query("limit"); q != "" {
+			if l, err := strconv.Atoi(q); err == nil && l > 0 {
+				limit = l
+			}
+		}
+		if limit > 50 {
+			limit = 50
+		}
+
+		questions, nextCursor, err := h.service.ListByProduct(c.Request.Context(), productID, limit, c.Query("cursor"))
+		if errors.Is(err, services.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "list failed"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"questions": questions, "count": len(questions), "next_cursor": nextCursor})
+	}
+}
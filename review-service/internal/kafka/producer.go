@@ -83,6 +83,24 @@ func (p *Producer) publishOnce(ctx context.Context, message interface{}) error {
 	return nil
 }
 
+// Publish gửi 1 message bất kỳ với key tùy chọn, dùng cho các topic ngoài rating (vd: email_topic)
+func (p *Producer) Publish(ctx context.Context, key string, message interface{}) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	err = p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(key),
+		Value: data,
+		Time:  time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
 func (p *Producer) Close() error {
 	return p.writer.Close()
 }
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+const (
+	AuthorTypeVendor        = "vendor"
+	AuthorTypeVerifiedBuyer = "verified_buyer"
+)
+
+type Question struct {
+	ID          string    `json:"id" dynamodbav:"id"`
+	ProductID   string    `json:"product_id" dynamodbav:"product_id"`
+	UserID      string    `json:"user_id" dynamodbav:"user_id"`
+	VendorID    string    `json:"vendor_id" dynamodbav:"vendor_id"`
+	Body        string    `json:"body" dynamodbav:"body"`
+	AnswerCount int       `json:"answer_count" dynamodbav:"answer_count"`
+	CreatedAt   time.Time `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+type Answer struct {
+	ID         string    `json:"id" dynamodbav:"id"`
+	QuestionID string    `json:"question_id" dynamodbav:"question_id"`
+	ProductID  string    `json:"product_id" dynamodbav:"product_id"`
+	UserID     string    `json:"user_id" dynamodbav:"user_id"`
+	Body       string    `json:"body" dynamodbav:"body"`
+	AuthorType string    `json:"author_type" dynamodbav:"author_type"`
+	Upvotes    int       `json:"upvotes" dynamodbav:"upvotes"`
+	CreatedAt  time.Time `json:"created_at" dynamodbav:"created_at"`
+}
+
+type QuestionWithAnswers struct {
+	Question
+	Answers []Answer `json:"answers"`
+}
+
+type CreateQuestionRequest struct {
+	Body string `json:"body" binding:"required,min=5,max=1000"`
+}
+
+type CreateAnswerRequest struct {
+	Body string `json:"body" binding:"required,min=1,max=2000"`
+}
+
+// Message gửi sang email-service khi có câu hỏi mới
+type NewQuestionEmail struct {
+	To       string                 `json:"to"`
+	UserID   string                 `json:"user_id"`
+	Subject  string                 `json:"subject"`
+	Template string                 `json:"template"`
+	Data     map[string]interface{} `json:"data"`
+}
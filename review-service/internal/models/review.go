@@ -14,7 +14,7 @@ type Review struct {
 
 type SumReviewPending struct {
 	ProductID   string    `json:"product_id" dynamodbav:"product_id"`
-	ReviewID    string    `json:"review_id", dynamodbav:"review_id"`
+	ReviewID    string    `json:"review_id" dynamodbav:"review_id"`
 	LastTimeSum time.Time `json:"last_time_sum" dynamodbav:"last_time_sum"`
 	Rating      int       `json:"rating" dynamodbav:"rating"`
 }
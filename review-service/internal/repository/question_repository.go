@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"review-service/internal/models"
+	logger "review-service/log"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrQuestionNotFound = errors.New("question not found")
+	ErrAnswerNotFound   = errors.New("answer not found")
+	ErrAlreadyVoted     = errors.New("already voted")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+)
+
+const questionProductIndex = "product_id-created_at-index"
+
+type QuestionRepository interface {
+	CreateQuestion(ctx context.Context, q *models.Question) error
+	GetQuestion(ctx context.Context, id string) (*models.Question, error)
+	ListByProduct(ctx context.Context, productID string, limit int, lastKey string) ([]models.Question, string, error)
+	CreateAnswer(ctx context.Context, a *models.Answer) error
+	GetAnswer(ctx context.Context, questionID, answerID string) (*models.Answer, error)
+	ListAnswers(ctx context.Context, questionID string) ([]models.Answer, error)
+	Upvote(ctx context.Context, questionID, answerID, userID string) (int, error)
+}
+
+type QuestionRepositoryImpl struct {
+	client      *dynamodb.Client
+	table       string
+	answerTable string
+	voteTable   string
+}
+
+func NewQuestionRepository(client *dynamodb.Client, table, answerTable, voteTable string) QuestionRepository {
+	return &QuestionRepositoryImpl{
+		client:      client,
+		table:       table,
+		answerTable: answerTable,
+		voteTable:   voteTable,
+	}
+}
+
+func (r *QuestionRepositoryImpl) CreateQuestion(ctx context.Context, q *models.Question) error {
+	if q.ID == "" {
+		q.ID = uuid.New().String()
+	}
+	now := time.Now().UTC()
+	q.CreatedAt = now
+	q.UpdatedAt = now
+
+	item, err := attributevalue.MarshalMap(q)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.table),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	})
+	return err
+}
+
+func (r *QuestionRepositoryImpl) GetQuestion(ctx context.Context, id string) (*models.Question, error) {
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.table),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.Item == nil {
+		return nil, ErrQuestionNotFound
+	}
+
+	var q models.Question
+	if err := attributevalue.UnmarshalMap(out.Item, &q); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// Câu hỏi mới nhất lên trước, phân trang bằng LastEvaluatedKey của GSI
+func (r *QuestionRepositoryImpl) ListByProduct(ctx context.Context, productID string, limit int, lastKey string) ([]models.Question, string, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.table),
+		IndexName:              aws.String(questionProductIndex),
+		KeyConditionExpression: aws.String("product_id = :pid"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pid": &types.AttributeValueMemberS{Value: productID},
+		},
+		ScanIndexForward: aws.Bool(false),
+	}
+	if limit > 0 {
+		input.Limit = aws.Int32(int32(limit))
+	}
+
+	if lastKey != "" {
+		startKey, err := decodeLastKey(lastKey)
+		if err != nil {
+			return nil, "", ErrInvalidCursor
+		}
+		// cursor phải là key của index này và đúng sản phẩm, không thì DynamoDB trả ValidationException
+		pid, ok := startKey["product_id"].(*types.AttributeValueMemberS)
+		if !ok || pid.Value != productID || startKey["id"] == nil || startKey["created_at"] == nil {
+			return nil, "", ErrInvalidCursor
+		}
+		input.ExclusiveStartKey = startKey
+	}
+
+	result, err := r.client.Query(ctx, input)
+	if err != nil {
+		logger.Error("Failed to list questions: " + err.Error())
+		return nil, "", err
+	}
+
+	questions := []models.Question{}
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &questions); err != nil {
+		return nil, "", err
+	}
+
+	nextKey := ""
+	if len(result.LastEvaluatedKey) != 0 {
+		enc, err := encodeLastKey(result.LastEvaluatedKey)
+		if err != nil {
+			return nil, "", err
+		}
+		nextKey = enc
+	}
+
+	return questions, nextKey, nil
+}
+
+func (r *QuestionRepositoryImpl) CreateAnswer(ctx context.Context, a *models.Answer) error {
+	if a.ID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		a.ID = id.String()
+	}
+	a.CreatedAt = time.Now().UTC()
+
+	item, err := attributevalue.MarshalMap(a)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.answerTable),
+		Item:      item,
+	}); err != nil {
+		return err
+	}
+
+	// Đếm số câu trả lời để hiển thị ở danh sách, lỗi ở đây không ảnh hưởng câu trả lời đã lưu
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.table),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: a.QuestionID},
+		},
+		UpdateExpression: aws.String("ADD answer_count :one SET updated_at = :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one": &types.AttributeValueMemberN{Value: "1"},
+			":now": &types.AttributeValueMemberS{Value: a.CreatedAt.Format(time.RFC3339Nano)},
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to increase answer count: " + err.Error())
+	}
+	return nil
+}
+
+func (r *QuestionRepositoryImpl) GetAnswer(ctx context.Context, questionID, answerID string) (*models.Answer, error) {
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.answerTable),
+		Key: map[string]types.AttributeValue{
+			"question_id": &types.AttributeValueMemberS{Value: questionID},
+			"id":          &types.AttributeValueMemberS{Value: answerID},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.Item == nil {
+		return nil, ErrAnswerNotFound
+	}
+
+	var a models.Answer
+	if err := attributevalue.UnmarshalMap(out.Item, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *QuestionRepositoryImpl) ListAnswers(ctx context.Context, questionID string) ([]models.Answer, error) {
+	answers := []models.Answer{}
+	var startKey map[string]types.AttributeValue
+
+	for {
+		out, err := r.client.Query(ctx, &dynamodb.QueryInput{
+			TableName:              aws.String(r.answerTable),
+			KeyConditionExpression: aws.String("question_id = :qid"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":qid": &types.AttributeValueMemberS{Value: questionID},
+			},
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var page []models.Answer
+		if err := attributevalue.UnmarshalListOfMaps(out.Items, &page); err != nil {
+			return nil, err
+		}
+		answers = append(answers, page...)
+
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = out.LastEvaluatedKey
+	}
+	return answers, nil
+}
+
+// Mỗi user chỉ vote 1 lần cho 1 câu trả lời. Ghi vote và tăng upvotes trong cùng một transaction
+// để không có vote nào được ghi mà không được đếm.
+func (r *QuestionRepositoryImpl) Upvote(ctx context.Context, questionID, answerID, userID string) (int, error) {
+	answerKey := map[string]types.AttributeValue{
+		"question_id": &types.AttributeValueMemberS{Value: questionID},
+		"id":          &types.AttributeValueMemberS{Value: answerID},
+	}
+	_, err := r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Put: &types.Put{
+				TableName: aws.String(r.voteTable),
+				Item: map[string]types.AttributeValue{
+					"answer_id":  &types.AttributeValueMemberS{Value: answerID},
+					"user_id":    &types.AttributeValueMemberS{Value: userID},
+					"created_at": &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+				},
+				ConditionExpression: aws.String("attribute_not_exists(user_id)"),
+			}},
+			{Update: &types.Update{
+				TableName:           aws.String(r.answerTable),
+				Key:                 answerKey,
+				UpdateExpression:    aws.String("ADD upvotes :one"),
+				ConditionExpression: aws.String("attribute_exists(id)"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":one": &types.AttributeValueMemberN{Value: "1"},
+				},
+			}},
+		},
+	})
+	if err != nil {
+		var canceled *types.TransactionCanceledException
+		if errors.As(err, &canceled) {
+			for i, reason := range canceled.CancellationReasons {
+				if aws.ToString(reason.Code) != "ConditionalCheckFailed" {
+					continue
+				}
+				if i == 0 {
+					return 0, ErrAlreadyVoted
+				}
+				return 0, ErrAnswerNotFound
+			}
+		}
+		return 0, err
+	}
+
+	// transaction không trả về giá trị mới, đọc lại (consistent) để lấy số upvote
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:            aws.String(r.answerTable),
+		Key:                  answerKey,
+		ProjectionExpression: aws.String("upvotes"),
+		ConsistentRead:       aws.Bool(true),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	upvotes := 0
+	if v, ok := out.Item["upvotes"].(*types.AttributeValueMemberN); ok {
+		upvotes, _ = strconv.Atoi(v.Value)
+	}
+	return upvotes, nil
+}
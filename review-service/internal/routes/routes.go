@@ -7,7 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterRoutes(r *gin.Engine, h *handlers.ReviewHandler) {
+func RegisterRoutes(r *gin.Engine, h *handlers.ReviewHandler, qh *handlers.QuestionHandler) {
 	// Health check
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok", "service": "review-service"})
@@ -17,10 +17,18 @@ func RegisterRoutes(r *gin.Engine, h *handlers.ReviewHandler) {
 		{
 			products.POST("create-reviews/:product_id", h.CreateReview())
 			products.GET("/reviews/:product_id", h.ListReviews())
+			products.GET("/questions/:product_id", qh.ListQuestions())
+			products.POST("/questions/:product_id", qh.AskQuestion())
 		}
 
 		reviews := r.Group("/reviews")
 		{
 			reviews.GET("/:review_id", h.GetReview())
 		}
+
+		questions := r.Group("/questions")
+		{
+			questions.POST("/:question_id/answers", qh.AnswerQuestion())
+			questions.POST("/:question_id/answers/:answer_id/upvote", qh.UpvoteAnswer())
+		}
 }
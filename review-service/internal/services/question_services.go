@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	orderpb "module/gRPC-Order/service"
+	productpb "module/gRPC-Product/service"
+	"review-service/internal/kafka"
+	"review-service/internal/models"
+	"review-service/internal/repository"
+	logger "review-service/log"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrProductNotFound  = errors.New("product not found")
+	ErrOwnProduct       = errors.New("vendor cannot ask questions on own product")
+	ErrNotAllowedAnswer = errors.New("only the vendor or verified buyers can answer")
+	ErrInvalidCursor    = repository.ErrInvalidCursor
+)
+
+type QuestionService interface {
+	Ask(ctx context.Context, productID, userID, body string) (*models.Question, error)
+	Answer(ctx context.Context, questionID, userID, body string) (*models.Answer, error)
+	Upvote(ctx context.Context, questionID, answerID, userID string) (int, error)
+	ListByProduct(ctx context.Context, productID string, limit int, cursor string) ([]models.QuestionWithAnswers, string, error)
+}
+
+type questionServiceImpl struct {
+	repo          repository.QuestionRepository
+	productClient productpb.ProductServiceClient
+	orderClient   orderpb.OrderServiceClient
+	emailProducer *kafka.Producer
+}
+
+func NewQuestionService(repo repository.QuestionRepository, productClient productpb.ProductServiceClient, orderClient orderpb.OrderServiceClient, emailProducer *kafka.Producer) QuestionService {
+	return &questionServiceImpl{
+		repo:          repo,
+		productClient: productClient,
+		orderClient:   orderClient,
+		emailProducer: emailProducer,
+	}
+}
+
+func (s *questionServiceImpl) Ask(ctx context.Context, productID, userID, body string) (*models.Question, error) {
+	product, err := s.productClient.GetBasicInfo(ctx, &productpb.ProductRequest{Id: productID})
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if product.GetId() == "" {
+		return nil, ErrProductNotFound
+	}
+	if product.GetVendorId() == userID {
+		return nil, ErrOwnProduct
+	}
+
+	q := &models.Question{
+		ProductID: productID,
+		UserID:    userID,
+		VendorID:  product.GetVendorId(),
+		Body:      body,
+	}
+	if err := s.repo.CreateQuestion(ctx, q); err != nil {
+		return nil, err
+	}
+
+	go s.notifyVendor(q, product.GetName())
+	return q, nil
+}
+
+func (s *questionServiceImpl) notifyVendor(q *models.Question, productName string) {
+	if s.emailProducer == nil || q.VendorID == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	msg := models.NewQuestionEmail{
+		UserID:   q.VendorID,
+		Subject:  "Có câu hỏi mới về sản phẩm " + productName,
+		Template: "./template/new_question.html",
+		Data: map[string]interface{}{
+			"ProductID":   q.ProductID,
+			"ProductName": productName,
+			"Question":    q.Body,
+		},
+	}
+	if err := s.emailProducer.Publish(ctx, q.VendorID, msg); err != nil {
+		logger.Error("Failed to publish new question email", zap.String("question_id", q.ID), zap.Error(err))
+	}
+}
+
+func (s *questionServiceImpl) Answer(ctx context.Context, questionID, userID, body string) (*models.Answer, error) {
+	q, err := s.repo.GetQuestion(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	authorType := models.AuthorTypeVendor
+	if q.VendorID != userID {
+		resp, err := s.orderClient.HasPurchased(ctx, &orderpb.HasPurchasedRequest{
+			UserId:    userID,
+			ProductId: q.ProductID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !resp.GetPurchased() {
+			return nil, ErrNotAllowedAnswer
+		}
+		authorType = models.AuthorTypeVerifiedBuyer
+	}
+
+	a := &models.Answer{
+		QuestionID: q.ID,
+		ProductID:  q.ProductID,
+		UserID:     userID,
+		Body:       body,
+		AuthorType: authorType,
+	}
+	if err := s.repo.CreateAnswer(ctx, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *questionServiceImpl) Upvote(ctx context.Context, questionID, answerID, userID string) (int, error) {
+	if _, err := s.repo.GetAnswer(ctx, questionID, answerID); err != nil {
+		return 0, err
+	}
+	return s.repo.Upvote(ctx, questionID, answerID, userID)
+}
+
+func (s *questionServiceImpl) ListByProduct(ctx context.Context, productID string, limit int, cursor string) ([]models.QuestionWithAnswers, string, error) {
+	questions, nextKey, err := s.repo.ListByProduct(ctx, productID, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	result := make([]models.QuestionWithAnswers, 0, len(questions))
+	for _, q := range questions {
+		item := models.QuestionWithAnswers{Question: q, Answers: []models.Answer{}}
+		if q.AnswerCount > 0 {
+			answers, err := s.repo.ListAnswers(ctx, q.ID)
+			if err != nil {
+				return nil, "", err
+			}
+			// Câu trả lời của vendor lên đầu, sau đó theo số upvote
+			sort.SliceStable(answers, func(i, j int) bool {
+				vi := answers[i].AuthorType == models.AuthorTypeVendor
+				vj := answers[j].AuthorType == models.AuthorTypeVendor
+				if vi != vj {
+					return vi
+				}
+				if answers[i].Upvotes != answers[j].Upvotes {
+					return answers[i].Upvotes > answers[j].Upvotes
+				}
+				return answers[i].CreatedAt.Before(answers[j].CreatedAt)
+			})
+			item.Answers = answers
+		}
+		result = append(result, item)
+	}
+	return result, nextKey, nil
+}
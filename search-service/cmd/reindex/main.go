@@ -0,0 +1,55 @@
+// Lệnh dựng lại index sản phẩm: tạo index phiên bản mới theo ProductIndexDefinition, nạp toàn bộ sản phẩm
+// từ product-service bằng _bulk rồi chuyển alias ELASTICSEARCH_INDEX sang. Search vẫn chạy trong lúc reindex.
+//
+//	go run ./cmd/reindex            # reindex
+//	go run ./cmd/reindex -rollback  # chuyển alias về index trước đó
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"search-service/database"
+	logger "search-service/log"
+	"search-service/models"
+	"search-service/repository"
+	"search-service/service"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	rollback := flag.Bool("rollback", false, "point the alias back to the previous index")
+	flag.Parse()
+
+	if err := godotenv.Load("./search-service/.env"); err != nil {
+		log.Println("Warning: Error loading .env file:", err)
+	}
+	logger.InitLogger()
+	defer logger.Sync()
+	database.InitElasticsearch()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+	defer cancel()
+
+	svc := service.NewReindexService(repository.NewReindexRepository(), service.NewProductServiceSource(), service.NewUserServiceVendorDirectory())
+	if *rollback {
+		state, err := svc.Rollback(ctx)
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		log.Printf("Alias %s now points to %s", state.Alias, state.PreviousIndex)
+		return
+	}
+
+	svc.OnProgress = func(state models.ReindexState) {
+		log.Printf("%s: processed %d, indexed %d, skipped %d, failed %d", state.NewIndex, state.Processed, state.Indexed, state.Skipped, state.Failed)
+	}
+	state, err := svc.Run(ctx)
+	if err != nil {
+		log.Fatalf("Reindex failed: %v", err)
+	}
+	log.Printf("Alias %s now points to %s (%d documents)", state.Alias, state.NewIndex, state.DocCount)
+}
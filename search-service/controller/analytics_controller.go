@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	logger "search-service/log"
+	"search-service/models"
+	"search-service/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultReportLimit = 20
+	maxReportLimit     = 100
+	defaultReportRange = 7 * 24 * time.Hour
+	// trending mặc định so 24h gần nhất với 24h trước đó
+	defaultTrendingRange = 24 * time.Hour
+	maxReportRange       = 90 * 24 * time.Hour
+)
+
+var validReports = map[string]bool{
+	models.ReportTopQueries:  true,
+	models.ReportZeroResults: true,
+	models.ReportLowCTR:      true,
+	models.ReportTrending:    true,
+}
+
+// dưới ngần này lượt tìm thì CTR/độ tăng chưa có ý nghĩa
+var defaultMinSearches = map[string]int64{
+	models.ReportTopQueries:  1,
+	models.ReportZeroResults: 1,
+	models.ReportLowCTR:      10,
+	models.ReportTrending:    5,
+}
+
+type AnalyticsController struct {
+	service service.AnalyticsService
+}
+
+func NewAnalyticsController(service service.AnalyticsService) *AnalyticsController {
+	return &AnalyticsController{service: service}
+}
+
+// RecordClick: client gọi khi người dùng bấm vào một kết quả, query_id lấy từ response của /search
+func (ctrl *AnalyticsController) RecordClick() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.SearchClickRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctrl.service.RecordClick(c.Request.Context(), req, c.GetHeader("X-User-ID"), c.GetHeader("X-Device-Id"))
+		c.Status(http.StatusAccepted)
+	}
+}
+
+func parseTimeParam(c *gin.Context, name string, fallback time.Time) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return fallback, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return t, fmt.Errorf("%s must be an RFC3339 timestamp", name)
+	}
+	return t, nil
+}
+
+// parseReportRequest đọc query string: from, to (RFC3339), limit, min_searches
+func parseReportRequest(c *gin.Context, report string) (models.AnalyticsReportRequest, error) {
+	req := models.AnalyticsReportRequest{Report: report}
+
+	var err error
+	if req.To, err = parseTimeParam(c, "to", time.Now().UTC()); err != nil {
+		return req, err
+	}
+	window := defaultReportRange
+	if report == models.ReportTrending {
+		window = defaultTrendingRange
+	}
+	if req.From, err = parseTimeParam(c, "from", req.To.Add(-window)); err != nil {
+		return req, err
+	}
+	if !req.From.Before(req.To) {
+		return req, fmt.Errorf("from must be before to")
+	}
+	if req.To.Sub(req.From) > maxReportRange {
+		return req, fmt.Errorf("time range must not exceed %d days", int(maxReportRange.Hours()/24))
+	}
+
+	if req.Limit, err = parseIntParam(c, "limit", defaultReportLimit); err != nil {
+		return req, err
+	}
+	if req.Limit == 0 || req.Limit > maxReportLimit {
+		return req, fmt.Errorf("limit must be between 1 and %d", maxReportLimit)
+	}
+	minSearches, err := parseIntParam(c, "min_searches", int(defaultMinSearches[report]))
+	if err != nil {
+		return req, err
+	}
+	req.MinSearches = int64(minSearches)
+	return req, nil
+}
+
+// GetReport: top-queries, zero-results, low-ctr, trending
+func (ctrl *AnalyticsController) GetReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := c.Param("report")
+		if !validReports[report] {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("unknown report: %s", report)})
+			return
+		}
+		req, err := parseReportRequest(c, report)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		result, err := ctrl.service.Report(ctx, req)
+		if err != nil {
+			logger.Err("Failed to build search analytics report", err, logger.Str("report", report))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build report"})
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
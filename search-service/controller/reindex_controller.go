@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	logger "search-service/log"
+	"search-service/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReindexController struct {
+	service *service.ReindexService
+}
+
+func NewReindexController(service *service.ReindexService) *ReindexController {
+	return &ReindexController{service: service}
+}
+
+func handleReindexError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrReindexRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNothingToRollback):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		logger.Err(message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// StartReindex tạo index mới và nạp lại từ product-service, theo dõi tiến độ qua GetReindexStatus
+func (ctrl *ReindexController) StartReindex() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+		defer cancel()
+
+		state, err := ctrl.service.Start(ctx)
+		if err != nil {
+			handleReindexError(c, "Failed to start reindex", err)
+			return
+		}
+		c.JSON(http.StatusAccepted, state)
+	}
+}
+
+func (ctrl *ReindexController) GetReindexStatus() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		status, err := ctrl.service.Status(ctx)
+		if err != nil {
+			handleReindexError(c, "Failed to get reindex status", err)
+			return
+		}
+		c.JSON(http.StatusOK, status)
+	}
+}
+
+func (ctrl *ReindexController) RollbackReindex() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+		defer cancel()
+
+		state, err := ctrl.service.Rollback(ctx)
+		if err != nil {
+			handleReindexError(c, "Failed to roll back reindex", err)
+			return
+		}
+		c.JSON(http.StatusOK, state)
+	}
+}
@@ -1,14 +1,44 @@
 package controller
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	logger "search-service/log"
+	"search-service/models"
+	"search-service/repository"
 	"search-service/service"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	// index.max_result_window mặc định của Elasticsearch, sâu hơn thì dùng cursor
+	maxResultWindow      = 10000
+	defaultPriceInterval = 100
+
+	defaultSuggestSize = 5
+	maxSuggestSize     = 10
+	// autocomplete gọi theo từng phím gõ, quá thời gian thì trả rỗng thay vì bắt client chờ
+	autocompleteTimeout = 300 * time.Millisecond
+)
+
+var validSorts = map[string]bool{
+	models.SortRelevance:   true,
+	models.SortPriceAsc:    true,
+	models.SortPriceDesc:   true,
+	models.SortNewest:      true,
+	models.SortBestSelling: true,
+	models.SortRating:      true,
+}
+
 type SearchController struct {
 	service service.SearchService
 }
@@ -19,49 +49,167 @@ func NewSearchController(service service.SearchService) *SearchController {
 	}
 }
 
-func (ctrl *SearchController) BasicSearch(query string) gin.HandlerFunc {
+func parseFloatParam(c *gin.Context, name string) (*float64, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < 0 {
+		return nil, fmt.Errorf("%s must be a non-negative number", name)
+	}
+	return &f, nil
+}
+
+func parseIntParam(c *gin.Context, name string, fallback int) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", name)
+	}
+	return n, nil
+}
+
+// parseSearchRequest đọc query string:
+// q|query, from, size, cursor, sort, min_price, max_price (price = max_price cũ), min_rating,
+// in_stock, vendor_id, category (lặp lại được), attr=tên:giá trị (lặp lại được), price_interval
+func parseSearchRequest(c *gin.Context) (models.SearchRequest, error) {
+	req := models.SearchRequest{
+		Query:  strings.TrimSpace(c.Query("q")),
+		Cursor: c.Query("cursor"),
+		Sort:   c.DefaultQuery("sort", models.SortRelevance),
+		// gateway gắn user đã đăng nhập và device id
+		UserID:   c.GetHeader("X-User-ID"),
+		DeviceID: c.GetHeader("X-Device-Id"),
+	}
+	if req.Query == "" {
+		req.Query = strings.TrimSpace(c.Query("query"))
+	}
+	if !validSorts[req.Sort] {
+		return req, fmt.Errorf("invalid sort: %s", req.Sort)
+	}
+
+	var err error
+	if req.From, err = parseIntParam(c, "from", 0); err != nil {
+		return req, err
+	}
+	if req.Size, err = parseIntParam(c, "size", defaultPageSize); err != nil {
+		return req, err
+	}
+	if req.Size == 0 || req.Size > maxPageSize {
+		return req, fmt.Errorf("size must be between 1 and %d", maxPageSize)
+	}
+	if req.Cursor == "" && req.From+req.Size > maxResultWindow {
+		return req, fmt.Errorf("from + size must not exceed %d, use cursor for deeper pages", maxResultWindow)
+	}
+
+	if req.MinPrice, err = parseFloatParam(c, "min_price"); err != nil {
+		return req, err
+	}
+	if req.MaxPrice, err = parseFloatParam(c, "max_price"); err != nil {
+		return req, err
+	}
+	if req.MaxPrice == nil {
+		if req.MaxPrice, err = parseFloatParam(c, "price"); err != nil {
+			return req, err
+		}
+	}
+	if req.MinPrice != nil && req.MaxPrice != nil && *req.MinPrice > *req.MaxPrice {
+		return req, errors.New("min_price must not be greater than max_price")
+	}
+	if req.MinRating, err = parseFloatParam(c, "min_rating"); err != nil {
+		return req, err
+	}
+	if req.MinRating != nil && *req.MinRating > 5 {
+		return req, errors.New("min_rating must be between 0 and 5")
+	}
+
+	interval, err := parseFloatParam(c, "price_interval")
+	if err != nil {
+		return req, err
+	}
+	req.PriceInterval = defaultPriceInterval
+	if interval != nil && *interval > 0 {
+		req.PriceInterval = *interval
+	}
+
+	req.InStock = c.Query("in_stock") == "true" || c.Query("in_stock") == "1"
+	req.VendorID = c.Query("vendor_id")
+
+	for _, category := range c.QueryArray("category") {
+		if category = strings.TrimSpace(category); category != "" {
+			req.Categories = append(req.Categories, category)
+		}
+	}
+	for _, attr := range c.QueryArray("attr") {
+		name, value, ok := strings.Cut(attr, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			return req, fmt.Errorf("invalid attr filter %q, expected name:value", attr)
+		}
+		if req.Attributes == nil {
+			req.Attributes = map[string][]string{}
+		}
+		req.Attributes[name] = append(req.Attributes[name], value)
+	}
+	return req, nil
+}
+
+// Search trả về hits kèm tổng số kết quả, cursor trang sau và facet
+func (ctrl *SearchController) Search() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		query := c.Query("query")
-		if query == "" {
-			query = c.Query("q")
+		req, err := parseSearchRequest(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
-		if query == "" {
-			logger.Err("Missing query parameter", nil)
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing query parameter"})
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		result, err := ctrl.service.Search(ctx, req)
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
 			return
 		}
-		products, err := ctrl.service.BasicSearch(query)
 		if err != nil {
 			logger.Err("Failed to perform search", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to perform search"})
 			return
 		}
-		c.JSON(http.StatusOK, products)
+		c.JSON(http.StatusOK, result)
 	}
 }
 
-func (ctrl *SearchController) AdvancedSearch(query string, filters map[string]interface{}) gin.HandlerFunc {
+// Autocomplete gợi ý khi gõ: sản phẩm, category và từ khoá phổ biến
+func (ctrl *SearchController) Autocomplete() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		query := c.Query("query")
-		if query == "" {
-			query = c.Query("q")
+		prefix := strings.TrimSpace(c.Query("q"))
+		if prefix == "" {
+			prefix = strings.TrimSpace(c.Query("query"))
 		}
-		if query == "" {
-			logger.Err("Missing query parameter", nil)
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing query parameter"})
+		size, err := parseIntParam(c, "size", defaultSuggestSize)
+		if err != nil || size == 0 || size > maxSuggestSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("size must be between 1 and %d", maxSuggestSize)})
 			return
 		}
-		if price := c.Query("price"); price != "" {
-			filters["price"] = map[string]interface{}{"lte": price}
-		}
-		if category := c.Query("category"); category != "" {
-			filters["category"] = category
-		}
-		products, err := ctrl.service.AdvancedSearch(query, filters)
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), autocompleteTimeout)
+		defer cancel()
+
+		result, err := ctrl.service.Autocomplete(ctx, prefix, size)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to perform search"})
+			logger.Err("Failed to autocomplete", err, logger.Str("prefix", prefix))
+			c.JSON(http.StatusOK, models.AutocompleteResult{
+				Products:   []models.ProductSuggestion{},
+				Categories: []string{},
+				Queries:    []string{},
+			})
 			return
 		}
-		c.JSON(http.StatusOK, products)
+		c.JSON(http.StatusOK, result)
 	}
 }
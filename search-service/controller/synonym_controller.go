@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	logger "search-service/log"
+	"search-service/models"
+	"search-service/repository"
+	"search-service/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SynonymController struct {
+	service *service.SynonymService
+}
+
+func NewSynonymController(service *service.SynonymService) *SynonymController {
+	return &SynonymController{service: service}
+}
+
+// isAdmin chỉ tin X-Role do gateway gắn từ token
+func isAdmin(c *gin.Context) bool {
+	return c.GetHeader("X-Role") == "ADMIN"
+}
+
+// RequireAdmin chặn các route quản trị khi request không đi qua nhóm admin của gateway.
+// Header do client tự gửi được nên /search/admin không được public ra ngoài, chỉ gateway gọi nội bộ.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isAdmin(c) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You don't have permission"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func handleSynonymError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidSynonymRule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrSynonymRuleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Synonym rule not found"})
+	default:
+		logger.Err(message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func (ctrl *SynonymController) ListSynonyms() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		rules, err := ctrl.service.List(ctx)
+		if err != nil {
+			handleSynonymError(c, "Failed to get synonyms", err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"rules": rules})
+	}
+}
+
+// ReplaceSynonyms: body {"rules": [{"id": "...", "synonyms": "a, b => c"}]}
+func (ctrl *SynonymController) ReplaceSynonyms() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Rules []models.SynonymRule `json:"rules"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+		defer cancel()
+
+		rules, err := ctrl.service.Replace(ctx, body.Rules)
+		if err != nil {
+			handleSynonymError(c, "Failed to update synonyms", err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"rules": rules})
+	}
+}
+
+func (ctrl *SynonymController) PutSynonymRule() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Synonyms string `json:"synonyms" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "synonyms is required"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+		defer cancel()
+
+		rule, err := ctrl.service.PutRule(ctx, models.SynonymRule{ID: c.Param("id"), Synonyms: body.Synonyms})
+		if err != nil {
+			handleSynonymError(c, "Failed to update synonym rule", err)
+			return
+		}
+		c.JSON(http.StatusOK, rule)
+	}
+}
+
+func (ctrl *SynonymController) DeleteSynonymRule() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+		defer cancel()
+
+		if err := ctrl.service.DeleteRule(ctx, c.Param("id")); err != nil {
+			handleSynonymError(c, "Failed to delete synonym rule", err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Synonym rule deleted"})
+	}
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	logger "search-service/log"
+	"search-service/repository"
+	"search-service/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VendorController struct {
+	service service.VendorService
+	search  service.SearchService
+}
+
+func NewVendorController(service service.VendorService, search service.SearchService) *VendorController {
+	return &VendorController{service: service, search: search}
+}
+
+// GetStorefront: hồ sơ shop kèm số sản phẩm đang bán, tổng lượt bán và rating
+func (ctrl *VendorController) GetStorefront() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		vendorID := c.Param("vendor_id")
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		storefront, err := ctrl.service.Storefront(ctx, vendorID)
+		if errors.Is(err, repository.ErrVendorNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+			return
+		}
+		if err != nil {
+			logger.Err("Failed to load vendor storefront", err, logger.Str("vendor_id", vendorID))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load storefront"})
+			return
+		}
+		c.JSON(http.StatusOK, storefront)
+	}
+}
+
+// SearchProducts: sản phẩm đang bán của shop, cùng tham số với /search (q, category, sort, cursor, ...)
+func (ctrl *VendorController) SearchProducts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := parseSearchRequest(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req.VendorID = c.Param("vendor_id")
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		result, err := ctrl.search.Search(ctx, req)
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		if err != nil {
+			logger.Err("Failed to search vendor products", err, logger.Str("vendor_id", req.VendorID))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to perform search"})
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,119 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"search-service/models"
+	"search-service/repository"
+	"search-service/service"
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	defaultAnalyticsTopic = "search-analytics"
+	analyticsQueueLen     = 4096
+)
+
+func analyticsTopic() string {
+	if topic := os.Getenv("SEARCH_ANALYTICS_TOPIC"); topic != "" {
+		return topic
+	}
+	return defaultAnalyticsTopic
+}
+
+// AnalyticsProducer ghi query/click lên Kafka ở goroutine riêng, tìm kiếm không phải chờ Kafka
+type AnalyticsProducer struct {
+	writer *kafka.Writer
+	queue  chan models.SearchEvent
+	done   chan struct{}
+}
+
+func NewAnalyticsProducer(brokers []string) *AnalyticsProducer {
+	p := &AnalyticsProducer{
+		writer: &kafka.Writer{
+			Addr:  kafka.TCP(brokers...),
+			Topic: analyticsTopic(),
+			// click cùng query_id với lần tìm nên vào cùng partition, thường đến sau query
+			Balancer:               &kafka.Hash{},
+			BatchTimeout:           50 * time.Millisecond,
+			RequiredAcks:           kafka.RequireOne,
+			AllowAutoTopicCreation: true,
+		},
+		queue: make(chan models.SearchEvent, analyticsQueueLen),
+		done:  make(chan struct{}),
+	}
+
+	go func() {
+		defer close(p.done)
+		for event := range p.queue {
+			value, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("Error marshalling search event: %v", err)
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			err = p.writer.WriteMessages(ctx, kafka.Message{Key: []byte(event.QueryID), Value: value})
+			cancel()
+			if err != nil {
+				log.Printf("Error publishing search event: %v", err)
+			}
+		}
+	}()
+	return p
+}
+
+// Publish không bao giờ block, queue đầy thì bỏ event
+func (p *AnalyticsProducer) Publish(event models.SearchEvent) {
+	select {
+	case p.queue <- event:
+	default:
+		log.Printf("Search analytics queue is full, dropping %s event", event.Type)
+	}
+}
+
+// Close đợi queue gửi hết rồi đóng writer
+func (p *AnalyticsProducer) Close() error {
+	close(p.queue)
+	<-p.done
+	return p.writer.Close()
+}
+
+// InitAnalyticsConsumer đọc lại topic analytics và ghi vào index analytics để làm báo cáo
+func InitAnalyticsConsumer(svc service.AnalyticsService, brokers []string) {
+	topic := analyticsTopic()
+	log.Printf("Kafka consumer starting for topic: %s with brokers: %v", topic, brokers)
+
+	c := newBulkEventConsumer(svc.ApplyEvents, brokers, topic, "search-service-analytics", statsDLQTopic(), decodeSearchEvent)
+	defer c.Close()
+	c.run(context.Background())
+}
+
+// query và click cùng ghi vào một document theo query_id, thứ tự đến không quan trọng
+func decodeSearchEvent(m kafka.Message) ([]models.IndexOp, error) {
+	var event models.SearchEvent
+	if err := json.Unmarshal(m.Value, &event); err != nil {
+		return nil, fmt.Errorf("invalid search event: %w", err)
+	}
+	if event.QueryID == "" {
+		return nil, fmt.Errorf("search event without query_id")
+	}
+
+	op := models.IndexOp{Action: models.IndexOpUpdate, ID: event.QueryID, Upsert: map[string]interface{}{}}
+	switch event.Type {
+	case models.SearchEventQuery:
+		op.Script = repository.QueryEventScript(event)
+	case models.SearchEventClick:
+		if event.ClickID == "" {
+			return nil, fmt.Errorf("click event without click_id")
+		}
+		op.Script = repository.ClickEventScript(event)
+	default:
+		return nil, nil
+	}
+	return []models.IndexOp{op}, nil
+}
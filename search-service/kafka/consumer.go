@@ -3,88 +3,282 @@ package kafka
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"strconv"
 	"time"
 
 	"search-service/models"
+	"search-service/repository"
 	"search-service/service"
 	"github.com/segmentio/kafka-go"
 )
 
+const (
+	productEventsTopic     = "product-events"
+	defaultDeadLetterTopic = "product-events-dlq"
+	defaultEventBatchSize  = 500
+	// batch chưa đủ thì ghi sau ngần này thời gian kể từ message đầu tiên
+	eventFlushInterval = time.Second
+	// lỗi tạm thời của từng document (429, 5xx) thử lại tối đa ngần này lần rồi chuyển DLQ
+	maxItemAttempts = 5
+	maxRetryBackoff = 30 * time.Second
+)
+
 type ProductEvent struct {
-	Type    string          `json:"type"`
-	Product *models.Product `json:"product"`
-	ID      string          `json:"id"`
+	Type    string        `json:"type"`
+	Product *eventProduct `json:"product"`
+	ID      string        `json:"id"`
 }
 
-func InitProductEventConsumer(svc service.SearchService, brokers []string) {
+// product-service gửi danh sách ảnh, document chỉ giữ ảnh đầu tiên
+type eventProduct struct {
+	models.Product
+	ImagePath []string `json:"image_path"`
+}
+
+// eventDecoder chuyển một message thành các op cần ghi, nil là event không liên quan đến index
+type eventDecoder func(m kafka.Message) ([]models.IndexOp, error)
+
+// opApplier ghi một batch op, kết quả theo thứ tự ops (SearchService.ApplyIndexOps, AnalyticsService.ApplyEvents)
+type opApplier func(ctx context.Context, ops []models.IndexOp) ([]models.BulkItemResult, error)
+
+type bulkEventConsumer struct {
+	reader    *kafka.Reader
+	dlq       *DeadLetterProducer
+	dlqTopic  string
+	apply     opApplier
+	batchSize int
+	decode    eventDecoder
+}
+
+func eventBatchSize() int {
+	if n, err := strconv.Atoi(os.Getenv("PRODUCT_EVENTS_BATCH_SIZE")); err == nil && n > 0 {
+		return n
+	}
+	return defaultEventBatchSize
+}
+
+func newBulkEventConsumer(apply opApplier, brokers []string, topic, groupID, dlqTopic string, decode eventDecoder) *bulkEventConsumer {
 	r := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:  brokers,
-		Topic:    "product-events",
-		GroupID:  "search-service",
+		Topic:    topic,
+		GroupID:  groupID,
 		MinBytes: 10e3, // 10KB
 		MaxBytes: 10e6, // 10MB
 	})
+	return &bulkEventConsumer{
+		reader:    r,
+		dlq:       NewDeadLetterProducer(brokers),
+		dlqTopic:  dlqTopic,
+		apply:     apply,
+		batchSize: eventBatchSize(),
+		decode:    decode,
+	}
+}
+
+func (c *bulkEventConsumer) Close() {
+	c.reader.Close()
+	c.dlq.Close()
+}
 
-	defer r.Close()
+// InitProductEventConsumer đọc product-events theo batch và ghi bằng _bulk.
+// Offset chỉ commit khi cả batch đã vào Elasticsearch hoặc đã chuyển sang DLQ.
+func InitProductEventConsumer(svc service.SearchService, brokers []string) {
+	dlqTopic := os.Getenv("PRODUCT_EVENTS_DLQ_TOPIC")
+	if dlqTopic == "" {
+		dlqTopic = defaultDeadLetterTopic
+	}
 
-	log.Printf("Product event consumer initialized with brokers: %v", brokers)
-	log.Printf("Kafka consumer starting for topic: product-events with brokers: %v", brokers)
+	log.Printf("Kafka consumer starting for topic: %s with brokers: %v", productEventsTopic, brokers)
+
+	c := newBulkEventConsumer(svc.ApplyIndexOps, brokers, productEventsTopic, "search-service", dlqTopic, decodeProductEvent)
+	defer c.Close()
+	c.run(context.Background())
+}
 
+func (c *bulkEventConsumer) run(ctx context.Context) {
 	isConnected := false // Biến để chỉ log kết nối thành công 1 lần
 
 	for {
-		m, err := r.ReadMessage(context.Background())
+		batch, err := c.fetchBatch(ctx)
+		if len(batch) > 0 {
+			if !isConnected {
+				log.Printf("Kafka consumer connected and reading %s...", c.reader.Config().Topic)
+				isConnected = true
+			}
+			c.process(ctx, batch)
+		}
 		if err != nil {
-			log.Printf("Error reading message: %v", err) // Log lỗi đọc/kết nối
-			isConnected = false                          // Đánh dấu mất kết nối nếu có lỗi
-			time.Sleep(5 * time.Second)                  // Chờ chút trước khi thử lại
-			continue
+			log.Printf("Error reading message: %v", err)
+			isConnected = false
+			time.Sleep(5 * time.Second)
 		}
+	}
+}
+
+// fetchBatch chờ message đầu tiên, sau đó đọc thêm đến khi đủ batchSize hoặc hết eventFlushInterval
+func (c *bulkEventConsumer) fetchBatch(ctx context.Context) ([]kafka.Message, error) {
+	m, err := c.reader.FetchMessage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	batch := []kafka.Message{m}
 
-		// Log khi kết nối/đọc thành công lần đầu hoặc sau khi mất kết nối
-		if !isConnected {
-			log.Println("Kafka consumer connected and reading messages...")
-			isConnected = true
+	deadline := time.Now().Add(eventFlushInterval)
+	for len(batch) < c.batchSize {
+		fetchCtx, cancel := context.WithDeadline(ctx, deadline)
+		m, err := c.reader.FetchMessage(fetchCtx)
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) {
+			break
+		}
+		if err != nil {
+			return batch, err
 		}
+		batch = append(batch, m)
+	}
+	return batch, nil
+}
+
+func decodeProductEvent(m kafka.Message) ([]models.IndexOp, error) {
+	var event ProductEvent
+	if err := json.Unmarshal(m.Value, &event); err != nil {
+		return nil, fmt.Errorf("invalid product event: %w", err)
+	}
 
-		log.Printf("Received message offset %d: %s", m.Offset, string(m.Value)) // Log khi đọc được message
+	switch event.Type {
+	case "created", "updated", "INITIAL_SYNC":
+		if event.Product == nil || event.Product.ID == "" {
+			return nil, fmt.Errorf("%s event without product", event.Type)
+		}
+		product := event.Product.Product
+		if product.ImageURL == "" && len(event.Product.ImagePath) > 0 {
+			product.ImageURL = event.Product.ImagePath[0]
+		}
+		return []models.IndexOp{{
+			Action:  models.IndexOpIndex,
+			ID:      product.ID,
+			Version: repository.ProductVersion(&product),
+			Product: &product,
+		}}, nil
+	case "deleted":
+		if event.ID == "" {
+			return nil, fmt.Errorf("deleted event without id")
+		}
+		// event xoá không có updated_at, lấy thời điểm ghi message (luôn sau lần cập nhật cuối)
+		return []models.IndexOp{{Action: models.IndexOpDelete, ID: event.ID, Version: m.Time.UnixMilli()}}, nil
+	default:
+		return nil, nil
+	}
+}
 
-		var event ProductEvent
-		if err := json.Unmarshal(m.Value, &event); err != nil {
-			log.Printf("Error unmarshalling message at offset %d: %v", m.Offset, err)
+func (c *bulkEventConsumer) process(ctx context.Context, msgs []kafka.Message) {
+	// mỗi sản phẩm chỉ ghi bản đầy đủ mới nhất trong batch, các partition không đảm bảo thứ tự với nhau.
+	// Op update là số chênh lệch nên phải giữ hết.
+	latest := make(map[string]int)
+	var ops []models.IndexOp
+	var opMsgs []kafka.Message
+	for _, m := range msgs {
+		decoded, err := c.decode(m)
+		if err != nil {
+			c.deadLetter(ctx, m, err)
 			continue
 		}
-
-		log.Printf("Successfully unmarshalled event at offset %d: %+v", m.Offset, event)
-
-		switch event.Type {
-		case "created", "updated":
-			if event.Product != nil {
-				// Sửa log lỗi index: lấy lỗi từ svc.IndexProduct
-				if indexErr := svc.IndexProduct(event.Product); indexErr != nil {
-					log.Printf("Error indexing product %s: %v", event.Product.ID, indexErr) // Log lỗi index đúng
-				} else {
-					log.Printf("Successfully indexed product: %s", event.Product.ID)
-				}
+		for _, op := range decoded {
+			if op.Action == models.IndexOpUpdate {
+				ops = append(ops, op)
+				opMsgs = append(opMsgs, m)
+				continue
 			}
-		case "deleted":
-			if event.ID != "" {
-				// Sửa log lỗi delete: lấy lỗi từ svc.DeleteProduct
-				if deleteErr := svc.DeleteProduct(event.ID); deleteErr != nil {
-					log.Printf("Error deleting product %s: %v", event.ID, deleteErr) // Log lỗi delete đúng
-				} else {
-					log.Printf("Successfully deleted product: %s", event.ID)
+			if i, ok := latest[op.ID]; ok {
+				if op.Version >= ops[i].Version {
+					ops[i], opMsgs[i] = op, m
 				}
+				continue
 			}
-		case "INITIAL_SYNC":
-			if event.Product != nil {
-				if indexErr := svc.IndexProduct(event.Product); indexErr != nil {
-					log.Printf("Error indexing product during INITIAL_SYNC %s: %v", event.Product.ID, indexErr)
-				} else {
-					log.Printf("Indexed product during INITIAL_SYNC: %s", event.Product.ID)
+			latest[op.ID] = len(ops)
+			ops = append(ops, op)
+			opMsgs = append(opMsgs, m)
+		}
+	}
+
+	c.write(ctx, ops, opMsgs)
+
+	// commit lỗi thì batch được đọc lại, ghi lại cùng version/event key không làm sai dữ liệu
+	if err := c.reader.CommitMessages(ctx, msgs...); err != nil {
+		log.Printf("Error committing %d messages: %v", len(msgs), err)
+	}
+}
+
+func retryBackoff(attempt int) time.Duration {
+	d := 500 * time.Millisecond << min(attempt, 10)
+	return min(d, maxRetryBackoff)
+}
+
+// write chỉ trả về khi mọi op đã ghi xong, bị bỏ vì cũ hơn, hoặc đã vào DLQ
+func (c *bulkEventConsumer) write(ctx context.Context, ops []models.IndexOp, opMsgs []kafka.Message) {
+	// một message (đơn hàng nhiều sản phẩm) có thể sinh nhiều op, chỉ đưa vào DLQ một lần
+	deadLettered := make(map[string]bool)
+	sendToDLQ := func(m kafka.Message, cause error) {
+		key := fmt.Sprintf("%d:%d", m.Partition, m.Offset)
+		if deadLettered[key] {
+			return
+		}
+		deadLettered[key] = true
+		c.deadLetter(ctx, m, cause)
+	}
+
+	itemAttempt := 0
+	for requestAttempt := 0; len(ops) > 0; {
+		results, err := c.apply(ctx, ops)
+		if err != nil {
+			// Elasticsearch không nhận request: chờ rồi thử lại, không commit offset
+			log.Printf("Error writing %d events from %s to Elasticsearch: %v", len(ops), c.reader.Config().Topic, err)
+			time.Sleep(retryBackoff(requestAttempt))
+			requestAttempt++
+			continue
+		}
+		requestAttempt = 0
+		itemAttempt++
+
+		var retryOps []models.IndexOp
+		var retryMsgs []kafka.Message
+		for i, result := range results {
+			switch result.Outcome {
+			case models.BulkItemOK:
+			case models.BulkItemStale:
+				log.Printf("Skipped stale event for product %s (version %d)", ops[i].ID, ops[i].Version)
+			case models.BulkItemRetry:
+				if itemAttempt < maxItemAttempts {
+					retryOps = append(retryOps, ops[i])
+					retryMsgs = append(retryMsgs, opMsgs[i])
+					continue
 				}
+				sendToDLQ(opMsgs[i], fmt.Errorf("still failing after %d attempts: %s", itemAttempt, result.Error))
+			default:
+				sendToDLQ(opMsgs[i], fmt.Errorf("index rejected: %s", result.Error))
 			}
 		}
+
+		ops, opMsgs = retryOps, retryMsgs
+		if len(ops) > 0 {
+			time.Sleep(retryBackoff(itemAttempt))
+		}
+	}
+}
+
+// deadLetter ghi được vào DLQ mới trả về, nếu không event sẽ mất khi commit offset
+func (c *bulkEventConsumer) deadLetter(ctx context.Context, m kafka.Message, cause error) {
+	log.Printf("Sending %s event at partition %d offset %d to DLQ: %v", m.Topic, m.Partition, m.Offset, cause)
+	for attempt := 0; ; attempt++ {
+		err := c.dlq.Publish(ctx, c.dlqTopic, m, cause)
+		if err == nil {
+			return
+		}
+		log.Printf("Error writing to DLQ: %v", err)
+		time.Sleep(retryBackoff(attempt))
 	}
 }
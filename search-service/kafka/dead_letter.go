@@ -0,0 +1,44 @@
+package kafka
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// DeadLetterProducer chuyển event không index được sang topic riêng, giữ nguyên key/value để replay
+type DeadLetterProducer struct {
+	writer *kafka.Writer
+}
+
+func NewDeadLetterProducer(brokers []string) *DeadLetterProducer {
+	return &DeadLetterProducer{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+func (p *DeadLetterProducer) Publish(ctx context.Context, topic string, m kafka.Message, cause error) error {
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Topic: topic,
+		Key:   m.Key,
+		Value: m.Value,
+		Headers: append(m.Headers,
+			kafka.Header{Key: "dlq-original-topic", Value: []byte(m.Topic)},
+			kafka.Header{Key: "dlq-original-partition", Value: []byte(strconv.Itoa(m.Partition))},
+			kafka.Header{Key: "dlq-original-offset", Value: []byte(strconv.FormatInt(m.Offset, 10))},
+			kafka.Header{Key: "dlq-error", Value: []byte(cause.Error())},
+			kafka.Header{Key: "dlq-failed-at", Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+		),
+	})
+}
+
+func (p *DeadLetterProducer) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,86 @@
+package kafka
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"search-service/models"
+	"search-service/service"
+	"github.com/segmentio/kafka-go"
+)
+
+// quantity/sold_count không lấy từ order_success: product-service bắn product-events "updated"
+// (có version) sau mỗi lần đổi, cộng thêm delta ở đây sẽ bị tính hai lần
+const (
+	defaultRatingTopic   = "product_rating_updates"
+	defaultStatsDLQTopic = "search-service-dlq"
+)
+
+// RatingUpdateEvent: review-service gửi phần chênh lệch (số review mới, tổng sao mới), không phải rating tổng
+type RatingUpdateEvent struct {
+	ProductID          string   `json:"product_id"`
+	NewReviewsCount    int      `json:"new_reviews_count"`
+	NewReviewsSum      float64  `json:"new_reviews_sum"`
+	ProcessedReviewIDs []string `json:"processed_review_ids"`
+	Timestamp          string   `json:"timestamp"`
+}
+
+func statsDLQTopic() string {
+	if topic := os.Getenv("SEARCH_DLQ_TOPIC"); topic != "" {
+		return topic
+	}
+	return defaultStatsDLQTopic
+}
+
+// InitRatingEventConsumer cập nhật rating/rating_count từ các đợt gộp review
+func InitRatingEventConsumer(svc service.SearchService, brokers []string) {
+	topic := os.Getenv("KAFKA_RATING_TOPIC")
+	if topic == "" {
+		topic = defaultRatingTopic
+	}
+	log.Printf("Kafka consumer starting for topic: %s with brokers: %v", topic, brokers)
+
+	c := newBulkEventConsumer(svc.ApplyIndexOps, brokers, topic, "search-service-ratings", statsDLQTopic(), decodeRatingEvent)
+	defer c.Close()
+	c.run(context.Background())
+}
+
+func decodeRatingEvent(m kafka.Message) ([]models.IndexOp, error) {
+	var event RatingUpdateEvent
+	if err := json.Unmarshal(m.Value, &event); err != nil {
+		return nil, fmt.Errorf("invalid rating event: %w", err)
+	}
+	if event.ProductID == "" {
+		return nil, fmt.Errorf("rating event without product_id")
+	}
+	if event.NewReviewsCount <= 0 {
+		return nil, nil
+	}
+	return []models.IndexOp{{
+		Action: models.IndexOpUpdate,
+		ID:     event.ProductID,
+		Delta: &models.ProductStatsDelta{
+			EventKey:    ratingEventKey(event),
+			RatingCount: event.NewReviewsCount,
+			RatingSum:   event.NewReviewsSum,
+		},
+	}}, nil
+}
+
+// ratingEventKey: cùng một nhóm review luôn ra cùng key nên message gửi lại không bị cộng hai lần
+func ratingEventKey(event RatingUpdateEvent) string {
+	if len(event.ProcessedReviewIDs) == 0 {
+		return "rating:" + event.Timestamp
+	}
+	ids := append([]string(nil), event.ProcessedReviewIDs...)
+	sort.Strings(ids)
+	sum := sha1.Sum([]byte(strings.Join(ids, ",")))
+	return "rating:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,63 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"search-service/models"
+	"search-service/repository"
+	"search-service/service"
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	defaultVendorEventsTopic = "vendor-events"
+	vendorProfileUpdated     = "vendor_profile_updated"
+)
+
+// VendorEvent do user-service publish khi người bán sửa storefront
+type VendorEvent struct {
+	Type   string        `json:"type"`
+	Vendor models.Vendor `json:"vendor"`
+}
+
+func vendorEventsTopic() string {
+	if topic := os.Getenv("KAFKA_VENDOR_TOPIC"); topic != "" {
+		return topic
+	}
+	return defaultVendorEventsTopic
+}
+
+// InitVendorEventConsumer cập nhật index shop (storefront, tìm shop) theo vendor-events
+func InitVendorEventConsumer(svc service.VendorService, brokers []string) {
+	topic := vendorEventsTopic()
+	log.Printf("Kafka consumer starting for topic: %s with brokers: %v", topic, brokers)
+
+	c := newBulkEventConsumer(svc.ApplyVendorOps, brokers, topic, "search-service-vendors", statsDLQTopic(), decodeVendorEvent)
+	defer c.Close()
+	c.run(context.Background())
+}
+
+func decodeVendorEvent(m kafka.Message) ([]models.IndexOp, error) {
+	var event VendorEvent
+	if err := json.Unmarshal(m.Value, &event); err != nil {
+		return nil, fmt.Errorf("invalid vendor event: %w", err)
+	}
+	if event.Type != vendorProfileUpdated {
+		return nil, nil
+	}
+	if event.Vendor.ID == "" {
+		return nil, fmt.Errorf("vendor event without id")
+	}
+
+	vendor := event.Vendor
+	return []models.IndexOp{{
+		Action:  models.IndexOpIndex,
+		ID:      vendor.ID,
+		Version: repository.VendorVersion(&vendor),
+		Vendor:  &vendor,
+	}}, nil
+}
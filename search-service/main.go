@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -30,6 +31,21 @@ func waitForElasticsearch(url string, retries int, delay time.Duration) error {
 
 
 
+// loadMemoryIndex: engine nhúng bắt đầu rỗng, nạp lại từ product-service (có thể chưa chạy xong)
+func loadMemoryIndex(svc service.SearchService) {
+	for attempt := 1; attempt <= 10; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		n, err := service.LoadProducts(ctx, service.NewProductServiceSource(), svc, 500)
+		cancel()
+		if err == nil {
+			logger.Info(fmt.Sprintf("Loaded %d products into memory search engine", n))
+			return
+		}
+		logger.Err("Failed to load products into memory search engine", err)
+		time.Sleep(5 * time.Second)
+	}
+}
+
 func main() {
 
 	logger.InitLogger()
@@ -39,29 +55,64 @@ func main() {
 
 	
 
-	repo := repository.NewSearchRepository()
-	svc := service.NewSearchService(repo)
-	ctrl := controller.NewSearchController(svc)
-
-	router := gin.Default()
-	routes.SearchRoutes(router, ctrl)
-
 	kafkaHost := os.Getenv("KAFKA_URL")
 	brokers := []string{kafkaHost}
 	if kafkaHost == ""{
 		brokers = []string{"kafka:9092"}
 	}
 
-
-	go kafka.InitProductEventConsumer(svc, brokers)
-
-	err := waitForElasticsearch("http://elasticsearch:9200", 10, 3*time.Second)
+	engine := repository.SearchEngine()
+	repo, err := repository.NewSearchRepositoryForEngine(engine)
+	if err != nil {
+		logger.Err("Invalid SEARCH_ENGINE", err)
+		os.Exit(1)
+	}
+	vendorIndex, err := repository.NewVendorRepositoryForEngine(engine)
 	if err != nil {
-		logger.Err("Elasticsearch not available: %v", err)
+		logger.Err("Invalid SEARCH_ENGINE", err)
+		os.Exit(1)
 	}
+	vendors := service.NewUserServiceVendorDirectory()
+	router := gin.Default()
 
-	database.InitElasticsearch()
+	if engine == repository.EngineMemory {
+		// engine nhúng cho chạy local: chỉ có tìm kiếm và autocomplete,
+		// synonym, reindex và analytics cần Elasticsearch
+		svc := service.NewSearchService(repo, vendorIndex, vendors, nil)
+		vendorSvc := service.NewVendorService(vendorIndex, repo, vendors)
+		routes.SearchRoutes(router, controller.NewSearchController(svc))
+		routes.VendorRoutes(router, controller.NewVendorController(vendorSvc, svc))
+		go loadMemoryIndex(svc)
+		go kafka.InitProductEventConsumer(svc, brokers)
+		go kafka.InitRatingEventConsumer(svc, brokers)
+		go kafka.InitVendorEventConsumer(vendorSvc, brokers)
+	} else {
+		analyticsProducer := kafka.NewAnalyticsProducer(brokers)
+		defer analyticsProducer.Close()
+
+		svc := service.NewSearchService(repo, vendorIndex, vendors, analyticsProducer)
+		vendorSvc := service.NewVendorService(vendorIndex, repo, vendors)
+		analyticsSvc := service.NewAnalyticsService(repository.NewAnalyticsRepository(), analyticsProducer)
+
+		routes.SearchRoutes(router, controller.NewSearchController(svc))
+		routes.VendorRoutes(router, controller.NewVendorController(vendorSvc, svc))
+		routes.SynonymRoutes(router, controller.NewSynonymController(service.NewSynonymService(repository.NewSynonymRepository())))
+		reindexSvc := service.NewReindexService(repository.NewReindexRepository(), service.NewProductServiceSource(), vendors)
+		routes.ReindexRoutes(router, controller.NewReindexController(reindexSvc))
+		routes.AnalyticsRoutes(router, controller.NewAnalyticsController(analyticsSvc))
+
+		go kafka.InitProductEventConsumer(svc, brokers)
+		go kafka.InitRatingEventConsumer(svc, brokers)
+		go kafka.InitAnalyticsConsumer(analyticsSvc, brokers)
+		go kafka.InitVendorEventConsumer(vendorSvc, brokers)
+
+		err := waitForElasticsearch("http://elasticsearch:9200", 10, 3*time.Second)
+		if err != nil {
+			logger.Err("Elasticsearch not available: %v", err)
+		}
 
+		database.InitElasticsearch()
+	}
 
 	port := os.Getenv("PORT")
 	if port == "" {
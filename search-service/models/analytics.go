@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+const (
+	SearchEventQuery = "query"
+	SearchEventClick = "click"
+)
+
+// SearchEvent ghi lên topic analytics: mỗi lần tìm kiếm (query) hoặc mỗi lần bấm vào kết quả (click).
+// Click mang query_id của lần tìm sinh ra kết quả đó.
+type SearchEvent struct {
+	Type    string `json:"type"`
+	QueryID string `json:"query_id"`
+	ClickID string `json:"click_id,omitempty"`
+	// text người dùng gõ và bản chuẩn hoá dùng để gộp báo cáo
+	Query       string                 `json:"query,omitempty"`
+	Normalized  string                 `json:"normalized,omitempty"`
+	Filters     map[string]interface{} `json:"filters,omitempty"`
+	Sort        string                 `json:"sort,omitempty"`
+	FirstPage   bool                   `json:"first_page,omitempty"`
+	ResultCount int64                  `json:"result_count"`
+	LatencyMs   int64                  `json:"latency_ms"`
+	ProductID   string                 `json:"product_id,omitempty"`
+	Position    int                    `json:"position,omitempty"`
+	UserID      string                 `json:"user_id,omitempty"`
+	DeviceID    string                 `json:"device_id,omitempty"`
+	Timestamp   time.Time              `json:"timestamp"`
+}
+
+type SearchClickRequest struct {
+	QueryID   string `json:"query_id" binding:"required,max=64"`
+	ProductID string `json:"product_id" binding:"required,max=64"`
+	// vị trí trong trang kết quả, bắt đầu từ 1
+	Position int `json:"position" binding:"min=0"`
+}
+
+const (
+	ReportTopQueries  = "top-queries"
+	ReportZeroResults = "zero-results"
+	ReportLowCTR      = "low-ctr"
+	ReportTrending    = "trending"
+)
+
+type AnalyticsReportRequest struct {
+	Report string
+	From   time.Time
+	To     time.Time
+	Limit  int
+	// bỏ qua từ khoá có ít lượt tìm hơn, tránh nhiễu ở low-ctr và trending
+	MinSearches int64
+}
+
+type QueryStat struct {
+	Query    string `json:"query"`
+	Searches int64  `json:"searches"`
+	// số lượt tìm có ít nhất một click
+	ClickedSearches int64   `json:"clicked_searches"`
+	CTR             float64 `json:"ctr"`
+	AvgResults      float64 `json:"avg_results"`
+	// chỉ có ở báo cáo trending: số lượt tìm trong khoảng thời gian liền trước
+	PreviousSearches *int64   `json:"previous_searches,omitempty"`
+	Growth           *float64 `json:"growth,omitempty"`
+}
+
+type AnalyticsReport struct {
+	Report  string      `json:"report"`
+	From    time.Time   `json:"from"`
+	To      time.Time   `json:"to"`
+	Queries []QueryStat `json:"queries"`
+}
@@ -0,0 +1,14 @@
+package models
+
+type ProductSuggestion struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Price    float64 `json:"price"`
+	ImageURL string  `json:"image_url"`
+}
+
+type AutocompleteResult struct {
+	Products   []ProductSuggestion `json:"products"`
+	Categories []string            `json:"categories"`
+	Queries    []string            `json:"queries"` // từ khoá được tìm nhiều
+}
@@ -0,0 +1,47 @@
+package models
+
+const (
+	IndexOpIndex  = "index"
+	IndexOpDelete = "delete"
+	// cập nhật một phần (rating, click analytics), không dùng Version
+	IndexOpUpdate = "update"
+)
+
+// ProductStatsDelta: phần chênh lệch rating của sản phẩm từ event đánh giá.
+// Cộng dồn nên event trùng EventKey (Kafka gửi lại) phải bỏ qua.
+type ProductStatsDelta struct {
+	EventKey    string
+	RatingCount int
+	RatingSum   float64
+}
+
+// IndexOp: một thay đổi cần ghi vào index sản phẩm (hoặc index shop khi có Vendor).
+// Version (unix ms) dùng làm external version nên event cũ đến sau không ghi đè được bản mới.
+type IndexOp struct {
+	Action  string
+	ID      string
+	Version int64
+	Product *Product
+	Vendor  *Vendor
+	// update sản phẩm dùng Delta, engine nào cũng áp dụng được
+	Delta *ProductStatsDelta
+	// script Elasticsearch, chỉ dùng cho index riêng của Elasticsearch (analytics)
+	Script map[string]interface{}
+	// khác nil thì update chạy script cả khi document chưa có (scripted_upsert)
+	Upsert map[string]interface{}
+}
+
+const (
+	BulkItemOK = "ok"
+	// Elasticsearch đã có bản mới hơn
+	BulkItemStale = "stale"
+	// lỗi tạm thời (429, 5xx), thử lại được
+	BulkItemRetry = "retry"
+	// lỗi dữ liệu, thử lại cũng không được
+	BulkItemFailed = "failed"
+)
+
+type BulkItemResult struct {
+	Outcome string
+	Error   string
+}
@@ -1,10 +1,27 @@
 package models
 
+import "time"
+
+// chỉ sản phẩm đang bán mới hiện trong kết quả tìm kiếm
+const ProductStatusOnSale = "onsale"
+
 type Product struct {
-	ID 		string `json:"id"`
-	Name 	string `json:"name"`
-	Price 	float64 `json:"price"`
-	Category string `json:"category"`
-	Description string `json:"description"`
-	ImageURL string `json:"image_url"`
-}
\ No newline at end of file
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Price       float64                `json:"price"`
+	Category    string                 `json:"category"`
+	Description string                 `json:"description"`
+	ImageURL    string                 `json:"image_url"`
+	Quantity    int                    `json:"quantity"`
+	SoldCount   int                    `json:"sold_count"`
+	Rating      float64                `json:"rating"`
+	RatingCount int                    `json:"rating_count"`
+	Status      string                 `json:"status"`
+	UserID      string                 `json:"user_id"` // vendor
+	VendorName  string                 `json:"vendor_name,omitempty"`
+	Attributes  map[string]interface{} `json:"attributes,omitempty"`
+	// "tên=giá trị" của từng thuộc tính, dùng cho filter và facet
+	AttributeFacets []string  `json:"attribute_facets,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+const (
+	ReindexRunning    = "running"
+	ReindexCompleted  = "completed"
+	ReindexFailed     = "failed"
+	ReindexRolledBack = "rolled_back"
+)
+
+// ReindexState: tiến độ lần reindex gần nhất, lưu trong Elasticsearch để mọi replica cùng thấy
+type ReindexState struct {
+	Alias         string `json:"alias"`
+	Version       int    `json:"version"`
+	Status        string `json:"status"`
+	NewIndex      string `json:"new_index"`
+	PreviousIndex string `json:"previous_index,omitempty"`
+	Processed     int64  `json:"processed"`
+	Indexed       int64  `json:"indexed"`
+	// sản phẩm đã được ghi bản mới hơn qua Kafka trong lúc reindex
+	Skipped    int64      `json:"skipped"`
+	Failed     int64      `json:"failed"`
+	FailedIDs  []string   `json:"failed_ids,omitempty"`
+	DocCount   int64      `json:"doc_count,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	SeqNo       int64 `json:"-"`
+	PrimaryTerm int64 `json:"-"`
+}
+
+// BulkResult: kết quả một lần _bulk
+type BulkResult struct {
+	Indexed   int64
+	Skipped   int64
+	FailedIDs []string
+}
+
+// ReindexStatus: trạng thái alias hiện tại kèm lần reindex gần nhất
+type ReindexStatus struct {
+	Alias        string        `json:"alias"`
+	Version      int           `json:"version"`
+	AliasIndices []string      `json:"alias_indices"`
+	Indices      []string      `json:"indices"`
+	Reindex      *ReindexState `json:"reindex"`
+}
@@ -0,0 +1,89 @@
+package models
+
+const (
+	SortRelevance   = "relevance"
+	SortPriceAsc    = "price_asc"
+	SortPriceDesc   = "price_desc"
+	SortNewest      = "newest"
+	SortBestSelling = "best_selling"
+	SortRating      = "rating"
+)
+
+type SearchRequest struct {
+	Query string
+	From  int
+	Size  int
+	// Cursor lấy từ next_cursor của trang trước (search_after), có cursor thì bỏ qua From
+	Cursor     string
+	Sort       string
+	MinPrice   *float64
+	MaxPrice   *float64
+	MinRating  *float64
+	InStock    bool
+	VendorID   string
+	Categories []string
+	// tên thuộc tính -> các giá trị được chọn, cùng thuộc tính là OR, khác thuộc tính là AND
+	Attributes    map[string][]string
+	PriceInterval float64
+	// chỉ dùng để ghi analytics, không ảnh hưởng kết quả
+	UserID   string
+	DeviceID string
+}
+
+// AppliedFilters: các filter người dùng đã chọn, ghi kèm query trong analytics
+func (r SearchRequest) AppliedFilters() map[string]interface{} {
+	filters := map[string]interface{}{}
+	if r.MinPrice != nil {
+		filters["min_price"] = *r.MinPrice
+	}
+	if r.MaxPrice != nil {
+		filters["max_price"] = *r.MaxPrice
+	}
+	if r.MinRating != nil {
+		filters["min_rating"] = *r.MinRating
+	}
+	if r.InStock {
+		filters["in_stock"] = true
+	}
+	if r.VendorID != "" {
+		filters["vendor_id"] = r.VendorID
+	}
+	if len(r.Categories) > 0 {
+		filters["category"] = r.Categories
+	}
+	if len(r.Attributes) > 0 {
+		filters["attributes"] = r.Attributes
+	}
+	return filters
+}
+
+type FacetBucket struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type RangeBucket struct {
+	From  float64  `json:"from"`
+	To    *float64 `json:"to,omitempty"`
+	Count int64    `json:"count"`
+}
+
+type SearchFacets struct {
+	Categories     []FacetBucket            `json:"categories"`
+	PriceHistogram []RangeBucket            `json:"price_histogram"`
+	Ratings        []RangeBucket            `json:"ratings"`
+	Attributes     map[string][]FacetBucket `json:"attributes"`
+}
+
+type SearchResult struct {
+	Total      int64        `json:"total"`
+	From       int          `json:"from"`
+	Size       int          `json:"size"`
+	Hits       []Product    `json:"hits"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+	Facets     SearchFacets `json:"facets"`
+	// client gửi lại khi người dùng bấm vào một kết quả (POST /search/click)
+	QueryID string `json:"query_id,omitempty"`
+	// shop khớp từ khoá, chỉ có ở trang đầu của tìm kiếm toàn sàn
+	Vendors []Vendor `json:"vendors,omitempty"`
+}
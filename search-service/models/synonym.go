@@ -0,0 +1,7 @@
+package models
+
+// SynonymRule theo cú pháp Solr: "a, b, c" (tương đương) hoặc "a, b => c" (thay thế)
+type SynonymRule struct {
+	ID       string `json:"id,omitempty"`
+	Synonyms string `json:"synonyms"`
+}
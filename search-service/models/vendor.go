@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Vendor: thông tin shop do người bán quản lý ở user-service, đồng bộ qua topic vendor-events
+type Vendor struct {
+	ID          string    `json:"id"`
+	DisplayName string    `json:"display_name"`
+	LogoURL     string    `json:"logo_url,omitempty"`
+	BannerURL   string    `json:"banner_url,omitempty"`
+	Description string    `json:"description,omitempty"`
+	JoinedAt    time.Time `json:"joined_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// VendorStats tính từ các sản phẩm đang bán của shop trong index
+type VendorStats struct {
+	ProductCount int64 `json:"product_count"`
+	TotalSales   int64 `json:"total_sales"`
+	// trung bình theo số lượt đánh giá của từng sản phẩm
+	Rating      float64 `json:"rating"`
+	RatingCount int64   `json:"rating_count"`
+}
+
+type VendorStorefront struct {
+	Vendor
+	Stats         VendorStats `json:"stats"`
+	FollowerCount int64       `json:"follower_count"`
+}
@@ -0,0 +1,341 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"search-service/database"
+	"search-service/models"
+)
+
+const (
+	// số từ khoá lấy về để tính CTR/độ tăng rồi sắp xếp lại trong Go
+	analyticsCandidateSize = 1000
+)
+
+// AnalyticsRepository lưu query/click đọc từ topic analytics và tổng hợp báo cáo cho admin
+type AnalyticsRepository interface {
+	BulkApply(ctx context.Context, ops []models.IndexOp) ([]models.BulkItemResult, error)
+	Report(ctx context.Context, req models.AnalyticsReportRequest) ([]models.QueryStat, error)
+}
+
+type analyticsRepository struct{}
+
+func NewAnalyticsRepository() AnalyticsRepository {
+	return &analyticsRepository{}
+}
+
+func analyticsIndexName() string {
+	if name := os.Getenv("ELASTICSEARCH_ANALYTICS_INDEX"); name != "" {
+		return name
+	}
+	return os.Getenv("ELASTICSEARCH_INDEX") + "-analytics"
+}
+
+// QueryEventScript ghi thông tin lần tìm vào document theo query_id.
+// Click có thể đến trước query (khác batch), nên không ghi đè số click đã có.
+func QueryEventScript(event models.SearchEvent) map[string]interface{} {
+	doc := map[string]interface{}{
+		"query_id":     event.QueryID,
+		"query":        event.Query,
+		"filters":      event.Filters,
+		"sort":         event.Sort,
+		"first_page":   event.FirstPage,
+		"result_count": event.ResultCount,
+		"latency_ms":   event.LatencyMs,
+		"user_id":      event.UserID,
+		"device_id":    event.DeviceID,
+		"timestamp":    event.Timestamp,
+	}
+	if event.Normalized != "" {
+		doc["normalized"] = event.Normalized
+	}
+	return map[string]interface{}{
+		"lang":   "painless",
+		"source": "ctx._source.putAll(params.doc); if (ctx._source.clicks == null) { ctx._source.clicks = 0; }",
+		"params": map[string]interface{}{"doc": doc},
+	}
+}
+
+// ClickEventScript cộng một click, click_id trùng (message gửi lại) thì bỏ qua
+func ClickEventScript(event models.SearchEvent) map[string]interface{} {
+	return map[string]interface{}{
+		"lang": "painless",
+		"source": dedupeScript(`
+  ctx._source.query_id = params.query_id;
+  ctx._source.clicks = (ctx._source.clicks == null ? 0 : ((Number) ctx._source.clicks).longValue()) + 1;
+  ctx._source.last_clicked_at = params.clicked_at;`),
+		"params": map[string]interface{}{
+			"event_key":     event.ClickID,
+			"applied_limit": appliedEventsLimit,
+			"query_id":      event.QueryID,
+			"clicked_at":    event.Timestamp,
+		},
+	}
+}
+
+var (
+	analyticsIndexMu    sync.Mutex
+	analyticsIndexReady bool
+)
+
+func ensureAnalyticsIndex(ctx context.Context) error {
+	analyticsIndexMu.Lock()
+	defer analyticsIndexMu.Unlock()
+	if analyticsIndexReady {
+		return nil
+	}
+
+	indexName := analyticsIndexName()
+	res, err := database.ES.Indices.Exists([]string{indexName}, database.ES.Indices.Exists.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to check if analytics index exists: %w", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode == 404 {
+		mapping := map[string]interface{}{
+			"mappings": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"query_id":        map[string]interface{}{"type": "keyword"},
+					"query":           map[string]interface{}{"type": "keyword", "index": false},
+					"normalized":      map[string]interface{}{"type": "keyword"},
+					"filters":         map[string]interface{}{"type": "object", "enabled": false},
+					"sort":            map[string]interface{}{"type": "keyword"},
+					"first_page":      map[string]interface{}{"type": "boolean"},
+					"result_count":    map[string]interface{}{"type": "long"},
+					"latency_ms":      map[string]interface{}{"type": "long"},
+					"user_id":         map[string]interface{}{"type": "keyword"},
+					"device_id":       map[string]interface{}{"type": "keyword"},
+					"timestamp":       map[string]interface{}{"type": "date"},
+					"clicks":          map[string]interface{}{"type": "integer"},
+					"last_clicked_at": map[string]interface{}{"type": "date"},
+					"applied_events":  map[string]interface{}{"type": "keyword", "index": false, "doc_values": false},
+				},
+			},
+		}
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(mapping); err != nil {
+			return err
+		}
+		createRes, err := database.ES.Indices.Create(indexName,
+			database.ES.Indices.Create.WithBody(&buf),
+			database.ES.Indices.Create.WithContext(ctx),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create analytics index: %w", err)
+		}
+		defer createRes.Body.Close()
+		// replica khác vừa tạo thì bỏ qua
+		if createRes.IsError() && !strings.Contains(createRes.String(), "resource_already_exists_exception") {
+			return fmt.Errorf("failed to create analytics index: %s", createRes.String())
+		}
+	}
+
+	analyticsIndexReady = true
+	return nil
+}
+
+func (r *analyticsRepository) BulkApply(ctx context.Context, ops []models.IndexOp) ([]models.BulkItemResult, error) {
+	if err := ensureAnalyticsIndex(ctx); err != nil {
+		return nil, err
+	}
+	return bulkWrite(ctx, []string{analyticsIndexName()}, ops)
+}
+
+type esDocCount struct {
+	DocCount int64 `json:"doc_count"`
+}
+
+type esQueryStats struct {
+	Searches   esDocCount `json:"searches"`
+	Clicked    esDocCount `json:"clicked"`
+	AvgResults struct {
+		Value *float64 `json:"value"`
+	} `json:"avg_results"`
+}
+
+type esQueryStatBucket struct {
+	Key string `json:"key"`
+	esQueryStats
+	// chỉ có ở báo cáo trending
+	Current  *esQueryStats `json:"current"`
+	Previous *esQueryStats `json:"previous"`
+}
+
+type esQueryStatResponse struct {
+	Aggregations struct {
+		Queries struct {
+			Buckets []esQueryStatBucket `json:"buckets"`
+		} `json:"queries"`
+	} `json:"aggregations"`
+}
+
+func timeRange(from, to time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"range": map[string]interface{}{
+			"timestamp": map[string]interface{}{
+				"gte": from.UTC().Format(time.RFC3339),
+				"lt":  to.UTC().Format(time.RFC3339),
+			},
+		},
+	}
+}
+
+// queryStatAggs: lượt tìm chỉ tính trang đầu (trang sau là cùng một lần tìm),
+// click thì tính trên mọi trang vì người dùng có thể bấm ở trang 2
+func queryStatAggs() map[string]interface{} {
+	return map[string]interface{}{
+		"searches":    map[string]interface{}{"filter": map[string]interface{}{"term": map[string]interface{}{"first_page": true}}},
+		"clicked":     map[string]interface{}{"filter": map[string]interface{}{"range": map[string]interface{}{"clicks": map[string]interface{}{"gte": 1}}}},
+		"avg_results": map[string]interface{}{"avg": map[string]interface{}{"field": "result_count"}},
+	}
+}
+
+func buildReportBody(req models.AnalyticsReportRequest) map[string]interface{} {
+	filters := []interface{}{
+		map[string]interface{}{"exists": map[string]interface{}{"field": "normalized"}},
+	}
+	aggs := queryStatAggs()
+	terms := map[string]interface{}{
+		"field": "normalized",
+		// bucket dư ra để bù cho các từ khoá bị loại vì ít lượt tìm
+		"size":  req.Limit * 2,
+		"order": map[string]interface{}{"searches": "desc"},
+	}
+
+	switch req.Report {
+	case models.ReportZeroResults:
+		filters = append(filters, timeRange(req.From, req.To),
+			map[string]interface{}{"term": map[string]interface{}{"result_count": 0}},
+		)
+	case models.ReportLowCTR:
+		// từ khoá không có kết quả thì không có gì để bấm, đã có báo cáo riêng
+		filters = append(filters, timeRange(req.From, req.To),
+			map[string]interface{}{"range": map[string]interface{}{"result_count": map[string]interface{}{"gt": 0}}},
+		)
+		terms["size"] = analyticsCandidateSize
+	case models.ReportTrending:
+		// so với khoảng thời gian dài bằng ngay trước đó
+		previousFrom := req.From.Add(-req.To.Sub(req.From))
+		filters = append(filters, timeRange(previousFrom, req.To))
+		aggs = map[string]interface{}{
+			"current": map[string]interface{}{
+				"filter": timeRange(req.From, req.To),
+				"aggs":   queryStatAggs(),
+			},
+			"previous": map[string]interface{}{
+				"filter": timeRange(previousFrom, req.From),
+				"aggs":   map[string]interface{}{"searches": queryStatAggs()["searches"]},
+			},
+		}
+		terms["size"] = analyticsCandidateSize
+		terms["order"] = map[string]interface{}{"current>searches>_count": "desc"}
+	default:
+		filters = append(filters, timeRange(req.From, req.To))
+	}
+
+	return map[string]interface{}{
+		"size": 0,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{"filter": filters},
+		},
+		"aggs": map[string]interface{}{
+			"queries": map[string]interface{}{
+				"terms": terms,
+				"aggs":  aggs,
+			},
+		},
+	}
+}
+
+func (r *analyticsRepository) Report(ctx context.Context, req models.AnalyticsReportRequest) ([]models.QueryStat, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(buildReportBody(req)); err != nil {
+		return nil, err
+	}
+
+	res, err := database.ES.Search(
+		database.ES.Search.WithContext(ctx),
+		database.ES.Search.WithIndex(analyticsIndexName()),
+		database.ES.Search.WithBody(&buf),
+		database.ES.Search.WithIgnoreUnavailable(true),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("analytics report failed: %s", res.String())
+	}
+
+	var esResp esQueryStatResponse
+	if err := json.NewDecoder(res.Body).Decode(&esResp); err != nil {
+		return nil, err
+	}
+
+	result := make([]models.QueryStat, 0, len(esResp.Aggregations.Queries.Buckets))
+	for _, b := range esResp.Aggregations.Queries.Buckets {
+		stats := b.esQueryStats
+		var previous int64
+		if req.Report == models.ReportTrending {
+			if b.Current == nil || b.Previous == nil {
+				continue
+			}
+			stats, previous = *b.Current, b.Previous.Searches.DocCount
+		}
+
+		stat := models.QueryStat{
+			Query:           b.Key,
+			Searches:        stats.Searches.DocCount,
+			ClickedSearches: min(stats.Clicked.DocCount, stats.Searches.DocCount),
+		}
+		if stats.AvgResults.Value != nil {
+			stat.AvgResults = *stats.AvgResults.Value
+		}
+		if stat.Searches > 0 {
+			stat.CTR = float64(stat.ClickedSearches) / float64(stat.Searches)
+		}
+		if stat.Searches < req.MinSearches {
+			continue
+		}
+
+		if req.Report == models.ReportTrending {
+			if stat.Searches <= previous {
+				continue
+			}
+			growth := float64(stat.Searches-previous) / float64(max(previous, 1))
+			stat.PreviousSearches = &previous
+			stat.Growth = &growth
+		}
+		result = append(result, stat)
+	}
+
+	switch req.Report {
+	case models.ReportLowCTR:
+		sort.SliceStable(result, func(i, j int) bool {
+			if result[i].CTR != result[j].CTR {
+				return result[i].CTR < result[j].CTR
+			}
+			return result[i].Searches > result[j].Searches
+		})
+	case models.ReportTrending:
+		sort.SliceStable(result, func(i, j int) bool {
+			if *result[i].Growth != *result[j].Growth {
+				return *result[i].Growth > *result[j].Growth
+			}
+			return result[i].Searches > result[j].Searches
+		})
+	}
+	if len(result) > req.Limit {
+		result = result[:req.Limit]
+	}
+	return result, nil
+}
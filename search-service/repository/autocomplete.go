@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"search-service/database"
+	"search-service/models"
+)
+
+const (
+	// số từ tối đa lấy làm điểm bắt đầu gợi ý, để gõ từ giữa tên vẫn ra sản phẩm
+	maxSuggestStarts = 5
+	// từ khoá phải được tìm ít nhất ngần này lần mới gợi ý cho người khác
+	minPopularQueryCount = 3
+	maxQueryLength       = 100
+)
+
+func queryIndexName() string {
+	if name := os.Getenv("ELASTICSEARCH_QUERY_INDEX"); name != "" {
+		return name
+	}
+	return os.Getenv("ELASTICSEARCH_INDEX") + "-queries"
+}
+
+// NormalizeQuery: chữ thường, bỏ khoảng trắng thừa
+func NormalizeQuery(query string) string {
+	query = strings.ToLower(strings.Join(strings.Fields(query), " "))
+	if len([]rune(query)) > maxQueryLength {
+		query = string([]rune(query)[:maxQueryLength])
+	}
+	return query
+}
+
+// suggestInputs: cả chuỗi và các hậu tố bắt đầu từ mỗi từ, completion suggester chỉ khớp theo tiền tố
+func suggestInputs(text string) []string {
+	words := strings.Fields(text)
+	inputs := make([]string, 0, maxSuggestStarts)
+	for i := 0; i < len(words) && i < maxSuggestStarts; i++ {
+		inputs = append(inputs, strings.Join(words[i:], " "))
+	}
+	return inputs
+}
+
+// productDocument thêm các field chỉ dùng để gợi ý vào document trước khi index
+func productDocument(product *models.Product) ([]byte, error) {
+	data, err := json.Marshal(product)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	// sản phẩm ngừng bán không gợi ý, completion suggester không lọc được theo status
+	onSale := product.Status == "" || product.Status == models.ProductStatusOnSale
+	if inputs := suggestInputs(product.Name); len(inputs) > 0 && onSale {
+		doc["suggest"] = map[string]interface{}{
+			"input":  inputs,
+			"weight": product.SoldCount + 1,
+		}
+	}
+	if product.Category != "" {
+		doc["category_suggest"] = map[string]interface{}{
+			"input": []string{product.Category},
+		}
+	}
+	return json.Marshal(doc)
+}
+
+var (
+	queryIndexMu    sync.Mutex
+	queryIndexReady bool
+)
+
+func ensureQueryIndex(ctx context.Context) error {
+	queryIndexMu.Lock()
+	defer queryIndexMu.Unlock()
+	if queryIndexReady {
+		return nil
+	}
+
+	indexName := queryIndexName()
+	res, err := database.ES.Indices.Exists([]string{indexName}, database.ES.Indices.Exists.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to check if query index exists: %w", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode == 404 {
+		mapping := map[string]interface{}{
+			"mappings": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"query":            map[string]interface{}{"type": "keyword"},
+					"count":            map[string]interface{}{"type": "long"},
+					"last_searched_at": map[string]interface{}{"type": "date"},
+					"suggest":          map[string]interface{}{"type": "completion"},
+				},
+			},
+		}
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(mapping); err != nil {
+			return err
+		}
+		createRes, err := database.ES.Indices.Create(indexName,
+			database.ES.Indices.Create.WithBody(&buf),
+			database.ES.Indices.Create.WithContext(ctx),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create query index: %w", err)
+		}
+		defer createRes.Body.Close()
+		// replica khác vừa tạo thì bỏ qua
+		if createRes.IsError() && !strings.Contains(createRes.String(), "resource_already_exists_exception") {
+			return fmt.Errorf("failed to create query index: %s", createRes.String())
+		}
+	}
+
+	queryIndexReady = true
+	return nil
+}
+
+// RecordQuery tăng số lần tìm của từ khoá, weight của gợi ý bằng số lần tìm
+func (r *searchRepository) RecordQuery(ctx context.Context, query string) error {
+	query = NormalizeQuery(query)
+	if query == "" {
+		return nil
+	}
+	if err := ensureQueryIndex(ctx); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	body := map[string]interface{}{
+		"script": map[string]interface{}{
+			"source": "ctx._source.count += 1; ctx._source.last_searched_at = params.now; ctx._source.suggest.weight = (int) Math.min(ctx._source.count, 2147483647L);",
+			"params": map[string]interface{}{"now": now},
+		},
+		"upsert": map[string]interface{}{
+			"query":            query,
+			"count":            1,
+			"last_searched_at": now,
+			"suggest":          map[string]interface{}{"input": suggestInputs(query), "weight": 1},
+		},
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		return err
+	}
+
+	sum := sha1.Sum([]byte(query))
+	res, err := database.ES.Update(queryIndexName(), hex.EncodeToString(sum[:]), &buf,
+		database.ES.Update.WithContext(ctx),
+		database.ES.Update.WithRetryOnConflict(3),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("failed to record query: %s", res.String())
+	}
+	return nil
+}
+
+type esSuggestOption struct {
+	Text   string          `json:"text"`
+	ID     string          `json:"_id"`
+	Source json.RawMessage `json:"_source"`
+}
+
+type esSuggestResponse struct {
+	Error   json.RawMessage `json:"error"`
+	Suggest map[string][]struct {
+		Options []esSuggestOption `json:"options"`
+	} `json:"suggest"`
+}
+
+func (resp esSuggestResponse) options(name string) []esSuggestOption {
+	var options []esSuggestOption
+	for _, entry := range resp.Suggest[name] {
+		options = append(options, entry.Options...)
+	}
+	return options
+}
+
+func completion(field, prefix string, size int, fuzzy bool, skipDuplicates bool) map[string]interface{} {
+	opts := map[string]interface{}{
+		"field":           field,
+		"size":            size,
+		"skip_duplicates": skipDuplicates,
+	}
+	if fuzzy {
+		opts["fuzzy"] = map[string]interface{}{"fuzziness": "AUTO", "min_length": 4, "prefix_length": 2}
+	}
+	return map[string]interface{}{"prefix": prefix, "completion": opts}
+}
+
+// Autocomplete gợi ý sản phẩm, category và từ khoá phổ biến trong một lần _msearch
+func (r *searchRepository) Autocomplete(ctx context.Context, prefix string, size int) (*models.AutocompleteResult, error) {
+	prefix = NormalizeQuery(prefix)
+	result := &models.AutocompleteResult{
+		Products:   []models.ProductSuggestion{},
+		Categories: []string{},
+		Queries:    []string{},
+	}
+	if prefix == "" {
+		return result, nil
+	}
+
+	productBody := map[string]interface{}{
+		"_source": []string{"id", "name", "price", "image_url"},
+		"suggest": map[string]interface{}{
+			"products":   completion("suggest", prefix, size, true, true),
+			"categories": completion("category_suggest", prefix, size, false, true),
+		},
+	}
+	queryBody := map[string]interface{}{
+		"_source": []string{"query", "count"},
+		"suggest": map[string]interface{}{
+			// lấy dư vì các từ khoá ít người tìm bị bỏ
+			"queries": completion("suggest", prefix, size*3, false, true),
+		},
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, part := range []interface{}{
+		map[string]interface{}{"index": os.Getenv("ELASTICSEARCH_INDEX")}, productBody,
+		map[string]interface{}{"index": queryIndexName(), "ignore_unavailable": true}, queryBody,
+	} {
+		if err := encoder.Encode(part); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := database.ES.Msearch(&buf, database.ES.Msearch.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("autocomplete failed: %s", res.String())
+	}
+
+	var msearch struct {
+		Responses []esSuggestResponse `json:"responses"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&msearch); err != nil {
+		return nil, err
+	}
+	if len(msearch.Responses) != 2 || len(msearch.Responses[0].Error) > 0 {
+		return nil, fmt.Errorf("autocomplete failed: unexpected msearch response")
+	}
+
+	// một sản phẩm có nhiều input, có thể khớp nhiều lần
+	seen := map[string]bool{}
+	for _, option := range msearch.Responses[0].options("products") {
+		var suggestion models.ProductSuggestion
+		if err := json.Unmarshal(option.Source, &suggestion); err != nil || seen[option.ID] {
+			continue
+		}
+		seen[option.ID] = true
+		result.Products = append(result.Products, suggestion)
+	}
+	for _, option := range msearch.Responses[0].options("categories") {
+		result.Categories = append(result.Categories, option.Text)
+	}
+
+	// index từ khoá lỗi thì vẫn trả gợi ý sản phẩm
+	for _, option := range msearch.Responses[1].options("queries") {
+		var source struct {
+			Query string `json:"query"`
+			Count int64  `json:"count"`
+		}
+		if err := json.Unmarshal(option.Source, &source); err != nil || source.Count < minPopularQueryCount {
+			continue
+		}
+		result.Queries = append(result.Queries, source.Query)
+		if len(result.Queries) == size {
+			break
+		}
+	}
+	return result, nil
+}
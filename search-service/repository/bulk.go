@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"search-service/database"
+	logger "search-service/log"
+	"search-service/models"
+)
+
+type esBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		ID     string          `json:"_id"`
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+// ProductVersion: external version của một sản phẩm là updated_at (unix ms)
+func ProductVersion(product *models.Product) int64 {
+	if v := product.UpdatedAt.UnixMilli(); v > 0 {
+		return v
+	}
+	// dữ liệu cũ không có updated_at, event mới hơn luôn ghi đè được
+	return 1
+}
+
+// external_gte: gửi lại cùng một event (consumer chạy lại sau lỗi) vẫn ghi được, event cũ hơn bị 409
+func bulkAction(action, index, id string, version int64) map[string]interface{} {
+	if action == models.IndexOpUpdate {
+		return map[string]interface{}{
+			action: map[string]interface{}{"_index": index, "_id": id, "retry_on_conflict": 3},
+		}
+	}
+	return map[string]interface{}{
+		action: map[string]interface{}{
+			"_index":       index,
+			"_id":          id,
+			"version":      version,
+			"version_type": "external_gte",
+		},
+	}
+}
+
+func bulkOutcome(action string, status int) string {
+	switch {
+	case status == 409:
+		return models.BulkItemStale
+	case (action == models.IndexOpDelete || action == models.IndexOpUpdate) && status == 404:
+		// document chưa được index thì bỏ qua, lần index đầy đủ sau đã có số liệu mới
+		return models.BulkItemOK
+	case status < 300:
+		return models.BulkItemOK
+	case status == 429 || status >= 500:
+		return models.BulkItemRetry
+	default:
+		return models.BulkItemFailed
+	}
+}
+
+// bulkWrite ghi ops vào mọi index trong targets bằng một request _bulk.
+// Kết quả trả về theo targets[0], các index còn lại (index đang reindex) lỗi thì chỉ log.
+func bulkWrite(ctx context.Context, targets []string, ops []models.IndexOp) ([]models.BulkItemResult, error) {
+	results := make([]models.BulkItemResult, len(ops))
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	// op tương ứng với từng item trong response, -1 là bản ghi vào index phụ
+	owners := make([]int, 0, len(ops)*len(targets))
+	for i, op := range ops {
+		var doc []byte
+		switch op.Action {
+		case models.IndexOpIndex:
+			var data []byte
+			var err error
+			if op.Vendor != nil {
+				data, err = json.Marshal(op.Vendor)
+			} else {
+				op.Product.AttributeFacets = BuildAttributeFacets(op.Product.Attributes)
+				data, err = productDocument(op.Product)
+			}
+			if err != nil {
+				results[i] = models.BulkItemResult{Outcome: models.BulkItemFailed, Error: err.Error()}
+				continue
+			}
+			doc = data
+		case models.IndexOpUpdate:
+			script := op.Script
+			if op.Delta != nil {
+				script = productDeltaScript(op.Delta)
+			}
+			body := map[string]interface{}{"script": script}
+			if op.Upsert != nil {
+				body["upsert"] = op.Upsert
+				body["scripted_upsert"] = true
+			}
+			data, err := json.Marshal(body)
+			if err != nil {
+				results[i] = models.BulkItemResult{Outcome: models.BulkItemFailed, Error: err.Error()}
+				continue
+			}
+			doc = data
+		}
+		for t, target := range targets {
+			if err := encoder.Encode(bulkAction(op.Action, target, op.ID, op.Version)); err != nil {
+				return nil, err
+			}
+			if doc != nil {
+				buf.Write(doc)
+				buf.WriteByte('\n')
+			}
+			owner := i
+			if t > 0 {
+				owner = -1
+			}
+			owners = append(owners, owner)
+		}
+	}
+	if len(owners) == 0 {
+		return results, nil
+	}
+
+	res, err := database.ES.Bulk(&buf, database.ES.Bulk.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("bulk request failed: %s", res.String())
+	}
+
+	var bulkResp esBulkResponse
+	if err := json.NewDecoder(res.Body).Decode(&bulkResp); err != nil {
+		return nil, err
+	}
+	if len(bulkResp.Items) != len(owners) {
+		return nil, fmt.Errorf("bulk response has %d items, expected %d", len(bulkResp.Items), len(owners))
+	}
+
+	for k, item := range bulkResp.Items {
+		for action, op := range item {
+			outcome := bulkOutcome(action, op.Status)
+			if owners[k] < 0 {
+				if outcome == models.BulkItemRetry || outcome == models.BulkItemFailed {
+					logger.Error("Dual write to reindex target failed", logger.Str("id", op.ID), logger.Str("error", string(op.Error)))
+				}
+				continue
+			}
+			results[owners[k]] = models.BulkItemResult{Outcome: outcome, Error: string(op.Error)}
+		}
+	}
+	return results, nil
+}
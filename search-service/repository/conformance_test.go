@@ -0,0 +1,580 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"search-service/database"
+	"search-service/models"
+
+	"github.com/elastic/go-elasticsearch/v8"
+)
+
+// Bộ test chung cho mọi engine: cùng dữ liệu, cùng request thì phải cùng kết quả.
+// Engine nhúng luôn chạy, Elasticsearch chỉ chạy khi có ELASTICSEARCH_TEST_URL.
+
+func TestMemoryConformance(t *testing.T) {
+	runSearchConformance(t, func(t *testing.T) SearchRepository {
+		return NewMemorySearchRepository()
+	})
+}
+
+func TestMemoryVendorConformance(t *testing.T) {
+	runVendorConformance(t, func(t *testing.T) VendorRepository {
+		return NewMemoryVendorRepository()
+	})
+}
+
+// useTestElasticsearch trỏ database.ES vào cluster test, mỗi test một alias riêng
+func useTestElasticsearch(t *testing.T) {
+	url := os.Getenv("ELASTICSEARCH_TEST_URL")
+	if url == "" {
+		t.Skip("ELASTICSEARCH_TEST_URL is not set")
+	}
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{url}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	database.ES = client
+}
+
+func useTestAlias(t *testing.T) string {
+	alias := fmt.Sprintf("conformance-%d", time.Now().UnixNano())
+	t.Setenv("ELASTICSEARCH_INDEX", alias)
+	resetIndexState()
+	t.Cleanup(func() {
+		res, err := database.ES.Indices.Delete([]string{alias + "*"})
+		if err == nil {
+			res.Body.Close()
+		}
+		resetIndexState()
+	})
+	return alias
+}
+
+func TestElasticsearchConformance(t *testing.T) {
+	useTestElasticsearch(t)
+	runSearchConformance(t, func(t *testing.T) SearchRepository {
+		alias := useTestAlias(t)
+		return &refreshingRepository{SearchRepository: NewSearchRepository(), alias: alias}
+	})
+}
+
+func TestElasticsearchVendorConformance(t *testing.T) {
+	useTestElasticsearch(t)
+	runVendorConformance(t, func(t *testing.T) VendorRepository {
+		useTestAlias(t)
+		return &refreshingVendorRepository{VendorRepository: NewVendorRepository()}
+	})
+}
+
+func resetIndexState() {
+	productIndexMu.Lock()
+	productIndexReady = false
+	productIndexMu.Unlock()
+	queryIndexMu.Lock()
+	queryIndexReady = false
+	queryIndexMu.Unlock()
+	vendorIndexMu.Lock()
+	vendorIndexReady = false
+	vendorIndexMu.Unlock()
+}
+
+// refreshingRepository refresh index sau mỗi lần ghi để test đọc được ngay (Elasticsearch là near real-time)
+type refreshingRepository struct {
+	SearchRepository
+	alias string
+}
+
+func (r *refreshingRepository) refresh(ctx context.Context) error {
+	return refreshIndices(ctx, r.alias, queryIndexName())
+}
+
+func refreshIndices(ctx context.Context, indices ...string) error {
+	res, err := database.ES.Indices.Refresh(
+		database.ES.Indices.Refresh.WithContext(ctx),
+		database.ES.Indices.Refresh.WithIndex(indices...),
+		database.ES.Indices.Refresh.WithIgnoreUnavailable(true),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("refresh failed: %s", res.String())
+	}
+	return nil
+}
+
+func (r *refreshingRepository) BulkApply(ctx context.Context, ops []models.IndexOp) ([]models.BulkItemResult, error) {
+	results, err := r.SearchRepository.BulkApply(ctx, ops)
+	if err != nil {
+		return nil, err
+	}
+	return results, r.refresh(ctx)
+}
+
+func (r *refreshingRepository) RecordQuery(ctx context.Context, query string) error {
+	if err := r.SearchRepository.RecordQuery(ctx, query); err != nil {
+		return err
+	}
+	return r.refresh(ctx)
+}
+
+type refreshingVendorRepository struct {
+	VendorRepository
+}
+
+func (r *refreshingVendorRepository) BulkApply(ctx context.Context, ops []models.IndexOp) ([]models.BulkItemResult, error) {
+	results, err := r.VendorRepository.BulkApply(ctx, ops)
+	if err != nil {
+		return nil, err
+	}
+	return results, refreshIndices(ctx, vendorIndexName())
+}
+
+var conformanceBase = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func conformanceProducts() []models.Product {
+	product := func(id, name, category, description string, price float64, quantity, sold int, rating float64, vendor string, age int) models.Product {
+		return models.Product{
+			ID:          id,
+			Name:        name,
+			Category:    category,
+			Description: description,
+			Price:       price,
+			Quantity:    quantity,
+			SoldCount:   sold,
+			Rating:      rating,
+			RatingCount: 10,
+			Status:      models.ProductStatusOnSale,
+			UserID:      vendor,
+			CreatedAt:   conformanceBase.Add(time.Duration(age) * time.Hour),
+			UpdatedAt:   conformanceBase.Add(time.Duration(age) * time.Hour),
+		}
+	}
+
+	products := []models.Product{
+		product("p1", "Điện thoại Samsung Galaxy S24", "Điện thoại", "Màn hình 6.2 inch", 20000000, 5, 100, 4.8, "v1", 1),
+		product("p2", "Điện thoại iPhone 15", "Điện thoại", "Chip A16", 22000000, 0, 300, 4.6, "v2", 2),
+		product("p3", "Ốp lưng Samsung", "Phụ kiện", "Ốp silicon cho điện thoại", 150000, 50, 20, 3.9, "v1", 3),
+		product("p4", "Tai nghe Bluetooth", "Phụ kiện", "Tai nghe không dây, pin 20 giờ", 900000, 12, 20, 4.2, "v3", 4),
+		product("p5", "Áo thun cotton", "Thời trang", "Áo thun nam", 200000, 30, 5, 2.5, "v2", 5),
+		product("p6", "Điện thoại Samsung cũ", "Điện thoại", "Hàng ngừng kinh doanh", 5000000, 1, 0, 3.0, "v1", 6),
+	}
+	products[0].Attributes = map[string]interface{}{"color": "black", "storage": "256GB"}
+	products[1].Attributes = map[string]interface{}{"color": "white", "storage": "128GB"}
+	products[2].Attributes = map[string]interface{}{"color": "black"}
+	products[5].Status = "stopped"
+	return products
+}
+
+func seedConformance(t *testing.T, repo SearchRepository) {
+	t.Helper()
+	var ops []models.IndexOp
+	for _, p := range conformanceProducts() {
+		p := p
+		ops = append(ops, models.IndexOp{Action: models.IndexOpIndex, ID: p.ID, Version: ProductVersion(&p), Product: &p})
+	}
+	applyOps(t, repo, ops...)
+}
+
+func applyOps(t *testing.T, repo SearchRepository, ops ...models.IndexOp) []models.BulkItemResult {
+	t.Helper()
+	results, err := repo.BulkApply(context.Background(), ops)
+	if err != nil {
+		t.Fatalf("BulkApply: %v", err)
+	}
+	if len(results) != len(ops) {
+		t.Fatalf("BulkApply returned %d results for %d ops", len(results), len(ops))
+	}
+	return results
+}
+
+func search(t *testing.T, repo SearchRepository, req models.SearchRequest) *models.SearchResult {
+	t.Helper()
+	if req.Size == 0 {
+		req.Size = 10
+	}
+	if req.PriceInterval == 0 {
+		req.PriceInterval = 1000000
+	}
+	result, err := repo.Search(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Search(%+v): %v", req, err)
+	}
+	return result
+}
+
+func hitIDs(result *models.SearchResult) []string {
+	ids := make([]string, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids
+}
+
+func expectIDs(t *testing.T, result *models.SearchResult, want ...string) {
+	t.Helper()
+	got := hitIDs(result)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("hits = %v, want %v", got, want)
+	}
+}
+
+func expectSet(t *testing.T, result *models.SearchResult, want ...string) {
+	t.Helper()
+	got := map[string]bool{}
+	for _, id := range hitIDs(result) {
+		got[id] = true
+	}
+	if len(got) != len(want) || int(result.Total) != len(want) {
+		t.Fatalf("hits = %v (total %d), want %v", hitIDs(result), result.Total, want)
+	}
+	for _, id := range want {
+		if !got[id] {
+			t.Fatalf("hits = %v, want %v", hitIDs(result), want)
+		}
+	}
+}
+
+func float(v float64) *float64 { return &v }
+
+func runSearchConformance(t *testing.T, newRepo func(t *testing.T) SearchRepository) {
+	setup := func(t *testing.T) SearchRepository {
+		repo := newRepo(t)
+		seedConformance(t, repo)
+		return repo
+	}
+
+	t.Run("versioning", func(t *testing.T) {
+		repo := setup(t)
+		p := conformanceProducts()[0]
+		stale := p
+		stale.Name = "Bản cũ"
+		results := applyOps(t, repo, models.IndexOp{Action: models.IndexOpIndex, ID: p.ID, Version: ProductVersion(&p) - 1, Product: &stale})
+		if results[0].Outcome != models.BulkItemStale {
+			t.Fatalf("stale index outcome = %s", results[0].Outcome)
+		}
+		results = applyOps(t, repo, models.IndexOp{Action: models.IndexOpDelete, ID: p.ID, Version: ProductVersion(&p) - 1})
+		if results[0].Outcome != models.BulkItemStale {
+			t.Fatalf("stale delete outcome = %s", results[0].Outcome)
+		}
+		expectSet(t, search(t, repo, models.SearchRequest{Query: "galaxy"}), "p1")
+
+		results = applyOps(t, repo, models.IndexOp{Action: models.IndexOpDelete, ID: p.ID, Version: ProductVersion(&p) + 1})
+		if results[0].Outcome != models.BulkItemOK {
+			t.Fatalf("delete outcome = %s", results[0].Outcome)
+		}
+		expectSet(t, search(t, repo, models.SearchRequest{Query: "galaxy"}))
+
+		// index lại bằng version cũ hơn lần xoá thì bị chặn
+		results = applyOps(t, repo, models.IndexOp{Action: models.IndexOpIndex, ID: p.ID, Version: ProductVersion(&p), Product: &p})
+		if results[0].Outcome != models.BulkItemStale {
+			t.Fatalf("index after delete outcome = %s", results[0].Outcome)
+		}
+
+		results = applyOps(t, repo, models.IndexOp{Action: models.IndexOpUpdate, ID: "missing",
+			Delta: &models.ProductStatsDelta{EventKey: "rating:x", RatingCount: 1, RatingSum: 5}})
+		if results[0].Outcome != models.BulkItemOK {
+			t.Fatalf("update missing outcome = %s", results[0].Outcome)
+		}
+	})
+
+	t.Run("vietnamese folding", func(t *testing.T) {
+		repo := setup(t)
+		expectSet(t, search(t, repo, models.SearchRequest{Query: "dien thoai"}), "p1", "p2", "p3")
+		expectSet(t, search(t, repo, models.SearchRequest{Query: "op lung"}), "p3")
+		expectSet(t, search(t, repo, models.SearchRequest{Query: "ÁO THUN"}), "p5")
+	})
+
+	t.Run("fuzzy", func(t *testing.T) {
+		repo := setup(t)
+		expectSet(t, search(t, repo, models.SearchRequest{Query: "samsng galaxy"}), "p1")
+	})
+
+	t.Run("all terms required", func(t *testing.T) {
+		repo := setup(t)
+		expectSet(t, search(t, repo, models.SearchRequest{Query: "samsung galaxy"}), "p1")
+	})
+
+	t.Run("name ranks above description", func(t *testing.T) {
+		repo := setup(t)
+		result := search(t, repo, models.SearchRequest{Query: "tai nghe"})
+		if len(result.Hits) == 0 || result.Hits[0].ID != "p4" {
+			t.Fatalf("hits = %v, want p4 first", hitIDs(result))
+		}
+		result = search(t, repo, models.SearchRequest{Query: "điện thoại"})
+		expectSet(t, result, "p1", "p2", "p3")
+		if result.Hits[2].ID != "p3" {
+			t.Fatalf("hits = %v, want description match p3 last", hitIDs(result))
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		repo := setup(t)
+		expectSet(t, search(t, repo, models.SearchRequest{}), "p1", "p2", "p3", "p4", "p5")
+		expectSet(t, search(t, repo, models.SearchRequest{MinPrice: float(200000), MaxPrice: float(1000000)}), "p4", "p5")
+		expectSet(t, search(t, repo, models.SearchRequest{MinRating: float(4.5)}), "p1", "p2")
+		expectSet(t, search(t, repo, models.SearchRequest{Query: "điện thoại", InStock: true}), "p1", "p3")
+		expectSet(t, search(t, repo, models.SearchRequest{VendorID: "v1"}), "p1", "p3")
+		expectSet(t, search(t, repo, models.SearchRequest{Categories: []string{"Phụ kiện", "Thời trang"}}), "p3", "p4", "p5")
+		expectSet(t, search(t, repo, models.SearchRequest{Attributes: map[string][]string{"color": {"black"}}}), "p1", "p3")
+		expectSet(t, search(t, repo, models.SearchRequest{Attributes: map[string][]string{
+			"color":   {"black", "white"},
+			"storage": {"128GB"},
+		}}), "p2")
+	})
+
+	t.Run("sort and cursor", func(t *testing.T) {
+		repo := setup(t)
+		expectIDs(t, search(t, repo, models.SearchRequest{Sort: models.SortPriceAsc}), "p3", "p5", "p4", "p1", "p2")
+		expectIDs(t, search(t, repo, models.SearchRequest{Sort: models.SortPriceDesc}), "p2", "p1", "p4", "p5", "p3")
+		expectIDs(t, search(t, repo, models.SearchRequest{Sort: models.SortNewest}), "p5", "p4", "p3", "p2", "p1")
+		expectIDs(t, search(t, repo, models.SearchRequest{Sort: models.SortRating}), "p1", "p2", "p4", "p3", "p5")
+
+		// p3 và p4 cùng sold_count, thứ tự phải ổn định qua các trang
+		var pages []string
+		req := models.SearchRequest{Sort: models.SortBestSelling, Size: 2}
+		for i := 0; i < 5; i++ {
+			result := search(t, repo, req)
+			pages = append(pages, hitIDs(result)...)
+			if result.NextCursor == "" {
+				break
+			}
+			req.Cursor = result.NextCursor
+		}
+		if fmt.Sprint(pages) != fmt.Sprint([]string{"p2", "p1", "p3", "p4", "p5"}) {
+			t.Fatalf("paged hits = %v", pages)
+		}
+
+		if _, err := repo.Search(context.Background(), models.SearchRequest{Size: 2, Cursor: "not-a-cursor", PriceInterval: 1000000}); err == nil {
+			t.Fatal("expected error for invalid cursor")
+		}
+	})
+
+	t.Run("facets", func(t *testing.T) {
+		repo := setup(t)
+		result := search(t, repo, models.SearchRequest{Categories: []string{"Phụ kiện"}})
+		expectSet(t, result, "p3", "p4")
+
+		// lựa chọn category không thu hẹp facet category
+		categories := map[string]int64{}
+		for _, b := range result.Facets.Categories {
+			categories[b.Value] = b.Count
+		}
+		want := map[string]int64{"Điện thoại": 2, "Phụ kiện": 2, "Thời trang": 1}
+		if fmt.Sprint(categories) != fmt.Sprint(want) {
+			t.Fatalf("category facet = %v, want %v", categories, want)
+		}
+
+		ratings := map[float64]int64{}
+		for _, b := range result.Facets.Ratings {
+			ratings[b.From] = b.Count
+		}
+		// facet khác tính trên kết quả đã lọc category (p3: 3.9, p4: 4.2)
+		wantRatings := map[float64]int64{1: 2, 2: 2, 3: 2, 4: 1}
+		if fmt.Sprint(ratings) != fmt.Sprint(wantRatings) {
+			t.Fatalf("rating facet = %v, want %v", ratings, wantRatings)
+		}
+
+		colors := map[string]int64{}
+		for _, b := range result.Facets.Attributes["color"] {
+			colors[b.Value] = b.Count
+		}
+		if len(colors) != 1 || colors["black"] != 1 {
+			t.Fatalf("color facet = %v", colors)
+		}
+
+		var histogram int64
+		for _, b := range result.Facets.PriceHistogram {
+			histogram += b.Count
+		}
+		if histogram != 2 {
+			t.Fatalf("price histogram counts %d products, want 2", histogram)
+		}
+	})
+
+	t.Run("stats deltas", func(t *testing.T) {
+		repo := setup(t)
+		rating := models.IndexOp{Action: models.IndexOpUpdate, ID: "p4",
+			Delta: &models.ProductStatsDelta{EventKey: "rating:1", RatingCount: 10, RatingSum: 30}}
+		applyOps(t, repo, rating)
+		applyOps(t, repo, rating) // event lặp lại không được cộng hai lần
+
+		result := search(t, repo, models.SearchRequest{Query: "tai nghe"})
+		expectSet(t, result, "p4")
+		p := result.Hits[0]
+		if p.SoldCount != 20 || p.Quantity != 12 || p.RatingCount != 20 {
+			t.Fatalf("stats = sold %d, quantity %d, rating_count %d", p.SoldCount, p.Quantity, p.RatingCount)
+		}
+		if p.Rating < 3.59 || p.Rating > 3.61 {
+			t.Fatalf("rating = %v, want 3.6", p.Rating)
+		}
+	})
+
+	t.Run("popularity", func(t *testing.T) {
+		repo := setup(t)
+		// cùng độ khớp, sản phẩm bán chạy hơn lên trước
+		var ops []models.IndexOp
+		for _, p := range []models.Product{
+			{ID: "p7", Name: "Sạc dự phòng 10000mAh", Price: 300000, Quantity: 10, SoldCount: 2, Rating: 4, RatingCount: 10, UpdatedAt: conformanceBase},
+			{ID: "p8", Name: "Sạc dự phòng 10000mAh", Price: 300000, Quantity: 10, SoldCount: 500, Rating: 4, RatingCount: 10, UpdatedAt: conformanceBase},
+		} {
+			p := p
+			ops = append(ops, models.IndexOp{Action: models.IndexOpIndex, ID: p.ID, Version: ProductVersion(&p), Product: &p})
+		}
+		applyOps(t, repo, ops...)
+		expectIDs(t, search(t, repo, models.SearchRequest{Query: "sạc dự phòng"}), "p8", "p7")
+	})
+
+	t.Run("vendor stats", func(t *testing.T) {
+		repo := setup(t)
+		stats, err := repo.VendorStats(context.Background(), "v1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// p6 ngừng bán không tính
+		want := models.VendorStats{ProductCount: 2, TotalSales: 120, Rating: 4.35, RatingCount: 20}
+		if *stats != want {
+			t.Fatalf("stats = %+v, want %+v", *stats, want)
+		}
+
+		stats, err = repo.VendorStats(context.Background(), "unknown")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *stats != (models.VendorStats{}) {
+			t.Fatalf("stats of unknown vendor = %+v", *stats)
+		}
+	})
+
+	t.Run("autocomplete", func(t *testing.T) {
+		repo := setup(t)
+		ctx := context.Background()
+		result, err := repo.Autocomplete(ctx, "dien", 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Products) != 2 || result.Products[0].ID != "p2" || result.Products[1].ID != "p1" {
+			t.Fatalf("product suggestions = %+v", result.Products)
+		}
+		if fmt.Sprint(result.Categories) != fmt.Sprint([]string{"Điện thoại"}) {
+			t.Fatalf("category suggestions = %v", result.Categories)
+		}
+
+		result, err = repo.Autocomplete(ctx, "galax", 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Products) != 1 || result.Products[0].ID != "p1" {
+			t.Fatalf("product suggestions = %+v", result.Products)
+		}
+
+		for i := 0; i < minPopularQueryCount; i++ {
+			if err := repo.RecordQuery(ctx, "Tai nghe chống ồn"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := repo.RecordQuery(ctx, "tai nghe gaming"); err != nil {
+			t.Fatal(err)
+		}
+		result, err = repo.Autocomplete(ctx, "tai ng", 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(result.Queries) != fmt.Sprint([]string{"tai nghe chống ồn"}) {
+			t.Fatalf("query suggestions = %v", result.Queries)
+		}
+	})
+}
+
+func runVendorConformance(t *testing.T, newRepo func(t *testing.T) VendorRepository) {
+	vendor := func(id, name, description string, age int) models.Vendor {
+		return models.Vendor{
+			ID:          id,
+			DisplayName: name,
+			Description: description,
+			JoinedAt:    conformanceBase,
+			UpdatedAt:   conformanceBase.Add(time.Duration(age) * time.Hour),
+		}
+	}
+	setup := func(t *testing.T) VendorRepository {
+		repo := newRepo(t)
+		var ops []models.IndexOp
+		for _, v := range []models.Vendor{
+			vendor("v1", "Samsung Official Store", "Điện thoại và máy tính bảng chính hãng", 1),
+			vendor("v2", "Điện Máy Xanh", "Điện máy, gia dụng", 1),
+			vendor("v3", "Thời trang Hà Nội", "Quần áo nam nữ", 1),
+		} {
+			v := v
+			ops = append(ops, models.IndexOp{Action: models.IndexOpIndex, ID: v.ID, Version: VendorVersion(&v), Vendor: &v})
+		}
+		results, err := repo.BulkApply(context.Background(), ops)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range results {
+			if r.Outcome != models.BulkItemOK {
+				t.Fatalf("BulkApply outcome = %+v", r)
+			}
+		}
+		return repo
+	}
+	searchIDs := func(t *testing.T, repo VendorRepository, query string) []string {
+		t.Helper()
+		vendors, err := repo.Search(context.Background(), query, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := []string{}
+		for _, v := range vendors {
+			ids = append(ids, v.ID)
+		}
+		return ids
+	}
+
+	t.Run("get and versioning", func(t *testing.T) {
+		repo := setup(t)
+		stale := vendor("v1", "Tên cũ", "", 0)
+		results, err := repo.BulkApply(context.Background(), []models.IndexOp{{Action: models.IndexOpIndex, ID: "v1", Version: VendorVersion(&stale), Vendor: &stale}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if results[0].Outcome != models.BulkItemStale {
+			t.Fatalf("stale vendor outcome = %s", results[0].Outcome)
+		}
+
+		v, err := repo.Get(context.Background(), "v1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.DisplayName != "Samsung Official Store" || !v.JoinedAt.Equal(conformanceBase) {
+			t.Fatalf("vendor = %+v", v)
+		}
+		if _, err := repo.Get(context.Background(), "missing"); !errors.Is(err, ErrVendorNotFound) {
+			t.Fatalf("Get(missing) error = %v", err)
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		repo := setup(t)
+		if ids := searchIDs(t, repo, "may xanh"); fmt.Sprint(ids) != "[v2]" {
+			t.Fatalf("may xanh = %v", ids)
+		}
+		if ids := searchIDs(t, repo, "samsng"); fmt.Sprint(ids) != "[v1]" {
+			t.Fatalf("samsng = %v", ids)
+		}
+		// khớp tên shop xếp trước khớp mô tả
+		if ids := searchIDs(t, repo, "điện"); fmt.Sprint(ids) != "[v2 v1]" {
+			t.Fatalf("điện = %v", ids)
+		}
+		if ids := searchIDs(t, repo, "nội thất"); len(ids) != 0 {
+			t.Fatalf("nội thất = %v", ids)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+)
+
+// engine tìm kiếm chọn qua SEARCH_ENGINE
+const (
+	EngineElasticsearch = "elasticsearch"
+	// engine nhúng trong bộ nhớ, chạy local / test không cần cluster Elasticsearch.
+	// Chỉ có tìm kiếm + autocomplete: không có synonym, reindex và analytics
+	EngineMemory = "memory"
+)
+
+func SearchEngine() string {
+	if engine := os.Getenv("SEARCH_ENGINE"); engine != "" {
+		return engine
+	}
+	return EngineElasticsearch
+}
+
+// NewSearchRepositoryForEngine trả về SearchRepository của engine đã chọn
+func NewSearchRepositoryForEngine(engine string) (SearchRepository, error) {
+	switch engine {
+	case EngineElasticsearch:
+		return NewSearchRepository(), nil
+	case EngineMemory:
+		return NewMemorySearchRepository(), nil
+	default:
+		return nil, fmt.Errorf("unknown search engine: %s", engine)
+	}
+}
+
+func NewVendorRepositoryForEngine(engine string) (VendorRepository, error) {
+	switch engine {
+	case EngineElasticsearch:
+		return NewVendorRepository(), nil
+	case EngineMemory:
+		return NewMemoryVendorRepository(), nil
+	default:
+		return nil, fmt.Errorf("unknown search engine: %s", engine)
+	}
+}
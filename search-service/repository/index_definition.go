@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"search-service/database"
+)
+
+// ProductIndexVersion tăng mỗi khi đổi ProductIndexDefinition, sau đó chạy reindex để dựng index mới
+const ProductIndexVersion = 2
+
+// boost khi tìm theo từ khoá: tên > category > mô tả
+const (
+	nameBoost       = 4
+	nameFoldedBoost = 3
+	categoryBoost   = 2
+	phraseBoost     = 3
+	shingleBoost    = 2
+	// không sửa lỗi chính tả cho từ <= 3 ký tự, 1 lỗi cho 4-6 ký tự, 2 lỗi từ 7 ký tự
+	searchFuzziness = "AUTO:4,7"
+)
+
+// function_score: điểm text nhân với (1 + độ phổ biến + rating), tối đa gấp maxPopularityBoost lần
+const (
+	soldCountWeight = 0.5
+	ratingFactor    = 0.1
+	// rating của sản phẩm ít đánh giá không đáng tin, chưa cộng điểm
+	minRatingCount     = 3
+	maxPopularityBoost = 3
+)
+
+func synonymsSetName() string {
+	if name := os.Getenv("ELASTICSEARCH_SYNONYMS_SET"); name != "" {
+		return name
+	}
+	return "product-synonyms"
+}
+
+func vietnameseText(analyzer, searchAnalyzer string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":            "text",
+		"analyzer":        analyzer,
+		"search_analyzer": searchAnalyzer,
+	}
+}
+
+// ProductIndexDefinition: settings + mappings của index sản phẩm.
+// Tên giữ nguyên dấu (vi_text) để khớp chính xác được ưu tiên, subfield folded bỏ dấu để gõ không dấu vẫn ra.
+// Synonym chỉ áp dụng lúc search (synonym_graph updateable) nên sửa synonym không cần index lại.
+func ProductIndexDefinition() map[string]interface{} {
+	return map[string]interface{}{
+		"settings": map[string]interface{}{
+			"analysis": map[string]interface{}{
+				"filter": map[string]interface{}{
+					"vi_folding": map[string]interface{}{
+						"type": "asciifolding",
+					},
+					"vi_shingle": map[string]interface{}{
+						"type":             "shingle",
+						"min_shingle_size": 2,
+						"max_shingle_size": 3,
+						"output_unigrams":  false,
+					},
+					"product_synonyms": map[string]interface{}{
+						"type":         "synonym_graph",
+						"synonyms_set": synonymsSetName(),
+						"updateable":   true,
+						"lenient":      true,
+					},
+				},
+				"analyzer": map[string]interface{}{
+					"vi_text": map[string]interface{}{
+						"type":      "custom",
+						"tokenizer": "standard",
+						"filter":    []string{"lowercase"},
+					},
+					"vi_text_search": map[string]interface{}{
+						"type":      "custom",
+						"tokenizer": "standard",
+						"filter":    []string{"lowercase", "product_synonyms"},
+					},
+					"vi_folded": map[string]interface{}{
+						"type":      "custom",
+						"tokenizer": "standard",
+						"filter":    []string{"lowercase", "vi_folding"},
+					},
+					// synonym đứng trước folding, rule đã được thêm biến thể không dấu khi lưu
+					"vi_folded_search": map[string]interface{}{
+						"type":      "custom",
+						"tokenizer": "standard",
+						"filter":    []string{"lowercase", "product_synonyms", "vi_folding"},
+					},
+					// từ ghép nhiều âm tiết ("điện thoại", "máy giặt") khớp nguyên cụm được cộng điểm
+					"vi_shingle": map[string]interface{}{
+						"type":      "custom",
+						"tokenizer": "standard",
+						"filter":    []string{"lowercase", "vi_folding", "vi_shingle"},
+					},
+					// dùng cho completion suggester
+					"custom_analyzer": map[string]interface{}{
+						"type":      "custom",
+						"tokenizer": "standard",
+						"filter":    []string{"lowercase", "asciifolding"},
+					},
+				},
+			},
+		},
+		"mappings": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"id": map[string]interface{}{
+					"type": "keyword",
+				},
+				"name": map[string]interface{}{
+					"type":            "text",
+					"analyzer":        "vi_text",
+					"search_analyzer": "vi_text_search",
+					"fields": map[string]interface{}{
+						"folded": vietnameseText("vi_folded", "vi_folded_search"),
+						"shingle": map[string]interface{}{
+							"type":     "text",
+							"analyzer": "vi_shingle",
+						},
+					},
+				},
+				"description": vietnameseText("vi_folded", "vi_folded_search"),
+				"category": map[string]interface{}{
+					"type": "keyword",
+					"fields": map[string]interface{}{
+						"text": vietnameseText("vi_folded", "vi_folded_search"),
+					},
+				},
+				"price": map[string]interface{}{
+					"type": "float",
+				},
+				"quantity": map[string]interface{}{
+					"type": "integer",
+				},
+				"sold_count": map[string]interface{}{
+					"type": "integer",
+				},
+				"rating": map[string]interface{}{
+					"type": "float",
+				},
+				"rating_count": map[string]interface{}{
+					"type": "integer",
+				},
+				"status": map[string]interface{}{
+					"type": "keyword",
+				},
+				"user_id": map[string]interface{}{
+					"type": "keyword",
+				},
+				"vendor_name": map[string]interface{}{
+					"type":            "text",
+					"analyzer":        "vi_folded",
+					"search_analyzer": "vi_folded_search",
+					"fields": map[string]interface{}{
+						"keyword": map[string]interface{}{"type": "keyword"},
+					},
+				},
+				"attributes": map[string]interface{}{
+					"type":    "object",
+					"enabled": false,
+				},
+				"attribute_facets": map[string]interface{}{
+					"type": "keyword",
+				},
+				// key các event cập nhật một phần đã áp dụng, xem partial_update.go
+				"applied_events": map[string]interface{}{
+					"type":       "keyword",
+					"index":      false,
+					"doc_values": false,
+				},
+				"image_url": map[string]interface{}{
+					"type":  "keyword",
+					"index": false,
+				},
+				"created_at": map[string]interface{}{
+					"type": "date",
+				},
+				"updated_at": map[string]interface{}{
+					"type": "date",
+				},
+				// autocomplete: completion suggester cho tên sản phẩm và category
+				"suggest": map[string]interface{}{
+					"type":     "completion",
+					"analyzer": "custom_analyzer",
+				},
+				"category_suggest": map[string]interface{}{
+					"type":     "completion",
+					"analyzer": "custom_analyzer",
+				},
+			},
+		},
+	}
+}
+
+var (
+	productIndexMu    sync.Mutex
+	productIndexReady bool
+)
+
+// ensureProductIndex: lần đầu chạy (chưa có alias lẫn index) thì tạo index phiên bản hiện tại và gắn alias.
+// Index cũ tạo trực tiếp bằng tên alias vẫn dùng được cho đến lần reindex đầu tiên.
+func ensureProductIndex(ctx context.Context, alias string) error {
+	productIndexMu.Lock()
+	defer productIndexMu.Unlock()
+	if productIndexReady {
+		return nil
+	}
+
+	res, err := database.ES.Indices.Exists([]string{alias}, database.ES.Indices.Exists.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to check if index exists: %w", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode == 404 {
+		if err := createProductIndex(ctx, bootstrapIndexName(alias), alias); err != nil {
+			return err
+		}
+	}
+
+	productIndexReady = true
+	return nil
+}
+
+// createProductIndex tạo index theo ProductIndexDefinition, alias rỗng thì chưa gắn alias
+func createProductIndex(ctx context.Context, indexName, alias string) error {
+	if err := ensureSynonymsSet(ctx); err != nil {
+		return err
+	}
+
+	definition := ProductIndexDefinition()
+	if alias != "" {
+		definition["aliases"] = map[string]interface{}{alias: map[string]interface{}{}}
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(definition); err != nil {
+		return fmt.Errorf("failed to encode index mapping: %w", err)
+	}
+	createRes, err := database.ES.Indices.Create(indexName,
+		database.ES.Indices.Create.WithBody(&buf),
+		database.ES.Indices.Create.WithContext(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+	defer createRes.Body.Close()
+
+	if createRes.IsError() && !strings.Contains(createRes.String(), "resource_already_exists_exception") {
+		return fmt.Errorf("failed to create index: %s", createRes.String())
+	}
+	return nil
+}
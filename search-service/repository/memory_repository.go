@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"search-service/models"
+)
+
+// memoryDoc: một sản phẩm trong engine nhúng, token được phân tích sẵn lúc ghi
+type memoryDoc struct {
+	product models.Product
+	// external version như Elasticsearch, document đã xoá vẫn giữ version để chặn event cũ
+	version       int64
+	deleted       bool
+	appliedEvents []string
+	fields        memoryFields
+	suggest       []string
+	category      string
+}
+
+type memoryQuery struct {
+	query   string
+	count   int64
+	suggest []string
+}
+
+// memorySearchRepository là SearchRepository chạy hoàn toàn trong bộ nhớ, cùng ngữ nghĩa với
+// searchRepository (Elasticsearch): versioning, filter, facet, cursor, function_score, autocomplete.
+// Không có synonym và không lưu xuống đĩa, khởi động lại thì nạp lại từ product-service.
+type memorySearchRepository struct {
+	mu      sync.RWMutex
+	docs    map[string]*memoryDoc
+	queries map[string]*memoryQuery
+}
+
+func NewMemorySearchRepository() SearchRepository {
+	return &memorySearchRepository{
+		docs:    make(map[string]*memoryDoc),
+		queries: make(map[string]*memoryQuery),
+	}
+}
+
+func newMemoryDoc(product models.Product, version int64) *memoryDoc {
+	product.AttributeFacets = BuildAttributeFacets(product.Attributes)
+	doc := &memoryDoc{product: product, version: version, fields: analyzeProduct(&product)}
+	// như productDocument: sản phẩm ngừng bán không gợi ý
+	if isOnSale(&product) {
+		for _, input := range suggestInputs(product.Name) {
+			doc.suggest = append(doc.suggest, completionText(input))
+		}
+	}
+	doc.category = completionText(product.Category)
+	return doc
+}
+
+func isOnSale(product *models.Product) bool {
+	return product.Status == "" || product.Status == models.ProductStatusOnSale
+}
+
+func (r *memorySearchRepository) BulkApply(ctx context.Context, ops []models.IndexOp) ([]models.BulkItemResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := make([]models.BulkItemResult, len(ops))
+	for i, op := range ops {
+		results[i] = r.apply(op)
+	}
+	return results, nil
+}
+
+func (r *memorySearchRepository) apply(op models.IndexOp) models.BulkItemResult {
+	existing := r.docs[op.ID]
+	switch op.Action {
+	case models.IndexOpIndex:
+		if op.Product == nil {
+			return models.BulkItemResult{Outcome: models.BulkItemFailed, Error: "index op without product"}
+		}
+		// external_gte
+		if existing != nil && existing.version > op.Version {
+			return models.BulkItemResult{Outcome: models.BulkItemStale}
+		}
+		r.docs[op.ID] = newMemoryDoc(*op.Product, op.Version)
+	case models.IndexOpDelete:
+		if existing != nil && existing.version > op.Version {
+			return models.BulkItemResult{Outcome: models.BulkItemStale}
+		}
+		r.docs[op.ID] = &memoryDoc{version: op.Version, deleted: true}
+	case models.IndexOpUpdate:
+		if op.Delta == nil {
+			return models.BulkItemResult{Outcome: models.BulkItemFailed, Error: "memory engine only supports product stats updates"}
+		}
+		// giống 404 của Elasticsearch: chưa index thì bỏ qua
+		if existing == nil || existing.deleted {
+			return models.BulkItemResult{Outcome: models.BulkItemOK}
+		}
+		existing.applyDelta(op.Delta)
+	default:
+		return models.BulkItemResult{Outcome: models.BulkItemFailed, Error: fmt.Sprintf("unknown action %s", op.Action)}
+	}
+	return models.BulkItemResult{Outcome: models.BulkItemOK}
+}
+
+// applyDelta: cùng logic với productDeltaScript
+func (d *memoryDoc) applyDelta(delta *models.ProductStatsDelta) {
+	for _, key := range d.appliedEvents {
+		if key == delta.EventKey {
+			return
+		}
+	}
+	d.appliedEvents = append(d.appliedEvents, delta.EventKey)
+	if len(d.appliedEvents) > appliedEventsLimit {
+		d.appliedEvents = d.appliedEvents[1:]
+	}
+
+	p := &d.product
+	if delta.RatingCount > 0 {
+		total := p.RatingCount + delta.RatingCount
+		p.Rating = (p.Rating*float64(p.RatingCount) + delta.RatingSum) / float64(total)
+		p.RatingCount = total
+	}
+}
+
+func (r *memorySearchRepository) RecordQuery(ctx context.Context, query string) error {
+	query = NormalizeQuery(query)
+	if query == "" {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	q, ok := r.queries[query]
+	if !ok {
+		q = &memoryQuery{query: query}
+		for _, input := range suggestInputs(query) {
+			q.suggest = append(q.suggest, completionText(input))
+		}
+		r.queries[query] = q
+	}
+	q.count++
+	return nil
+}
+
+type memorySuggestion struct {
+	text   string
+	weight int64
+	fuzzy  bool
+}
+
+// bestSuggestion: input khớp tốt nhất của một document, completion suggester chỉ trả mỗi document một lần
+func bestSuggestion(inputs []string, prefix string, fuzzy bool) (memorySuggestion, bool) {
+	var best memorySuggestion
+	found := false
+	for _, input := range inputs {
+		if strings.HasPrefix(input, prefix) {
+			return memorySuggestion{text: input}, true
+		}
+		if fuzzy && !found && fuzzyPrefixMatch(prefix, input) {
+			best, found = memorySuggestion{text: input, fuzzy: true}, true
+		}
+	}
+	return best, found
+}
+
+func (r *memorySearchRepository) Autocomplete(ctx context.Context, prefix string, size int) (*models.AutocompleteResult, error) {
+	prefix = NormalizeQuery(prefix)
+	result := &models.AutocompleteResult{
+		Products:   []models.ProductSuggestion{},
+		Categories: []string{},
+		Queries:    []string{},
+	}
+	prefix = completionText(prefix)
+	if prefix == "" {
+		return result, nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type productMatch struct {
+		doc        *memoryDoc
+		suggestion memorySuggestion
+	}
+	var products []productMatch
+	categories := map[string]bool{}
+	for _, doc := range r.docs {
+		if doc.deleted {
+			continue
+		}
+		if s, ok := bestSuggestion(doc.suggest, prefix, true); ok {
+			s.weight = int64(doc.product.SoldCount) + 1
+			products = append(products, productMatch{doc: doc, suggestion: s})
+		}
+		if doc.category != "" && strings.HasPrefix(doc.category, prefix) {
+			categories[doc.product.Category] = true
+		}
+	}
+	// khớp đúng tiền tố trước khớp mờ, sau đó theo weight
+	sort.Slice(products, func(i, j int) bool {
+		a, b := products[i].suggestion, products[j].suggestion
+		if a.fuzzy != b.fuzzy {
+			return !a.fuzzy
+		}
+		if a.weight != b.weight {
+			return a.weight > b.weight
+		}
+		return products[i].doc.product.ID < products[j].doc.product.ID
+	})
+	// skip_duplicates: cùng text gợi ý chỉ lấy một lần
+	seen := map[string]bool{}
+	for _, m := range products {
+		if len(result.Products) == size {
+			break
+		}
+		if seen[m.suggestion.text] {
+			continue
+		}
+		seen[m.suggestion.text] = true
+		p := m.doc.product
+		result.Products = append(result.Products, models.ProductSuggestion{ID: p.ID, Name: p.Name, Price: p.Price, ImageURL: p.ImageURL})
+	}
+
+	names := make([]string, 0, len(categories))
+	for name := range categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result.Categories = append(result.Categories, names[:min(size, len(names))]...)
+
+	var queries []*memoryQuery
+	for _, q := range r.queries {
+		if q.count < minPopularQueryCount {
+			continue
+		}
+		if _, ok := bestSuggestion(q.suggest, prefix, false); ok {
+			queries = append(queries, q)
+		}
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		if queries[i].count != queries[j].count {
+			return queries[i].count > queries[j].count
+		}
+		return queries[i].query < queries[j].query
+	})
+	for _, q := range queries[:min(size, len(queries))] {
+		result.Queries = append(result.Queries, q.query)
+	}
+	return result, nil
+}
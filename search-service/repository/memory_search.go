@@ -0,0 +1,490 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"search-service/models"
+)
+
+// memoryFields: token của các field được tìm, tương ứng analyzer trong ProductIndexDefinition
+type memoryFields struct {
+	name        []string // vi_text: giữ dấu
+	nameFolded  []string // vi_folded
+	category    []string
+	description []string
+	vendorName  []string
+}
+
+// analyzeText ≈ standard tokenizer + lowercase (+ asciifolding)
+func analyzeText(text string, fold bool) []string {
+	if fold {
+		text = FoldDiacritics(text)
+	}
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.Is(unicode.Mn, r)
+	})
+}
+
+func analyzeProduct(p *models.Product) memoryFields {
+	return memoryFields{
+		name:        analyzeText(p.Name, false),
+		nameFolded:  analyzeText(p.Name, true),
+		category:    analyzeText(p.Category, true),
+		description: analyzeText(p.Description, true),
+		vendorName:  analyzeText(p.VendorName, true),
+	}
+}
+
+// completionText: dạng so khớp của completion suggester (custom_analyzer)
+func completionText(text string) string {
+	return strings.Join(analyzeText(text, true), " ")
+}
+
+// maxEdits theo searchFuzziness (AUTO:4,7)
+func maxEdits(term string) int {
+	switch n := len([]rune(term)); {
+	case n < 4:
+		return 0
+	case n < 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance: Levenshtein có tính đổi chỗ hai ký tự liền nhau, như fuzzy_transpositions của Elasticsearch
+func editDistance(a, b []rune) int {
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(a)][len(b)]
+}
+
+// termScore: 1 nếu khớp đúng, 0.5 nếu khớp mờ (prefix_length 1), 0 nếu không khớp
+func termScore(queryTerm string, docTerms []string) float64 {
+	edits := maxEdits(queryTerm)
+	q := []rune(queryTerm)
+	best := 0.0
+	for _, term := range docTerms {
+		if term == queryTerm {
+			return 1
+		}
+		t := []rune(term)
+		if edits == 0 || best > 0 || t[0] != q[0] {
+			continue
+		}
+		if diff := len(t) - len(q); diff > edits || -diff > edits {
+			continue
+		}
+		if editDistance(q, t) <= edits {
+			best = 0.5
+		}
+	}
+	return best
+}
+
+// fuzzyPrefixMatch: fuzzy của completion suggester (AUTO, min_length 4, prefix_length 2)
+func fuzzyPrefixMatch(prefix, input string) bool {
+	p, in := []rune(prefix), []rune(input)
+	if len(p) < 4 || len(in) < 2 || p[0] != in[0] || p[1] != in[1] {
+		return false
+	}
+	edits := 1
+	if len(p) > 5 {
+		edits = 2
+	}
+	for n := len(p) - edits; n <= len(p)+edits; n++ {
+		if n > 0 && n <= len(in) && editDistance(p, in[:n]) <= edits {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredMatches theo minimum_should_match "2<75%"
+func requiredMatches(terms int) int {
+	if terms <= 2 {
+		return terms
+	}
+	return terms * 3 / 4
+}
+
+// fieldScore: tổng điểm các từ khớp, 0 nếu số từ khớp chưa đủ minimum_should_match
+func fieldScore(queryTerms, docTerms []string, boost float64) float64 {
+	if len(docTerms) == 0 {
+		return 0
+	}
+	score, matched := 0.0, 0
+	for _, term := range queryTerms {
+		if s := termScore(term, docTerms); s > 0 {
+			score += s
+			matched++
+		}
+	}
+	if matched < requiredMatches(len(queryTerms)) {
+		return 0
+	}
+	return score * boost
+}
+
+// phraseMatch: các từ xuất hiện liền nhau theo thứ tự, cho phép lệch tổng cộng slop vị trí
+func phraseMatch(queryTerms, docTerms []string, slop int) bool {
+	if len(queryTerms) == 0 {
+		return false
+	}
+	for start, term := range docTerms {
+		if term != queryTerms[0] {
+			continue
+		}
+		pos, used, ok := start, 0, true
+		for _, next := range queryTerms[1:] {
+			found := false
+			for gap := 0; gap <= slop-used && pos+1+gap < len(docTerms); gap++ {
+				if docTerms[pos+1+gap] == next {
+					pos, used, found = pos+1+gap, used+gap, true
+					break
+				}
+			}
+			if !found {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// shingles 2-3 từ như filter vi_shingle
+func shingles(terms []string) map[string]bool {
+	out := map[string]bool{}
+	for size := 2; size <= 3; size++ {
+		for i := 0; i+size <= len(terms); i++ {
+			out[strings.Join(terms[i:i+size], " ")] = true
+		}
+	}
+	return out
+}
+
+// textScore mô phỏng textQuery: multi_match best_fields lấy field điểm cao nhất,
+// cộng thêm điểm cụm từ trong tên. 0 là không khớp.
+func textScore(query string, f *memoryFields) float64 {
+	terms, folded := analyzeText(query, false), analyzeText(query, true)
+	if len(folded) == 0 {
+		return 0
+	}
+
+	score := max(
+		fieldScore(terms, f.name, nameBoost),
+		fieldScore(folded, f.nameFolded, nameFoldedBoost),
+		fieldScore(folded, f.category, categoryBoost),
+		fieldScore(folded, f.description, 1),
+		fieldScore(folded, f.vendorName, 1),
+	)
+	if score == 0 {
+		return 0
+	}
+
+	if phraseMatch(folded, f.nameFolded, 1) {
+		score += phraseBoost * float64(len(folded))
+	}
+	nameShingles := shingles(f.nameFolded)
+	for shingle := range shingles(folded) {
+		if nameShingles[shingle] {
+			score += shingleBoost
+		}
+	}
+	return score
+}
+
+// popularityFactor mô phỏng popularityScore (function_score, score_mode sum, max_boost)
+func popularityFactor(p *models.Product) float64 {
+	factor := 1 + soldCountWeight*math.Log1p(float64(max(p.SoldCount, 0)))
+	if p.RatingCount >= minRatingCount {
+		factor += ratingFactor * p.Rating
+	}
+	return min(factor, maxPopularityBoost)
+}
+
+func matchesBaseFilters(p *models.Product, req models.SearchRequest) bool {
+	if !isOnSale(p) {
+		return false
+	}
+	if req.MinPrice != nil && p.Price < *req.MinPrice {
+		return false
+	}
+	if req.MaxPrice != nil && p.Price > *req.MaxPrice {
+		return false
+	}
+	if req.MinRating != nil && p.Rating < *req.MinRating {
+		return false
+	}
+	if req.InStock && p.Quantity <= 0 {
+		return false
+	}
+	if req.VendorID != "" && p.UserID != req.VendorID {
+		return false
+	}
+	return true
+}
+
+func matchesCategories(p *models.Product, req models.SearchRequest) bool {
+	if len(req.Categories) == 0 {
+		return true
+	}
+	for _, category := range req.Categories {
+		if p.Category == category {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAttributes: cùng thuộc tính là OR, khác thuộc tính là AND
+func matchesAttributes(p *models.Product, req models.SearchRequest) bool {
+	for name, values := range req.Attributes {
+		found := false
+		for _, value := range values {
+			for _, facet := range p.AttributeFacets {
+				if facet == name+"="+value {
+					found = true
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+type memoryHit struct {
+	product *models.Product
+	score   float64
+	// giá trị sort chính, cùng thứ tự với sortClause
+	sortValue float64
+}
+
+func sortValue(hit memoryHit, req models.SearchRequest) (float64, bool) {
+	p := hit.product
+	switch req.Sort {
+	case models.SortPriceAsc:
+		return p.Price, true
+	case models.SortPriceDesc:
+		return p.Price, false
+	case models.SortNewest:
+		return float64(p.CreatedAt.UnixMilli()), false
+	case models.SortBestSelling:
+		return float64(p.SoldCount), false
+	case models.SortRating:
+		return p.Rating, false
+	default:
+		if strings.TrimSpace(req.Query) == "" {
+			return float64(p.CreatedAt.UnixMilli()), false
+		}
+		return hit.score, false
+	}
+}
+
+// lessHit so sánh (giá trị sort, id) theo hướng sort, id tăng dần để phân trang ổn định
+func lessHit(aValue float64, aID string, bValue float64, bID string, asc bool) bool {
+	if aValue != bValue {
+		if asc {
+			return aValue < bValue
+		}
+		return aValue > bValue
+	}
+	return aID < bID
+}
+
+// decodeMemoryCursor: cursor của engine nhúng là [giá trị sort, id]
+func decodeMemoryCursor(cursor string) (float64, string, error) {
+	values, err := decodeCursor(cursor)
+	if err != nil || len(values) != 2 {
+		return 0, "", ErrInvalidCursor
+	}
+	value, ok := toFloat(values[0])
+	id, idOK := values[1].(string)
+	if !ok || !idOK {
+		return 0, "", ErrInvalidCursor
+	}
+	return value, id, nil
+}
+
+func (r *memorySearchRepository) Search(ctx context.Context, req models.SearchRequest) (*models.SearchResult, error) {
+	var afterValue float64
+	var afterID string
+	if req.Cursor != "" {
+		var err error
+		if afterValue, afterID, err = decodeMemoryCursor(req.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	query := strings.TrimSpace(req.Query)
+	boostPopularity := query != "" && (req.Sort == "" || req.Sort == models.SortRelevance)
+
+	// matched: khớp query + filter cơ bản, là tập dùng tính facet (như aggs của Elasticsearch)
+	var matched []memoryHit
+	for _, doc := range r.docs {
+		if doc.deleted || !matchesBaseFilters(&doc.product, req) {
+			continue
+		}
+		score := 1.0
+		if query != "" {
+			if score = textScore(query, &doc.fields); score == 0 {
+				continue
+			}
+			if boostPopularity {
+				score *= popularityFactor(&doc.product)
+			}
+		}
+		hit := memoryHit{product: &doc.product, score: score}
+		hit.sortValue, _ = sortValue(hit, req)
+		matched = append(matched, hit)
+	}
+
+	// post_filter: lựa chọn facet chỉ lọc hits
+	var hits []memoryHit
+	for _, hit := range matched {
+		if matchesCategories(hit.product, req) && matchesAttributes(hit.product, req) {
+			hits = append(hits, hit)
+		}
+	}
+	_, asc := sortValue(memoryHit{product: &models.Product{}}, req)
+	sort.Slice(hits, func(i, j int) bool {
+		return lessHit(hits[i].sortValue, hits[i].product.ID, hits[j].sortValue, hits[j].product.ID, asc)
+	})
+
+	result := &models.SearchResult{
+		Total:  int64(len(hits)),
+		From:   req.From,
+		Size:   req.Size,
+		Hits:   []models.Product{},
+		Facets: memoryFacets(matched, req),
+	}
+
+	start := req.From
+	if req.Cursor != "" {
+		result.From = 0
+		start = sort.Search(len(hits), func(i int) bool {
+			return lessHit(afterValue, afterID, hits[i].sortValue, hits[i].product.ID, asc)
+		})
+	}
+	page := hits[min(start, len(hits)):min(start+req.Size, len(hits))]
+	for _, hit := range page {
+		result.Hits = append(result.Hits, *hit.product)
+	}
+	if n := len(page); n == req.Size && n > 0 {
+		last := page[n-1]
+		result.NextCursor = encodeCursor([]interface{}{last.sortValue, last.product.ID})
+	}
+	return result, nil
+}
+
+func termBuckets(counts map[string]int64, size int) []models.FacetBucket {
+	buckets := make([]models.FacetBucket, 0, len(counts))
+	for value, count := range counts {
+		buckets = append(buckets, models.FacetBucket{Value: value, Count: count})
+	}
+	// như terms aggregation: nhiều nhất trước, bằng nhau thì theo key
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Count != buckets[j].Count {
+			return buckets[i].Count > buckets[j].Count
+		}
+		return buckets[i].Value < buckets[j].Value
+	})
+	if len(buckets) > size {
+		buckets = buckets[:size]
+	}
+	return buckets
+}
+
+// memoryFacets: facet của một nhóm bỏ qua lựa chọn của chính nhóm đó, giống buildSearchBody
+func memoryFacets(matched []memoryHit, req models.SearchRequest) models.SearchFacets {
+	facets := models.SearchFacets{
+		Categories:     []models.FacetBucket{},
+		PriceHistogram: []models.RangeBucket{},
+		Ratings:        []models.RangeBucket{},
+		Attributes:     map[string][]models.FacetBucket{},
+	}
+
+	categories := map[string]int64{}
+	attributes := map[string]int64{}
+	prices := map[float64]int64{}
+	ratings := make([]int64, len(ratingRanges))
+	for _, hit := range matched {
+		p := hit.product
+		inCategory, inAttributes := matchesCategories(p, req), matchesAttributes(p, req)
+		if inAttributes {
+			categories[p.Category]++
+		}
+		if inCategory {
+			for _, facet := range p.AttributeFacets {
+				attributes[facet]++
+			}
+		}
+		if !inCategory || !inAttributes {
+			continue
+		}
+		if req.PriceInterval > 0 {
+			prices[math.Floor(p.Price/req.PriceInterval)*req.PriceInterval]++
+		}
+		for i, from := range ratingRanges {
+			if p.Rating >= from {
+				ratings[i]++
+			}
+		}
+	}
+
+	facets.Categories = termBuckets(categories, 50)
+	for _, b := range termBuckets(attributes, 200) {
+		name, value, ok := strings.Cut(b.Value, "=")
+		if !ok {
+			continue
+		}
+		facets.Attributes[name] = append(facets.Attributes[name], models.FacetBucket{Value: value, Count: b.Count})
+	}
+
+	keys := make([]float64, 0, len(prices))
+	for key := range prices {
+		keys = append(keys, key)
+	}
+	sort.Float64s(keys)
+	for _, key := range keys {
+		to := key + req.PriceInterval
+		facets.PriceHistogram = append(facets.PriceHistogram, models.RangeBucket{From: key, To: &to, Count: prices[key]})
+	}
+
+	// range aggregation trả bucket theo from tăng dần
+	for i := len(ratingRanges) - 1; i >= 0; i-- {
+		facets.Ratings = append(facets.Ratings, models.RangeBucket{From: ratingRanges[i], Count: ratings[i]})
+	}
+	return facets
+}
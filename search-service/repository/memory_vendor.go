@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"search-service/models"
+)
+
+type memoryVendor struct {
+	vendor      models.Vendor
+	version     int64
+	name        []string
+	description []string
+}
+
+// memoryVendorRepository: VendorRepository của engine nhúng, cùng ngữ nghĩa với vendorRepository
+type memoryVendorRepository struct {
+	mu      sync.RWMutex
+	vendors map[string]*memoryVendor
+}
+
+func NewMemoryVendorRepository() VendorRepository {
+	return &memoryVendorRepository{vendors: make(map[string]*memoryVendor)}
+}
+
+func (r *memoryVendorRepository) BulkApply(ctx context.Context, ops []models.IndexOp) ([]models.BulkItemResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := make([]models.BulkItemResult, len(ops))
+	for i, op := range ops {
+		if op.Action != models.IndexOpIndex || op.Vendor == nil {
+			results[i] = models.BulkItemResult{Outcome: models.BulkItemFailed, Error: fmt.Sprintf("unsupported vendor op %s", op.Action)}
+			continue
+		}
+		if existing := r.vendors[op.ID]; existing != nil && existing.version > op.Version {
+			results[i] = models.BulkItemResult{Outcome: models.BulkItemStale}
+			continue
+		}
+		r.vendors[op.ID] = &memoryVendor{
+			vendor:      *op.Vendor,
+			version:     op.Version,
+			name:        analyzeText(op.Vendor.DisplayName, true),
+			description: analyzeText(op.Vendor.Description, true),
+		}
+		results[i] = models.BulkItemResult{Outcome: models.BulkItemOK}
+	}
+	return results, nil
+}
+
+func (r *memoryVendorRepository) Get(ctx context.Context, id string) (*models.Vendor, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	v, ok := r.vendors[id]
+	if !ok {
+		return nil, ErrVendorNotFound
+	}
+	vendor := v.vendor
+	return &vendor, nil
+}
+
+func (r *memoryVendorRepository) Search(ctx context.Context, query string, size int) ([]models.Vendor, error) {
+	terms := analyzeText(query, true)
+	if len(terms) == 0 {
+		return []models.Vendor{}, nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type vendorHit struct {
+		vendor models.Vendor
+		score  float64
+	}
+	var hits []vendorHit
+	for _, v := range r.vendors {
+		score := max(fieldScore(terms, v.name, vendorNameBoost), fieldScore(terms, v.description, 1))
+		if score > 0 {
+			hits = append(hits, vendorHit{vendor: v.vendor, score: score})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return hits[i].vendor.ID < hits[j].vendor.ID
+	})
+
+	vendors := make([]models.Vendor, 0, min(size, len(hits)))
+	for _, hit := range hits[:min(size, len(hits))] {
+		vendors = append(vendors, hit.vendor)
+	}
+	return vendors, nil
+}
+
+// VendorStats: cùng công thức với aggregation weighted_avg của Elasticsearch
+func (r *memorySearchRepository) VendorStats(ctx context.Context, vendorID string) (*models.VendorStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := &models.VendorStats{}
+	ratingSum := 0.0
+	for _, doc := range r.docs {
+		p := &doc.product
+		if doc.deleted || p.UserID != vendorID || !isOnSale(p) {
+			continue
+		}
+		stats.ProductCount++
+		stats.TotalSales += int64(p.SoldCount)
+		stats.RatingCount += int64(p.RatingCount)
+		ratingSum += p.Rating * float64(p.RatingCount)
+	}
+	if stats.RatingCount > 0 {
+		stats.Rating = roundRating(ratingSum / float64(stats.RatingCount))
+	}
+	return stats, nil
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"strings"
+
+	"search-service/models"
+)
+
+// số event gần nhất được nhớ trên mỗi document để bỏ qua event Kafka gửi lại
+const appliedEventsLimit = 20
+
+// dedupeScript bọc script cập nhật: event đã áp dụng (cùng key) thì noop
+func dedupeScript(body string) string {
+	return `if (ctx._source.applied_events == null) { ctx._source.applied_events = new ArrayList(); }
+if (ctx._source.applied_events.contains(params.event_key)) {
+  ctx.op = 'noop';
+} else {
+  ctx._source.applied_events.add(params.event_key);
+  if (ctx._source.applied_events.size() > params.applied_limit) { ctx._source.applied_events.remove(0); }
+` + body + `
+}`
+}
+
+// gộp thêm một nhóm đánh giá mới vào rating trung bình
+const ratingScript = `
+  long oldCount = ctx._source.rating_count == null ? 0 : ((Number) ctx._source.rating_count).longValue();
+  double oldRating = ctx._source.rating == null ? 0 : ((Number) ctx._source.rating).doubleValue();
+  long total = oldCount + params.count;
+  if (total > 0) { ctx._source.rating = (oldRating * oldCount + params.sum) / total; }
+  ctx._source.rating_count = total;`
+
+// productDeltaScript chuyển ProductStatsDelta thành painless script cho _bulk update
+func productDeltaScript(delta *models.ProductStatsDelta) map[string]interface{} {
+	var body strings.Builder
+	if delta.RatingCount > 0 {
+		body.WriteString(ratingScript)
+	}
+	return map[string]interface{}{
+		"lang":   "painless",
+		"source": dedupeScript(body.String()),
+		"params": map[string]interface{}{
+			"event_key":     delta.EventKey,
+			"applied_limit": appliedEventsLimit,
+			"count":         delta.RatingCount,
+			"sum":           delta.RatingSum,
+		},
+	}
+}
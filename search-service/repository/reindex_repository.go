@@ -0,0 +1,435 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+
+	"search-service/database"
+	logger "search-service/log"
+	"search-service/models"
+)
+
+var (
+	// replica khác vừa ghi trạng thái reindex trước
+	ErrReindexStateConflict = errors.New("reindex state changed concurrently")
+	ErrIndexNotFound        = errors.New("index not found")
+)
+
+const (
+	reindexStateID = "current"
+	// writer đọc lại trạng thái reindex sau ngần này thời gian
+	ReindexStateTTL = 5 * time.Second
+	// reindex không cập nhật tiến độ quá lâu coi như tiến trình đã chết
+	ReindexStaleAfter = 2 * time.Minute
+)
+
+func productAlias() string {
+	return os.Getenv("ELASTICSEARCH_INDEX")
+}
+
+func reindexStateIndex() string {
+	return productAlias() + "-reindex"
+}
+
+// bootstrapIndexName: index tạo tự động lần đầu chạy, tên cố định để nhiều replica không tạo trùng
+func bootstrapIndexName(alias string) string {
+	return fmt.Sprintf("%s_v%d", alias, ProductIndexVersion)
+}
+
+func versionedIndexName(alias string, now time.Time) string {
+	return fmt.Sprintf("%s_v%d_%s", alias, ProductIndexVersion, now.UTC().Format("20060102150405"))
+}
+
+// ReindexRepository quản lý các index phiên bản phía sau alias ELASTICSEARCH_INDEX
+type ReindexRepository interface {
+	Alias() string
+	CreateIndex(ctx context.Context) (string, error)
+	BulkLoad(ctx context.Context, index string, products []*models.Product) (*models.BulkResult, error)
+	FinishIndex(ctx context.Context, index string) error
+	CountDocuments(ctx context.Context, index string) (int64, error)
+	// AliasIndices trả về các index alias đang trỏ tới, legacy = true khi tên alias đang là một index thật
+	AliasIndices(ctx context.Context) (indices []string, legacy bool, err error)
+	SwapAlias(ctx context.Context, to string, from []string, legacy bool) error
+	IndexExists(ctx context.Context, index string) (bool, error)
+	ListIndices(ctx context.Context) ([]string, error)
+	DeleteIndex(ctx context.Context, index string) error
+
+	GetState(ctx context.Context) (*models.ReindexState, error)
+	// SaveState ghi đè có điều kiện theo seq_no của lần đọc trước, state mới (SeqNo = 0) thì chỉ tạo khi chưa có
+	SaveState(ctx context.Context, state *models.ReindexState) error
+}
+
+type reindexRepository struct{}
+
+func NewReindexRepository() ReindexRepository {
+	return &reindexRepository{}
+}
+
+func (r *reindexRepository) Alias() string {
+	return productAlias()
+}
+
+func (r *reindexRepository) CreateIndex(ctx context.Context) (string, error) {
+	name := versionedIndexName(productAlias(), time.Now())
+	if err := createProductIndex(ctx, name, ""); err != nil {
+		return "", err
+	}
+
+	// tắt refresh trong lúc nạp dữ liệu, FinishIndex bật lại
+	body := strings.NewReader(`{"index":{"refresh_interval":"-1"}}`)
+	res, err := database.ES.Indices.PutSettings(body,
+		database.ES.Indices.PutSettings.WithIndex(name),
+		database.ES.Indices.PutSettings.WithContext(ctx),
+	)
+	if err != nil {
+		return name, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return name, fmt.Errorf("failed to update index settings: %s", res.String())
+	}
+	return name, nil
+}
+
+// BulkLoad nạp sản phẩm export vào index mới, version theo updated_at nên bản Kafka mới hơn
+// đã ghi song song vào index này được giữ lại (tính là skipped)
+func (r *reindexRepository) BulkLoad(ctx context.Context, index string, products []*models.Product) (*models.BulkResult, error) {
+	ops := make([]models.IndexOp, 0, len(products))
+	for _, product := range products {
+		ops = append(ops, models.IndexOp{Action: models.IndexOpIndex, ID: product.ID, Version: ProductVersion(product), Product: product})
+	}
+	results, err := bulkWrite(ctx, []string{index}, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.BulkResult{}
+	for i, item := range results {
+		switch item.Outcome {
+		case models.BulkItemOK:
+			result.Indexed++
+		case models.BulkItemStale:
+			result.Skipped++
+		default:
+			logger.Error("Bulk item failed", logger.Str("id", ops[i].ID), logger.Str("error", item.Error))
+			result.FailedIDs = append(result.FailedIDs, ops[i].ID)
+		}
+	}
+	return result, nil
+}
+
+func (r *reindexRepository) FinishIndex(ctx context.Context, index string) error {
+	body := strings.NewReader(`{"index":{"refresh_interval":null}}`)
+	res, err := database.ES.Indices.PutSettings(body,
+		database.ES.Indices.PutSettings.WithIndex(index),
+		database.ES.Indices.PutSettings.WithContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("failed to update index settings: %s", res.String())
+	}
+
+	refreshRes, err := database.ES.Indices.Refresh(
+		database.ES.Indices.Refresh.WithIndex(index),
+		database.ES.Indices.Refresh.WithContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+	defer refreshRes.Body.Close()
+	if refreshRes.IsError() {
+		return fmt.Errorf("failed to refresh index: %s", refreshRes.String())
+	}
+	return nil
+}
+
+func (r *reindexRepository) CountDocuments(ctx context.Context, index string) (int64, error) {
+	res, err := database.ES.Count(
+		database.ES.Count.WithIndex(index),
+		database.ES.Count.WithContext(ctx),
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return 0, ErrIndexNotFound
+	}
+	if res.IsError() {
+		return 0, fmt.Errorf("failed to count documents: %s", res.String())
+	}
+
+	var count struct {
+		Count int64 `json:"count"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&count); err != nil {
+		return 0, err
+	}
+	return count.Count, nil
+}
+
+func (r *reindexRepository) AliasIndices(ctx context.Context) ([]string, bool, error) {
+	alias := productAlias()
+	res, err := database.ES.Indices.GetAlias(
+		database.ES.Indices.GetAlias.WithName(alias),
+		database.ES.Indices.GetAlias.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		exists, err := r.IndexExists(ctx, alias)
+		return nil, exists, err
+	}
+	if res.IsError() {
+		return nil, false, fmt.Errorf("failed to get alias: %s", res.String())
+	}
+
+	var aliases map[string]json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&aliases); err != nil {
+		return nil, false, err
+	}
+	indices := make([]string, 0, len(aliases))
+	for index := range aliases {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+	return indices, false, nil
+}
+
+// SwapAlias chuyển alias sang index mới trong một lệnh _aliases nên search không lúc nào thấy alias rỗng.
+// Index cũ tạo bằng tên alias bị xoá cùng lệnh vì alias không được trùng tên index.
+func (r *reindexRepository) SwapAlias(ctx context.Context, to string, from []string, legacy bool) error {
+	alias := productAlias()
+	actions := []interface{}{
+		map[string]interface{}{"add": map[string]interface{}{"index": to, "alias": alias}},
+	}
+	for _, index := range from {
+		if index != to {
+			actions = append(actions, map[string]interface{}{"remove": map[string]interface{}{"index": index, "alias": alias}})
+		}
+	}
+	if legacy {
+		actions = append(actions, map[string]interface{}{"remove_index": map[string]interface{}{"index": alias}})
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(map[string]interface{}{"actions": actions}); err != nil {
+		return err
+	}
+	res, err := database.ES.Indices.UpdateAliases(&buf, database.ES.Indices.UpdateAliases.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("failed to swap alias: %s", res.String())
+	}
+	return nil
+}
+
+func (r *reindexRepository) IndexExists(ctx context.Context, index string) (bool, error) {
+	res, err := database.ES.Indices.Exists([]string{index}, database.ES.Indices.Exists.WithContext(ctx))
+	if err != nil {
+		return false, err
+	}
+	res.Body.Close()
+	return res.StatusCode == 200, nil
+}
+
+// ListIndices: các index phiên bản của alias, cũ trước mới sau
+func (r *reindexRepository) ListIndices(ctx context.Context) ([]string, error) {
+	res, err := database.ES.Indices.Get([]string{productAlias() + "_v*"},
+		database.ES.Indices.Get.WithContext(ctx),
+		database.ES.Indices.Get.WithFilterPath("*.settings.index.creation_date"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to list indices: %s", res.String())
+	}
+
+	var body map[string]struct {
+		Settings struct {
+			Index struct {
+				CreationDate string `json:"creation_date"`
+			} `json:"index"`
+		} `json:"settings"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	indices := make([]string, 0, len(body))
+	for index := range body {
+		indices = append(indices, index)
+	}
+	sort.Slice(indices, func(i, j int) bool {
+		a, b := body[indices[i]].Settings.Index.CreationDate, body[indices[j]].Settings.Index.CreationDate
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return a < b
+	})
+	return indices, nil
+}
+
+func (r *reindexRepository) DeleteIndex(ctx context.Context, index string) error {
+	res, err := database.ES.Indices.Delete([]string{index}, database.ES.Indices.Delete.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() && res.StatusCode != 404 {
+		return fmt.Errorf("failed to delete index: %s", res.String())
+	}
+	return nil
+}
+
+func (r *reindexRepository) GetState(ctx context.Context) (*models.ReindexState, error) {
+	res, err := database.ES.Get(reindexStateIndex(), reindexStateID, database.ES.Get.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return nil, nil
+	}
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to get reindex state: %s", res.String())
+	}
+
+	var doc struct {
+		SeqNo       int64               `json:"_seq_no"`
+		PrimaryTerm int64               `json:"_primary_term"`
+		Source      models.ReindexState `json:"_source"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&doc); err != nil {
+		return nil, err
+	}
+	doc.Source.SeqNo = doc.SeqNo
+	doc.Source.PrimaryTerm = doc.PrimaryTerm
+	return &doc.Source, nil
+}
+
+func (r *reindexRepository) SaveState(ctx context.Context, state *models.ReindexState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	opts := []func(*esapi.IndexRequest){
+		database.ES.Index.WithContext(ctx),
+		database.ES.Index.WithDocumentID(reindexStateID),
+		database.ES.Index.WithRefresh("true"),
+	}
+	if state.PrimaryTerm == 0 {
+		opts = append(opts, database.ES.Index.WithOpType("create"))
+	} else {
+		opts = append(opts,
+			database.ES.Index.WithIfSeqNo(int(state.SeqNo)),
+			database.ES.Index.WithIfPrimaryTerm(int(state.PrimaryTerm)),
+		)
+	}
+
+	res, err := database.ES.Index(reindexStateIndex(), bytes.NewReader(data), opts...)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 409 {
+		return ErrReindexStateConflict
+	}
+	if res.IsError() {
+		return fmt.Errorf("failed to save reindex state: %s", res.String())
+	}
+
+	var doc struct {
+		SeqNo       int64 `json:"_seq_no"`
+		PrimaryTerm int64 `json:"_primary_term"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&doc); err != nil {
+		return err
+	}
+	state.SeqNo = doc.SeqNo
+	state.PrimaryTerm = doc.PrimaryTerm
+
+	rememberReindexTarget(state)
+	return nil
+}
+
+// IsReindexActive: reindex đang chạy và vẫn còn cập nhật tiến độ
+func IsReindexActive(state *models.ReindexState, now time.Time) bool {
+	return state != nil && state.Status == models.ReindexRunning && now.Sub(state.UpdatedAt) < ReindexStaleAfter
+}
+
+var (
+	reindexTargetMu       sync.Mutex
+	reindexTarget         string
+	reindexTargetLoadedAt time.Time
+)
+
+func rememberReindexTarget(state *models.ReindexState) {
+	reindexTargetMu.Lock()
+	defer reindexTargetMu.Unlock()
+	reindexTarget = ""
+	switch {
+	case IsReindexActive(state, time.Now()):
+		reindexTarget = state.NewIndex
+	case canRollback(state):
+		// alias đã sang index mới, vẫn ghi vào index cũ để rollback không trả dữ liệu cũ
+		reindexTarget = state.PreviousIndex
+	}
+	reindexTargetLoadedAt = time.Now()
+}
+
+func canRollback(state *models.ReindexState) bool {
+	return state != nil && state.Status == models.ReindexCompleted && state.PreviousIndex != ""
+}
+
+// dualWriteIndex: index mới đang được dựng (hoặc index cũ còn giữ để rollback), thay đổi
+// từ Kafka phải ghi vào cả index này. Trạng thái được cache ReindexStateTTL.
+func dualWriteIndex(ctx context.Context) string {
+	reindexTargetMu.Lock()
+	if time.Since(reindexTargetLoadedAt) < ReindexStateTTL {
+		target := reindexTarget
+		reindexTargetMu.Unlock()
+		return target
+	}
+	reindexTargetMu.Unlock()
+
+	state, err := (&reindexRepository{}).GetState(ctx)
+	if err != nil {
+		// index trạng thái chưa có hoặc ES lỗi: chỉ ghi vào alias, lần sau đọc lại
+		logger.Err("Failed to load reindex state", err)
+		state = nil
+	}
+	// index cũ bị xoá tay thì không ghi nữa, tránh ES tự tạo lại index không có mapping
+	if canRollback(state) {
+		exists, err := (&reindexRepository{}).IndexExists(ctx, state.PreviousIndex)
+		if err != nil || !exists {
+			state = nil
+		}
+	}
+	rememberReindexTarget(state)
+
+	reindexTargetMu.Lock()
+	defer reindexTargetMu.Unlock()
+	return reindexTarget
+}
@@ -0,0 +1,404 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"search-service/models"
+)
+
+var ErrInvalidCursor = errors.New("invalid search cursor")
+
+// buckets cố định cho facet rating: từ N sao trở lên
+var ratingRanges = []float64{4, 3, 2, 1}
+
+// BuildAttributeFacets chuyển attributes của sản phẩm thành "tên=giá trị" để filter / facet,
+// chỉ lấy giá trị đơn hoặc danh sách giá trị đơn
+func BuildAttributeFacets(attributes map[string]interface{}) []string {
+	var facets []string
+	for name, value := range attributes {
+		switch v := value.(type) {
+		case []interface{}:
+			for _, item := range v {
+				if s, ok := facetValue(item); ok {
+					facets = append(facets, name+"="+s)
+				}
+			}
+		default:
+			if s, ok := facetValue(v); ok {
+				facets = append(facets, name+"="+s)
+			}
+		}
+	}
+	sort.Strings(facets)
+	return facets
+}
+
+func facetValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		v = strings.TrimSpace(v)
+		return v, v != ""
+	case bool, float64, float32, int, int64, json.Number:
+		return fmt.Sprint(v), true
+	default:
+		return "", false
+	}
+}
+
+func encodeCursor(values []interface{}) string {
+	if len(values) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(cursor string) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var values []interface{}
+	if err := json.Unmarshal(data, &values); err != nil || len(values) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return values, nil
+}
+
+// textQuery: khớp mờ trên tên / category / mô tả, cộng điểm khi khớp nguyên cụm trong tên
+func textQuery(query string) map[string]interface{} {
+	if strings.TrimSpace(query) == "" {
+		return map[string]interface{}{"match_all": map[string]interface{}{}}
+	}
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"must": []interface{}{
+				map[string]interface{}{
+					"multi_match": map[string]interface{}{
+						"query": query,
+						"fields": []string{
+							fmt.Sprintf("name^%d", nameBoost),
+							fmt.Sprintf("name.folded^%d", nameFoldedBoost),
+							fmt.Sprintf("category.text^%d", categoryBoost),
+							"description",
+							"vendor_name",
+						},
+						"type":                 "best_fields",
+						"fuzziness":            searchFuzziness,
+						"prefix_length":        1,
+						"max_expansions":       50,
+						"minimum_should_match": "2<75%",
+					},
+				},
+			},
+			"should": []interface{}{
+				map[string]interface{}{
+					"match_phrase": map[string]interface{}{
+						"name.folded": map[string]interface{}{"query": query, "slop": 1, "boost": phraseBoost},
+					},
+				},
+				map[string]interface{}{
+					"match": map[string]interface{}{
+						"name.shingle": map[string]interface{}{"query": query, "boost": shingleBoost},
+					},
+				},
+			},
+		},
+	}
+}
+
+// popularityScore trộn điểm text với lượt bán và rating, sản phẩm bán chạy / được đánh giá tốt lên trên
+// khi độ khớp gần bằng nhau
+func popularityScore(query map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"function_score": map[string]interface{}{
+			"query": query,
+			"functions": []interface{}{
+				map[string]interface{}{"weight": 1},
+				map[string]interface{}{
+					"field_value_factor": map[string]interface{}{
+						"field": "sold_count", "modifier": "log1p", "missing": 0,
+					},
+					"weight": soldCountWeight,
+				},
+				map[string]interface{}{
+					"filter": map[string]interface{}{
+						"range": map[string]interface{}{"rating_count": map[string]interface{}{"gte": minRatingCount}},
+					},
+					"field_value_factor": map[string]interface{}{
+						"field": "rating", "factor": ratingFactor, "missing": 0,
+					},
+				},
+			},
+			"score_mode": "sum",
+			"boost_mode": "multiply",
+			"max_boost":  maxPopularityBoost,
+		},
+	}
+}
+
+// sortClause luôn kết thúc bằng id để search_after phân trang ổn định
+func sortClause(req models.SearchRequest) []interface{} {
+	field := func(name, order string) map[string]interface{} {
+		return map[string]interface{}{name: map[string]interface{}{"order": order}}
+	}
+
+	var clauses []interface{}
+	switch req.Sort {
+	case models.SortPriceAsc:
+		clauses = append(clauses, field("price", "asc"))
+	case models.SortPriceDesc:
+		clauses = append(clauses, field("price", "desc"))
+	case models.SortNewest:
+		clauses = append(clauses, field("created_at", "desc"))
+	case models.SortBestSelling:
+		clauses = append(clauses, field("sold_count", "desc"))
+	case models.SortRating:
+		clauses = append(clauses, field("rating", "desc"))
+	default:
+		if strings.TrimSpace(req.Query) == "" {
+			// không có từ khoá thì điểm như nhau, xếp mới nhất trước
+			clauses = append(clauses, field("created_at", "desc"))
+		} else {
+			clauses = append(clauses, field("_score", "desc"))
+		}
+	}
+	return append(clauses, field("id", "asc"))
+}
+
+// selectionFilters: filter do user chọn từ facet, đặt ở post_filter để facet vẫn thấy các lựa chọn khác
+func selectionFilters(req models.SearchRequest) (category []interface{}, attributes []interface{}) {
+	if len(req.Categories) > 0 {
+		category = append(category, map[string]interface{}{
+			"terms": map[string]interface{}{"category": req.Categories},
+		})
+	}
+
+	names := make([]string, 0, len(req.Attributes))
+	for name := range req.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values := make([]string, 0, len(req.Attributes[name]))
+		for _, value := range req.Attributes[name] {
+			values = append(values, name+"="+value)
+		}
+		attributes = append(attributes, map[string]interface{}{
+			"terms": map[string]interface{}{"attribute_facets": values},
+		})
+	}
+	return category, attributes
+}
+
+// onSaleFilter: ẩn sản phẩm không ở trạng thái đang bán, document cũ chưa có status vẫn hiện
+func onSaleFilter() map[string]interface{} {
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"should": []interface{}{
+				map[string]interface{}{"term": map[string]interface{}{"status": models.ProductStatusOnSale}},
+				map[string]interface{}{"bool": map[string]interface{}{
+					"must_not": map[string]interface{}{"exists": map[string]interface{}{"field": "status"}},
+				}},
+			},
+			"minimum_should_match": 1,
+		},
+	}
+}
+
+func baseFilters(req models.SearchRequest) []interface{} {
+	filters := []interface{}{onSaleFilter()}
+
+	if req.MinPrice != nil || req.MaxPrice != nil {
+		price := map[string]interface{}{}
+		if req.MinPrice != nil {
+			price["gte"] = *req.MinPrice
+		}
+		if req.MaxPrice != nil {
+			price["lte"] = *req.MaxPrice
+		}
+		filters = append(filters, map[string]interface{}{"range": map[string]interface{}{"price": price}})
+	}
+	if req.MinRating != nil {
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{"rating": map[string]interface{}{"gte": *req.MinRating}},
+		})
+	}
+	if req.InStock {
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{"quantity": map[string]interface{}{"gt": 0}},
+		})
+	}
+	if req.VendorID != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"user_id": req.VendorID},
+		})
+	}
+	return filters
+}
+
+func filterAgg(filters []interface{}, values map[string]interface{}) map[string]interface{} {
+	filter := map[string]interface{}{"match_all": map[string]interface{}{}}
+	if len(filters) > 0 {
+		filter = map[string]interface{}{"bool": map[string]interface{}{"filter": filters}}
+	}
+	return map[string]interface{}{
+		"filter": filter,
+		"aggs":   map[string]interface{}{"values": values},
+	}
+}
+
+func buildSearchBody(req models.SearchRequest) (map[string]interface{}, error) {
+	categoryFilters, attributeFilters := selectionFilters(req)
+	selected := append(append([]interface{}{}, categoryFilters...), attributeFilters...)
+
+	ratings := make([]interface{}, 0, len(ratingRanges))
+	for _, from := range ratingRanges {
+		ratings = append(ratings, map[string]interface{}{"from": from})
+	}
+
+	query := map[string]interface{}{
+		"bool": map[string]interface{}{
+			"must":   []interface{}{textQuery(req.Query)},
+			"filter": baseFilters(req),
+		},
+	}
+	if strings.TrimSpace(req.Query) != "" && (req.Sort == "" || req.Sort == models.SortRelevance) {
+		query = popularityScore(query)
+	}
+
+	body := map[string]interface{}{
+		"query":            query,
+		"size":             req.Size,
+		"sort":             sortClause(req),
+		"track_total_hits": true,
+		"aggs": map[string]interface{}{
+			// facet của một nhóm bỏ qua lựa chọn của chính nhóm đó
+			"categories": filterAgg(attributeFilters, map[string]interface{}{
+				"terms": map[string]interface{}{"field": "category", "size": 50},
+			}),
+			"attributes": filterAgg(categoryFilters, map[string]interface{}{
+				"terms": map[string]interface{}{"field": "attribute_facets", "size": 200},
+			}),
+			"price": filterAgg(selected, map[string]interface{}{
+				"histogram": map[string]interface{}{"field": "price", "interval": req.PriceInterval, "min_doc_count": 1},
+			}),
+			"ratings": filterAgg(selected, map[string]interface{}{
+				"range": map[string]interface{}{"field": "rating", "ranges": ratings},
+			}),
+		},
+	}
+	if len(selected) > 0 {
+		body["post_filter"] = map[string]interface{}{"bool": map[string]interface{}{"filter": selected}}
+	}
+
+	if req.Cursor != "" {
+		after, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		body["search_after"] = after
+	} else {
+		body["from"] = req.From
+	}
+	return body, nil
+}
+
+type esBucket struct {
+	Key      interface{} `json:"key"`
+	From     *float64    `json:"from"`
+	To       *float64    `json:"to"`
+	DocCount int64       `json:"doc_count"`
+}
+
+type esFilteredAgg struct {
+	Values struct {
+		Buckets []esBucket `json:"buckets"`
+	} `json:"values"`
+}
+
+type esSearchResponse struct {
+	Hits struct {
+		Total struct {
+			Value int64 `json:"value"`
+		} `json:"total"`
+		Hits []struct {
+			Source models.Product `json:"_source"`
+			Sort   []interface{}  `json:"sort"`
+		} `json:"hits"`
+	} `json:"hits"`
+	Aggregations struct {
+		Categories esFilteredAgg `json:"categories"`
+		Attributes esFilteredAgg `json:"attributes"`
+		Price      esFilteredAgg `json:"price"`
+		Ratings    esFilteredAgg `json:"ratings"`
+	} `json:"aggregations"`
+}
+
+func (resp *esSearchResponse) toResult(req models.SearchRequest) *models.SearchResult {
+	result := &models.SearchResult{
+		Total: resp.Hits.Total.Value,
+		From:  req.From,
+		Size:  req.Size,
+		Hits:  make([]models.Product, 0, len(resp.Hits.Hits)),
+		Facets: models.SearchFacets{
+			Categories:     []models.FacetBucket{},
+			PriceHistogram: []models.RangeBucket{},
+			Ratings:        []models.RangeBucket{},
+			Attributes:     map[string][]models.FacetBucket{},
+		},
+	}
+	if req.Cursor != "" {
+		result.From = 0
+	}
+
+	for _, hit := range resp.Hits.Hits {
+		result.Hits = append(result.Hits, hit.Source)
+	}
+	if n := len(resp.Hits.Hits); n == req.Size && n > 0 {
+		result.NextCursor = encodeCursor(resp.Hits.Hits[n-1].Sort)
+	}
+
+	for _, b := range resp.Aggregations.Categories.Values.Buckets {
+		result.Facets.Categories = append(result.Facets.Categories, models.FacetBucket{Value: fmt.Sprint(b.Key), Count: b.DocCount})
+	}
+	for _, b := range resp.Aggregations.Attributes.Values.Buckets {
+		name, value, ok := strings.Cut(fmt.Sprint(b.Key), "=")
+		if !ok {
+			continue
+		}
+		result.Facets.Attributes[name] = append(result.Facets.Attributes[name], models.FacetBucket{Value: value, Count: b.DocCount})
+	}
+	for _, b := range resp.Aggregations.Price.Values.Buckets {
+		from, _ := toFloat(b.Key)
+		to := from + req.PriceInterval
+		result.Facets.PriceHistogram = append(result.Facets.PriceHistogram, models.RangeBucket{From: from, To: &to, Count: b.DocCount})
+	}
+	for _, b := range resp.Aggregations.Ratings.Values.Buckets {
+		if b.From == nil {
+			continue
+		}
+		result.Facets.Ratings = append(result.Facets.Ratings, models.RangeBucket{From: *b.From, Count: b.DocCount})
+	}
+	return result
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
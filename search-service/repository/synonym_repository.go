@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"search-service/database"
+	"search-service/models"
+)
+
+var ErrSynonymRuleNotFound = errors.New("synonym rule not found")
+
+// maxSynonymRules: giới hạn số rule của một synonym set trong Elasticsearch
+const maxSynonymRules = 10000
+
+// SynonymRepository quản lý synonym set mà search analyzer của index sản phẩm tham chiếu.
+// Elasticsearch tự reload search analyzer sau mỗi lần sửa set.
+type SynonymRepository interface {
+	List(ctx context.Context) ([]models.SynonymRule, error)
+	Replace(ctx context.Context, rules []models.SynonymRule) error
+	PutRule(ctx context.Context, rule models.SynonymRule) error
+	DeleteRule(ctx context.Context, id string) error
+}
+
+type synonymRepository struct{}
+
+func NewSynonymRepository() SynonymRepository {
+	return &synonymRepository{}
+}
+
+// ensureSynonymsSet tạo set rỗng để index tham chiếu được ngay cả khi admin chưa thêm rule nào
+func ensureSynonymsSet(ctx context.Context) error {
+	res, err := database.ES.SynonymsGetSynonym(synonymsSetName(),
+		database.ES.SynonymsGetSynonym.WithContext(ctx),
+		database.ES.SynonymsGetSynonym.WithSize(1),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to check synonyms set: %w", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != 404 {
+		return nil
+	}
+	return putSynonymsSet(ctx, []models.SynonymRule{})
+}
+
+func putSynonymsSet(ctx context.Context, rules []models.SynonymRule) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(map[string]interface{}{"synonyms_set": rules}); err != nil {
+		return err
+	}
+	res, err := database.ES.SynonymsPutSynonym(synonymsSetName(), &buf,
+		database.ES.SynonymsPutSynonym.WithContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("failed to update synonyms set: %s", res.String())
+	}
+	return nil
+}
+
+func (r *synonymRepository) List(ctx context.Context) ([]models.SynonymRule, error) {
+	res, err := database.ES.SynonymsGetSynonym(synonymsSetName(),
+		database.ES.SynonymsGetSynonym.WithContext(ctx),
+		database.ES.SynonymsGetSynonym.WithSize(maxSynonymRules),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return []models.SynonymRule{}, nil
+	}
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to get synonyms set: %s", res.String())
+	}
+
+	var body struct {
+		SynonymsSet []models.SynonymRule `json:"synonyms_set"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	if body.SynonymsSet == nil {
+		body.SynonymsSet = []models.SynonymRule{}
+	}
+	return body.SynonymsSet, nil
+}
+
+func (r *synonymRepository) Replace(ctx context.Context, rules []models.SynonymRule) error {
+	return putSynonymsSet(ctx, rules)
+}
+
+func (r *synonymRepository) PutRule(ctx context.Context, rule models.SynonymRule) error {
+	if err := ensureSynonymsSet(ctx); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(map[string]interface{}{"synonyms": rule.Synonyms}); err != nil {
+		return err
+	}
+	res, err := database.ES.SynonymsPutSynonymRule(&buf, rule.ID, synonymsSetName(),
+		database.ES.SynonymsPutSynonymRule.WithContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("failed to put synonym rule: %s", res.String())
+	}
+	return nil
+}
+
+func (r *synonymRepository) DeleteRule(ctx context.Context, id string) error {
+	res, err := database.ES.SynonymsDeleteSynonymRule(id, synonymsSetName(),
+		database.ES.SynonymsDeleteSynonymRule.WithContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return ErrSynonymRuleNotFound
+	}
+	if res.IsError() {
+		return fmt.Errorf("failed to delete synonym rule: %s", res.String())
+	}
+	return nil
+}
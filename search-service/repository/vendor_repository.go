@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"search-service/database"
+	"search-service/models"
+)
+
+var ErrVendorNotFound = errors.New("vendor not found")
+
+// tìm shop: khớp tên shop được ưu tiên hơn mô tả
+const vendorNameBoost = 3
+
+// VendorRepository lưu thông tin shop để hiện storefront và tìm shop theo tên
+type VendorRepository interface {
+	// BulkApply ghi các op có Vendor, version là updated_at của hồ sơ shop
+	BulkApply(ctx context.Context, ops []models.IndexOp) ([]models.BulkItemResult, error)
+	Get(ctx context.Context, id string) (*models.Vendor, error)
+	Search(ctx context.Context, query string, size int) ([]models.Vendor, error)
+}
+
+// VendorVersion: external version của hồ sơ shop
+func VendorVersion(vendor *models.Vendor) int64 {
+	if v := vendor.UpdatedAt.UnixMilli(); v > 0 {
+		return v
+	}
+	return 1
+}
+
+type vendorRepository struct{}
+
+func NewVendorRepository() VendorRepository {
+	return &vendorRepository{}
+}
+
+func vendorIndexName() string {
+	return productAlias() + "-vendors"
+}
+
+var (
+	vendorIndexMu    sync.Mutex
+	vendorIndexReady bool
+)
+
+func ensureVendorIndex(ctx context.Context) error {
+	vendorIndexMu.Lock()
+	defer vendorIndexMu.Unlock()
+	if vendorIndexReady {
+		return nil
+	}
+
+	indexName := vendorIndexName()
+	res, err := database.ES.Indices.Exists([]string{indexName}, database.ES.Indices.Exists.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to check if vendor index exists: %w", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode == 404 {
+		folded := map[string]interface{}{"type": "text", "analyzer": "vi_folded"}
+		mapping := map[string]interface{}{
+			"settings": map[string]interface{}{
+				"analysis": map[string]interface{}{
+					"analyzer": map[string]interface{}{
+						"vi_folded": map[string]interface{}{
+							"type":      "custom",
+							"tokenizer": "standard",
+							"filter":    []string{"lowercase", "asciifolding"},
+						},
+					},
+				},
+			},
+			"mappings": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"id":           map[string]interface{}{"type": "keyword"},
+					"display_name": folded,
+					"description":  folded,
+					"logo_url":     map[string]interface{}{"type": "keyword", "index": false},
+					"banner_url":   map[string]interface{}{"type": "keyword", "index": false},
+					"joined_at":    map[string]interface{}{"type": "date"},
+					"updated_at":   map[string]interface{}{"type": "date"},
+				},
+			},
+		}
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(mapping); err != nil {
+			return err
+		}
+		createRes, err := database.ES.Indices.Create(indexName,
+			database.ES.Indices.Create.WithBody(&buf),
+			database.ES.Indices.Create.WithContext(ctx),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create vendor index: %w", err)
+		}
+		defer createRes.Body.Close()
+		// replica khác vừa tạo thì bỏ qua
+		if createRes.IsError() && !strings.Contains(createRes.String(), "resource_already_exists_exception") {
+			return fmt.Errorf("failed to create vendor index: %s", createRes.String())
+		}
+	}
+
+	vendorIndexReady = true
+	return nil
+}
+
+func (r *vendorRepository) BulkApply(ctx context.Context, ops []models.IndexOp) ([]models.BulkItemResult, error) {
+	if err := ensureVendorIndex(ctx); err != nil {
+		return nil, err
+	}
+	return bulkWrite(ctx, []string{vendorIndexName()}, ops)
+}
+
+func (r *vendorRepository) Get(ctx context.Context, id string) (*models.Vendor, error) {
+	if err := ensureVendorIndex(ctx); err != nil {
+		return nil, err
+	}
+	res, err := database.ES.Get(vendorIndexName(), id, database.ES.Get.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return nil, ErrVendorNotFound
+	}
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to get vendor: %s", res.String())
+	}
+
+	var doc struct {
+		Source models.Vendor `json:"_source"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return &doc.Source, nil
+}
+
+func (r *vendorRepository) Search(ctx context.Context, query string, size int) ([]models.Vendor, error) {
+	if err := ensureVendorIndex(ctx); err != nil {
+		return nil, err
+	}
+	body := map[string]interface{}{
+		"size": size,
+		"query": map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":                query,
+				"fields":               []string{fmt.Sprintf("display_name^%d", vendorNameBoost), "description"},
+				"fuzziness":            searchFuzziness,
+				"prefix_length":        1,
+				"minimum_should_match": "2<75%",
+			},
+		},
+		"sort": []interface{}{"_score", map[string]interface{}{"id": "asc"}},
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		return nil, err
+	}
+
+	res, err := database.ES.Search(
+		database.ES.Search.WithContext(ctx),
+		database.ES.Search.WithIndex(vendorIndexName()),
+		database.ES.Search.WithBody(&buf),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("vendor search failed: %s", res.String())
+	}
+
+	var esResp struct {
+		Hits struct {
+			Hits []struct {
+				Source models.Vendor `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&esResp); err != nil {
+		return nil, err
+	}
+	vendors := make([]models.Vendor, 0, len(esResp.Hits.Hits))
+	for _, hit := range esResp.Hits.Hits {
+		vendors = append(vendors, hit.Source)
+	}
+	return vendors, nil
+}
+
+// VendorStats tổng hợp số liệu shop từ index sản phẩm, chỉ tính sản phẩm đang bán
+func (r *searchRepository) VendorStats(ctx context.Context, vendorID string) (*models.VendorStats, error) {
+	body := map[string]interface{}{
+		"size":             0,
+		"track_total_hits": true,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": baseFilters(models.SearchRequest{VendorID: vendorID}),
+			},
+		},
+		"aggs": map[string]interface{}{
+			"total_sales":  map[string]interface{}{"sum": map[string]interface{}{"field": "sold_count"}},
+			"rating_count": map[string]interface{}{"sum": map[string]interface{}{"field": "rating_count"}},
+			"rating": map[string]interface{}{
+				"weighted_avg": map[string]interface{}{
+					"value":  map[string]interface{}{"field": "rating"},
+					"weight": map[string]interface{}{"field": "rating_count", "missing": 0},
+				},
+			},
+		},
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		return nil, err
+	}
+
+	res, err := database.ES.Search(
+		database.ES.Search.WithContext(ctx),
+		database.ES.Search.WithIndex(productAlias()),
+		database.ES.Search.WithBody(&buf),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("vendor stats failed: %s", res.String())
+	}
+
+	type metric struct {
+		Value *float64 `json:"value"`
+	}
+	var esResp struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+		} `json:"hits"`
+		Aggregations struct {
+			TotalSales  metric `json:"total_sales"`
+			RatingCount metric `json:"rating_count"`
+			Rating      metric `json:"rating"`
+		} `json:"aggregations"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&esResp); err != nil {
+		return nil, err
+	}
+
+	value := func(m metric) float64 {
+		if m.Value == nil {
+			return 0
+		}
+		return *m.Value
+	}
+	return &models.VendorStats{
+		ProductCount: esResp.Hits.Total.Value,
+		TotalSales:   int64(value(esResp.Aggregations.TotalSales)),
+		RatingCount:  int64(value(esResp.Aggregations.RatingCount)),
+		Rating:       roundRating(value(esResp.Aggregations.Rating)),
+	}, nil
+}
+
+// roundRating: làm tròn 2 chữ số để các engine trả cùng một giá trị
+func roundRating(rating float64) float64 {
+	return math.Round(rating*100) / 100
+}
@@ -6,6 +6,51 @@ import (
 )
 
 func SearchRoutes(router *gin.Engine, ctrl *controller.SearchController) {
-	router.GET("/search", ctrl.BasicSearch("search"))
-	router.GET("/search/advanced", ctrl.AdvancedSearch("advanced", map[string]interface{}{}))
-}
\ No newline at end of file
+	router.GET("/search", ctrl.Search())
+	// giữ lại đường dẫn cũ, cùng handler với /search
+	router.GET("/search/advanced", ctrl.Search())
+	router.GET("/search/autocomplete", ctrl.Autocomplete())
+}
+
+// SynonymRoutes: admin sửa synonym, gateway kiểm tra role
+func SynonymRoutes(router *gin.Engine, ctrl *controller.SynonymController) {
+	admin := router.Group("/search/admin/synonyms")
+	admin.Use(controller.RequireAdmin())
+	{
+		admin.GET("", ctrl.ListSynonyms())
+		admin.PUT("", ctrl.ReplaceSynonyms())
+		admin.PUT("/:id", ctrl.PutSynonymRule())
+		admin.DELETE("/:id", ctrl.DeleteSynonymRule())
+	}
+}
+
+// ReindexRoutes: dựng lại index sản phẩm và chuyển alias, chỉ admin
+func ReindexRoutes(router *gin.Engine, ctrl *controller.ReindexController) {
+	admin := router.Group("/search/admin/reindex")
+	admin.Use(controller.RequireAdmin())
+	{
+		admin.GET("", ctrl.GetReindexStatus())
+		admin.POST("", ctrl.StartReindex())
+		admin.POST("/rollback", ctrl.RollbackReindex())
+	}
+}
+
+// AnalyticsRoutes: click-through là public (gateway gắn user/device), báo cáo chỉ admin
+func AnalyticsRoutes(router *gin.Engine, ctrl *controller.AnalyticsController) {
+	router.POST("/search/click", ctrl.RecordClick())
+
+	admin := router.Group("/search/admin/analytics")
+	admin.Use(controller.RequireAdmin())
+	{
+		admin.GET("/:report", ctrl.GetReport())
+	}
+}
+
+// VendorRoutes: storefront public của shop
+func VendorRoutes(router *gin.Engine, ctrl *controller.VendorController) {
+	vendors := router.Group("/search/vendors/:vendor_id")
+	{
+		vendors.GET("", ctrl.GetStorefront())
+		vendors.GET("/products", ctrl.SearchProducts())
+	}
+}
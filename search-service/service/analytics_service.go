@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"search-service/models"
+	"search-service/repository"
+)
+
+// AnalyticsPublisher gửi event lên topic analytics, không được chặn request tìm kiếm
+type AnalyticsPublisher interface {
+	Publish(event models.SearchEvent)
+}
+
+type AnalyticsService interface {
+	RecordClick(ctx context.Context, click models.SearchClickRequest, userID, deviceID string)
+	// ApplyEvents ghi các event đọc từ topic analytics vào Elasticsearch
+	ApplyEvents(ctx context.Context, ops []models.IndexOp) ([]models.BulkItemResult, error)
+	Report(ctx context.Context, req models.AnalyticsReportRequest) (*models.AnalyticsReport, error)
+}
+
+type analyticsService struct {
+	repo      repository.AnalyticsRepository
+	publisher AnalyticsPublisher
+}
+
+func NewAnalyticsService(repo repository.AnalyticsRepository, publisher AnalyticsPublisher) AnalyticsService {
+	return &analyticsService{repo: repo, publisher: publisher}
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func (s *analyticsService) RecordClick(ctx context.Context, click models.SearchClickRequest, userID, deviceID string) {
+	if s.publisher == nil {
+		return
+	}
+	s.publisher.Publish(models.SearchEvent{
+		Type:      models.SearchEventClick,
+		QueryID:   click.QueryID,
+		ClickID:   newEventID(),
+		ProductID: click.ProductID,
+		Position:  click.Position,
+		UserID:    userID,
+		DeviceID:  deviceID,
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+func (s *analyticsService) ApplyEvents(ctx context.Context, ops []models.IndexOp) ([]models.BulkItemResult, error) {
+	return s.repo.BulkApply(ctx, ops)
+}
+
+func (s *analyticsService) Report(ctx context.Context, req models.AnalyticsReportRequest) (*models.AnalyticsReport, error) {
+	queries, err := s.repo.Report(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AnalyticsReport{
+		Report:  req.Report,
+		From:    req.From,
+		To:      req.To,
+		Queries: queries,
+	}, nil
+}
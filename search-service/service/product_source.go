@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	pb "module/gRPC-Product/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"search-service/log"
+	"search-service/models"
+	"search-service/repository"
+)
+
+// ProductSource: nguồn dữ liệu đầy đủ để dựng lại index
+type ProductSource interface {
+	ExportProducts(ctx context.Context, fn func(product *models.Product) error) error
+}
+
+type grpcProductSource struct {
+	addr string
+}
+
+// NewProductServiceSource đọc sản phẩm qua stream ExportProducts của product-service
+func NewProductServiceSource() ProductSource {
+	addr := os.Getenv("PRODUCT_SERVICE_GRPC_ADDR")
+	if addr == "" {
+		addr = "product-service:8089"
+	}
+	return &grpcProductSource{addr: addr}
+}
+
+func (s *grpcProductSource) ExportProducts(ctx context.Context, fn func(product *models.Product) error) error {
+	conn, err := grpc.NewClient(s.addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	stream, err := pb.NewProductServiceClient(conn).ExportProducts(ctx, &pb.ExportProductsRequest{})
+	if err != nil {
+		return err
+	}
+	for {
+		p, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		product := &models.Product{
+			ID:          p.Id,
+			Name:        p.Name,
+			Price:       p.Price,
+			Category:    p.Category,
+			Description: p.Description,
+			ImageURL:    p.ImageUrl,
+			Quantity:    int(p.Quantity),
+			SoldCount:   int(p.SoldCount),
+			Rating:      p.Rating,
+			RatingCount: int(p.RatingCount),
+			Status:      p.Status,
+			UserID:      p.UserId,
+			CreatedAt:   time.UnixMilli(p.CreatedAt).UTC(),
+			UpdatedAt:   time.UnixMilli(p.UpdatedAt).UTC(),
+		}
+		if p.AttributesJson != "" {
+			if err := json.Unmarshal([]byte(p.AttributesJson), &product.Attributes); err != nil {
+				logger.Err("Failed to decode product attributes", err, logger.Str("id", p.Id))
+			}
+		}
+		if err := fn(product); err != nil {
+			return err
+		}
+	}
+}
+
+// LoadProducts nạp toàn bộ sản phẩm từ source qua ApplyIndexOps, dùng cho engine nhúng (khởi động là index rỗng).
+// Trả về số sản phẩm đã ghi.
+func LoadProducts(ctx context.Context, source ProductSource, svc SearchService, batchSize int) (int, error) {
+	loaded := 0
+	batch := make([]models.IndexOp, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		results, err := svc.ApplyIndexOps(ctx, batch)
+		if err != nil {
+			return err
+		}
+		for i, result := range results {
+			if result.Outcome == models.BulkItemFailed {
+				logger.Error("Failed to load product", logger.Str("id", batch[i].ID), logger.Str("error", result.Error))
+				continue
+			}
+			loaded++
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	err := source.ExportProducts(ctx, func(product *models.Product) error {
+		batch = append(batch, models.IndexOp{
+			Action:  models.IndexOpIndex,
+			ID:      product.ID,
+			Version: repository.ProductVersion(product),
+			Product: product,
+		})
+		if len(batch) >= batchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	return loaded, err
+}
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"search-service/log"
+	"search-service/models"
+	"search-service/repository"
+)
+
+var (
+	ErrReindexRunning    = errors.New("a reindex is already running")
+	ErrNothingToRollback = errors.New("no previous index to roll back to")
+)
+
+const (
+	defaultReindexBatchSize = 500
+	maxReindexFailedIDs     = 100
+	// quá tỉ lệ sản phẩm lỗi này thì không chuyển alias
+	maxReindexFailureRatio = 0.01
+)
+
+// ReindexService dựng index mới từ product-service rồi chuyển alias sang, index cũ giữ lại để rollback
+type ReindexService struct {
+	repo      repository.ReindexRepository
+	source    ProductSource
+	vendors   VendorDirectory
+	batchSize int
+	// gọi sau mỗi batch, lệnh cmd/reindex dùng để in tiến độ
+	OnProgress func(state models.ReindexState)
+}
+
+func NewReindexService(repo repository.ReindexRepository, source ProductSource, vendors VendorDirectory) *ReindexService {
+	batchSize := defaultReindexBatchSize
+	if n, err := strconv.Atoi(os.Getenv("REINDEX_BATCH_SIZE")); err == nil && n > 0 {
+		batchSize = n
+	}
+	return &ReindexService{repo: repo, source: source, vendors: vendors, batchSize: batchSize}
+}
+
+func (s *ReindexService) Status(ctx context.Context) (*models.ReindexStatus, error) {
+	aliasIndices, legacy, err := s.repo.AliasIndices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if legacy {
+		aliasIndices = []string{s.repo.Alias()}
+	}
+	indices, err := s.repo.ListIndices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	state, err := s.repo.GetState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ReindexStatus{
+		Alias:        s.repo.Alias(),
+		Version:      repository.ProductIndexVersion,
+		AliasIndices: aliasIndices,
+		Indices:      indices,
+		Reindex:      state,
+	}, nil
+}
+
+// Start tạo index mới rồi chạy reindex ở background, trả về trạng thái ban đầu
+func (s *ReindexService) Start(ctx context.Context) (*models.ReindexState, error) {
+	state, err := s.claim(ctx)
+	if err != nil {
+		return nil, err
+	}
+	started := *state
+	go func() {
+		if err := s.run(context.Background(), state); err != nil {
+			logger.Err("Reindex failed", err, logger.Str("index", state.NewIndex))
+		}
+	}()
+	return &started, nil
+}
+
+// Run chạy reindex đến khi xong, dùng cho cmd/reindex
+func (s *ReindexService) Run(ctx context.Context) (*models.ReindexState, error) {
+	state, err := s.claim(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = s.run(ctx, state)
+	return state, err
+}
+
+func (s *ReindexService) claim(ctx context.Context) (*models.ReindexState, error) {
+	if s.repo.Alias() == "" {
+		return nil, fmt.Errorf("ELASTICSEARCH_INDEX is not set")
+	}
+	prev, err := s.repo.GetState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if repository.IsReindexActive(prev, now) {
+		return nil, ErrReindexRunning
+	}
+
+	newIndex, err := s.repo.CreateIndex(ctx)
+	if err != nil {
+		if newIndex != "" {
+			s.repo.DeleteIndex(ctx, newIndex)
+		}
+		return nil, err
+	}
+
+	state := &models.ReindexState{
+		Alias:     s.repo.Alias(),
+		Version:   repository.ProductIndexVersion,
+		Status:    models.ReindexRunning,
+		NewIndex:  newIndex,
+		StartedAt: now,
+		UpdatedAt: now,
+	}
+	if prev != nil {
+		state.SeqNo, state.PrimaryTerm = prev.SeqNo, prev.PrimaryTerm
+	}
+	if err := s.repo.SaveState(ctx, state); err != nil {
+		s.repo.DeleteIndex(ctx, newIndex)
+		if errors.Is(err, repository.ErrReindexStateConflict) {
+			return nil, ErrReindexRunning
+		}
+		return nil, err
+	}
+	return state, nil
+}
+
+func (s *ReindexService) save(ctx context.Context, state *models.ReindexState) error {
+	state.UpdatedAt = time.Now().UTC()
+	if err := s.repo.SaveState(ctx, state); err != nil {
+		return err
+	}
+	if s.OnProgress != nil {
+		s.OnProgress(*state)
+	}
+	return nil
+}
+
+func (s *ReindexService) fail(ctx context.Context, state *models.ReindexState, cause error) error {
+	// vẫn dọn dẹp khi ctx đã bị huỷ
+	ctx = context.WithoutCancel(ctx)
+	if err := s.repo.DeleteIndex(ctx, state.NewIndex); err != nil {
+		logger.Err("Failed to delete unfinished index", err, logger.Str("index", state.NewIndex))
+	}
+	// tiến trình khác đã ghi đè trạng thái thì không ghi lại
+	if errors.Is(cause, repository.ErrReindexStateConflict) {
+		return cause
+	}
+
+	now := time.Now().UTC()
+	state.Status = models.ReindexFailed
+	state.Error = cause.Error()
+	state.FinishedAt = &now
+	if err := s.save(ctx, state); err != nil {
+		logger.Err("Failed to save reindex state", err)
+	}
+	return cause
+}
+
+func (s *ReindexService) run(ctx context.Context, state *models.ReindexState) error {
+	// chờ các writer đọc được trạng thái mới và bắt đầu ghi song song vào index mới,
+	// thay đổi xảy ra sau đó không bị bản export cũ hơn bỏ sót
+	select {
+	case <-time.After(repository.ReindexStateTTL):
+	case <-ctx.Done():
+		return s.fail(ctx, state, ctx.Err())
+	}
+
+	batch := make([]*models.Product, 0, s.batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		fillVendorNames(ctx, s.vendors, batch)
+		result, err := s.repo.BulkLoad(ctx, state.NewIndex, batch)
+		if err != nil {
+			return err
+		}
+		batch = batch[:0]
+
+		state.Indexed += result.Indexed
+		state.Skipped += result.Skipped
+		state.Failed += int64(len(result.FailedIDs))
+		for _, id := range result.FailedIDs {
+			if len(state.FailedIDs) < maxReindexFailedIDs {
+				state.FailedIDs = append(state.FailedIDs, id)
+			}
+		}
+		return s.save(ctx, state)
+	}
+
+	err := s.source.ExportProducts(ctx, func(product *models.Product) error {
+		state.Processed++
+		batch = append(batch, product)
+		if len(batch) >= s.batchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return s.fail(ctx, state, fmt.Errorf("failed to load products: %w", err))
+	}
+
+	if state.Processed > 0 && float64(state.Failed)/float64(state.Processed) > maxReindexFailureRatio {
+		return s.fail(ctx, state, fmt.Errorf("%d of %d products failed to index", state.Failed, state.Processed))
+	}
+	if err := s.repo.FinishIndex(ctx, state.NewIndex); err != nil {
+		return s.fail(ctx, state, err)
+	}
+	count, err := s.repo.CountDocuments(ctx, state.NewIndex)
+	if err != nil {
+		return s.fail(ctx, state, err)
+	}
+	state.DocCount = count
+
+	current, legacy, err := s.repo.AliasIndices(ctx)
+	if err != nil {
+		return s.fail(ctx, state, err)
+	}
+	// product-service trả về rỗng do lỗi thì không thay index đang có dữ liệu
+	if count == 0 {
+		oldCount, err := s.repo.CountDocuments(ctx, s.repo.Alias())
+		if err != nil && !errors.Is(err, repository.ErrIndexNotFound) {
+			return s.fail(ctx, state, err)
+		}
+		if oldCount > 0 {
+			return s.fail(ctx, state, fmt.Errorf("new index is empty while current index has %d documents", oldCount))
+		}
+	}
+
+	if err := s.repo.SwapAlias(ctx, state.NewIndex, current, legacy); err != nil {
+		return s.fail(ctx, state, err)
+	}
+	// index cũ tạo bằng tên alias đã bị xoá khi chuyển nên không rollback được
+	if len(current) == 1 && !legacy {
+		state.PreviousIndex = current[0]
+	}
+
+	now := time.Now().UTC()
+	state.Status = models.ReindexCompleted
+	state.FinishedAt = &now
+	if err := s.save(ctx, state); err != nil {
+		logger.Err("Failed to save reindex state", err)
+	}
+
+	s.cleanup(ctx, state)
+	logger.Info("Reindex completed", logger.Str("index", state.NewIndex), logger.Str("previous", state.PreviousIndex))
+	return nil
+}
+
+// cleanup xoá các index phiên bản cũ, chỉ giữ index hiện tại và index trước đó để rollback
+func (s *ReindexService) cleanup(ctx context.Context, state *models.ReindexState) {
+	indices, err := s.repo.ListIndices(ctx)
+	if err != nil {
+		logger.Err("Failed to list old indices", err)
+		return
+	}
+	for _, index := range indices {
+		if index == state.NewIndex || index == state.PreviousIndex {
+			continue
+		}
+		if err := s.repo.DeleteIndex(ctx, index); err != nil {
+			logger.Err("Failed to delete old index", err, logger.Str("index", index))
+		}
+	}
+}
+
+// Rollback chuyển alias về index trước lần reindex gần nhất, index này vẫn được ghi song song
+// từ khi chuyển alias (xem dualWriteIndex) nên không bị cũ
+func (s *ReindexService) Rollback(ctx context.Context) (*models.ReindexState, error) {
+	state, err := s.repo.GetState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if repository.IsReindexActive(state, time.Now().UTC()) {
+		return nil, ErrReindexRunning
+	}
+	if state == nil || state.Status != models.ReindexCompleted || state.PreviousIndex == "" {
+		return nil, ErrNothingToRollback
+	}
+	exists, err := s.repo.IndexExists(ctx, state.PreviousIndex)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNothingToRollback
+	}
+
+	current, _, err := s.repo.AliasIndices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SwapAlias(ctx, state.PreviousIndex, current, false); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	state.Status = models.ReindexRolledBack
+	state.FinishedAt = &now
+	if err := s.save(ctx, state); err != nil {
+		return nil, err
+	}
+	logger.Info("Reindex rolled back", logger.Str("index", state.PreviousIndex))
+	return state, nil
+}
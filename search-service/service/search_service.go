@@ -2,10 +2,7 @@ package service
 
 import (
 	"context"
-
-	pb "module/gRPC-Product/service"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"time"
 
 	"search-service/log"
 	"search-service/models"
@@ -13,67 +10,114 @@ import (
 )
 
 type SearchService interface {
-	BasicSearch(query string) ([]models.Product, error)
-	AdvancedSearch(query string, filters map[string]interface{}) ([]models.Product, error)
-	IndexProduct(product *models.Product) error
-	DeleteProduct(id string) error
-	SyncProductFromProductService() error
+	Search(ctx context.Context, req models.SearchRequest) (*models.SearchResult, error)
+	Autocomplete(ctx context.Context, prefix string, size int) (*models.AutocompleteResult, error)
+	ApplyIndexOps(ctx context.Context, ops []models.IndexOp) ([]models.BulkItemResult, error)
 }
 
+// số shop tối đa kèm theo kết quả tìm kiếm toàn sàn
+const maxVendorResults = 3
+
+// số từ khoá chờ ghi tối đa, đầy thì bỏ bớt (gợi ý từ khoá phổ biến chỉ cần gần đúng)
+const recordQueueLen = 1024
+
 type searchService struct {
-	repo repository.SearchRepository
+	repo        repository.SearchRepository
+	vendorIndex repository.VendorRepository
+	vendors     VendorDirectory
+	analytics   AnalyticsPublisher
+	queries     chan string
 }
 
-func NewSearchService(repo repository.SearchRepository) SearchService {
-	return &searchService{
+func NewSearchService(repo repository.SearchRepository, vendorIndex repository.VendorRepository, vendors VendorDirectory, analytics AnalyticsPublisher) SearchService {
+	s := &searchService{
 		repo : repo,
+		vendorIndex: vendorIndex,
+		vendors: vendors,
+		analytics: analytics,
+		queries: make(chan string, recordQueueLen),
 	}
+	go s.recordQueries()
+	return s
 }
 
-func (s *searchService) BasicSearch(query string) ([]models.Product, error) {
-	return s.repo.BasicSearch(query)
-}
-
-func (s *searchService) AdvancedSearch(query string, filters map[string]interface{}) ([]models.Product, error) {
-	return s.repo.AdvancedSearch(query, filters)
-}
-
-func (s *searchService) IndexProduct(product *models.Product) error {
-	return s.repo.IndexProduct(product)
+// recordQueries ghi từ khoá lần lượt ở một goroutine, search không tạo goroutine mới cho mỗi request
+func (s *searchService) recordQueries() {
+	for query := range s.queries {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		if err := s.repo.RecordQuery(ctx, query); err != nil {
+			logger.Err("Failed to record search query", err)
+		}
+		cancel()
+	}
 }
 
-func (s *searchService) DeleteProduct(id string) error {
-	return s.repo.DeleteProduct(id)
+// searchVendors chạy song song với tìm sản phẩm, lỗi thì chỉ bỏ phần shop
+func (s *searchService) searchVendors(ctx context.Context, req models.SearchRequest) <-chan []models.Vendor {
+	out := make(chan []models.Vendor, 1)
+	if s.vendorIndex == nil || req.Query == "" || req.VendorID != "" || req.Cursor != "" || req.From > 0 {
+		out <- nil
+		return out
+	}
+	go func() {
+		vendors, err := s.vendorIndex.Search(ctx, req.Query, maxVendorResults)
+		if err != nil {
+			logger.Err("Failed to search vendors", err)
+		}
+		out <- vendors
+	}()
+	return out
 }
 
-func (s *searchService) SyncProductFromProductService() error {
-	conn, err := grpc.NewClient("product-service:8089", grpc.WithTransportCredentials(insecure.NewCredentials()))
+func (s *searchService) Search(ctx context.Context, req models.SearchRequest) (*models.SearchResult, error) {
+	start := time.Now()
+	vendors := s.searchVendors(ctx, req)
+	result, err := s.repo.Search(ctx, req)
 	if err != nil {
-		logger.Error("Failed to connect to product service: %v", logger.ErrField(err))
-		return err 
+		return nil, err
 	}
-	defer conn.Close()
+	result.Vendors = <-vendors
 
-	client := pb.NewProductServiceClient(conn)
-	resp, err := client.GetAllProducts(context.Background(), &pb.Empty{})
-	if err != nil {
-		logger.Error("Failed to get all products from product service: %v", logger.ErrField(err))
-		return err 
+	if s.analytics != nil {
+		result.QueryID = newEventID()
+		s.analytics.Publish(models.SearchEvent{
+			Type:        models.SearchEventQuery,
+			QueryID:     result.QueryID,
+			Query:       req.Query,
+			Normalized:  repository.NormalizeQuery(req.Query),
+			Filters:     req.AppliedFilters(),
+			Sort:        req.Sort,
+			FirstPage:   req.Cursor == "" && req.From == 0,
+			ResultCount: result.Total,
+			LatencyMs:   time.Since(start).Milliseconds(),
+			UserID:      req.UserID,
+			DeviceID:    req.DeviceID,
+			Timestamp:   start.UTC(),
+		})
 	}
 
-	for _, p := range resp.Products {
-		err := s.repo.IndexProduct(&models.Product{
-			ID:          p.Id,
-			Name:        p.Name,
-			Description: p.Description,
-			Price:       float64(p.Price),
-			Category:    p.Category,
-			ImageURL: 	 p.ImageUrl,
-		})
-		if err != nil {
-			logger.Err("Failed to index product: %v", err)
+	// chỉ ghi trang đầu của từ khoá có kết quả, làm nguồn gợi ý từ khoá phổ biến
+	if req.Query != "" && req.Cursor == "" && req.From == 0 && result.Total > 0 {
+		select {
+		case s.queries <- req.Query:
+		default:
+			logger.Debug("Search query queue is full, dropping query", logger.Str("query", req.Query))
 		}
 	}
+	return result, nil
+}
 
-	return nil 
-}
\ No newline at end of file
+func (s *searchService) Autocomplete(ctx context.Context, prefix string, size int) (*models.AutocompleteResult, error) {
+	return s.repo.Autocomplete(ctx, prefix, size)
+}
+
+func (s *searchService) ApplyIndexOps(ctx context.Context, ops []models.IndexOp) ([]models.BulkItemResult, error) {
+	var products []*models.Product
+	for _, op := range ops {
+		if op.Action == models.IndexOpIndex && op.Product != nil {
+			products = append(products, op.Product)
+		}
+	}
+	fillVendorNames(ctx, s.vendors, products)
+	return s.repo.BulkApply(ctx, ops)
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"search-service/models"
+	"search-service/repository"
+)
+
+var ErrInvalidSynonymRule = errors.New("invalid synonym rule")
+
+const (
+	maxSynonymRuleLength = 1024
+	maxSynonymRules      = 5000
+)
+
+var synonymRuleIDRe = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+type SynonymService struct {
+	repo repository.SynonymRepository
+}
+
+func NewSynonymService(repo repository.SynonymRepository) *SynonymService {
+	return &SynonymService{repo: repo}
+}
+
+func normalizeTerms(part string) []string {
+	var terms []string
+	for _, term := range strings.Split(part, ",") {
+		term = strings.ToLower(strings.Join(strings.Fields(term), " "))
+		if term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// withFoldedVariants thêm biến thể không dấu của từng từ, synonym filter chạy trước bước bỏ dấu
+// nên rule "điện thoại, smartphone" phải có cả "dien thoai" thì người gõ không dấu mới khớp
+func withFoldedVariants(terms []string) []string {
+	seen := make(map[string]bool, len(terms)*2)
+	out := make([]string, 0, len(terms)*2)
+	for _, term := range terms {
+		for _, t := range []string{term, repository.FoldDiacritics(term)} {
+			if !seen[t] {
+				seen[t] = true
+				out = append(out, t)
+			}
+		}
+	}
+	return out
+}
+
+// NormalizeSynonymRule kiểm tra cú pháp và chuẩn hoá rule trước khi lưu
+func NormalizeSynonymRule(rule models.SynonymRule) (models.SynonymRule, error) {
+	if rule.ID != "" && !synonymRuleIDRe.MatchString(rule.ID) {
+		return rule, fmt.Errorf("%w: id must be 1-64 letters, digits, '-' or '_'", ErrInvalidSynonymRule)
+	}
+	if len(rule.Synonyms) > maxSynonymRuleLength {
+		return rule, fmt.Errorf("%w: rule is longer than %d characters", ErrInvalidSynonymRule, maxSynonymRuleLength)
+	}
+
+	if lhs, rhs, ok := strings.Cut(rule.Synonyms, "=>"); ok {
+		from, to := normalizeTerms(lhs), normalizeTerms(rhs)
+		if len(from) == 0 || len(to) == 0 || strings.Contains(rhs, "=>") {
+			return rule, fmt.Errorf("%w: expected \"a, b => c\"", ErrInvalidSynonymRule)
+		}
+		rule.Synonyms = strings.Join(withFoldedVariants(from), ", ") + " => " + strings.Join(to, ", ")
+		return rule, nil
+	}
+
+	terms := normalizeTerms(rule.Synonyms)
+	if len(terms) < 2 {
+		return rule, fmt.Errorf("%w: equivalent synonyms need at least two terms", ErrInvalidSynonymRule)
+	}
+	rule.Synonyms = strings.Join(withFoldedVariants(terms), ", ")
+	return rule, nil
+}
+
+func (s *SynonymService) List(ctx context.Context) ([]models.SynonymRule, error) {
+	return s.repo.List(ctx)
+}
+
+// Replace thay toàn bộ synonym set, có hiệu lực ngay cho các lần search sau
+func (s *SynonymService) Replace(ctx context.Context, rules []models.SynonymRule) ([]models.SynonymRule, error) {
+	if len(rules) > maxSynonymRules {
+		return nil, fmt.Errorf("%w: at most %d rules", ErrInvalidSynonymRule, maxSynonymRules)
+	}
+	normalized := make([]models.SynonymRule, 0, len(rules))
+	ids := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		rule, err := NormalizeSynonymRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		if rule.ID != "" {
+			if ids[rule.ID] {
+				return nil, fmt.Errorf("%w: duplicate id %s", ErrInvalidSynonymRule, rule.ID)
+			}
+			ids[rule.ID] = true
+		}
+		normalized = append(normalized, rule)
+	}
+
+	if err := s.repo.Replace(ctx, normalized); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx)
+}
+
+func (s *SynonymService) PutRule(ctx context.Context, rule models.SynonymRule) (*models.SynonymRule, error) {
+	if rule.ID == "" {
+		return nil, fmt.Errorf("%w: id is required", ErrInvalidSynonymRule)
+	}
+	rule, err := NormalizeSynonymRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.PutRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (s *SynonymService) DeleteRule(ctx context.Context, id string) error {
+	return s.repo.DeleteRule(ctx, id)
+}
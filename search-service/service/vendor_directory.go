@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"search-service/log"
+	"search-service/models"
+	"search-service/repository"
+)
+
+// tên shop ít khi đổi, cache lâu để reindex không gọi user-service cho từng sản phẩm
+const vendorNameTTL = 30 * time.Minute
+
+// VendorDirectory tra thông tin shop của người bán theo user id
+type VendorDirectory interface {
+	// VendorNames trả về tên của các id tra được, id lỗi thì bỏ qua
+	VendorNames(ctx context.Context, ids []string) map[string]string
+	// Vendor: hồ sơ shop hiện tại, không có thì repository.ErrVendorNotFound
+	Vendor(ctx context.Context, id string) (*models.Vendor, error)
+	// FollowerCount: số người đang follow shop, không cache vì thay đổi liên tục
+	FollowerCount(ctx context.Context, id string) (int64, error)
+}
+
+type cachedVendorName struct {
+	name      string
+	expiresAt time.Time
+}
+
+type userServiceVendorDirectory struct {
+	baseURL    string
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cachedVendorName
+}
+
+func NewUserServiceVendorDirectory() VendorDirectory {
+	baseURL := os.Getenv("USER_SERVICE_URL")
+	if baseURL == "" {
+		baseURL = "http://user-service:8095"
+	}
+	return &userServiceVendorDirectory{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		cache:      make(map[string]cachedVendorName),
+	}
+}
+
+func (d *userServiceVendorDirectory) VendorNames(ctx context.Context, ids []string) map[string]string {
+	names := make(map[string]string, len(ids))
+	now := time.Now()
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		if _, ok := names[id]; ok {
+			continue
+		}
+
+		d.mu.Lock()
+		cached, ok := d.cache[id]
+		d.mu.Unlock()
+		if ok && now.Before(cached.expiresAt) {
+			names[id] = cached.name
+			continue
+		}
+
+		name, err := d.fetchName(ctx, id)
+		if err != nil {
+			logger.Err("Failed to fetch vendor name", err, logger.Str("user_id", id))
+			// tên cũ vẫn tốt hơn để trống
+			if ok {
+				names[id] = cached.name
+			}
+			continue
+		}
+		d.mu.Lock()
+		d.cache[id] = cachedVendorName{name: name, expiresAt: now.Add(vendorNameTTL)}
+		d.mu.Unlock()
+		names[id] = name
+	}
+	return names
+}
+
+func (d *userServiceVendorDirectory) fetchName(ctx context.Context, userID string) (string, error) {
+	vendor, err := d.Vendor(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return vendor.DisplayName, nil
+}
+
+// Vendor: GET /vendors/:id, người bán chưa tạo storefront thì user-service trả tên tài khoản
+func (d *userServiceVendorDirectory) Vendor(ctx context.Context, id string) (*models.Vendor, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.baseURL+"/vendors/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// id không phải uuid thì user-service trả 400
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest {
+		return nil, repository.ErrVendorNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user-service returned status %d for vendor %s", resp.StatusCode, id)
+	}
+
+	var vendor models.Vendor
+	if err := json.NewDecoder(resp.Body).Decode(&vendor); err != nil {
+		return nil, err
+	}
+	return &vendor, nil
+}
+
+// FollowerCount: GET /vendors/:id/followers
+func (d *userServiceVendorDirectory) FollowerCount(ctx context.Context, id string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.baseURL+"/vendors/"+url.PathEscape(id)+"/followers", nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("user-service returned status %d for followers of vendor %s", resp.StatusCode, id)
+	}
+
+	var body struct {
+		FollowerCount int64 `json:"follower_count"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, err
+	}
+	return body.FollowerCount, nil
+}
+
+// fillVendorNames gắn tên người bán vào các sản phẩm chưa có
+func fillVendorNames(ctx context.Context, vendors VendorDirectory, products []*models.Product) {
+	if vendors == nil {
+		return
+	}
+	var ids []string
+	for _, p := range products {
+		if p.VendorName == "" && p.UserID != "" {
+			ids = append(ids, p.UserID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	names := vendors.VendorNames(ctx, ids)
+	for _, p := range products {
+		if p.VendorName == "" {
+			p.VendorName = names[p.UserID]
+		}
+	}
+}
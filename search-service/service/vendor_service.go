@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"search-service/log"
+	"search-service/models"
+	"search-service/repository"
+)
+
+// VendorService: trang storefront của shop và đồng bộ hồ sơ shop từ vendor-events
+type VendorService interface {
+	Storefront(ctx context.Context, vendorID string) (*models.VendorStorefront, error)
+	ApplyVendorOps(ctx context.Context, ops []models.IndexOp) ([]models.BulkItemResult, error)
+}
+
+type vendorService struct {
+	repo      repository.VendorRepository
+	products  repository.SearchRepository
+	directory VendorDirectory
+}
+
+func NewVendorService(repo repository.VendorRepository, products repository.SearchRepository, directory VendorDirectory) VendorService {
+	return &vendorService{repo: repo, products: products, directory: directory}
+}
+
+// vendor: shop chưa có trong index (chưa sửa storefront từ khi có vendor-events) thì lấy từ user-service rồi lưu lại
+func (s *vendorService) vendor(ctx context.Context, vendorID string) (*models.Vendor, error) {
+	vendor, err := s.repo.Get(ctx, vendorID)
+	if !errors.Is(err, repository.ErrVendorNotFound) {
+		return vendor, err
+	}
+
+	vendor, err = s.directory.Vendor(ctx, vendorID)
+	if err != nil {
+		return nil, err
+	}
+	op := models.IndexOp{Action: models.IndexOpIndex, ID: vendor.ID, Version: repository.VendorVersion(vendor), Vendor: vendor}
+	if _, err := s.repo.BulkApply(ctx, []models.IndexOp{op}); err != nil {
+		logger.Err("Failed to cache vendor profile", err, logger.Str("vendor_id", vendorID))
+	}
+	return vendor, nil
+}
+
+func (s *vendorService) Storefront(ctx context.Context, vendorID string) (*models.VendorStorefront, error) {
+	vendor, err := s.vendor(ctx, vendorID)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.products.VendorStats(ctx, vendorID)
+	if err != nil {
+		return nil, err
+	}
+	storefront := &models.VendorStorefront{Vendor: *vendor, Stats: *stats}
+	// user-service lỗi thì vẫn trả trang shop, chỉ thiếu số follower
+	if storefront.FollowerCount, err = s.directory.FollowerCount(ctx, vendorID); err != nil {
+		logger.Err("Failed to fetch vendor follower count", err, logger.Str("vendor_id", vendorID))
+	}
+	return storefront, nil
+}
+
+func (s *vendorService) ApplyVendorOps(ctx context.Context, ops []models.IndexOp) ([]models.BulkItemResult, error) {
+	return s.repo.BulkApply(ctx, ops)
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+    "context"
     "fmt"
     "log"
+    "time"
 
     "github.com/gin-gonic/gin"
     "gorm.io/driver/postgres"
@@ -38,8 +40,28 @@ func main() {
         log.Fatalf("failed to migrate database: %v", err)
     }
 
+    if err := db.AutoMigrate(&models.Wishlist{}, &models.WishlistItem{}); err != nil {
+        log.Fatalf("failed to migrate database: %v", err)
+    }
+
+    if err := db.AutoMigrate(&models.BrowsingHistory{}); err != nil {
+        log.Fatalf("failed to migrate database: %v", err)
+    }
+
+    if err := db.AutoMigrate(&models.VendorProfile{}); err != nil {
+        log.Fatalf("failed to migrate database: %v", err)
+    }
+
+    if err := db.AutoMigrate(&models.VendorFollow{}, &models.VendorProductState{}, &models.VendorActivity{}, &models.FeedDigest{}); err != nil {
+        log.Fatalf("failed to migrate database: %v", err)
+    }
+
     // Initialize layers
     userRepo := repository.NewUserRepository(db)
+    wishlistRepo := repository.NewWishlistRepository(db)
+    historyRepo := repository.NewHistoryRepository(db)
+    vendorRepo := repository.NewVendorRepository(db)
+    followRepo := repository.NewFollowRepository(db)
 
     // Kafka configuration (optional) - use env from config package only
     var pub events.EventPublisher
@@ -51,6 +73,8 @@ func main() {
         pub = events.NewKafkaPublisher(brokers, topic)
         // start consumer (reads user.created)
         events.StartUserCreatedConsumer(brokers, topic, userRepo)
+        // role do auth-service quản lý, findVendor cần user_type mới nhất
+        events.StartUserRoleConsumer(brokers, userRepo)
     } else {
         log.Println("KAFKA_BROKERS not set, using LoggingPublisher")
         pub = events.NewLoggingPublisher()
@@ -59,10 +83,37 @@ func main() {
     userService := services.NewUserService(userRepo, pub)
     userHandler := &handlers.UserHandler{UserService: userService}
 
+    productClient := services.NewProductClient()
+    wishlistService := services.NewWishlistService(wishlistRepo, productClient, pub)
+    wishlistHandler := &handlers.WishlistHandler{WishlistService: wishlistService}
+
+    historyService := services.NewHistoryService(historyRepo, productClient)
+    historyHandler := &handlers.HistoryHandler{HistoryService: historyService}
+
+    vendorService := services.NewVendorService(vendorRepo, userRepo, pub)
+    vendorHandler := &handlers.VendorHandler{VendorService: vendorService}
+
+    followService := services.NewFollowService(followRepo, userRepo, pub)
+    followHandler := &handlers.FollowHandler{FollowService: followService}
+
+    if kafkaBrokers != "" {
+        brokers := cfg.SplitAndTrim(kafkaBrokers, ",")
+        // price-drop / back-in-stock cho wishlist
+        events.StartProductEventConsumer(brokers, wishlistService.HandleProductChange)
+        // lịch sử xem sản phẩm từ api-gateway
+        events.StartProductViewConsumer(brokers, historyService.RecordView)
+        events.StartHistoryMergeConsumer(brokers, historyService.MergeGuestHistory)
+        // feed sản phẩm mới / giảm giá của các shop được follow
+        events.StartVendorFeedConsumer(brokers, followService.HandleProductEvent)
+    }
+
+    // email tổng hợp hằng ngày, quét mỗi giờ
+    followService.StartDigestJob(context.Background(), time.Hour)
+
     // Setup Gin
     r := gin.Default()
 
-    routes.Register(r, userHandler)
+    routes.Register(r, userHandler, wishlistHandler, historyHandler, vendorHandler, followHandler)
 
     addr := fmt.Sprintf("%s:%s", config.Server.Host, config.Server.Port)
     log.Printf("Starting server on %s\n", addr)
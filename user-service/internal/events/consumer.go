@@ -74,3 +74,23 @@ func StartUserCreatedConsumer(brokers []string, topic string, repo repository.Us
 		}
 	}()
 }
+
+const UserRoleUpdatedTopic = "user.role_updated"
+
+// StartUserRoleConsumer cập nhật user_type khi auth-service đổi role (vd. USER -> SELLER)
+func StartUserRoleConsumer(brokers []string, repo repository.UserRepository) {
+	startJSONConsumer(brokers, UserRoleUpdatedTopic, "user-service-roles", func(value []byte) error {
+		var event struct {
+			ID       string `json:"id"`
+			UserType string `json:"user_type"`
+		}
+		if err := json.Unmarshal(value, &event); err != nil {
+			return err
+		}
+		uid, err := uuid.Parse(event.ID)
+		if err != nil {
+			return err
+		}
+		return repo.UpdateUserType(uid, event.UserType)
+	})
+}
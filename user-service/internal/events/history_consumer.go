@@ -0,0 +1,62 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"user-service/internal/models"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	ProductViewTopic  = "product_views"
+	HistoryMergeTopic = "browsing_history_merge"
+)
+
+// StartProductViewConsumer đọc lượt xem sản phẩm do api-gateway publish để lưu lịch sử xem
+func StartProductViewConsumer(brokers []string, handle func(event models.ProductViewEvent) error) {
+	startJSONConsumer(brokers, ProductViewTopic, "user-service-history", func(value []byte) error {
+		var event models.ProductViewEvent
+		if err := json.Unmarshal(value, &event); err != nil {
+			return err
+		}
+		return handle(event)
+	})
+}
+
+// StartHistoryMergeConsumer gộp lịch sử xem của khách vào tài khoản sau khi login
+func StartHistoryMergeConsumer(brokers []string, handle func(event models.HistoryMergeEvent) error) {
+	startJSONConsumer(brokers, HistoryMergeTopic, "user-service-history-merge", func(value []byte) error {
+		var event models.HistoryMergeEvent
+		if err := json.Unmarshal(value, &event); err != nil {
+			return err
+		}
+		return handle(event)
+	})
+}
+
+func startJSONConsumer(brokers []string, topic, groupID string, handle func(value []byte) error) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: brokers,
+		Topic:   topic,
+		GroupID: groupID,
+	})
+
+	go func() {
+		defer r.Close()
+		for {
+			m, err := r.ReadMessage(context.Background())
+			if err != nil {
+				log.Printf("%s read error: %v", topic, err)
+				time.Sleep(2 * time.Second)
+				continue
+			}
+			if err := handle(m.Value); err != nil {
+				log.Printf("failed to handle %s message: %v", topic, err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,94 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"user-service/internal/models"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const ProductEventTopic = "product-events"
+
+type productEvent struct {
+	Type    string                  `json:"type"`
+	Product *models.ProductSnapshot `json:"product"`
+	ID      string                  `json:"id"`
+}
+
+// StartProductEventConsumer đọc product-events của product-service để kích hoạt thông báo wishlist
+func StartProductEventConsumer(brokers []string, handle func(product models.ProductSnapshot) error) {
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: brokers,
+		Topic:   ProductEventTopic,
+		GroupID: "user-service-wishlist",
+	})
+
+	go func() {
+		defer r.Close()
+		for {
+			m, err := r.ReadMessage(context.Background())
+			if err != nil {
+				log.Printf("product-events read error: %v", err)
+				time.Sleep(2 * time.Second)
+				continue
+			}
+
+			var event productEvent
+			if err := json.Unmarshal(m.Value, &event); err != nil {
+				log.Printf("failed to unmarshal product event: %v", err)
+				continue
+			}
+
+			var product models.ProductSnapshot
+			switch event.Type {
+			case "created", "INITIAL_SYNC":
+				// sản phẩm mới chưa nằm trong wishlist nào
+				continue
+			case "deleted":
+				product = models.ProductSnapshot{ID: event.ID, Status: "deleted"}
+			default:
+				if event.Product == nil {
+					continue
+				}
+				product = *event.Product
+			}
+			if product.ID == "" {
+				product.ID = event.ID
+			}
+
+			if err := handle(product); err != nil {
+				log.Printf("failed to handle product event for %s: %v", product.ID, err)
+			}
+		}
+	}()
+}
+
+type feedProductEvent struct {
+	Type    string              `json:"type"`
+	Product *models.FeedProduct `json:"product"`
+	ID      string              `json:"id"`
+}
+
+// StartVendorFeedConsumer đọc product-events (group riêng với wishlist) để dựng feed các shop được follow
+func StartVendorFeedConsumer(brokers []string, handle func(eventType string, product models.FeedProduct) error) {
+	startJSONConsumer(brokers, ProductEventTopic, "user-service-feed", func(value []byte) error {
+		var event feedProductEvent
+		if err := json.Unmarshal(value, &event); err != nil {
+			return err
+		}
+		var product models.FeedProduct
+		if event.Product != nil {
+			product = *event.Product
+		} else if event.Type != "deleted" {
+			return nil
+		}
+		if product.ID == "" {
+			product.ID = event.ID
+		}
+		return handle(event.Type, product)
+	})
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"user-service/internal/models"
+	"user-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type FollowHandler struct {
+	FollowService *services.FollowService
+}
+
+func parseVendorID(c *gin.Context) (uuid.UUID, bool) {
+	vendorID, err := uuid.Parse(c.Param("vendor_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vendor_id"})
+		return uuid.Nil, false
+	}
+	return vendorID, true
+}
+
+// FollowVendor: follow lại thì chỉ cập nhật email_digest
+func (h *FollowHandler) FollowVendor(c *gin.Context) {
+	userID, _, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+	vendorID, ok := parseVendorID(c)
+	if !ok {
+		return
+	}
+	var req models.FollowVendorRequest
+	// body không bắt buộc
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	follow, err := h.FollowService.Follow(userID, vendorID, req)
+	switch {
+	case errors.Is(err, services.ErrVendorNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCannotFollowSelf):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, follow)
+	}
+}
+
+func (h *FollowHandler) UnfollowVendor(c *gin.Context) {
+	userID, _, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+	vendorID, ok := parseVendorID(c)
+	if !ok {
+		return
+	}
+	err := h.FollowService.Unfollow(userID, vendorID)
+	if errors.Is(err, services.ErrNotFollowing) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, "Vendor unfollowed")
+}
+
+func (h *FollowHandler) GetFollowing(c *gin.Context) {
+	userID, _, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+	vendors, err := h.FollowService.GetFollowing(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if vendors == nil {
+		vendors = []models.FollowedVendor{}
+	}
+	c.JSON(http.StatusOK, gin.H{"items": vendors})
+}
+
+func (h *FollowHandler) GetFeed(c *gin.Context) {
+	userID, _, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	page, err := h.FollowService.GetFeed(userID, c.Query("cursor"), limit)
+	if errors.Is(err, services.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// GetFollowerCount: public, search-service hiển thị trên trang shop
+func (h *FollowHandler) GetFollowerCount(c *gin.Context) {
+	vendorID, ok := parseVendorID(c)
+	if !ok {
+		return
+	}
+	count, err := h.FollowService.CountFollowers(vendorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"vendor_id": vendorID.String(), "follower_count": count})
+}
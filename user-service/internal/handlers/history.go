@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"user-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HistoryHandler struct {
+	HistoryService *services.HistoryService
+}
+
+func (h *HistoryHandler) GetRecentlyViewed(c *gin.Context) {
+	userID, _, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	items, err := h.HistoryService.GetRecentlyViewed(c.Request.Context(), userID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *HistoryHandler) ClearHistory(c *gin.Context) {
+	userID, _, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+	if err := h.HistoryService.ClearHistory(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, "Browsing history cleared")
+}
+
+func (h *HistoryHandler) RemoveItem(c *gin.Context) {
+	userID, _, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+	err := h.HistoryService.RemoveItem(userID, c.Param("product_id"))
+	if errors.Is(err, services.ErrHistoryItemNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, "Item removed from browsing history")
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"user-service/internal/models"
+	"user-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type VendorHandler struct {
+	VendorService *services.VendorService
+}
+
+func (h *VendorHandler) respondVendor(c *gin.Context, vendor *models.PublicVendor, err error) {
+	if errors.Is(err, services.ErrVendorNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, vendor)
+}
+
+func (h *VendorHandler) GetMyStorefront(c *gin.Context) {
+	userID, _, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+	vendor, err := h.VendorService.GetVendor(userID)
+	h.respondVendor(c, vendor, err)
+}
+
+func (h *VendorHandler) UpdateMyStorefront(c *gin.Context) {
+	userID, _, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+	var req models.UpdateVendorProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	vendor, err := h.VendorService.UpdateProfile(userID, req)
+	h.respondVendor(c, vendor, err)
+}
+
+// GetVendor: public, search-service dùng để dựng trang shop và tên người bán
+func (h *VendorHandler) GetVendor(c *gin.Context) {
+	vendorID, err := uuid.Parse(c.Param("vendor_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vendor_id"})
+		return
+	}
+	vendor, err := h.VendorService.GetVendor(vendorID)
+	h.respondVendor(c, vendor, err)
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"user-service/internal/models"
+	"user-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WishlistHandler struct {
+	WishlistService *services.WishlistService
+}
+
+func (h *WishlistHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrWishlistNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrProductNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func parseIDs(c *gin.Context, names ...string) (uuid.UUID, []uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.GetHeader("X-User-ID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return uuid.Nil, nil, false
+	}
+	ids := make([]uuid.UUID, 0, len(names))
+	for _, name := range names {
+		id, err := uuid.Parse(c.Param(name))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+			return uuid.Nil, nil, false
+		}
+		ids = append(ids, id)
+	}
+	return userID, ids, true
+}
+
+func (h *WishlistHandler) CreateWishlist(c *gin.Context) {
+	userID, _, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+	var req models.CreateWishlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	w, err := h.WishlistService.CreateWishlist(userID, req.Name)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, w)
+}
+
+func (h *WishlistHandler) GetWishlists(c *gin.Context) {
+	userID, _, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+	lists, err := h.WishlistService.GetWishlists(userID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, lists)
+}
+
+func (h *WishlistHandler) GetWishlist(c *gin.Context) {
+	userID, ids, ok := parseIDs(c, "wishlist_id")
+	if !ok {
+		return
+	}
+	w, err := h.WishlistService.GetWishlist(userID, ids[0])
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, w)
+}
+
+func (h *WishlistHandler) RenameWishlist(c *gin.Context) {
+	userID, ids, ok := parseIDs(c, "wishlist_id")
+	if !ok {
+		return
+	}
+	var req models.CreateWishlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	w, err := h.WishlistService.RenameWishlist(userID, ids[0], req.Name)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, w)
+}
+
+func (h *WishlistHandler) DeleteWishlist(c *gin.Context) {
+	userID, ids, ok := parseIDs(c, "wishlist_id")
+	if !ok {
+		return
+	}
+	if err := h.WishlistService.DeleteWishlist(userID, ids[0]); err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, "Wishlist deleted successfully")
+}
+
+func (h *WishlistHandler) ShareWishlist(c *gin.Context) {
+	userID, ids, ok := parseIDs(c, "wishlist_id")
+	if !ok {
+		return
+	}
+	w, err := h.WishlistService.ShareWishlist(userID, ids[0])
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"share_token": *w.ShareToken,
+		"share_path":  "/api/wishlists/shared/" + *w.ShareToken,
+	})
+}
+
+func (h *WishlistHandler) UnshareWishlist(c *gin.Context) {
+	userID, ids, ok := parseIDs(c, "wishlist_id")
+	if !ok {
+		return
+	}
+	if err := h.WishlistService.UnshareWishlist(userID, ids[0]); err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, "Wishlist is no longer shared")
+}
+
+// GetSharedWishlist là route public, không cần X-User-ID
+func (h *WishlistHandler) GetSharedWishlist(c *gin.Context) {
+	w, err := h.WishlistService.GetSharedWishlist(c.Param("token"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	// Không trả về thông tin subscription của chủ wishlist
+	items := make([]gin.H, 0, len(w.Items))
+	for _, item := range w.Items {
+		items = append(items, gin.H{
+			"product_id":   item.ProductID,
+			"product_name": item.ProductName,
+			"price":        item.LastPrice,
+			"available":    item.LastAvailable,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"name": w.Name, "items": items})
+}
+
+func (h *WishlistHandler) AddItem(c *gin.Context) {
+	userID, ids, ok := parseIDs(c, "wishlist_id")
+	if !ok {
+		return
+	}
+	var req models.AddWishlistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	item, err := h.WishlistService.AddItem(c.Request.Context(), userID, ids[0], req)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, item)
+}
+
+func (h *WishlistHandler) UpdateSubscription(c *gin.Context) {
+	userID, ids, ok := parseIDs(c, "wishlist_id", "item_id")
+	if !ok {
+		return
+	}
+	var req models.UpdateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	item, err := h.WishlistService.UpdateSubscription(userID, ids[0], ids[1], req)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *WishlistHandler) RemoveItem(c *gin.Context) {
+	userID, ids, ok := parseIDs(c, "wishlist_id", "item_id")
+	if !ok {
+		return
+	}
+	if err := h.WishlistService.RemoveItem(userID, ids[0], ids[1]); err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, "Item removed successfully")
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ActivityNewProduct = "new_product"
+	ActivityPriceDrop  = "price_drop"
+	// bắt đầu sale theo lịch (regular_price > price)
+	ActivityPromotion = "promotion"
+
+	DefaultFeedPageSize = 20
+	MaxFeedPageSize     = 50
+)
+
+// VendorFollow: user theo dõi một shop, email_digest = nhận email tổng hợp hằng ngày
+type VendorFollow struct {
+	UserID      uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+	VendorID    string    `gorm:"type:varchar(64);primaryKey;index" json:"vendor_id"`
+	EmailDigest bool      `gorm:"not null;default:false" json:"email_digest"`
+	CreatedAt   time.Time `json:"followed_at"`
+}
+
+type FollowVendorRequest struct {
+	EmailDigest bool `json:"email_digest"`
+}
+
+type FollowedVendor struct {
+	VendorFollow
+	VendorName string `json:"vendor_name"`
+}
+
+// VendorProductState: giá lần cuối thấy trên product-events, để nhận ra giảm giá / bắt đầu khuyến mãi
+type VendorProductState struct {
+	ProductID    string  `gorm:"type:varchar(64);primaryKey"`
+	VendorID     string  `gorm:"type:varchar(64);not null"`
+	Price        float64 `gorm:"not null"`
+	RegularPrice float64
+	UpdatedAt    time.Time `gorm:"autoUpdateTime:false"`
+}
+
+// VendorActivity: một mục trong feed của các shop, event_key chống ghi trùng khi Kafka gửi lại
+type VendorActivity struct {
+	ID            uint      `gorm:"primaryKey;index:idx_vendor_activities_vendor,priority:2" json:"id"`
+	VendorID      string    `gorm:"type:varchar(64);not null;index:idx_vendor_activities_vendor,priority:1" json:"vendor_id"`
+	Type          string    `gorm:"type:varchar(20);not null" json:"type"`
+	ProductID     string    `gorm:"type:varchar(64);not null" json:"product_id"`
+	ProductName   string    `gorm:"type:varchar(255)" json:"product_name"`
+	ImageURL      string    `gorm:"type:varchar(500)" json:"image_url,omitempty"`
+	Price         float64   `json:"price"`
+	PreviousPrice float64   `json:"previous_price,omitempty"`
+	EventKey      string    `gorm:"type:varchar(128);not null;uniqueIndex" json:"-"`
+	CreatedAt     time.Time `gorm:"index" json:"created_at"`
+}
+
+type FeedItem struct {
+	VendorActivity
+	VendorName string `json:"vendor_name"`
+}
+
+type FeedPage struct {
+	Items []FeedItem `json:"items"`
+	// rỗng là hết
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// FeedDigest: lần gửi email tổng hợp gần nhất của user
+type FeedDigest struct {
+	UserID     uuid.UUID `gorm:"type:uuid;primaryKey"`
+	LastSentAt time.Time `gorm:"not null"`
+	// instance đang gửi, last_sent_at chỉ tiến lên sau khi publish thành công
+	ClaimedAt *time.Time
+}
+
+// FeedProduct: các field của product-events mà feed cần
+type FeedProduct struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Price        float64   `json:"price"`
+	RegularPrice float64   `json:"regular_price"`
+	Status       string    `json:"status"`
+	UserID       string    `json:"user_id"`
+	ImagePath    []string  `json:"image_path"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// OnSale: "" là dữ liệu cũ chưa có status
+func (p *FeedProduct) OnSale() bool {
+	return p.Status == "" || p.Status == "onsale"
+}
+
+// Discounted: đang trong đợt sale
+func (p *FeedProduct) Discounted() bool {
+	return p.RegularPrice > p.Price
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+const (
+	HistoryOwnerUser   = "user"
+	HistoryOwnerDevice = "device"
+
+	// Chỉ giữ N sản phẩm xem gần nhất cho mỗi owner
+	MaxHistoryItems = 100
+)
+
+// BrowsingHistory: mỗi owner (user hoặc device của khách) chỉ có một dòng cho mỗi sản phẩm,
+// xem lại thì tăng view_count và cập nhật last_viewed_at
+type BrowsingHistory struct {
+	ID           uint      `gorm:"primaryKey" json:"-"`
+	OwnerType    string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_history_owner_product;index:idx_history_owner_viewed,priority:1" json:"-"`
+	OwnerID      string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_history_owner_product;index:idx_history_owner_viewed,priority:2" json:"-"`
+	ProductID    string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_history_owner_product" json:"product_id"`
+	ViewCount    int       `gorm:"not null;default:1" json:"view_count"`
+	LastViewedAt time.Time `gorm:"not null;index:idx_history_owner_viewed,priority:3,sort:desc" json:"last_viewed_at"`
+	CreatedAt    time.Time `json:"first_viewed_at"`
+}
+
+func (BrowsingHistory) TableName() string {
+	return "browsing_history"
+}
+
+// ProductViewEvent do api-gateway publish vào topic product_views
+type ProductViewEvent struct {
+	ProductID string    `json:"product_id"`
+	UserID    string    `json:"user_id,omitempty"`
+	DeviceID  string    `json:"device_id,omitempty"`
+	Platform  string    `json:"platform,omitempty"`
+	ViewedAt  time.Time `json:"viewed_at"`
+}
+
+type HistoryMergeEvent struct {
+	UserID   string    `json:"user_id"`
+	DeviceID string    `json:"device_id"`
+	MergedAt time.Time `json:"merged_at"`
+}
+
+type RecentlyViewedItem struct {
+	BrowsingHistory
+	Product *ProductSnapshot `json:"product,omitempty"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VendorProfile: thông tin shop do người bán tự quản lý, hiện trên trang storefront
+type VendorProfile struct {
+	UserID      uuid.UUID `gorm:"type:uuid;primaryKey" json:"vendor_id"`
+	DisplayName string    `gorm:"type:varchar(100);not null" json:"display_name"`
+	LogoURL     string    `gorm:"type:varchar(500)" json:"logo_url"`
+	BannerURL   string    `gorm:"type:varchar(500)" json:"banner_url"`
+	Description string    `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type UpdateVendorProfileRequest struct {
+	DisplayName string `json:"display_name" binding:"required,min=1,max=100"`
+	LogoURL     string `json:"logo_url" binding:"omitempty,url,max=500"`
+	BannerURL   string `json:"banner_url" binding:"omitempty,url,max=500"`
+	Description string `json:"description" binding:"max=2000"`
+}
+
+// PublicVendor: thông tin shop ai cũng xem được, joined_at là ngày tạo tài khoản.
+// Cùng định dạng với document vendor của search-service.
+type PublicVendor struct {
+	ID          string    `json:"id"`
+	DisplayName string    `json:"display_name"`
+	LogoURL     string    `json:"logo_url,omitempty"`
+	BannerURL   string    `json:"banner_url,omitempty"`
+	Description string    `json:"description,omitempty"`
+	JoinedAt    time.Time `json:"joined_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// VendorProfileEvent publish lên topic vendor-events mỗi khi người bán sửa storefront
+type VendorProfileEvent struct {
+	Type   string       `json:"type"`
+	Vendor PublicVendor `json:"vendor"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Wishlist struct {
+	ID         uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name       string         `gorm:"type:varchar(100);not null" json:"name"`
+	ShareToken *string        `gorm:"type:varchar(64);uniqueIndex" json:"share_token,omitempty"`
+	Items      []WishlistItem `gorm:"foreignKey:WishlistID;constraint:OnDelete:CASCADE" json:"items,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// WishlistItem lưu kèm snapshot giá / tồn kho lần cuối để so sánh khi có product-events mới
+type WishlistItem struct {
+	ID                uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	WishlistID        uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_wishlist_product" json:"wishlist_id"`
+	UserID            uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	ProductID         string     `gorm:"type:varchar(64);not null;index;uniqueIndex:idx_wishlist_product" json:"product_id"`
+	ProductName       string     `gorm:"type:varchar(255)" json:"product_name"`
+	PriceAtAdd        float64    `json:"price_at_add"`
+	LastPrice         float64    `json:"last_price"`
+	LastAvailable     bool       `json:"last_available"`
+	NotifyPriceBelow  *float64   `json:"notify_price_below,omitempty"`
+	NotifyBackInStock bool       `gorm:"default:false" json:"notify_back_in_stock"`
+	LastNotifiedAt    *time.Time `json:"last_notified_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+type CreateWishlistRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=100"`
+}
+
+type AddWishlistItemRequest struct {
+	ProductID         string   `json:"product_id" binding:"required"`
+	NotifyPriceBelow  *float64 `json:"notify_price_below,omitempty" binding:"omitempty,gt=0"`
+	NotifyBackInStock bool     `json:"notify_back_in_stock"`
+}
+
+type UpdateSubscriptionRequest struct {
+	NotifyPriceBelow  *float64 `json:"notify_price_below,omitempty" binding:"omitempty,gte=0"` // 0 = tắt
+	NotifyBackInStock *bool    `json:"notify_back_in_stock,omitempty"`
+}
+
+// ProductSnapshot là các field của product-service mà wishlist cần
+type ProductSnapshot struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Price    float64 `json:"price"`
+	Quantity int     `json:"quantity"`
+	Status   string  `json:"status"`
+}
+
+// Available: còn hàng và đang mở bán ("" là dữ liệu cũ chưa có status)
+func (p *ProductSnapshot) Available() bool {
+	return p.Quantity > 0 && (p.Status == "" || p.Status == "onsale")
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"user-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FollowRepository interface {
+	SaveFollow(follow *models.VendorFollow) error
+	DeleteFollow(userID uuid.UUID, vendorID string) error
+	GetFollowing(userID uuid.UUID) ([]models.FollowedVendor, error)
+	CountFollowers(vendorID string) (int64, error)
+
+	GetFeed(userID uuid.UUID, beforeID uint, limit int) ([]models.FeedItem, error)
+	GetProductState(productID string) (*models.VendorProductState, error)
+	// RecordProductChange lưu giá mới và activity (nếu có) trong cùng transaction
+	RecordProductChange(state *models.VendorProductState, activity *models.VendorActivity) error
+	DeleteProductState(productID string) error
+	DeleteActivitiesBefore(t time.Time) (int64, error)
+
+	GetDigestSubscribers() ([]uuid.UUID, error)
+	ClaimDigest(userID uuid.UUID, now time.Time, interval time.Duration) (time.Time, bool, error)
+	MarkDigestSent(userID uuid.UUID, sentAt time.Time) error
+	ReleaseDigest(userID uuid.UUID) error
+	GetDigestActivities(userID uuid.UUID, since time.Time, limit int) ([]models.FeedItem, error)
+}
+
+// tên shop như VendorService.GetVendor: chưa tạo storefront thì dùng tên tài khoản
+const vendorNameColumn = "COALESCE(NULLIF(vendor_profiles.display_name, ''), TRIM(CONCAT_WS(' ', users.first_name, users.last_name)), '') AS vendor_name"
+
+type followRepository struct {
+	db *gorm.DB
+}
+
+func NewFollowRepository(db *gorm.DB) FollowRepository {
+	return &followRepository{db: db}
+}
+
+// SaveFollow: follow lại thì chỉ cập nhật email_digest, giữ ngày follow đầu
+func (r *followRepository) SaveFollow(follow *models.VendorFollow) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "vendor_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"email_digest"}),
+	}).Create(follow).Error
+}
+
+func (r *followRepository) DeleteFollow(userID uuid.UUID, vendorID string) error {
+	res := r.db.Where("user_id = ? AND vendor_id = ?", userID, vendorID).Delete(&models.VendorFollow{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *followRepository) GetFollowing(userID uuid.UUID) ([]models.FollowedVendor, error) {
+	var vendors []models.FollowedVendor
+	err := r.db.Table("vendor_follows").
+		Select("vendor_follows.*, "+vendorNameColumn).
+		Joins("LEFT JOIN vendor_profiles ON vendor_profiles.user_id::text = vendor_follows.vendor_id").
+		Joins("LEFT JOIN users ON users.id::text = vendor_follows.vendor_id").
+		Where("vendor_follows.user_id = ?", userID).
+		Order("vendor_follows.created_at DESC").
+		Scan(&vendors).Error
+	return vendors, err
+}
+
+func (r *followRepository) CountFollowers(vendorID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.VendorFollow{}).Where("vendor_id = ?", vendorID).Count(&count).Error
+	return count, err
+}
+
+func (r *followRepository) feedQuery(userID uuid.UUID) *gorm.DB {
+	return r.db.Table("vendor_activities").
+		Select("vendor_activities.*, "+vendorNameColumn).
+		Joins("JOIN vendor_follows ON vendor_follows.vendor_id = vendor_activities.vendor_id AND vendor_follows.user_id = ?", userID).
+		Joins("LEFT JOIN vendor_profiles ON vendor_profiles.user_id::text = vendor_activities.vendor_id").
+		Joins("LEFT JOIN users ON users.id::text = vendor_activities.vendor_id")
+}
+
+// GetFeed: id tăng dần theo thời gian ghi nên dùng làm cursor, beforeID = 0 là trang đầu
+func (r *followRepository) GetFeed(userID uuid.UUID, beforeID uint, limit int) ([]models.FeedItem, error) {
+	q := r.feedQuery(userID)
+	if beforeID > 0 {
+		q = q.Where("vendor_activities.id < ?", beforeID)
+	}
+	var items []models.FeedItem
+	err := q.Order("vendor_activities.id DESC").Limit(limit).Scan(&items).Error
+	return items, err
+}
+
+func (r *followRepository) GetProductState(productID string) (*models.VendorProductState, error) {
+	var state models.VendorProductState
+	if err := r.db.First(&state, "product_id = ?", productID).Error; err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (r *followRepository) RecordProductChange(state *models.VendorProductState, activity *models.VendorActivity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "product_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"vendor_id", "price", "regular_price", "updated_at"}),
+		}).Create(state).Error; err != nil {
+			return err
+		}
+		if activity == nil {
+			return nil
+		}
+		// Kafka gửi lại cùng event thì event_key trùng, bỏ qua
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "event_key"}},
+			DoNothing: true,
+		}).Create(activity).Error
+	})
+}
+
+func (r *followRepository) DeleteProductState(productID string) error {
+	return r.db.Where("product_id = ?", productID).Delete(&models.VendorProductState{}).Error
+}
+
+func (r *followRepository) DeleteActivitiesBefore(t time.Time) (int64, error) {
+	res := r.db.Where("created_at < ?", t).Delete(&models.VendorActivity{})
+	return res.RowsAffected, res.Error
+}
+
+func (r *followRepository) GetDigestSubscribers() ([]uuid.UUID, error) {
+	var userIDs []uuid.UUID
+	err := r.db.Model(&models.VendorFollow{}).
+		Where("email_digest = ?", true).
+		Distinct().
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// claim quá hạn này coi như instance gửi đã chết, instance khác được claim lại
+const digestClaimLease = 15 * time.Minute
+
+// ClaimDigest giữ quyền gửi nếu lần gửi trước cách đây ít nhất interval, trả về mốc thời gian
+// lần gửi trước. Update có điều kiện để nhiều instance chạy cùng lúc chỉ một bên gửi.
+// Chỉ đặt claimed_at, last_sent_at do MarkDigestSent ghi sau khi gửi được.
+func (r *followRepository) ClaimDigest(userID uuid.UUID, now time.Time, interval time.Duration) (time.Time, bool, error) {
+	var digest models.FeedDigest
+	err := r.db.First(&digest, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// lần đầu: lấy activity trong interval gần nhất
+		digest = models.FeedDigest{UserID: userID, LastSentAt: now.Add(-interval)}
+		if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&digest).Error; err != nil {
+			return time.Time{}, false, err
+		}
+		err = r.db.First(&digest, "user_id = ?", userID).Error
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if now.Sub(digest.LastSentAt) < interval {
+		return digest.LastSentAt, false, nil
+	}
+	staleBefore := now.Add(-digestClaimLease)
+	if digest.ClaimedAt != nil && digest.ClaimedAt.After(staleBefore) {
+		return digest.LastSentAt, false, nil
+	}
+	res := r.db.Model(&models.FeedDigest{}).
+		Where("user_id = ? AND last_sent_at = ? AND (claimed_at IS NULL OR claimed_at < ?)", userID, digest.LastSentAt, staleBefore).
+		Update("claimed_at", now)
+	return digest.LastSentAt, res.RowsAffected == 1, res.Error
+}
+
+func (r *followRepository) MarkDigestSent(userID uuid.UUID, sentAt time.Time) error {
+	return r.db.Model(&models.FeedDigest{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"last_sent_at": sentAt, "claimed_at": nil}).Error
+}
+
+// ReleaseDigest trả claim mà không tiến mốc gửi (không có activity mới hoặc publish lỗi)
+func (r *followRepository) ReleaseDigest(userID uuid.UUID) error {
+	return r.db.Model(&models.FeedDigest{}).
+		Where("user_id = ?", userID).
+		Update("claimed_at", nil).Error
+}
+
+// GetDigestActivities: chỉ các shop user bật email_digest
+func (r *followRepository) GetDigestActivities(userID uuid.UUID, since time.Time, limit int) ([]models.FeedItem, error) {
+	var items []models.FeedItem
+	err := r.feedQuery(userID).
+		Where("vendor_follows.email_digest = ? AND vendor_activities.created_at > ?", true, since).
+		Order("vendor_activities.id DESC").
+		Limit(limit).
+		Scan(&items).Error
+	return items, err
+}
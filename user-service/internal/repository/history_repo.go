@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"time"
+
+	"user-service/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type HistoryRepository interface {
+	RecordView(ownerType, ownerID, productID string, at time.Time) error
+	Trim(ownerType, ownerID string, keep int) error
+	List(ownerType, ownerID string, limit int) ([]models.BrowsingHistory, error)
+	Clear(ownerType, ownerID string) error
+	DeleteItem(ownerType, ownerID, productID string) error
+	MergeDeviceIntoUser(deviceID, userID string) error
+}
+
+type historyRepository struct {
+	db *gorm.DB
+}
+
+func NewHistoryRepository(db *gorm.DB) HistoryRepository {
+	return &historyRepository{db: db}
+}
+
+func (r *historyRepository) RecordView(ownerType, ownerID, productID string, at time.Time) error {
+	h := &models.BrowsingHistory{
+		OwnerType:    ownerType,
+		OwnerID:      ownerID,
+		ProductID:    productID,
+		ViewCount:    1,
+		LastViewedAt: at,
+	}
+	// event có thể đến trễ / lệch thứ tự nên chỉ lấy thời gian lớn hơn
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "owner_type"}, {Name: "owner_id"}, {Name: "product_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"view_count":     gorm.Expr("browsing_history.view_count + 1"),
+			"last_viewed_at": gorm.Expr("GREATEST(browsing_history.last_viewed_at, EXCLUDED.last_viewed_at)"),
+		}),
+	}).Create(h).Error
+}
+
+func (r *historyRepository) Trim(ownerType, ownerID string, keep int) error {
+	return r.db.Exec(`
+		DELETE FROM browsing_history
+		WHERE owner_type = ? AND owner_id = ? AND id NOT IN (
+			SELECT id FROM browsing_history
+			WHERE owner_type = ? AND owner_id = ?
+			ORDER BY last_viewed_at DESC
+			LIMIT ?
+		)`, ownerType, ownerID, ownerType, ownerID, keep).Error
+}
+
+func (r *historyRepository) List(ownerType, ownerID string, limit int) ([]models.BrowsingHistory, error) {
+	var items []models.BrowsingHistory
+	err := r.db.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).
+		Order("last_viewed_at DESC").
+		Limit(limit).
+		Find(&items).Error
+	return items, err
+}
+
+func (r *historyRepository) Clear(ownerType, ownerID string) error {
+	return r.db.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).
+		Delete(&models.BrowsingHistory{}).Error
+}
+
+func (r *historyRepository) DeleteItem(ownerType, ownerID, productID string) error {
+	res := r.db.Where("owner_type = ? AND owner_id = ? AND product_id = ?", ownerType, ownerID, productID).
+		Delete(&models.BrowsingHistory{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// MergeDeviceIntoUser chuyển lịch sử của device sang user trong một transaction,
+// sản phẩm trùng thì cộng view_count và giữ last_viewed_at mới hơn
+func (r *historyRepository) MergeDeviceIntoUser(deviceID, userID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			INSERT INTO browsing_history (owner_type, owner_id, product_id, view_count, last_viewed_at, created_at)
+			SELECT ?, ?, product_id, view_count, last_viewed_at, created_at
+			FROM browsing_history
+			WHERE owner_type = ? AND owner_id = ?
+			ON CONFLICT (owner_type, owner_id, product_id) DO UPDATE SET
+				view_count = browsing_history.view_count + EXCLUDED.view_count,
+				last_viewed_at = GREATEST(browsing_history.last_viewed_at, EXCLUDED.last_viewed_at),
+				created_at = LEAST(browsing_history.created_at, EXCLUDED.created_at)`,
+			models.HistoryOwnerUser, userID, models.HistoryOwnerDevice, deviceID).Error; err != nil {
+			return err
+		}
+		return tx.Where("owner_type = ? AND owner_id = ?", models.HistoryOwnerDevice, deviceID).
+			Delete(&models.BrowsingHistory{}).Error
+	})
+}
@@ -12,6 +12,7 @@ type UserRepository interface {
 	FindUserByID(id uuid.UUID) (*models.User, error)
 	DeleteUser(id uuid.UUID) error
 	UpdateUser(user *models.User) error
+	UpdateUserType(id uuid.UUID, userType string) error
 	CreateAddress(address *models.UserAddress) error
 	UpdateAddress(address *models.UserAddress) error
 	GetAddresses(userID uuid.UUID, limit, offset int) ([]models.UserAddress, error)
@@ -46,6 +47,18 @@ func (r *userRepository) UpdateUser(user *models.User) error {
 	return r.db.Save(user).Error
 }
 
+// UpdateUserType: chưa có user (user.created chưa tới) thì trả ErrRecordNotFound
+func (r *userRepository) UpdateUserType(id uuid.UUID, userType string) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", id).Update("user_type", userType)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 func (r *userRepository) CreateAddress(address *models.UserAddress) error {
 	tx := r.db.Begin()
 
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"user-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VendorRepository interface {
+	FindProfile(userID uuid.UUID) (*models.VendorProfile, error)
+	SaveProfile(profile *models.VendorProfile) error
+}
+
+type vendorRepository struct {
+	db *gorm.DB
+}
+
+func NewVendorRepository(db *gorm.DB) VendorRepository {
+	return &vendorRepository{db: db}
+}
+
+func (r *vendorRepository) FindProfile(userID uuid.UUID) (*models.VendorProfile, error) {
+	var profile models.VendorProfile
+	if err := r.db.First(&profile, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// SaveProfile tạo mới hoặc ghi đè, created_at giữ nguyên lần tạo đầu
+func (r *vendorRepository) SaveProfile(profile *models.VendorProfile) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"display_name", "logo_url", "banner_url", "description", "updated_at"}),
+	}).Create(profile).Error
+}
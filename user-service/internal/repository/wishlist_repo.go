@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"user-service/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WishlistRepository interface {
+	CreateWishlist(w *models.Wishlist) error
+	GetWishlists(userID uuid.UUID) ([]models.Wishlist, error)
+	GetWishlist(userID, id uuid.UUID) (*models.Wishlist, error)
+	GetWishlistByShareToken(token string) (*models.Wishlist, error)
+	UpdateWishlist(w *models.Wishlist) error
+	DeleteWishlist(userID, id uuid.UUID) error
+	AddItem(item *models.WishlistItem) error
+	GetItem(wishlistID, itemID uuid.UUID) (*models.WishlistItem, error)
+	UpdateItem(item *models.WishlistItem) error
+	DeleteItem(wishlistID, itemID uuid.UUID) error
+	GetItemsByProduct(productID string) ([]models.WishlistItem, error)
+	// UpdateProductSnapshot cập nhật mọi item của sản phẩm trừ các id trong except
+	UpdateProductSnapshot(productID, name string, price float64, available bool, except []uuid.UUID) error
+	MarkNotified(ids []uuid.UUID) error
+}
+
+type wishlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWishlistRepository(db *gorm.DB) WishlistRepository {
+	return &wishlistRepository{db: db}
+}
+
+func (r *wishlistRepository) CreateWishlist(w *models.Wishlist) error {
+	return r.db.Create(w).Error
+}
+
+func (r *wishlistRepository) GetWishlists(userID uuid.UUID) ([]models.Wishlist, error) {
+	var lists []models.Wishlist
+	if err := r.db.Preload("Items").Where("user_id = ?", userID).Order("created_at ASC").Find(&lists).Error; err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+func (r *wishlistRepository) GetWishlist(userID, id uuid.UUID) (*models.Wishlist, error) {
+	var w models.Wishlist
+	if err := r.db.Preload("Items").First(&w, "id = ? AND user_id = ?", id, userID).Error; err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (r *wishlistRepository) GetWishlistByShareToken(token string) (*models.Wishlist, error) {
+	var w models.Wishlist
+	if err := r.db.Preload("Items").First(&w, "share_token = ?", token).Error; err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (r *wishlistRepository) UpdateWishlist(w *models.Wishlist) error {
+	return r.db.Model(w).Select("name", "share_token").Updates(w).Error
+}
+
+func (r *wishlistRepository) DeleteWishlist(userID, id uuid.UUID) error {
+	tx := r.db.Begin()
+
+	res := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Wishlist{})
+	if res.Error != nil {
+		tx.Rollback()
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+
+	// Wishlist là soft delete nên cascade của FK không chạy, phải tự xoá item
+	if err := tx.Where("wishlist_id = ?", id).Delete(&models.WishlistItem{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+func (r *wishlistRepository) AddItem(item *models.WishlistItem) error {
+	return r.db.Create(item).Error
+}
+
+func (r *wishlistRepository) GetItem(wishlistID, itemID uuid.UUID) (*models.WishlistItem, error) {
+	var item models.WishlistItem
+	if err := r.db.First(&item, "id = ? AND wishlist_id = ?", itemID, wishlistID).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *wishlistRepository) UpdateItem(item *models.WishlistItem) error {
+	return r.db.Save(item).Error
+}
+
+func (r *wishlistRepository) DeleteItem(wishlistID, itemID uuid.UUID) error {
+	res := r.db.Where("id = ? AND wishlist_id = ?", itemID, wishlistID).Delete(&models.WishlistItem{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *wishlistRepository) GetItemsByProduct(productID string) ([]models.WishlistItem, error) {
+	var items []models.WishlistItem
+	if err := r.db.Where("product_id = ?", productID).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *wishlistRepository) UpdateProductSnapshot(productID, name string, price float64, available bool, except []uuid.UUID) error {
+	updates := map[string]interface{}{
+		"last_price":     price,
+		"last_available": available,
+	}
+	if name != "" {
+		updates["product_name"] = name
+	}
+	q := r.db.Model(&models.WishlistItem{}).Where("product_id = ?", productID)
+	if len(except) > 0 {
+		q = q.Where("id NOT IN ?", except)
+	}
+	return q.Updates(updates).Error
+}
+
+func (r *wishlistRepository) MarkNotified(ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.WishlistItem{}).Where("id IN ?", ids).Update("last_notified_at", gorm.Expr("NOW()")).Error
+}
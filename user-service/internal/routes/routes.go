@@ -6,7 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func Register(r *gin.Engine, h *handlers.UserHandler) {
+func Register(r *gin.Engine, h *handlers.UserHandler, wh *handlers.WishlistHandler, hh *handlers.HistoryHandler, vh *handlers.VendorHandler, fh *handlers.FollowHandler) {
 	users := r.Group("/me")
 	{
 		users.POST("", h.CreateUser)
@@ -21,5 +21,44 @@ func Register(r *gin.Engine, h *handlers.UserHandler) {
 			addresses.GET("", h.GetUserAddresses)
 			addresses.DELETE("/:address_id", h.DeleteAddress)
 		}
+
+		wishlists := users.Group("/wishlists")
+		{
+			wishlists.POST("", wh.CreateWishlist)
+			wishlists.GET("", wh.GetWishlists)
+			wishlists.GET("/:wishlist_id", wh.GetWishlist)
+			wishlists.PUT("/:wishlist_id", wh.RenameWishlist)
+			wishlists.DELETE("/:wishlist_id", wh.DeleteWishlist)
+			wishlists.POST("/:wishlist_id/share", wh.ShareWishlist)
+			wishlists.DELETE("/:wishlist_id/share", wh.UnshareWishlist)
+			wishlists.POST("/:wishlist_id/items", wh.AddItem)
+			wishlists.PUT("/:wishlist_id/items/:item_id", wh.UpdateSubscription)
+			wishlists.DELETE("/:wishlist_id/items/:item_id", wh.RemoveItem)
+		}
+
+		history := users.Group("/history")
+		{
+			history.GET("", hh.GetRecentlyViewed)
+			history.DELETE("", hh.ClearHistory)
+			history.DELETE("/:product_id", hh.RemoveItem)
+		}
+
+		// storefront của người bán
+		users.GET("/storefront", vh.GetMyStorefront)
+		users.PUT("/storefront", vh.UpdateMyStorefront)
+
+		following := users.Group("/following")
+		{
+			following.GET("", fh.GetFollowing)
+			following.PUT("/:vendor_id", fh.FollowVendor)
+			following.DELETE("/:vendor_id", fh.UnfollowVendor)
+		}
+		users.GET("/feed", fh.GetFeed)
 	}
+
+	// Public, xem wishlist qua link chia sẻ
+	r.GET("/wishlists/shared/:token", wh.GetSharedWishlist)
+	// Public, thông tin shop
+	r.GET("/vendors/:vendor_id", vh.GetVendor)
+	r.GET("/vendors/:vendor_id/followers", fh.GetFollowerCount)
 }
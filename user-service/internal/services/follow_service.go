@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"user-service/internal/events"
+	"user-service/internal/models"
+	"user-service/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	feedDigestInterval = 24 * time.Hour
+	// digest quá dài thì chỉ lấy các mục mới nhất, phần còn lại xem trong feed
+	feedDigestMaxItems = 30
+	// activity cũ hơn thì xoá, feed không cần giữ lâu
+	feedRetention = 90 * 24 * time.Hour
+)
+
+var (
+	ErrCannotFollowSelf = errors.New("cannot follow your own store")
+	ErrNotFollowing     = errors.New("not following this vendor")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+)
+
+type FollowService struct {
+	repo      repository.FollowRepository
+	users     repository.UserRepository
+	publisher events.EventPublisher
+}
+
+func NewFollowService(repo repository.FollowRepository, users repository.UserRepository, publisher events.EventPublisher) *FollowService {
+	return &FollowService{repo: repo, users: users, publisher: publisher}
+}
+
+func (s *FollowService) Follow(userID, vendorID uuid.UUID, req models.FollowVendorRequest) (*models.VendorFollow, error) {
+	if userID == vendorID {
+		return nil, ErrCannotFollowSelf
+	}
+	if _, err := findVendor(s.users, vendorID); err != nil {
+		return nil, err
+	}
+
+	follow := &models.VendorFollow{
+		UserID:      userID,
+		VendorID:    vendorID.String(),
+		EmailDigest: req.EmailDigest,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.repo.SaveFollow(follow); err != nil {
+		return nil, err
+	}
+	return follow, nil
+}
+
+func (s *FollowService) Unfollow(userID, vendorID uuid.UUID) error {
+	err := s.repo.DeleteFollow(userID, vendorID.String())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFollowing
+	}
+	return err
+}
+
+func (s *FollowService) GetFollowing(userID uuid.UUID) ([]models.FollowedVendor, error) {
+	return s.repo.GetFollowing(userID)
+}
+
+func (s *FollowService) CountFollowers(vendorID uuid.UUID) (int64, error) {
+	return s.repo.CountFollowers(vendorID.String())
+}
+
+// GetFeed: activity của các shop đang follow, mới nhất trước. cursor là next_cursor của trang trước.
+func (s *FollowService) GetFeed(userID uuid.UUID, cursor string, limit int) (*models.FeedPage, error) {
+	if limit <= 0 {
+		limit = models.DefaultFeedPageSize
+	}
+	limit = min(limit, models.MaxFeedPageSize)
+
+	var beforeID uint64
+	if cursor != "" {
+		id, err := strconv.ParseUint(cursor, 10, 64)
+		if err != nil || id == 0 {
+			return nil, ErrInvalidCursor
+		}
+		beforeID = id
+	}
+
+	// lấy dư 1 để biết còn trang sau không
+	items, err := s.repo.GetFeed(userID, uint(beforeID), limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &models.FeedPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.NextCursor = strconv.FormatUint(uint64(page.Items[limit-1].ID), 10)
+	}
+	if page.Items == nil {
+		page.Items = []models.FeedItem{}
+	}
+	return page, nil
+}
+
+// HandleProductEvent so sánh với giá lần trước để sinh activity: sản phẩm mới, giảm giá, bắt đầu khuyến mãi.
+// INITIAL_SYNC chỉ ghi mốc giá, không đẩy vào feed.
+func (s *FollowService) HandleProductEvent(eventType string, product models.FeedProduct) error {
+	if eventType == "deleted" {
+		return s.repo.DeleteProductState(product.ID)
+	}
+	if product.ID == "" || product.UserID == "" {
+		return nil
+	}
+
+	prev, err := s.repo.GetProductState(product.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	// event đến trễ, mốc đã mới hơn
+	if prev != nil && !product.UpdatedAt.IsZero() && product.UpdatedAt.Before(prev.UpdatedAt) {
+		return nil
+	}
+
+	state := &models.VendorProductState{
+		ProductID:    product.ID,
+		VendorID:     product.UserID,
+		Price:        product.Price,
+		RegularPrice: product.RegularPrice,
+		UpdatedAt:    product.UpdatedAt,
+	}
+	var activity *models.VendorActivity
+	if eventType != "INITIAL_SYNC" && product.OnSale() {
+		activity = newActivity(eventType, product, prev)
+	}
+	if activity != nil {
+		// shop chưa ai follow thì không cần lưu activity
+		followers, err := s.repo.CountFollowers(product.UserID)
+		if err != nil {
+			return err
+		}
+		if followers == 0 {
+			activity = nil
+		}
+	}
+	return s.repo.RecordProductChange(state, activity)
+}
+
+func newActivity(eventType string, product models.FeedProduct, prev *models.VendorProductState) *models.VendorActivity {
+	activity := &models.VendorActivity{
+		VendorID:    product.UserID,
+		ProductID:   product.ID,
+		ProductName: product.Name,
+		Price:       product.Price,
+	}
+	if len(product.ImagePath) > 0 {
+		activity.ImageURL = product.ImagePath[0]
+	}
+
+	switch {
+	case eventType == "created":
+		activity.Type = models.ActivityNewProduct
+		activity.EventKey = fmt.Sprintf("%s:%s", models.ActivityNewProduct, product.ID)
+		return activity
+	case prev == nil || product.Price >= prev.Price:
+		return nil
+	case product.Discounted() && prev.RegularPrice <= prev.Price:
+		activity.Type = models.ActivityPromotion
+	default:
+		activity.Type = models.ActivityPriceDrop
+	}
+	activity.PreviousPrice = prev.Price
+	if product.RegularPrice > prev.Price {
+		activity.PreviousPrice = product.RegularPrice
+	}
+	activity.EventKey = fmt.Sprintf("%s:%s:%d", activity.Type, product.ID, product.UpdatedAt.UnixMilli())
+	return activity
+}
+
+// StartDigestJob gửi email tổng hợp và dọn activity cũ, chạy mỗi interval cho đến khi ctx bị huỷ
+func (s *FollowService) StartDigestJob(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := s.SendDigests(time.Now()); err != nil {
+				log.Printf("Vendor feed digest failed: %v", err)
+			}
+			if n, err := s.repo.DeleteActivitiesBefore(time.Now().Add(-feedRetention)); err != nil {
+				log.Printf("failed to clean up vendor activities: %v", err)
+			} else if n > 0 {
+				log.Printf("removed %d old vendor activities", n)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	log.Printf("Vendor feed digest job started, every %s", interval)
+}
+
+// SendDigests gửi cho mỗi user bật digest tối đa 1 email mỗi ngày, chỉ khi có activity mới
+func (s *FollowService) SendDigests(now time.Time) error {
+	userIDs, err := s.repo.GetDigestSubscribers()
+	if err != nil {
+		return err
+	}
+	sent := 0
+	for _, userID := range userIDs {
+		since, ok, err := s.repo.ClaimDigest(userID, now, feedDigestInterval)
+		if err != nil {
+			log.Printf("failed to claim vendor digest for user %s: %v", userID, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		items, err := s.repo.GetDigestActivities(userID, since, feedDigestMaxItems)
+		if err != nil {
+			log.Printf("failed to load vendor digest for user %s: %v", userID, err)
+			s.releaseDigest(userID)
+			continue
+		}
+		if len(items) == 0 {
+			s.releaseDigest(userID)
+			continue
+		}
+		if err := s.sendDigest(userID, items); err != nil {
+			// giữ last_sent_at cũ để lần chạy sau gửi lại
+			log.Printf("failed to publish vendor digest for user %s: %v", userID, err)
+			s.releaseDigest(userID)
+			continue
+		}
+		if err := s.repo.MarkDigestSent(userID, now); err != nil {
+			log.Printf("failed to mark vendor digest sent for user %s: %v", userID, err)
+		}
+		sent++
+	}
+	if sent > 0 {
+		log.Printf("Sent %d vendor feed digests", sent)
+	}
+	return nil
+}
+
+func (s *FollowService) releaseDigest(userID uuid.UUID) {
+	if err := s.repo.ReleaseDigest(userID); err != nil {
+		log.Printf("failed to release vendor digest claim for user %s: %v", userID, err)
+	}
+}
+
+func (s *FollowService) sendDigest(userID uuid.UUID, items []models.FeedItem) error {
+	if s.publisher == nil {
+		return nil
+	}
+	data := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		data = append(data, map[string]interface{}{
+			"Type":          item.Type,
+			"VendorName":    item.VendorName,
+			"ProductID":     item.ProductID,
+			"ProductName":   item.ProductName,
+			"Price":         item.Price,
+			"PreviousPrice": item.PreviousPrice,
+		})
+	}
+	payload := map[string]interface{}{
+		"to":       "",
+		"user_id":  userID.String(),
+		"subject":  "Cập nhật mới từ các shop bạn theo dõi",
+		"template": "./template/vendor_feed_digest.html",
+		"data": map[string]interface{}{
+			"Items": data,
+		},
+	}
+	return s.publisher.Publish(EmailTopic, payload)
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"user-service/internal/models"
+	"user-service/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultHistoryLimit = 20
+	// số request product-service chạy song song khi lấy thông tin sản phẩm cho danh sách
+	historyProductFetchConcurrency = 5
+)
+
+var ErrHistoryItemNotFound = errors.New("product not found in browsing history")
+
+type HistoryService struct {
+	repo     repository.HistoryRepository
+	products *ProductClient
+}
+
+func NewHistoryService(repo repository.HistoryRepository, products *ProductClient) *HistoryService {
+	return &HistoryService{repo: repo, products: products}
+}
+
+// RecordView lưu lượt xem từ topic product_views. Đã đăng nhập thì ghi vào user,
+// còn khách thì ghi theo device_id để gộp lại khi login
+func (s *HistoryService) RecordView(event models.ProductViewEvent) error {
+	if event.ProductID == "" || len(event.ProductID) > 64 {
+		return nil
+	}
+
+	ownerType, ownerID := models.HistoryOwnerUser, event.UserID
+	if ownerID == "" {
+		ownerType, ownerID = models.HistoryOwnerDevice, event.DeviceID
+	}
+	if ownerID == "" || len(ownerID) > 64 {
+		return nil
+	}
+
+	viewedAt := event.ViewedAt
+	if viewedAt.IsZero() {
+		viewedAt = time.Now()
+	}
+
+	if err := s.repo.RecordView(ownerType, ownerID, event.ProductID, viewedAt); err != nil {
+		return err
+	}
+	return s.repo.Trim(ownerType, ownerID, models.MaxHistoryItems)
+}
+
+func (s *HistoryService) MergeGuestHistory(event models.HistoryMergeEvent) error {
+	if event.UserID == "" || event.DeviceID == "" {
+		return nil
+	}
+	if err := s.repo.MergeDeviceIntoUser(event.DeviceID, event.UserID); err != nil {
+		return err
+	}
+	return s.repo.Trim(models.HistoryOwnerUser, event.UserID, models.MaxHistoryItems)
+}
+
+// GetRecentlyViewed trả về sản phẩm xem gần nhất kèm thông tin hiện tại của sản phẩm,
+// sản phẩm đã bị xoá thì bỏ qua
+func (s *HistoryService) GetRecentlyViewed(ctx context.Context, userID uuid.UUID, limit int) ([]models.RecentlyViewedItem, error) {
+	if limit <= 0 || limit > models.MaxHistoryItems {
+		limit = defaultHistoryLimit
+	}
+
+	rows, err := s.repo.List(models.HistoryOwnerUser, userID.String(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.RecentlyViewedItem, len(rows))
+	missing := make([]bool, len(rows))
+	sem := make(chan struct{}, historyProductFetchConcurrency)
+	var wg sync.WaitGroup
+	for i, row := range rows {
+		items[i].BrowsingHistory = row
+		wg.Add(1)
+		go func(i int, productID string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			p, err := s.products.GetProduct(ctx, productID)
+			if errors.Is(err, ErrProductNotFound) {
+				missing[i] = true
+				return
+			}
+			// product-service lỗi thì vẫn trả về product_id
+			if err == nil {
+				items[i].Product = p
+			}
+		}(i, row.ProductID)
+	}
+	wg.Wait()
+
+	result := make([]models.RecentlyViewedItem, 0, len(items))
+	for i, item := range items {
+		if !missing[i] {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+func (s *HistoryService) ClearHistory(userID uuid.UUID) error {
+	return s.repo.Clear(models.HistoryOwnerUser, userID.String())
+}
+
+func (s *HistoryService) RemoveItem(userID uuid.UUID, productID string) error {
+	err := s.repo.DeleteItem(models.HistoryOwnerUser, userID.String(), productID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrHistoryItemNotFound
+	}
+	return err
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	cfg "user-service/internal/config"
+	"user-service/internal/models"
+)
+
+var ErrProductNotFound = errors.New("product not found")
+
+type ProductClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+func NewProductClient() *ProductClient {
+	return &ProductClient{
+		baseURL: cfg.GetEnv("PRODUCT_SERVICE_URL", "http://product-service:8082"),
+		http:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (c *ProductClient) GetProduct(ctx context.Context, id string) (*models.ProductSnapshot, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/products/get/"+id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrProductNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("product-service returned %d", resp.StatusCode)
+	}
+
+	var p models.ProductSnapshot
+	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+		return nil, err
+	}
+	if p.ID == "" {
+		return nil, ErrProductNotFound
+	}
+	return &p, nil
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"user-service/internal/events"
+	"user-service/internal/models"
+	"user-service/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	VendorEventsTopic = "vendor-events"
+
+	VendorProfileUpdated = "vendor_profile_updated"
+)
+
+var ErrVendorNotFound = errors.New("vendor not found")
+
+const userTypeSeller = "SELLER"
+
+// findVendor: chỉ tài khoản SELLER mới có storefront, user thường coi như không tồn tại
+func findVendor(users repository.UserRepository, userID uuid.UUID) (*models.User, error) {
+	user, err := users.FindUserByID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrVendorNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.UserType == nil || *user.UserType != userTypeSeller {
+		return nil, ErrVendorNotFound
+	}
+	return user, nil
+}
+
+type VendorService struct {
+	repo      repository.VendorRepository
+	users     repository.UserRepository
+	publisher events.EventPublisher
+}
+
+func NewVendorService(repo repository.VendorRepository, users repository.UserRepository, publisher events.EventPublisher) *VendorService {
+	return &VendorService{repo: repo, users: users, publisher: publisher}
+}
+
+func userDisplayName(user *models.User) string {
+	var parts []string
+	for _, p := range []*string{user.FirstName, user.LastName} {
+		if p != nil && strings.TrimSpace(*p) != "" {
+			parts = append(parts, strings.TrimSpace(*p))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func toPublicVendor(user *models.User, profile *models.VendorProfile) *models.PublicVendor {
+	v := &models.PublicVendor{
+		ID:        user.ID.String(),
+		JoinedAt:  user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+	// người bán chưa tạo storefront thì dùng tên tài khoản như trước
+	if profile == nil {
+		v.DisplayName = userDisplayName(user)
+		return v
+	}
+	v.DisplayName = profile.DisplayName
+	v.LogoURL = profile.LogoURL
+	v.BannerURL = profile.BannerURL
+	v.Description = profile.Description
+	v.UpdatedAt = profile.UpdatedAt
+	return v
+}
+
+func (s *VendorService) GetVendor(userID uuid.UUID) (*models.PublicVendor, error) {
+	user, err := findVendor(s.users, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := s.repo.FindProfile(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return toPublicVendor(user, nil), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toPublicVendor(user, profile), nil
+}
+
+// UpdateProfile lưu storefront rồi báo cho search-service cập nhật trang shop và kết quả tìm kiếm
+func (s *VendorService) UpdateProfile(userID uuid.UUID, req models.UpdateVendorProfileRequest) (*models.PublicVendor, error) {
+	user, err := findVendor(s.users, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	profile := &models.VendorProfile{
+		UserID:      userID,
+		DisplayName: strings.TrimSpace(req.DisplayName),
+		LogoURL:     req.LogoURL,
+		BannerURL:   req.BannerURL,
+		Description: strings.TrimSpace(req.Description),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.SaveProfile(profile); err != nil {
+		return nil, err
+	}
+
+	vendor := toPublicVendor(user, profile)
+	if s.publisher != nil {
+		event := models.VendorProfileEvent{Type: VendorProfileUpdated, Vendor: *vendor}
+		if err := s.publisher.Publish(VendorEventsTopic, event); err != nil {
+			log.Printf("failed to publish vendor profile event for %s: %v", userID, err)
+		}
+	}
+	return vendor, nil
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"user-service/internal/events"
+	"user-service/internal/models"
+	"user-service/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	EmailTopic = "email_topic"
+
+	// Không gửi lại cho cùng một item trong khoảng này, tránh spam khi giá dao động quanh ngưỡng
+	wishlistNotifyCooldown = 24 * time.Hour
+)
+
+var ErrWishlistNotFound = errors.New("wishlist not found")
+
+type WishlistService struct {
+	repo      repository.WishlistRepository
+	products  *ProductClient
+	publisher events.EventPublisher
+}
+
+func NewWishlistService(repo repository.WishlistRepository, products *ProductClient, publisher events.EventPublisher) *WishlistService {
+	return &WishlistService{repo: repo, products: products, publisher: publisher}
+}
+
+func (s *WishlistService) CreateWishlist(userID uuid.UUID, name string) (*models.Wishlist, error) {
+	w := &models.Wishlist{UserID: userID, Name: name}
+	if err := s.repo.CreateWishlist(w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (s *WishlistService) GetWishlists(userID uuid.UUID) ([]models.Wishlist, error) {
+	return s.repo.GetWishlists(userID)
+}
+
+func (s *WishlistService) GetWishlist(userID, id uuid.UUID) (*models.Wishlist, error) {
+	w, err := s.repo.GetWishlist(userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWishlistNotFound
+	}
+	return w, err
+}
+
+func (s *WishlistService) RenameWishlist(userID, id uuid.UUID, name string) (*models.Wishlist, error) {
+	w, err := s.GetWishlist(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	w.Name = name
+	if err := s.repo.UpdateWishlist(w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (s *WishlistService) DeleteWishlist(userID, id uuid.UUID) error {
+	err := s.repo.DeleteWishlist(userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrWishlistNotFound
+	}
+	return err
+}
+
+// ShareWishlist tạo token chia sẻ, ai có link đều xem được (chỉ đọc)
+func (s *WishlistService) ShareWishlist(userID, id uuid.UUID) (*models.Wishlist, error) {
+	w, err := s.GetWishlist(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if w.ShareToken != nil {
+		return w, nil
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(buf)
+	w.ShareToken = &token
+	if err := s.repo.UpdateWishlist(w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (s *WishlistService) UnshareWishlist(userID, id uuid.UUID) error {
+	w, err := s.GetWishlist(userID, id)
+	if err != nil {
+		return err
+	}
+	w.ShareToken = nil
+	return s.repo.UpdateWishlist(w)
+}
+
+func (s *WishlistService) GetSharedWishlist(token string) (*models.Wishlist, error) {
+	w, err := s.repo.GetWishlistByShareToken(token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWishlistNotFound
+	}
+	return w, err
+}
+
+func (s *WishlistService) AddItem(ctx context.Context, userID, wishlistID uuid.UUID, req models.AddWishlistItemRequest) (*models.WishlistItem, error) {
+	if _, err := s.GetWishlist(userID, wishlistID); err != nil {
+		return nil, err
+	}
+
+	product, err := s.products.GetProduct(ctx, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &models.WishlistItem{
+		WishlistID:        wishlistID,
+		UserID:            userID,
+		ProductID:         product.ID,
+		ProductName:       product.Name,
+		PriceAtAdd:        product.Price,
+		LastPrice:         product.Price,
+		LastAvailable:     product.Available(),
+		NotifyPriceBelow:  req.NotifyPriceBelow,
+		NotifyBackInStock: req.NotifyBackInStock,
+	}
+	if err := s.repo.AddItem(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *WishlistService) UpdateSubscription(userID, wishlistID, itemID uuid.UUID, req models.UpdateSubscriptionRequest) (*models.WishlistItem, error) {
+	if _, err := s.GetWishlist(userID, wishlistID); err != nil {
+		return nil, err
+	}
+	item, err := s.repo.GetItem(wishlistID, itemID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWishlistNotFound
+		}
+		return nil, err
+	}
+
+	if req.NotifyPriceBelow != nil {
+		if *req.NotifyPriceBelow == 0 {
+			item.NotifyPriceBelow = nil
+		} else {
+			item.NotifyPriceBelow = req.NotifyPriceBelow
+		}
+	}
+	if req.NotifyBackInStock != nil {
+		item.NotifyBackInStock = *req.NotifyBackInStock
+	}
+	if err := s.repo.UpdateItem(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *WishlistService) RemoveItem(userID, wishlistID, itemID uuid.UUID) error {
+	if _, err := s.GetWishlist(userID, wishlistID); err != nil {
+		return err
+	}
+	err := s.repo.DeleteItem(wishlistID, itemID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrWishlistNotFound
+	}
+	return err
+}
+
+// HandleProductChange so sánh trạng thái mới của sản phẩm với snapshot trong wishlist:
+// giá vượt xuống dưới ngưỡng hoặc từ hết hàng -> có hàng thì gửi email, sau đó cập nhật snapshot.
+func (s *WishlistService) HandleProductChange(product models.ProductSnapshot) error {
+	items, err := s.repo.GetItemsByProduct(product.ID)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	available := product.Available()
+	now := time.Now()
+
+	// Một user có thể để cùng sản phẩm ở nhiều list, chỉ gửi 1 email mỗi loại
+	priceDrop := make(map[uuid.UUID]models.WishlistItem)
+	backInStock := make(map[uuid.UUID]models.WishlistItem)
+	var notified []uuid.UUID
+	// item đang cooldown mà lẽ ra đã báo thì giữ nguyên snapshot, hết cooldown event sau vẫn so với giá cũ và báo
+	var deferred []uuid.UUID
+
+	for _, item := range items {
+		priceFired := item.NotifyPriceBelow != nil && available &&
+			product.Price < *item.NotifyPriceBelow && item.LastPrice >= *item.NotifyPriceBelow
+		stockFired := item.NotifyBackInStock && available && !item.LastAvailable
+		if !priceFired && !stockFired {
+			continue
+		}
+		if item.LastNotifiedAt != nil && now.Sub(*item.LastNotifiedAt) < wishlistNotifyCooldown {
+			deferred = append(deferred, item.ID)
+			continue
+		}
+		if priceFired {
+			if _, ok := priceDrop[item.UserID]; !ok {
+				priceDrop[item.UserID] = item
+			}
+		}
+		if stockFired {
+			if _, ok := backInStock[item.UserID]; !ok {
+				backInStock[item.UserID] = item
+			}
+		}
+		notified = append(notified, item.ID)
+	}
+
+	for userID, item := range priceDrop {
+		s.sendEmail(userID, "Sản phẩm trong danh sách yêu thích đã giảm giá", "./template/wishlist_price_drop.html", map[string]interface{}{
+			"ProductID":   product.ID,
+			"ProductName": product.Name,
+			"OldPrice":    item.LastPrice,
+			"NewPrice":    product.Price,
+			"Threshold":   *item.NotifyPriceBelow,
+		})
+	}
+	for userID := range backInStock {
+		s.sendEmail(userID, "Sản phẩm bạn yêu thích đã có hàng trở lại", "./template/wishlist_back_in_stock.html", map[string]interface{}{
+			"ProductID":   product.ID,
+			"ProductName": product.Name,
+			"Price":       product.Price,
+		})
+	}
+
+	if err := s.repo.MarkNotified(notified); err != nil {
+		log.Printf("failed to mark wishlist items notified: %v", err)
+	}
+	return s.repo.UpdateProductSnapshot(product.ID, product.Name, product.Price, available, deferred)
+}
+
+func (s *WishlistService) sendEmail(userID uuid.UUID, subject, template string, data map[string]interface{}) {
+	if s.publisher == nil {
+		return
+	}
+	payload := map[string]interface{}{
+		"to":       "",
+		"user_id":  userID.String(),
+		"subject":  subject,
+		"template": template,
+		"data":     data,
+	}
+	if err := s.publisher.Publish(EmailTopic, payload); err != nil {
+		log.Printf("failed to publish wishlist email for user %s: %v", userID, err)
+	}
+}
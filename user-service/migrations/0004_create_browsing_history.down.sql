@@ -0,0 +1 @@
+DROP TABLE IF EXISTS browsing_history;
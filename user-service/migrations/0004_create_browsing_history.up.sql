@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS browsing_history (
+    id BIGSERIAL PRIMARY KEY,
+    owner_type VARCHAR(10) NOT NULL,
+    owner_id VARCHAR(64) NOT NULL,
+    product_id VARCHAR(64) NOT NULL,
+    view_count INTEGER NOT NULL DEFAULT 1,
+    last_viewed_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_history_owner_product ON browsing_history(owner_type, owner_id, product_id);
+CREATE INDEX IF NOT EXISTS idx_history_owner_viewed ON browsing_history(owner_type, owner_id, last_viewed_at DESC);
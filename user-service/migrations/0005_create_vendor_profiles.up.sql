@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS vendor_profiles (
+    user_id UUID PRIMARY KEY,
+    display_name VARCHAR(100) NOT NULL,
+    logo_url VARCHAR(500),
+    banner_url VARCHAR(500),
+    description TEXT,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
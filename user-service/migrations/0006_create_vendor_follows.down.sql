@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS feed_digests;
+DROP TABLE IF EXISTS vendor_activities;
+DROP TABLE IF EXISTS vendor_product_states;
+DROP TABLE IF EXISTS vendor_follows;
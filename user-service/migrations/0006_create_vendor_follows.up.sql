@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS vendor_follows (
+    user_id UUID NOT NULL,
+    vendor_id VARCHAR(64) NOT NULL,
+    email_digest BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ,
+    PRIMARY KEY (user_id, vendor_id)
+);
+CREATE INDEX IF NOT EXISTS idx_vendor_follows_vendor_id ON vendor_follows(vendor_id);
+
+CREATE TABLE IF NOT EXISTS vendor_product_states (
+    product_id VARCHAR(64) PRIMARY KEY,
+    vendor_id VARCHAR(64) NOT NULL,
+    price NUMERIC NOT NULL,
+    regular_price NUMERIC,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS vendor_activities (
+    id BIGSERIAL PRIMARY KEY,
+    vendor_id VARCHAR(64) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    product_id VARCHAR(64) NOT NULL,
+    product_name VARCHAR(255),
+    image_url VARCHAR(500),
+    price NUMERIC,
+    previous_price NUMERIC,
+    event_key VARCHAR(128) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_vendor_activities_vendor ON vendor_activities(vendor_id, id);
+CREATE INDEX IF NOT EXISTS idx_vendor_activities_created_at ON vendor_activities(created_at);
+
+CREATE TABLE IF NOT EXISTS feed_digests (
+    user_id UUID PRIMARY KEY,
+    last_sent_at TIMESTAMPTZ NOT NULL
+);